@@ -2,12 +2,34 @@ package ai
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/onsi/ginkgo/v2"
 	"github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
 	utilversion "k8s.io/apimachinery/pkg/util/version"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
 	"k8s.io/kubernetes/test/e2e/framework"
+	e2econfig "k8s.io/kubernetes/test/e2e/framework/config"
+	e2edeployment "k8s.io/kubernetes/test/e2e/framework/deployment"
+	e2enode "k8s.io/kubernetes/test/e2e/framework/node"
+	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
 	e2eskipper "k8s.io/kubernetes/test/e2e/framework/skipper"
+	imageutils "k8s.io/kubernetes/test/utils/image"
+	admissionapi "k8s.io/pod-security-admission/api"
+	"k8s.io/utils/ptr"
 
 	frameworkutil "github.com/carlory/ai-conformance/e2e/util/framework"
 )
@@ -26,9 +48,1163 @@ var _ = WGDescribe("DRA Support", func() {
 		Description: The resources.k8s.io/v1 API group MUST be served by the API server.
 	*/
 	frameworkutil.AIConformanceIt("should support DRA", func(ctx context.Context) {
-		resources, err := f.ClientSet.Discovery().ServerResourcesForGroupVersion("resource.k8s.io/v1")
+		resources, err := frameworkutil.CachedDiscovery(f.ClientSet).ServerResourcesForGroupVersion("resource.k8s.io/v1")
 		framework.ExpectNoError(err)
 		gomega.Expect(resources).NotTo(gomega.BeNil())
 		gomega.Expect(resources.APIResources).NotTo(gomega.BeEmpty())
 	})
 })
+
+var dra struct {
+	DeviceClass string `default:"" usage:"DeviceClass whose devices are requested by the ResourceClaims of the DRA Support specs, e.g. gpu.nvidia.com. If unspecified, the first DeviceClass whose driver publishes devices with the productName attribute, e.g. accelerators, is used, or else the first one whose driver publishes devices with a string attribute"`
+	Driver      string `default:"" usage:"DRA driver whose devices are requested by the ResourceClaims of the DRA Support specs, e.g. gpu.nvidia.com, when multiple drivers are installed. Only the DeviceClasses selecting it and its ResourceSlices are considered. If unspecified, the drivers publishing accelerators are preferred, see -ai.dra.deviceClass"`
+}
+
+var _ = e2econfig.AddOptions(&dra, "ai.dra")
+
+var _ = WGDescribe("DRA Support", func() {
+	f := framework.NewDefaultFramework("dra-device-class")
+	f.NamespacePodSecurityLevel = admissionapi.LevelBaseline
+
+	ginkgo.BeforeEach(func(ctx context.Context) {
+		e2eskipper.SkipUnlessServerVersionGTE(utilversion.MustParseSemantic("v1.34.0"), f.ClientSet.Discovery())
+	})
+
+	/*
+		Release: v1.34
+		Testname: Dynamic Resource Allocation (DRA) with a DeviceClass
+		Description: Create a ResourceClaim requesting a device of a DeviceClass of the installed DRA drivers, narrowed
+		by a CEL selector on an attribute of a published device, and a pod using the claim. The pod MUST be running and
+		the claim MUST be allocated with a device of the driver of the DeviceClass and reserved for the pod.
+	*/
+	frameworkutil.AIConformanceIt("should allocate a device of a DeviceClass selected by a CEL expression", func(ctx context.Context) {
+		ns := f.Namespace.Name
+		request := newDRARequest(ctx, f)
+
+		ginkgo.By("Creating a ResourceClaim requesting a device of the DeviceClass")
+		claim, err := f.ClientSet.ResourceV1().ResourceClaims(ns).Create(ctx, newDeviceClassClaim("accelerator", request), metav1.CreateOptions{})
+		framework.ExpectNoError(err, "error when creating ResourceClaim")
+		frameworkutil.DeferCleanup(f.ClientSet.ResourceV1().ResourceClaims(ns).Delete, claim.Name, metav1.DeleteOptions{})
+
+		ginkgo.By("Creating a pod using the ResourceClaim")
+		pod := newClaimPod(ns, f.NamespacePodSecurityLevel, claim.Name)
+		pod, err = f.ClientSet.CoreV1().Pods(ns).Create(ctx, pod, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "error when creating pod")
+		frameworkutil.DeferCleanup(f.ClientSet.CoreV1().Pods(ns).Delete, pod.Name, metav1.DeleteOptions{})
+		err = e2epod.WaitForPodRunningInNamespace(ctx, f.ClientSet, pod)
+		framework.ExpectNoError(err, "error when waiting for pod to be running")
+
+		ginkgo.By("Verifying the ResourceClaim is allocated and reserved for the pod")
+		claim, err = f.ClientSet.ResourceV1().ResourceClaims(ns).Get(ctx, claim.Name, metav1.GetOptions{})
+		framework.ExpectNoError(err, "error when getting ResourceClaim")
+		gomega.Expect(claim.Status.Allocation).NotTo(gomega.BeNil(), "ResourceClaim %s is not allocated", claim.Name)
+		results := claim.Status.Allocation.Devices.Results
+		gomega.Expect(results).To(gomega.HaveLen(1), "ResourceClaim %s should be allocated with 1 device", claim.Name)
+		gomega.Expect(results[0].Driver).To(gomega.Equal(request.Driver), "the allocated device should be of the driver of DeviceClass %s", request.DeviceClassName)
+		gomega.Expect(claim.Status.ReservedFor).To(gomega.ContainElement(gomega.HaveField("UID", pod.UID)), "ResourceClaim %s should be reserved for pod %s", claim.Name, pod.Name)
+		framework.Logf("ResourceClaim %s is allocated with device %s/%s/%s", claim.Name, results[0].Driver, results[0].Pool, results[0].Device)
+	})
+})
+
+var _ = WGDescribe("DRA Support", func() {
+	f := framework.NewDefaultFramework("dra-deallocation")
+	f.NamespacePodSecurityLevel = admissionapi.LevelBaseline
+
+	ginkgo.BeforeEach(func(ctx context.Context) {
+		e2eskipper.SkipUnlessServerVersionGTE(utilversion.MustParseSemantic("v1.34.0"), f.ClientSet.Discovery())
+	})
+
+	// A DRA driver which leaks the allocations of the deleted pods makes its devices unusable until the claims are
+	// deleted.
+	frameworkutil.AIConformanceShouldIt("the device of a ResourceClaim should be deallocated and reusable after its pod is deleted", func(ctx context.Context) {
+		ns := f.Namespace.Name
+		request := newDRARequest(ctx, f)
+
+		ginkgo.By("Creating a pod using a ResourceClaim requesting a device of the DeviceClass")
+		claim, err := f.ClientSet.ResourceV1().ResourceClaims(ns).Create(ctx, newDeviceClassClaim("accelerator", request), metav1.CreateOptions{})
+		framework.ExpectNoError(err, "error when creating ResourceClaim")
+		frameworkutil.DeferCleanup(f.ClientSet.ResourceV1().ResourceClaims(ns).Delete, claim.Name, metav1.DeleteOptions{})
+		pod, err := f.ClientSet.CoreV1().Pods(ns).Create(ctx, newClaimPod(ns, f.NamespacePodSecurityLevel, claim.Name), metav1.CreateOptions{})
+		framework.ExpectNoError(err, "error when creating pod")
+		frameworkutil.DeferCleanup(e2epod.DeletePodWithWait, f.ClientSet, pod)
+		err = e2epod.WaitForPodRunningInNamespace(ctx, f.ClientSet, pod)
+		framework.ExpectNoError(err, "error when waiting for pod to be running")
+
+		claim, err = f.ClientSet.ResourceV1().ResourceClaims(ns).Get(ctx, claim.Name, metav1.GetOptions{})
+		framework.ExpectNoError(err, "error when getting ResourceClaim")
+		gomega.Expect(claim.Status.Allocation).NotTo(gomega.BeNil(), "ResourceClaim %s is not allocated", claim.Name)
+		gomega.Expect(claim.Status.Allocation.Devices.Results).To(gomega.HaveLen(1), "ResourceClaim %s should be allocated with 1 device", claim.Name)
+		device := claim.Status.Allocation.Devices.Results[0]
+		framework.Logf("ResourceClaim %s is allocated with device %s/%s/%s", claim.Name, device.Driver, device.Pool, device.Device)
+
+		ginkgo.By(fmt.Sprintf("Deleting pod %s", pod.Name))
+		err = e2epod.DeletePodWithWait(ctx, f.ClientSet, pod)
+		framework.ExpectNoError(err, "error when deleting pod %s", pod.Name)
+
+		// The resource claim controller removes the deleted pod from the consumers of the claim and deallocates
+		// the claim without consumers.
+		ginkgo.By(fmt.Sprintf("Waiting for ResourceClaim %s to be deallocated", claim.Name))
+		err = framework.Gomega().Eventually(ctx, func(ctx context.Context) error {
+			claim, err := f.ClientSet.ResourceV1().ResourceClaims(ns).Get(ctx, claim.Name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			if len(claim.Status.ReservedFor) > 0 {
+				return fmt.Errorf("ResourceClaim %s is still reserved for %v", claim.Name, claim.Status.ReservedFor)
+			}
+			if claim.Status.Allocation != nil {
+				return fmt.Errorf("ResourceClaim %s is still allocated with %v", claim.Name, claim.Status.Allocation.Devices.Results)
+			}
+			return nil
+		}).WithTimeout(f.Timeouts.PodDelete).WithPolling(framework.Poll).Should(gomega.Succeed())
+		framework.ExpectNoError(err, "error when waiting for ResourceClaim %s to be deallocated", claim.Name)
+
+		ginkgo.By(fmt.Sprintf("Verifying device %s/%s/%s is not allocated to any ResourceClaim", device.Driver, device.Pool, device.Device))
+		claims, err := f.ClientSet.ResourceV1().ResourceClaims(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+		framework.ExpectNoError(err, "error when listing ResourceClaims")
+		gomega.Expect(frameworkutil.ClaimsAllocatedDevice(claims.Items, device)).To(gomega.BeEmpty(),
+			"device %s/%s/%s should be deallocated", device.Driver, device.Pool, device.Device)
+
+		ginkgo.By("Creating a pod using a new ResourceClaim requesting a device of the DeviceClass")
+		reused, err := f.ClientSet.ResourceV1().ResourceClaims(ns).Create(ctx, newDeviceClassClaim("reused", request), metav1.CreateOptions{})
+		framework.ExpectNoError(err, "error when creating ResourceClaim")
+		frameworkutil.DeferCleanup(f.ClientSet.ResourceV1().ResourceClaims(ns).Delete, reused.Name, metav1.DeleteOptions{})
+		newPod, err := f.ClientSet.CoreV1().Pods(ns).Create(ctx, newClaimPod(ns, f.NamespacePodSecurityLevel, reused.Name), metav1.CreateOptions{})
+		framework.ExpectNoError(err, "error when creating pod")
+		frameworkutil.DeferCleanup(e2epod.DeletePodWithWait, f.ClientSet, newPod)
+		err = e2epod.WaitForPodRunningInNamespace(ctx, f.ClientSet, newPod)
+		framework.ExpectNoError(err, "error when waiting for pod to be running")
+
+		reused, err = f.ClientSet.ResourceV1().ResourceClaims(ns).Get(ctx, reused.Name, metav1.GetOptions{})
+		framework.ExpectNoError(err, "error when getting ResourceClaim")
+		gomega.Expect(reused.Status.Allocation).NotTo(gomega.BeNil(), "ResourceClaim %s is not allocated", reused.Name)
+		gomega.Expect(reused.Status.ReservedFor).To(gomega.ContainElement(gomega.HaveField("UID", newPod.UID)), "ResourceClaim %s should be reserved for pod %s", reused.Name, newPod.Name)
+		for _, result := range reused.Status.Allocation.Devices.Results {
+			framework.Logf("ResourceClaim %s is allocated with device %s/%s/%s", reused.Name, result.Driver, result.Pool, result.Device)
+		}
+	})
+})
+
+// newDRARequest returns the request for a device of the DeviceClass of -ai.dra.deviceClass and the driver of
+// -ai.dra.driver, see frameworkutil.NewDeviceClassRequest, or skips the test if none is found.
+func newDRARequest(ctx context.Context, f *framework.Framework) *frameworkutil.DeviceClassRequest {
+	classes, err := frameworkutil.ListDeviceClasses(ctx, f.ClientSet)
+	framework.ExpectNoError(err)
+	opts := metav1.ListOptions{}
+	if dra.Driver != "" {
+		opts.FieldSelector = resourceapi.ResourceSliceSelectorDriver + "=" + dra.Driver
+	}
+	resourceSlices, err := f.ClientSet.ResourceV1().ResourceSlices().List(ctx, opts)
+	framework.ExpectNoError(err, "error when listing ResourceSlices")
+	request := frameworkutil.NewDeviceClassRequest(classes, resourceSlices.Items, dra.DeviceClass, dra.Driver)
+	if request == nil {
+		if dra.Driver != "" {
+			e2eskipper.Skipf("None of the %d DeviceClasses selects driver %s which publishes devices with a string attribute", len(classes), dra.Driver)
+		}
+		e2eskipper.Skipf("None of the %d DeviceClasses selects a driver which publishes devices with a string attribute", len(classes))
+	}
+	framework.Logf("Requesting a device of DeviceClass %s of driver %s with selector %s, matching device %s", request.DeviceClassName, request.Driver, request.Selector, request.Device)
+	return request
+}
+
+// newDeviceClassClaim returns a ResourceClaim requesting a device of the DeviceClass of the request.
+func newDeviceClassClaim(name string, request *frameworkutil.DeviceClassRequest) *resourceapi.ResourceClaim {
+	return &resourceapi.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: resourceapi.ResourceClaimSpec{
+			Devices: resourceapi.DeviceClaim{
+				Requests: []resourceapi.DeviceRequest{
+					{
+						Name: "accelerator",
+						Exactly: &resourceapi.ExactDeviceRequest{
+							DeviceClassName: request.DeviceClassName,
+							Selectors: []resourceapi.DeviceSelector{
+								{CEL: &resourceapi.CELDeviceSelector{Expression: request.Selector}},
+							},
+							AllocationMode: resourceapi.DeviceAllocationModeExactCount,
+							Count:          1,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// newClaimPod returns a pod using the ResourceClaim, which tolerates the NoSchedule taints of the accelerator nodes.
+func newClaimPod(ns string, level admissionapi.Level, claimName string) *v1.Pod {
+	pod := e2epod.MakePod(ns, nil, nil, level, "")
+	pod.Spec.Tolerations = []v1.Toleration{
+		{
+			Effect:   v1.TaintEffectNoSchedule,
+			Operator: v1.TolerationOpExists,
+		},
+	}
+	pod.Spec.ResourceClaims = []v1.PodResourceClaim{{Name: "accelerator", ResourceClaimName: &claimName}}
+	pod.Spec.Containers[0].Resources.Claims = []v1.ResourceClaim{{Name: "accelerator"}}
+	return pod
+}
+
+var acceleratorHealth struct {
+	UnhealthyNodes string `default:"" usage:"comma-separated names of the nodes which are known to have unhealthy accelerators, e.g. because of a pending hardware replacement. Their unhealthy accelerators are logged instead of failing the test"`
+}
+
+var _ = e2econfig.AddOptions(&acceleratorHealth, "ai.acceleratorHealth")
+
+var _ = WGDescribe("Accelerator Health", func() {
+	f := framework.NewDefaultFramework("accelerator-health")
+	f.SkipNamespaceCreation = true
+
+	var vendor *frameworkutil.AcceleratorVendor
+
+	ginkgo.BeforeEach(func(ctx context.Context) {
+		nodes, err := e2enode.GetReadyNodesIncludingTainted(ctx, f.ClientSet)
+		framework.ExpectNoError(err, "error when listing ready nodes")
+		vendor = frameworkutil.DetectAcceleratorVendor(nodes.Items)
+		if vendor == nil {
+			e2eskipper.Skipf("none of the %d ready nodes advertises %v", len(nodes.Items), acceleratorResourceNames())
+		}
+	})
+
+	/*
+		Release: v1.34
+		Testname: Accelerator Health, device plugin
+		Description: The kubelet MUST exclude the accelerators reported unhealthy by the device plugin from the
+		allocatable of the node. The allocatable accelerators of the ready accelerator nodes MUST be equal to their
+		capacity, unless the node is cordoned or specified by -ai.acceleratorHealth.unhealthyNodes, in which case
+		the unhealthy accelerators are logged.
+	*/
+	frameworkutil.AIConformanceIt("allocatable accelerators should be equal to the capacity on healthy nodes", func(ctx context.Context) {
+		nodes, err := e2enode.GetReadyNodesIncludingTainted(ctx, f.ClientSet)
+		framework.ExpectNoError(err, "error when listing ready nodes")
+		unhealthy, err := frameworkutil.UnhealthyAccelerators(nodes.Items, vendor.ResourceName)
+		framework.ExpectNoError(err, "error when counting unhealthy accelerators")
+
+		knownUnhealthyNodes := sets.New[string]()
+		for _, name := range strings.Split(acceleratorHealth.UnhealthyNodes, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				knownUnhealthyNodes.Insert(name)
+			}
+		}
+
+		var failures []string
+		for _, node := range nodes.Items {
+			n, ok := unhealthy[node.Name]
+			if !ok {
+				continue
+			}
+			capacity := node.Status.Capacity[vendor.ResourceName]
+			allocatable := node.Status.Allocatable[vendor.ResourceName]
+			msg := fmt.Sprintf("node %s has %d unhealthy %s: capacity %s, allocatable %s", node.Name, n, vendor.ResourceName, capacity.String(), allocatable.String())
+			switch {
+			case node.Spec.Unschedulable:
+				framework.Logf("%s, ignored because the node is cordoned", msg)
+			case knownUnhealthyNodes.Has(node.Name):
+				framework.Logf("%s, ignored because the node is specified by -ai.acceleratorHealth.unhealthyNodes", msg)
+			default:
+				failures = append(failures, msg)
+			}
+		}
+		if len(failures) > 0 {
+			framework.Failf("allocatable %s is below the capacity without a known reason:\n%s", vendor.ResourceName, strings.Join(failures, "\n"))
+		}
+	})
+
+	// The device plugin should advertise the accelerators in the capacity and allocatable of every ready accelerator
+	// node within -ai.accelerator.capacityTimeout after the node becomes Ready, otherwise the pods requesting
+	// accelerators can't be scheduled to a node which is reported Ready, e.g. a node provisioned by the cluster
+	// autoscaler. The node status doesn't record when the accelerators were first advertised, so only the nodes
+	// which don't advertise them yet are verified, and the accelerator nodes can't be told apart from the other ones
+	// unless they are selected by -ai.accelerator.nodeSelector. The spec is skipped if it's unspecified.
+	frameworkutil.AIConformanceShouldIt("accelerators should be advertised by the ready accelerator nodes in time", func(ctx context.Context) {
+		if !frameworkutil.AcceleratorNodeSelectorSpecified() {
+			e2eskipper.Skipf("The accelerator nodes which don't advertise %s yet can't be identified, specify them via -ai.accelerator.nodeSelector", vendor.ResourceName)
+		}
+		nodes, err := e2enode.GetReadyNodesIncludingTainted(ctx, f.ClientSet)
+		framework.ExpectNoError(err, "error when listing ready nodes")
+		for _, node := range nodes.Items {
+			candidate, err := frameworkutil.IsAcceleratorNodeCandidate(&node, vendor.ResourceName)
+			framework.ExpectNoError(err)
+			if !candidate {
+				continue
+			}
+			ginkgo.By(fmt.Sprintf("Waiting for node %s to advertise %s", node.Name, vendor.ResourceName))
+			err = frameworkutil.WaitForAcceleratorCapacity(ctx, f.ClientSet, node.Name, vendor.ResourceName)
+			framework.ExpectNoError(err)
+		}
+	})
+
+	// A broken device plugin may advertise quantities which the API server accepts but which don't count whole
+	// devices, e.g. fractional or negative ones, or an allocatable above the capacity, which are silently rounded
+	// when the accelerators are counted. The capacity and the allocatable of the accelerators on the ready
+	// accelerator nodes should be non-negative integers, and the allocatable should not exceed the capacity.
+	frameworkutil.AIConformanceShouldIt("accelerator capacity and allocatable should be well-formed quantities on the ready nodes", func(ctx context.Context) {
+		nodes, err := e2enode.GetReadyNodesIncludingTainted(ctx, f.ClientSet)
+		framework.ExpectNoError(err, "error when listing ready nodes")
+		malformed, err := frameworkutil.MalformedAcceleratorQuantities(nodes.Items, vendor.ResourceName)
+		framework.ExpectNoError(err, "error when checking the quantities of %s", vendor.ResourceName)
+
+		var failures []string
+		for _, node := range nodes.Items {
+			if reason, ok := malformed[node.Name]; ok {
+				failures = append(failures, fmt.Sprintf("node %s: %s", node.Name, reason))
+			}
+		}
+		if len(failures) > 0 {
+			framework.Failf("%s is malformed on %d nodes:\n%s", vendor.ResourceName, len(failures), strings.Join(failures, "\n"))
+		}
+	})
+})
+
+var _ = WGDescribe("Device Plugin Resilience", func() {
+	f := framework.NewDefaultFramework("device-plugin-resilience")
+	f.NamespacePodSecurityLevel = admissionapi.LevelPrivileged
+
+	// A long-running pod requesting 1 accelerator with a readiness probe which accesses the accelerator is created,
+	// and the pod of the device plugin DaemonSet on its node is restarted. The DaemonSet is identified by
+	// -ai.accelerator.devicePluginSelector or the well-known labels of the device plugins of the vendor, and the spec
+	// is skipped if it can't be identified. The restart disrupts the other accelerator workloads on the node, so the
+	// spec is disruptive and serial, and it's not required by the conformance. After the device plugin is ready
+	// again, the pod should keep running without a restart and stay ready, should still access the same accelerator,
+	// and the allocatable accelerators of the node should return to the count before the restart.
+	frameworkutil.AIConformanceShouldIt("running pods should keep their accelerators when the device plugin restarts", framework.WithDisruptive(), framework.WithSerial(), func(ctx context.Context) {
+		ns := f.Namespace.Name
+		vendor := skipUnlessAcceleratorAllocatable(ctx, f.ClientSet)
+		lockAccelerators(ctx, f, vendor.ResourceName)
+		verifyAcceleratorsReleased(ctx, f, vendor.ResourceName)
+
+		ginkgo.By(fmt.Sprintf("Creating a long-running pod requesting 1 %s", vendor.ResourceName))
+		pod := e2epod.MakePod(ns, nil, nil, f.NamespacePodSecurityLevel, "")
+		pod.Spec.Containers[0].Resources.Limits = v1.ResourceList{
+			vendor.ResourceName: resource.MustParse("1"),
+		}
+		pod.Spec.Containers[0].ReadinessProbe = &v1.Probe{
+			ProbeHandler: v1.ProbeHandler{
+				Exec: &v1.ExecAction{Command: []string{"/bin/sh", "-c", vendor.DeviceCheckCommand}},
+			},
+			PeriodSeconds: 5,
+		}
+		requireAcceleratorNode(ctx, f.ClientSet, &pod.Spec, vendor.ResourceName)
+		pod, err := f.ClientSet.CoreV1().Pods(ns).Create(ctx, pod, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "error when creating pod")
+		frameworkutil.DeferCleanup(f.ClientSet.CoreV1().Pods(ns).Delete, pod.Name, metav1.DeleteOptions{})
+		err = e2epod.WaitTimeoutForPodReadyInNamespace(ctx, f.ClientSet, pod.Name, ns, f.Timeouts.PodStart)
+		framework.ExpectNoError(err, "error when waiting for pod to be ready")
+		pod, err = f.ClientSet.CoreV1().Pods(ns).Get(ctx, pod.Name, metav1.GetOptions{})
+		framework.ExpectNoError(err, "error when getting pod")
+		nodeName := pod.Spec.NodeName
+		devicesBefore := e2epod.ExecShellInPod(ctx, f, pod.Name, vendor.DeviceCheckCommand)
+		framework.Logf("pod %s output:\n %s", pod.Name, devicesBefore)
+
+		devicePluginPod, err := frameworkutil.DevicePluginPod(ctx, f.ClientSet, *vendor, nodeName)
+		framework.ExpectNoError(err, "error when finding the device plugin on node %s", nodeName)
+		if devicePluginPod == nil {
+			e2eskipper.Skipf("The device plugin of %s on node %s can't be identified, specify its DaemonSet via -ai.accelerator.devicePluginSelector", vendor.ResourceName, nodeName)
+		}
+		node, err := f.ClientSet.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+		framework.ExpectNoError(err, "error when getting node %s", nodeName)
+		allocatableBefore := node.Status.Allocatable[vendor.ResourceName]
+
+		ginkgo.By(fmt.Sprintf("Restarting device plugin pod %s/%s on node %s", devicePluginPod.Namespace, devicePluginPod.Name, nodeName))
+		_, err = frameworkutil.RestartDevicePlugin(ctx, f.ClientSet, devicePluginPod, f.Timeouts.PodStart)
+		framework.ExpectNoError(err)
+
+		ginkgo.By(fmt.Sprintf("Waiting for the allocatable %s of node %s to return to %s", vendor.ResourceName, nodeName, allocatableBefore.String()))
+		err = framework.Gomega().Eventually(ctx, func(ctx context.Context) error {
+			node, err := f.ClientSet.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			allocatable := node.Status.Allocatable[vendor.ResourceName]
+			if !allocatable.Equal(allocatableBefore) {
+				return fmt.Errorf("allocatable %s of node %s is %s, want %s", vendor.ResourceName, nodeName, allocatable.String(), allocatableBefore.String())
+			}
+			return nil
+		}).WithTimeout(f.Timeouts.PodStart).WithPolling(framework.Poll).Should(gomega.Succeed())
+		framework.ExpectNoError(err, "error when waiting for the allocatable %s of node %s", vendor.ResourceName, nodeName)
+
+		ginkgo.By("Verifying the pod keeps running with its accelerator")
+		// The readiness probe runs a few times after the restart of the device plugin.
+		probePeriod := time.Duration(pod.Spec.Containers[0].ReadinessProbe.PeriodSeconds) * time.Second
+		err = framework.Gomega().Consistently(ctx, func(ctx context.Context) error {
+			pod, err := f.ClientSet.CoreV1().Pods(ns).Get(ctx, pod.Name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			if pod.Status.Phase != v1.PodRunning || !podutil.IsPodReady(pod) {
+				return fmt.Errorf("pod %s is %s and not ready, conditions: %v", pod.Name, pod.Status.Phase, pod.Status.Conditions)
+			}
+			if restarts := pod.Status.ContainerStatuses[0].RestartCount; restarts > 0 {
+				return fmt.Errorf("pod %s restarted %d times", pod.Name, restarts)
+			}
+			return nil
+		}).WithTimeout(3 * probePeriod).WithPolling(framework.Poll).Should(gomega.Succeed())
+		framework.ExpectNoError(err, "pod %s should keep running and ready", pod.Name)
+		devicesAfter := e2epod.ExecShellInPod(ctx, f, pod.Name, vendor.DeviceCheckCommand)
+		gomega.Expect(devicesAfter).To(gomega.Equal(devicesBefore), "pod %s should access the same accelerator", pod.Name)
+	})
+})
+
+var _ = WGDescribe("Device Plugin Registration", func() {
+	f := framework.NewDefaultFramework("device-plugin-registration")
+	f.NamespacePodSecurityLevel = admissionapi.LevelPrivileged
+
+	// The device plugins registered on the accelerator nodes and the resources they register are reported for
+	// information, via the extended resources of the node status and the sockets and the checkpoint in the device
+	// plugins directory of the kubelet. The kubelet only accepts the registrations at the v1beta1 DevicePlugin API,
+	// so a registered device plugin serves that version. Each ready node with the accelerator resource in its
+	// capacity MUST have it allocatable, and MUST have it registered in the checkpoint if the checkpoint is readable.
+	frameworkutil.AIConformanceShouldIt("accelerator nodes should have an accelerator device plugin registered", func(ctx context.Context) {
+		ns := f.Namespace.Name
+		nodes, err := e2enode.GetReadyNodesIncludingTainted(ctx, f.ClientSet)
+		framework.ExpectNoError(err, "error when listing ready nodes")
+		vendor := frameworkutil.DetectAcceleratorVendor(nodes.Items)
+		if vendor == nil {
+			e2eskipper.Skipf("%d ready nodes do not have any GPU(s) of the supported vendors. Skipping...", len(nodes.Items))
+		}
+
+		var report, problems []string
+		for i := range nodes.Items {
+			node := &nodes.Items[i]
+			if _, ok := node.Status.Capacity[vendor.ResourceName]; !ok {
+				continue
+			}
+			ginkgo.By(fmt.Sprintf("Reading the device plugins registered on node %s", node.Name))
+			registration, err := frameworkutil.ReadDevicePluginRegistration(ctx, f.ClientSet, ns, node.Name)
+			framework.ExpectNoError(err, "error when reading the device plugins on node %s", node.Name)
+			registered := "unknown, the checkpoint is unavailable"
+			if registration.RegisteredDevices != nil {
+				registered = fmt.Sprintf("%v", registration.ResourceNames())
+			}
+			report = append(report, fmt.Sprintf("node %s: extended resources (allocatable/capacity) %v, device plugin sockets %v, registered resources %s",
+				node.Name, frameworkutil.ExtendedResources(node), registration.PluginSockets(), registered))
+
+			if allocatable := node.Status.Allocatable[vendor.ResourceName]; allocatable.IsZero() {
+				problems = append(problems, fmt.Sprintf("node %s has %s in its capacity, but none is allocatable", node.Name, vendor.ResourceName))
+			}
+			if registration.RegisteredDevices != nil && len(registration.RegisteredDevices[string(vendor.ResourceName)]) == 0 {
+				problems = append(problems, fmt.Sprintf("node %s has %s in its capacity, but no device plugin registers it, registered resources: %s",
+					node.Name, vendor.ResourceName, registered))
+			}
+		}
+		ginkgo.AddReportEntry("device plugins", report)
+		framework.Logf("Device plugins of %s:\n%s", vendor.ResourceName, strings.Join(report, "\n"))
+		gomega.Expect(problems).To(gomega.BeEmpty(), "the accelerator nodes should have a device plugin of %s registered", vendor.ResourceName)
+	})
+})
+
+var fractionalAccelerator struct {
+	Resources         string `default:"" usage:"comma-separated <resource>=<quantity> entries requested by the pod of the Fractional Accelerators spec, e.g. nvidia.com/gpu=1,nvidia.com/gpumem=3000. If unspecified, 1 of the first advertised of nvidia.com/gpu.shared, aliyun.com/gpu-mem and the MIG devices is requested"`
+	MemoryTestImage   string `default:"docker.io/pytorch/pytorch:2.5.1-cuda12.4-cudnn9-runtime" usage:"image of the pods of the GPU memory limit spec, which runs ai.fractionalAccelerator.memoryTestCommand, e.g. an image with ROCm for the AMD GPUs"`
+	MemoryTestCommand string `default:"python3 -c 'import sys, torch; torch.empty(int(sys.argv[1]) << 20, dtype=torch.uint8, device=0); print(sys.argv[1], \"MiB allocated\")' $ALLOCATE_MIB" usage:"shell command of the pods of the GPU memory limit spec which allocates $ALLOCATE_MIB MiB of the GPU memory, and exits with 0 only if the allocation succeeds"`
+}
+
+var _ = e2econfig.AddOptions(&fractionalAccelerator, "ai.fractionalAccelerator")
+
+var _ = WGDescribe("Fractional Accelerators", func() {
+	f := framework.NewDefaultFramework("fractional-accelerators")
+	f.NamespacePodSecurityLevel = admissionapi.LevelPrivileged
+
+	// Sharing the GPUs via fractional resources, e.g. time-slicing, MIG or GPU memory, is recommended for the
+	// inference workloads, but it's not required by the conformance. The pod requesting a fractional GPU MUST run
+	// and see a single GPU, and a single MIG device if a MIG device is requested.
+	frameworkutil.AIConformanceShouldIt("a pod requesting a fractional GPU should run with a constrained view of the GPUs", func(ctx context.Context) {
+		ns := f.Namespace.Name
+		nodes, err := e2enode.GetReadyNodesIncludingTainted(ctx, f.ClientSet)
+		framework.ExpectNoError(err, "error when listing ready nodes")
+		resources, resourceNames := skipUnlessFractionalAcceleratorAllocatable(nodes.Items)
+		lockAccelerators(ctx, f, resourceNames[0])
+		verifyAcceleratorsReleased(ctx, f, resourceNames[0])
+
+		ginkgo.By(fmt.Sprintf("Creating a pod requesting %v", resources))
+		pod := e2epod.MakePod(ns, nil, nil, f.NamespacePodSecurityLevel, "")
+		pod.Spec.Containers[0].Resources.Limits = resources
+		requireAcceleratorNode(ctx, f.ClientSet, &pod.Spec, resourceNames[0])
+		pod, err = f.ClientSet.CoreV1().Pods(ns).Create(ctx, pod, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "error when creating pod")
+		frameworkutil.DeferCleanup(f.ClientSet.CoreV1().Pods(ns).Delete, pod.Name, metav1.DeleteOptions{})
+		err = e2epod.WaitForPodRunningInNamespace(ctx, f.ClientSet, pod)
+		framework.ExpectNoError(err, "error when waiting for pod to be running")
+
+		ginkgo.By("Verifying the pod sees a single GPU")
+		out := e2epod.ExecShellInPod(ctx, f, pod.Name, "nvidia-smi -L")
+		framework.Logf("pod %s output:\n %s", pod.Name, out)
+		gpus, migDevices := frameworkutil.VisibleNVIDIAGPUs(out)
+		gomega.Expect(gpus).To(gomega.Equal(1), "pod %s should see a single GPU", pod.Name)
+		if slices.ContainsFunc(resourceNames, frameworkutil.IsMIGResource) {
+			gomega.Expect(migDevices).To(gomega.Equal(1), "pod %s should see a single MIG device", pod.Name)
+		}
+	})
+
+	// Partitioning the GPU memory, e.g. via MIG or GPU memory resources, is recommended to isolate the workloads
+	// sharing a GPU, but it's not required by the conformance. The spec is skipped unless the fractional resources
+	// partition the memory. A pod of -ai.fractionalAccelerator.memoryTestImage allocating half of the memory of its
+	// partition MUST succeed, and a pod allocating 1 GiB more than the memory of its partition MUST fail instead of
+	// consuming the memory of its neighbors.
+	frameworkutil.AIConformanceShouldIt("a pod should not allocate more GPU memory than its fractional allocation permits", func(ctx context.Context) {
+		ns := f.Namespace.Name
+		nodes, err := e2enode.GetReadyNodesIncludingTainted(ctx, f.ClientSet)
+		framework.ExpectNoError(err, "error when listing ready nodes")
+		resources, resourceNames := skipUnlessFractionalAcceleratorAllocatable(nodes.Items)
+		partition := frameworkutil.AcceleratorMemoryPartitionMiB(resources)
+		if partition == 0 {
+			e2eskipper.Skipf("The fractional resources %v don't partition the GPU memory, request a MIG device or a GPU memory resource via -ai.fractionalAccelerator.resources", resources)
+		}
+		lockAccelerators(ctx, f, resourceNames[0])
+		verifyAcceleratorsReleased(ctx, f, resourceNames[0])
+
+		runAllocation := func(mib int64) *v1.Pod {
+			ginkgo.By(fmt.Sprintf("Creating a pod requesting %v which allocates %d MiB of the GPU memory", resources, mib))
+			pod := e2epod.MakePod(ns, nil, nil, f.NamespacePodSecurityLevel, fractionalAccelerator.MemoryTestCommand)
+			pod.Spec.RestartPolicy = v1.RestartPolicyNever
+			pod.Spec.Containers[0].Image = fractionalAccelerator.MemoryTestImage
+			// A privileged container may access the devices of the whole GPU, bypassing the partition.
+			pod.Spec.Containers[0].SecurityContext = e2epod.GenerateContainerSecurityContext(admissionapi.LevelBaseline)
+			pod.Spec.Containers[0].Env = []v1.EnvVar{{Name: "ALLOCATE_MIB", Value: strconv.FormatInt(mib, 10)}}
+			pod.Spec.Containers[0].Resources.Limits = resources
+			requireAcceleratorNode(ctx, f.ClientSet, &pod.Spec, resourceNames[0])
+			pod, err := f.ClientSet.CoreV1().Pods(ns).Create(ctx, pod, metav1.CreateOptions{})
+			framework.ExpectNoError(err, "error when creating pod")
+			frameworkutil.DeferCleanup(e2epod.DeletePodWithWait, f.ClientSet, pod)
+			err = e2epod.WaitForPodCondition(ctx, f.ClientSet, ns, pod.Name, "terminated", f.Timeouts.PodStartSlow, func(pod *v1.Pod) (bool, error) {
+				return pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed, nil
+			})
+			framework.ExpectNoError(err, "error when waiting for pod %s to terminate", pod.Name)
+			pod, err = f.ClientSet.CoreV1().Pods(ns).Get(ctx, pod.Name, metav1.GetOptions{})
+			framework.ExpectNoError(err, "error when getting pod %s", pod.Name)
+			output, err := e2epod.GetPodLogs(ctx, f.ClientSet, ns, pod.Name, pod.Spec.Containers[0].Name)
+			framework.ExpectNoError(err, "error when getting logs of pod %s", pod.Name)
+			framework.Logf("pod %s is %s, output:\n %s", pod.Name, pod.Status.Phase, output)
+			return pod
+		}
+
+		// The allocation within the partition verifies the test command works, so that the failure of the
+		// over-allocation can be attributed to the limit.
+		pod := runAllocation(partition / 2)
+		gomega.Expect(pod.Status.Phase).To(gomega.Equal(v1.PodSucceeded), "pod %s should allocate %d MiB within its partition of %d MiB", pod.Name, partition/2, partition)
+
+		pod = runAllocation(partition + 1024)
+		gomega.Expect(pod.Status.Phase).To(gomega.Equal(v1.PodFailed), "pod %s should fail to allocate %d MiB beyond its partition of %d MiB", pod.Name, partition+1024, partition)
+	})
+})
+
+// skipUnlessFractionalAcceleratorAllocatable returns the resources configured by -ai.fractionalAccelerator.resources,
+// or 1 of the fractional accelerator resource detected from the given nodes if it's unspecified, with their names in
+// order. The spec is skipped if none of the nodes advertises them.
+func skipUnlessFractionalAcceleratorAllocatable(nodes []v1.Node) (v1.ResourceList, []v1.ResourceName) {
+	resources, err := frameworkutil.ParseResourceList(fractionalAccelerator.Resources)
+	framework.ExpectNoError(err, "error when parsing -ai.fractionalAccelerator.resources")
+	if len(resources) == 0 {
+		resourceName := frameworkutil.DetectFractionalAcceleratorResource(nodes)
+		if resourceName == "" {
+			e2eskipper.Skipf("None of the %d ready nodes advertises %v or a MIG device", len(nodes), frameworkutil.FractionalAcceleratorResources)
+		}
+		resources = v1.ResourceList{resourceName: resource.MustParse("1")}
+	}
+	var resourceNames []v1.ResourceName
+	for resourceName := range resources {
+		if !slices.ContainsFunc(nodes, func(node v1.Node) bool {
+			val, ok := node.Status.Allocatable[resourceName]
+			return ok && !val.IsZero()
+		}) {
+			e2eskipper.Skipf("None of the %d ready nodes advertises %s", len(nodes), resourceName)
+		}
+		resourceNames = append(resourceNames, resourceName)
+	}
+	slices.Sort(resourceNames)
+	return resources, resourceNames
+}
+
+var gracefulTermination struct {
+	GracePeriod time.Duration `default:"30s" usage:"termination grace period of the accelerator workload of the Graceful Termination spec, which takes 2 seconds to drain after SIGTERM"`
+}
+
+var _ = e2econfig.AddOptions(&gracefulTermination, "ai.gracefulTermination")
+
+// gracefulTerminationFinalizer keeps the deleted pod of the Graceful Termination spec until its termination status
+// is verified.
+const gracefulTerminationFinalizer = "e2e.ai-conformance/graceful-termination"
+
+var _ = WGDescribe("Graceful Termination", func() {
+	f := framework.NewDefaultFramework("graceful-termination")
+	f.NamespacePodSecurityLevel = admissionapi.LevelBaseline
+
+	/*
+		Release: v1.34
+		Testname: Graceful Termination, accelerator workload
+		Description: Create a pod requesting 1 accelerator which drains in 2 seconds after SIGTERM and exits with 0,
+		with the termination grace period of -ai.gracefulTermination.gracePeriod, and delete it. Its container MUST
+		exit with 0 within the grace period instead of being killed by SIGKILL, and the available accelerators MUST
+		return to the count before the pod within -ai.accelerator.releaseTimeout after the pod is removed.
+	*/
+	frameworkutil.AIConformanceIt("accelerator workloads should terminate gracefully and release their accelerators", func(ctx context.Context) {
+		ns := f.Namespace.Name
+		vendor := skipUnlessAcceleratorAllocatable(ctx, f.ClientSet)
+		lockAccelerators(ctx, f, vendor.ResourceName)
+		baseline, err := frameworkutil.CountAccelerators(ctx, f.ClientSet, vendor.ResourceName)
+		framework.ExpectNoError(err, "error when counting %s", vendor.ResourceName)
+
+		ginkgo.By(fmt.Sprintf("Creating a pod requesting 1 %s which drains after SIGTERM", vendor.ResourceName))
+		pod := e2epod.MakePod(ns, nil, nil, f.NamespacePodSecurityLevel,
+			`trap 'echo draining; sleep 2; echo terminated gracefully | tee /dev/termination-log; exit 0' TERM; echo running; while true; do sleep 1; done`)
+		pod.Finalizers = []string{gracefulTerminationFinalizer}
+		pod.Spec.RestartPolicy = v1.RestartPolicyNever
+		pod.Spec.TerminationGracePeriodSeconds = ptr.To(int64(gracefulTermination.GracePeriod.Seconds()))
+		pod.Spec.Containers[0].Resources.Limits = v1.ResourceList{
+			vendor.ResourceName: resource.MustParse("1"),
+		}
+		requireAcceleratorNode(ctx, f.ClientSet, &pod.Spec, vendor.ResourceName)
+		pod, err = f.ClientSet.CoreV1().Pods(ns).Create(ctx, pod, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "error when creating pod")
+		frameworkutil.DeferCleanup(e2epod.DeletePodWithWait, f.ClientSet, pod)
+		frameworkutil.DeferCleanup(frameworkutil.RemovePodFinalizer, f.ClientSet, ns, pod.Name, gracefulTerminationFinalizer)
+		err = e2epod.WaitForPodRunningInNamespace(ctx, f.ClientSet, pod)
+		framework.ExpectNoError(err, "error when waiting for pod to be running")
+
+		ginkgo.By(fmt.Sprintf("Deleting the pod with the grace period of %v", gracefulTermination.GracePeriod))
+		err = f.ClientSet.CoreV1().Pods(ns).Delete(ctx, pod.Name, metav1.DeleteOptions{})
+		framework.ExpectNoError(err, "error when deleting pod %s", pod.Name)
+		err = e2epod.WaitForPodCondition(ctx, f.ClientSet, ns, pod.Name, "terminated", gracefulTermination.GracePeriod+f.Timeouts.PodDelete, func(pod *v1.Pod) (bool, error) {
+			return pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed, nil
+		})
+		framework.ExpectNoError(err, "error when waiting for pod %s to terminate", pod.Name)
+
+		ginkgo.By("Verifying the pod terminated gracefully within the grace period")
+		pod, err = f.ClientSet.CoreV1().Pods(ns).Get(ctx, pod.Name, metav1.GetOptions{})
+		framework.ExpectNoError(err, "error when getting pod %s", pod.Name)
+		err = frameworkutil.VerifyGracefulTermination(pod)
+		framework.ExpectNoError(err, "pod %s should terminate gracefully", pod.Name)
+
+		ginkgo.By("Verifying the accelerator is released once the pod is removed")
+		err = frameworkutil.RemovePodFinalizer(ctx, f.ClientSet, ns, pod.Name, gracefulTerminationFinalizer)
+		framework.ExpectNoError(err)
+		err = e2epod.WaitForPodNotFoundInNamespace(ctx, f.ClientSet, pod.Name, ns, f.Timeouts.PodDelete)
+		framework.ExpectNoError(err, "error when waiting for pod %s to be removed", pod.Name)
+		err = frameworkutil.WaitForAcceleratorsReleased(ctx, f.ClientSet, vendor.ResourceName, baseline)
+		framework.ExpectNoError(err)
+	})
+})
+
+var _ = WGDescribe("Accelerator Disruption", func() {
+	f := framework.NewDefaultFramework("accelerator-disruption")
+	f.NamespacePodSecurityLevel = admissionapi.LevelBaseline
+
+	// Draining a node evicts its pods through the eviction API, which honors the PodDisruptionBudgets, so an
+	// inference Deployment on the accelerators stays available during the voluntary disruptions. The eviction of
+	// the only replica of a Deployment requesting 1 accelerator, covered by a PodDisruptionBudget with minAvailable
+	// 1, MUST be rejected with 429 and the replica MUST keep running. Once minAvailable is lowered to 0, the eviction
+	// MUST succeed and the Deployment MUST recover its replica on the accelerators. The test is skipped if the
+	// evictions are forbidden.
+	frameworkutil.AIConformanceShouldIt("a pod disruption budget should keep the accelerator workloads available during evictions", func(ctx context.Context) {
+		ns := f.Namespace.Name
+		name := "inference"
+		vendor := skipUnlessAcceleratorAllocatable(ctx, f.ClientSet)
+		lockAccelerators(ctx, f, vendor.ResourceName)
+		verifyAcceleratorsReleased(ctx, f, vendor.ResourceName)
+		frameworkutil.RequireAvailableAccelerators(ctx, f.ClientSet, vendor.ResourceName, 1, false)
+
+		ginkgo.By(fmt.Sprintf("Creating a Deployment requesting 1 %s", vendor.ResourceName))
+		podLabels := map[string]string{"app": name}
+		d := e2edeployment.NewDeployment(name, 1, podLabels, "main", imageutils.GetE2EImage(imageutils.BusyBox), appsv1.RecreateDeploymentStrategyType)
+		d.Spec.Template.Spec.Containers[0].Command = []string{"/bin/sh", "-c", "trap exit TERM; while true; do sleep 1; done"}
+		d.Spec.Template.Spec.Containers[0].Resources.Limits = v1.ResourceList{
+			vendor.ResourceName: resource.MustParse("1"),
+		}
+		requireAcceleratorNode(ctx, f.ClientSet, &d.Spec.Template.Spec, vendor.ResourceName)
+		d, err := f.ClientSet.AppsV1().Deployments(ns).Create(ctx, d, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "error when creating deployment")
+		frameworkutil.DeferCleanup(f.ClientSet.AppsV1().Deployments(ns).Delete, d.Name, metav1.DeleteOptions{})
+		err = frameworkutil.WaitForDeploymentComplete(ctx, f.ClientSet, d, f.Timeouts.PodStart)
+		framework.ExpectNoError(err, "error when waiting for deployment to complete")
+
+		ginkgo.By("Creating a PodDisruptionBudget with minAvailable 1 for the Deployment")
+		pdb := &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				MinAvailable: ptr.To(intstr.FromInt32(1)),
+				Selector:     &metav1.LabelSelector{MatchLabels: podLabels},
+			},
+		}
+		_, err = f.ClientSet.PolicyV1().PodDisruptionBudgets(ns).Create(ctx, pdb, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "error when creating pod disruption budget")
+		pdb, err = frameworkutil.WaitForPodDisruptionBudgetObserved(ctx, f.ClientSet, ns, name, f.Timeouts.PodStart)
+		framework.ExpectNoError(err)
+		gomega.Expect(pdb.Status.DisruptionsAllowed).To(gomega.BeZero(), "pod disruption budget %s should allow no disruption", name)
+
+		pods, err := e2edeployment.GetPodsForDeployment(ctx, f.ClientSet, d)
+		framework.ExpectNoError(err, "error when getting pods of deployment")
+		gomega.Expect(pods.Items).To(gomega.HaveLen(1), "deployment should have exactly 1 pod")
+		pod := pods.Items[0]
+		eviction := &policyv1.Eviction{ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: ns}}
+
+		ginkgo.By(fmt.Sprintf("Evicting pod %s as a node drain would", pod.Name))
+		err = f.ClientSet.PolicyV1().Evictions(ns).Evict(ctx, eviction)
+		if apierrors.IsForbidden(err) {
+			e2eskipper.Skipf("Evicting pod %s is forbidden: %v", pod.Name, err)
+		}
+		gomega.Expect(apierrors.IsTooManyRequests(err)).To(gomega.BeTrue(), "eviction should be rejected by the pod disruption budget with 429: %v", err)
+		current, err := f.ClientSet.CoreV1().Pods(ns).Get(ctx, pod.Name, metav1.GetOptions{})
+		framework.ExpectNoError(err, "error when getting pod %s", pod.Name)
+		gomega.Expect(current.DeletionTimestamp).To(gomega.BeNil(), "pod %s should not be deleted", pod.Name)
+		err = frameworkutil.WaitForDeploymentComplete(ctx, f.ClientSet, d, f.Timeouts.PodStart)
+		framework.ExpectNoError(err, "deployment should stay available")
+
+		ginkgo.By("Lowering minAvailable of the PodDisruptionBudget to 0 and evicting the pod again")
+		pdb.Spec.MinAvailable = ptr.To(intstr.FromInt32(0))
+		_, err = f.ClientSet.PolicyV1().PodDisruptionBudgets(ns).Update(ctx, pdb, metav1.UpdateOptions{})
+		framework.ExpectNoError(err, "error when updating pod disruption budget")
+		_, err = frameworkutil.WaitForPodDisruptionBudgetObserved(ctx, f.ClientSet, ns, name, f.Timeouts.PodStart)
+		framework.ExpectNoError(err)
+		err = f.ClientSet.PolicyV1().Evictions(ns).Evict(ctx, eviction)
+		framework.ExpectNoError(err, "error when evicting pod %s", pod.Name)
+		err = e2epod.WaitForPodNotFoundInNamespace(ctx, f.ClientSet, pod.Name, ns, f.Timeouts.PodDelete)
+		framework.ExpectNoError(err, "error when waiting for pod %s to be evicted", pod.Name)
+
+		ginkgo.By("Waiting for the Deployment to recover its replica on the accelerators")
+		err = frameworkutil.WaitForDeploymentComplete(ctx, f.ClientSet, d, f.Timeouts.PodStart)
+		framework.ExpectNoError(err, "error when waiting for deployment to recover")
+	})
+})
+
+var _ = WGDescribe("Accelerator Topology", func() {
+	f := framework.NewDefaultFramework("accelerator-topology")
+	// The pod reading the pod-resources API mounts the socket directory of the kubelet.
+	f.NamespacePodSecurityLevel = admissionapi.LevelPrivileged
+
+	/*
+		Release: v1.34
+		Testname: Accelerator Topology, single NUMA node alignment
+		Description: On an accelerator node whose kubelet runs the Topology Manager with the single-numa-node policy
+		and the static CPU Manager policy, create a guaranteed pod requesting 1 accelerator and 1 CPU. The exclusive
+		CPU and the accelerator assigned to the pod, as reported by the kubelet pod-resources API, MUST be on the same
+		NUMA node.
+	*/
+	frameworkutil.AIConformanceIt("accelerators and CPUs of a guaranteed pod should be aligned to a single NUMA node", func(ctx context.Context) {
+		ns := f.Namespace.Name
+		vendor := skipUnlessAcceleratorAllocatable(ctx, f.ClientSet)
+
+		ginkgo.By("Finding an accelerator node with the single-numa-node Topology Manager policy")
+		nodes, err := e2enode.GetReadyNodesIncludingTainted(ctx, f.ClientSet)
+		framework.ExpectNoError(err, "error when listing ready nodes")
+		var selectedNode *v1.Node
+		var policies []string
+		for i := range nodes.Items {
+			node := &nodes.Items[i]
+			if val, ok := node.Status.Allocatable[vendor.ResourceName]; !ok || val.IsZero() {
+				continue
+			}
+			config, err := frameworkutil.GetKubeletConfig(ctx, f.ClientSet, node.Name)
+			framework.ExpectNoError(err)
+			policies = append(policies, fmt.Sprintf("%s: topology manager %s, cpu manager %s", node.Name, config.TopologyManagerPolicy, config.CPUManagerPolicy))
+			if config.TopologyManagerPolicy == frameworkutil.SingleNUMANodeTopologyPolicy && config.CPUManagerPolicy == frameworkutil.StaticCPUManagerPolicy {
+				selectedNode = node
+				break
+			}
+		}
+		if selectedNode == nil {
+			e2eskipper.Skipf("None of the accelerator nodes runs the Topology Manager with the %s policy and the CPU Manager with the %s policy: %v",
+				frameworkutil.SingleNUMANodeTopologyPolicy, frameworkutil.StaticCPUManagerPolicy, policies)
+		}
+		lockAccelerators(ctx, f, vendor.ResourceName)
+		verifyAcceleratorsReleased(ctx, f, vendor.ResourceName)
+
+		ginkgo.By(fmt.Sprintf("Creating a guaranteed pod requesting 1 %s and 1 CPU on node %s", vendor.ResourceName, selectedNode.Name))
+		pod := e2epod.MakePod(ns, nil, nil, f.NamespacePodSecurityLevel, "")
+		resources := v1.ResourceList{
+			v1.ResourceCPU:      resource.MustParse("1"),
+			v1.ResourceMemory:   resource.MustParse("128Mi"),
+			vendor.ResourceName: resource.MustParse("1"),
+		}
+		pod.Spec.Containers[0].Resources = v1.ResourceRequirements{Requests: resources, Limits: resources}
+		err = frameworkutil.RequireAcceleratorNode(&pod.Spec, []v1.Node{*selectedNode}, vendor.ResourceName)
+		framework.ExpectNoError(err)
+		pod, err = f.ClientSet.CoreV1().Pods(ns).Create(ctx, pod, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "error when creating pod")
+		frameworkutil.DeferCleanup(f.ClientSet.CoreV1().Pods(ns).Delete, pod.Name, metav1.DeleteOptions{})
+		err = e2epod.WaitForPodRunningInNamespace(ctx, f.ClientSet, pod)
+		framework.ExpectNoError(err, "error when waiting for pod to be running")
+
+		ginkgo.By("Verifying the CPU and the accelerator assigned to the pod are on the same NUMA node")
+		podResources, err := frameworkutil.ListPodResources(ctx, f.ClientSet, ns, selectedNode.Name)
+		framework.ExpectNoError(err, "error when listing the pod resources on node %s", selectedNode.Name)
+		if podResources == nil {
+			e2eskipper.Skipf("The kubelet pod-resources API is not served on node %s", selectedNode.Name)
+		}
+		container := podResources.Container(ns, pod.Name, pod.Spec.Containers[0].Name)
+		gomega.Expect(container).NotTo(gomega.BeNil(), "pod %s is not reported by the kubelet pod-resources API", pod.Name)
+		numaCPUs, err := frameworkutil.NUMANodeCPUs(ctx, f.ClientSet, ns, selectedNode.Name)
+		framework.ExpectNoError(err)
+		framework.Logf("NUMA nodes of node %s: %v", selectedNode.Name, numaCPUs)
+		framework.ExpectNoError(frameworkutil.VerifyNUMAAlignment(container, vendor.ResourceName, numaCPUs))
+	})
+
+	// Hugepages aligned with the NUMA node of the accelerator are recommended for the high-performance inference
+	// workloads, but they're not required by the conformance. The spec is skipped unless an accelerator node has
+	// allocatable hugepages. A guaranteed pod requesting 1 accelerator and a hugepage MUST run. If the kubelet runs
+	// the Topology Manager with the single-numa-node policy and the static Memory Manager policy, the hugepages
+	// assigned to the pod, as reported by the kubelet pod-resources API, MUST be on the NUMA node of its accelerator.
+	frameworkutil.AIConformanceShouldIt("accelerators and hugepages of a guaranteed pod should be aligned to a single NUMA node", func(ctx context.Context) {
+		ns := f.Namespace.Name
+		vendor := skipUnlessAcceleratorAllocatable(ctx, f.ClientSet)
+
+		ginkgo.By("Finding an accelerator node with allocatable hugepages")
+		nodes, err := e2enode.GetReadyNodesIncludingTainted(ctx, f.ClientSet)
+		framework.ExpectNoError(err, "error when listing ready nodes")
+		var selectedNode *v1.Node
+		var hugePages v1.ResourceName
+		var pageSize resource.Quantity
+		aligned := false
+		for i := range nodes.Items {
+			node := &nodes.Items[i]
+			if val, ok := node.Status.Allocatable[vendor.ResourceName]; !ok || val.IsZero() {
+				continue
+			}
+			resourceName, size := frameworkutil.HugePagesResource(node)
+			if resourceName == "" {
+				continue
+			}
+			config, err := frameworkutil.GetKubeletConfig(ctx, f.ClientSet, node.Name)
+			framework.ExpectNoError(err)
+			nodeAligned := config.TopologyManagerPolicy == frameworkutil.SingleNUMANodeTopologyPolicy && config.MemoryManagerPolicy == frameworkutil.StaticMemoryManagerPolicy
+			// Prefer the node which aligns the hugepages, so that the alignment can be verified.
+			if selectedNode == nil || nodeAligned && !aligned {
+				selectedNode, hugePages, pageSize, aligned = node, resourceName, size, nodeAligned
+			}
+			if aligned {
+				break
+			}
+		}
+		if selectedNode == nil {
+			e2eskipper.Skipf("None of the %s nodes has allocatable hugepages", vendor.ResourceName)
+		}
+		lockAccelerators(ctx, f, vendor.ResourceName)
+		verifyAcceleratorsReleased(ctx, f, vendor.ResourceName)
+
+		ginkgo.By(fmt.Sprintf("Creating a guaranteed pod requesting 1 %s and %s of %s on node %s", vendor.ResourceName, pageSize.String(), hugePages, selectedNode.Name))
+		pod := e2epod.MakePod(ns, nil, nil, f.NamespacePodSecurityLevel, "")
+		resources := v1.ResourceList{
+			v1.ResourceCPU:      resource.MustParse("1"),
+			v1.ResourceMemory:   resource.MustParse("128Mi"),
+			hugePages:           pageSize,
+			vendor.ResourceName: resource.MustParse("1"),
+		}
+		pod.Spec.Containers[0].Resources = v1.ResourceRequirements{Requests: resources, Limits: resources}
+		err = frameworkutil.RequireAcceleratorNode(&pod.Spec, []v1.Node{*selectedNode}, vendor.ResourceName)
+		framework.ExpectNoError(err)
+		pod, err = f.ClientSet.CoreV1().Pods(ns).Create(ctx, pod, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "error when creating pod")
+		frameworkutil.DeferCleanup(f.ClientSet.CoreV1().Pods(ns).Delete, pod.Name, metav1.DeleteOptions{})
+		err = e2epod.WaitForPodRunningInNamespace(ctx, f.ClientSet, pod)
+		framework.ExpectNoError(err, "error when waiting for pod to be running")
+
+		if !aligned {
+			framework.Logf("The kubelet on node %s doesn't run the Topology Manager with the %s policy and the Memory Manager with the %s policy, skipping the alignment assertion",
+				selectedNode.Name, frameworkutil.SingleNUMANodeTopologyPolicy, frameworkutil.StaticMemoryManagerPolicy)
+			return
+		}
+		ginkgo.By(fmt.Sprintf("Verifying the %s and the accelerator assigned to the pod are on the same NUMA node", hugePages))
+		podResources, err := frameworkutil.ListPodResources(ctx, f.ClientSet, ns, selectedNode.Name)
+		framework.ExpectNoError(err, "error when listing the pod resources on node %s", selectedNode.Name)
+		if podResources == nil {
+			e2eskipper.Skipf("The kubelet pod-resources API is not served on node %s", selectedNode.Name)
+		}
+		container := podResources.Container(ns, pod.Name, pod.Spec.Containers[0].Name)
+		gomega.Expect(container).NotTo(gomega.BeNil(), "pod %s is not reported by the kubelet pod-resources API", pod.Name)
+		framework.ExpectNoError(frameworkutil.VerifyHugePagesNUMAAlignment(container, vendor.ResourceName, hugePages))
+	})
+})
+
+var acceleratorIsolation struct {
+	Taint string `default:"" usage:"taint which the accelerator nodes are expected to carry, in the key[=value]:effect form, e.g. nvidia.com/gpu=present:NoSchedule. If unspecified, the NoSchedule or NoExecute taint carried by all the accelerator nodes is verified, and the Accelerator Isolation spec is skipped if there's none"`
+}
+
+var _ = e2econfig.AddOptions(&acceleratorIsolation, "ai.acceleratorIsolation")
+
+var _ = WGDescribe("Accelerator Isolation", func() {
+	f := framework.NewDefaultFramework("accelerator-isolation")
+	f.NamespacePodSecurityLevel = admissionapi.LevelBaseline
+
+	// Tainting the accelerator nodes is recommended, so that only the workloads which tolerate the taint land on
+	// them, but it's not required by the conformance. All the accelerator nodes MUST carry the taint configured by
+	// -ai.acceleratorIsolation.taint. A pod requesting 1 accelerator without the toleration MUST be unschedulable
+	// on them, and a pod tolerating only the taint MUST run.
+	frameworkutil.AIConformanceShouldIt("accelerator nodes should be tainted to keep the pods which don't tolerate the taint away", func(ctx context.Context) {
+		ns := f.Namespace.Name
+		vendor := skipUnlessAcceleratorAllocatable(ctx, f.ClientSet)
+		nodes, err := e2enode.GetReadyNodesIncludingTainted(ctx, f.ClientSet)
+		framework.ExpectNoError(err, "error when listing ready nodes")
+		acceleratorNodes, err := frameworkutil.AcceleratorNodes(nodes.Items, vendor.ResourceName)
+		framework.ExpectNoError(err)
+
+		var taint *v1.Taint
+		if acceleratorIsolation.Taint != "" {
+			taint, err = frameworkutil.ParseTaint(acceleratorIsolation.Taint)
+			framework.ExpectNoError(err, "error when parsing -ai.acceleratorIsolation.taint")
+			untainted := frameworkutil.NodesWithoutTaint(acceleratorNodes, taint)
+			gomega.Expect(untainted).To(gomega.BeEmpty(), "the accelerator nodes should carry taint %s", taint.ToString())
+		} else {
+			taint = frameworkutil.CommonTaint(acceleratorNodes)
+			if taint == nil {
+				e2eskipper.Skipf("The %d %s nodes don't share a NoSchedule or NoExecute taint, specify one via -ai.acceleratorIsolation.taint", len(acceleratorNodes), vendor.ResourceName)
+			}
+		}
+		framework.Logf("The %d %s nodes carry taint %s", len(acceleratorNodes), vendor.ResourceName, taint.ToString())
+		lockAccelerators(ctx, f, vendor.ResourceName)
+		verifyAcceleratorsReleased(ctx, f, vendor.ResourceName)
+
+		newPod := func(tolerations ...v1.Toleration) *v1.Pod {
+			pod := e2epod.MakePod(ns, nil, nil, f.NamespacePodSecurityLevel, "")
+			pod.Spec.Containers[0].Resources.Limits = v1.ResourceList{
+				vendor.ResourceName: resource.MustParse("1"),
+			}
+			requireAcceleratorNode(ctx, f.ClientSet, &pod.Spec, vendor.ResourceName)
+			// Replace the toleration of all the NoSchedule taints added by requireAcceleratorNode.
+			pod.Spec.Tolerations = tolerations
+			return pod
+		}
+
+		ginkgo.By(fmt.Sprintf("Creating a pod requesting 1 %s which doesn't tolerate taint %s", vendor.ResourceName, taint.ToString()))
+		pod, err := f.ClientSet.CoreV1().Pods(ns).Create(ctx, newPod(), metav1.CreateOptions{})
+		framework.ExpectNoError(err, "error when creating pod")
+		frameworkutil.DeferCleanup(e2epod.DeletePodWithWait, f.ClientSet, pod)
+		err = e2epod.WaitForPodCondition(ctx, f.ClientSet, ns, pod.Name, "unschedulable", f.Timeouts.PodStart, func(pod *v1.Pod) (bool, error) {
+			if pod.Spec.NodeName != "" {
+				return false, fmt.Errorf("pod %s not tolerating taint %s is scheduled to node %s", pod.Name, taint.ToString(), pod.Spec.NodeName)
+			}
+			return frameworkutil.IsPodUnschedulable(pod), nil
+		})
+		framework.ExpectNoError(err, "pod %s should be unschedulable", pod.Name)
+		pod, err = f.ClientSet.CoreV1().Pods(ns).Get(ctx, pod.Name, metav1.GetOptions{})
+		framework.ExpectNoError(err, "error when getting pod %s", pod.Name)
+		_, cond := podutil.GetPodConditionFromList(pod.Status.Conditions, v1.PodScheduled)
+		gomega.Expect(cond).NotTo(gomega.BeNil())
+		gomega.Expect(cond.Message).To(gomega.ContainSubstring(taint.Key), "pod %s should be unschedulable because of taint %s", pod.Name, taint.ToString())
+		// Release the accelerator which the tolerating pod may need if it's the only one.
+		err = e2epod.DeletePodWithWait(ctx, f.ClientSet, pod)
+		framework.ExpectNoError(err, "error when deleting pod %s", pod.Name)
+
+		ginkgo.By(fmt.Sprintf("Creating a pod requesting 1 %s which tolerates taint %s", vendor.ResourceName, taint.ToString()))
+		pod, err = f.ClientSet.CoreV1().Pods(ns).Create(ctx, newPod(frameworkutil.TolerationForTaint(taint)), metav1.CreateOptions{})
+		framework.ExpectNoError(err, "error when creating pod")
+		frameworkutil.DeferCleanup(f.ClientSet.CoreV1().Pods(ns).Delete, pod.Name, metav1.DeleteOptions{})
+		err = e2epod.WaitForPodRunningInNamespace(ctx, f.ClientSet, pod)
+		framework.ExpectNoError(err, "error when waiting for pod to be running")
+	})
+})
+
+var acceleratorCheckpoint struct {
+	Registry string `default:"" usage:"repository which the checkpoint image of the accelerator workload is pushed to, e.g. registry.example.com/checkpoints, which the nodes can pull from. If unspecified, the Accelerator Checkpoint spec is skipped, as the checkpointed workload is restored from the image"`
+}
+
+var _ = e2econfig.AddOptions(&acceleratorCheckpoint, "ai.checkpoint")
+
+var _ = WGDescribe("Accelerator Checkpoint", func() {
+	f := framework.NewDefaultFramework("accelerator-checkpoint")
+	// The pod building the checkpoint image mounts the checkpoint directory of the kubelet.
+	f.NamespacePodSecurityLevel = admissionapi.LevelPrivileged
+
+	// Checkpointing and restoring the accelerator workloads, e.g. to migrate them off a node under maintenance, is
+	// optional and depends on the ContainerCheckpoint feature gate of the kubelet and the support of the container
+	// runtime and CRIU for the devices of the vendor. The spec is skipped if the workload can't be checkpointed.
+	// The workload restored from its checkpoint image MUST resume from its checkpointed state instead of starting
+	// over, and keep the access to its accelerator.
+	frameworkutil.AIConformanceShouldIt("a checkpointed accelerator workload should resume with its accelerator after the restore", func(ctx context.Context) {
+		ns := f.Namespace.Name
+		vendor := skipUnlessAcceleratorAllocatable(ctx, f.ClientSet)
+		if acceleratorCheckpoint.Registry == "" {
+			e2eskipper.Skipf("The workload can't be restored without a registry for its checkpoint image, specify one via -ai.checkpoint.registry")
+		}
+		lockAccelerators(ctx, f, vendor.ResourceName)
+		verifyAcceleratorsReleased(ctx, f, vendor.ResourceName)
+
+		ginkgo.By(fmt.Sprintf("Creating a pod requesting 1 %s which counts the seconds it runs", vendor.ResourceName))
+		pod := e2epod.MakePod(ns, nil, nil, f.NamespacePodSecurityLevel,
+			`echo started >> /tmp/starts; i=0; while true; do i=$((i+1)); echo $i > /tmp/counter; sleep 1; done`)
+		pod.Spec.Containers[0].Resources.Limits = v1.ResourceList{
+			vendor.ResourceName: resource.MustParse("1"),
+		}
+		requireAcceleratorNode(ctx, f.ClientSet, &pod.Spec, vendor.ResourceName)
+		pod, err := f.ClientSet.CoreV1().Pods(ns).Create(ctx, pod, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "error when creating pod")
+		frameworkutil.DeferCleanup(e2epod.DeletePodWithWait, f.ClientSet, pod)
+		err = e2epod.WaitForPodRunningInNamespace(ctx, f.ClientSet, pod)
+		framework.ExpectNoError(err, "error when waiting for pod to be running")
+		pod, err = f.ClientSet.CoreV1().Pods(ns).Get(ctx, pod.Name, metav1.GetOptions{})
+		framework.ExpectNoError(err, "error when getting pod %s", pod.Name)
+		containerName := pod.Spec.Containers[0].Name
+
+		readCounter := func(podName string) (int, error) {
+			out, stderr, err := e2epod.ExecShellInPodWithFullOutput(ctx, f, podName, "cat /tmp/counter")
+			if err != nil {
+				return 0, fmt.Errorf("error when reading the counter of pod %s: %w, stderr: %s", podName, err, stderr)
+			}
+			return strconv.Atoi(strings.TrimSpace(out))
+		}
+		var counter int
+		err = framework.Gomega().Eventually(ctx, func() error {
+			counter, err = readCounter(pod.Name)
+			if err != nil {
+				return err
+			}
+			if counter < 5 {
+				return fmt.Errorf("pod %s has run for %d seconds", pod.Name, counter)
+			}
+			return nil
+		}).WithTimeout(time.Minute).WithPolling(framework.Poll).Should(gomega.Succeed())
+		framework.ExpectNoError(err, "error when waiting for pod %s to count", pod.Name)
+
+		ginkgo.By(fmt.Sprintf("Checkpointing container %s of pod %s after %d seconds", containerName, pod.Name, counter))
+		archive, err := frameworkutil.CheckpointContainer(ctx, f.ClientSet, pod.Spec.NodeName, ns, pod.Name, containerName)
+		if err != nil {
+			e2eskipper.Skipf("The accelerator workload can't be checkpointed on node %s: %v", pod.Spec.NodeName, err)
+		}
+		if archive == "" {
+			e2eskipper.Skipf("The kubelet on node %s doesn't serve the checkpoint API, e.g. the ContainerCheckpoint feature gate is disabled", pod.Spec.NodeName)
+		}
+		framework.Logf("Checkpointed container %s of pod %s to %s on node %s", containerName, pod.Name, archive, pod.Spec.NodeName)
+
+		image := fmt.Sprintf("%s/%s:%s", strings.TrimSuffix(acceleratorCheckpoint.Registry, "/"), ns, containerName)
+		ginkgo.By(fmt.Sprintf("Building checkpoint image %s", image))
+		err = frameworkutil.BuildCheckpointImage(ctx, f.ClientSet, ns, pod.Spec.NodeName, archive, containerName, image)
+		framework.ExpectNoError(err)
+
+		ginkgo.By(fmt.Sprintf("Deleting pod %s to release its %s", pod.Name, vendor.ResourceName))
+		err = e2epod.DeletePodWithWait(ctx, f.ClientSet, pod)
+		framework.ExpectNoError(err, "error when deleting pod %s", pod.Name)
+
+		ginkgo.By(fmt.Sprintf("Restoring the workload from checkpoint image %s on node %s", image, pod.Spec.NodeName))
+		node, err := f.ClientSet.CoreV1().Nodes().Get(ctx, pod.Spec.NodeName, metav1.GetOptions{})
+		framework.ExpectNoError(err, "error when getting node %s", pod.Spec.NodeName)
+		restored := e2epod.MakePod(ns, nil, nil, f.NamespacePodSecurityLevel, "")
+		restored.Spec.Containers[0].Name = containerName
+		restored.Spec.Containers[0].Image = image
+		// The processes are restored from the checkpoint rather than started by the command.
+		restored.Spec.Containers[0].Command = nil
+		restored.Spec.Containers[0].Resources.Limits = v1.ResourceList{
+			vendor.ResourceName: resource.MustParse("1"),
+		}
+		err = frameworkutil.RequireAcceleratorNode(&restored.Spec, []v1.Node{*node}, vendor.ResourceName)
+		framework.ExpectNoError(err)
+		restored, err = f.ClientSet.CoreV1().Pods(ns).Create(ctx, restored, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "error when creating pod")
+		frameworkutil.DeferCleanup(e2epod.DeletePodWithWait, f.ClientSet, restored)
+		err = e2epod.WaitForPodRunningInNamespace(ctx, f.ClientSet, restored)
+		framework.ExpectNoError(err, "error when waiting for the restored pod %s to be running", restored.Name)
+
+		ginkgo.By("Verifying the restored workload resumes from its checkpointed state")
+		out := e2epod.ExecShellInPod(ctx, f, restored.Name, "cat /tmp/starts")
+		gomega.Expect(strings.Count(out, "started")).To(gomega.Equal(1), "the restored pod %s should resume the workload instead of starting it over", restored.Name)
+		restoredCounter, err := readCounter(restored.Name)
+		framework.ExpectNoError(err)
+		gomega.Expect(restoredCounter).To(gomega.BeNumerically(">=", counter), "the restored pod %s should resume counting from %d", restored.Name, counter)
+
+		ginkgo.By(fmt.Sprintf("Verifying the restored workload keeps the access to 1 %s", vendor.ResourceName))
+		envName := vendor.VisibleDevicesEnvName()
+		if envName == "" {
+			framework.Logf("The device plugin of %s doesn't inject an environment variable of the allocated accelerators, skipping the verification", vendor.Name)
+			return
+		}
+		out = e2epod.ExecShellInPod(ctx, f, restored.Name, "env")
+		err = frameworkutil.VerifyVisibleDevices(out, envName, 1)
+		framework.ExpectNoError(err, "the restored pod %s should see the allocated accelerator", restored.Name)
+	})
+})
+
+var scratchStorage struct {
+	StorageClass  string `default:"" usage:"StorageClass of the generic ephemeral volume which the accelerator workload of the Accelerator Scratch Storage spec writes its scratch data to, e.g. a class of local SSDs. If unspecified, the default StorageClass is used, and the spec is skipped if there is none"`
+	Size          string `default:"10Gi" usage:"storage requested by the generic ephemeral volume of the Accelerator Scratch Storage spec, at least -ai.scratchStorage.writeMiB"`
+	WriteMiB      int    `default:"1024" usage:"MiB which the accelerator workload of the Accelerator Scratch Storage spec writes to its generic ephemeral volume with dd"`
+	MinThroughput int    `default:"0" usage:"minimum throughput in MiB/s of the writes of the accelerator workload of the Accelerator Scratch Storage spec to its generic ephemeral volume. If 0, the throughput is only logged"`
+}
+
+var _ = e2econfig.AddOptions(&scratchStorage, "ai.scratchStorage")
+
+var _ = WGDescribe("Accelerator Scratch Storage", func() {
+	f := framework.NewDefaultFramework("accelerator-scratch-storage")
+	f.NamespacePodSecurityLevel = admissionapi.LevelBaseline
+
+	// The accelerator workloads, e.g. the training jobs staging their datasets and checkpoints, need scratch storage
+	// which lives and dies with their pods. Provisioning it via generic ephemeral volumes is recommended, but it
+	// depends on a StorageClass, so the spec is skipped if -ai.scratchStorage.storageClass doesn't exist or, if it's
+	// unspecified, there is no default StorageClass. A pod requesting 1 accelerator with a generic ephemeral volume of
+	// the class SHOULD run on an accelerator node with its claim bound, and write -ai.scratchStorage.writeMiB to the
+	// volume at -ai.scratchStorage.minThroughput at least.
+	frameworkutil.AIConformanceShouldIt("an accelerator workload should write to a generic ephemeral volume", func(ctx context.Context) {
+		ns := f.Namespace.Name
+		vendor := skipUnlessAcceleratorAllocatable(ctx, f.ClientSet)
+		size, err := resource.ParseQuantity(scratchStorage.Size)
+		framework.ExpectNoError(err, "error when parsing -ai.scratchStorage.size")
+		gomega.Expect(size.Value()).To(gomega.BeNumerically(">=", int64(scratchStorage.WriteMiB)<<20), "-ai.scratchStorage.size %s should hold -ai.scratchStorage.writeMiB %d", scratchStorage.Size, scratchStorage.WriteMiB)
+
+		storageClass := scratchStorage.StorageClass
+		if storageClass != "" {
+			_, err := f.ClientSet.StorageV1().StorageClasses().Get(ctx, storageClass, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				e2eskipper.Skipf("StorageClass %s of -ai.scratchStorage.storageClass doesn't exist", storageClass)
+			}
+			framework.ExpectNoError(err, "error when getting StorageClass %s", storageClass)
+		} else {
+			classes, err := f.ClientSet.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+			framework.ExpectNoError(err, "error when listing StorageClasses")
+			storageClass = frameworkutil.DefaultStorageClass(classes.Items)
+			if storageClass == "" {
+				e2eskipper.Skipf("None of the %d StorageClasses is the default one, specify one via -ai.scratchStorage.storageClass", len(classes.Items))
+			}
+		}
+		lockAccelerators(ctx, f, vendor.ResourceName)
+		verifyAcceleratorsReleased(ctx, f, vendor.ResourceName)
+		frameworkutil.RequireAvailableAccelerators(ctx, f.ClientSet, vendor.ResourceName, 1, false)
+
+		ginkgo.By(fmt.Sprintf("Creating a pod requesting 1 %s which writes %d MiB to a generic ephemeral volume of StorageClass %s", vendor.ResourceName, scratchStorage.WriteMiB, storageClass))
+		pod := e2epod.MakePod(ns, nil, nil, f.NamespacePodSecurityLevel,
+			fmt.Sprintf("dd if=/dev/zero of=/scratch/dd bs=1M count=%d conv=fsync 2>&1 && rm /scratch/dd", scratchStorage.WriteMiB))
+		pod.Spec.RestartPolicy = v1.RestartPolicyNever
+		pod.Spec.Containers[0].Resources.Limits = v1.ResourceList{
+			vendor.ResourceName: resource.MustParse("1"),
+		}
+		pod.Spec.Containers[0].VolumeMounts = []v1.VolumeMount{{Name: "scratch", MountPath: "/scratch"}}
+		pod.Spec.Volumes = []v1.Volume{
+			{
+				Name: "scratch",
+				VolumeSource: v1.VolumeSource{
+					Ephemeral: &v1.EphemeralVolumeSource{
+						VolumeClaimTemplate: &v1.PersistentVolumeClaimTemplate{
+							Spec: v1.PersistentVolumeClaimSpec{
+								AccessModes:      []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+								StorageClassName: ptr.To(storageClass),
+								Resources: v1.VolumeResourceRequirements{
+									Requests: v1.ResourceList{v1.ResourceStorage: size},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		requireAcceleratorNode(ctx, f.ClientSet, &pod.Spec, vendor.ResourceName)
+		pod, err = f.ClientSet.CoreV1().Pods(ns).Create(ctx, pod, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "error when creating pod")
+		frameworkutil.DeferCleanup(e2epod.DeletePodWithWait, f.ClientSet, pod)
+		// The volume may be provisioned after the pod is scheduled, e.g. by a class binding on the first consumer.
+		err = e2epod.WaitForPodSuccessInNamespaceTimeout(ctx, f.ClientSet, pod.Name, ns, f.Timeouts.PodStartSlow)
+		framework.ExpectNoError(err, "pod %s should write to its generic ephemeral volume", pod.Name)
+
+		ginkgo.By("Verifying the claim of the generic ephemeral volume is bound")
+		// The claim of a generic ephemeral volume is named <pod>-<volume> and owned by the pod.
+		claimName := pod.Name + "-scratch"
+		claim, err := f.ClientSet.CoreV1().PersistentVolumeClaims(ns).Get(ctx, claimName, metav1.GetOptions{})
+		framework.ExpectNoError(err, "error when getting claim %s of pod %s", claimName, pod.Name)
+		gomega.Expect(claim.Status.Phase).To(gomega.Equal(v1.ClaimBound), "claim %s of pod %s should be bound", claimName, pod.Name)
+
+		ginkgo.By("Verifying the write throughput to the generic ephemeral volume")
+		output, err := e2epod.GetPodLogs(ctx, f.ClientSet, ns, pod.Name, pod.Spec.Containers[0].Name)
+		framework.ExpectNoError(err, "error when getting logs of pod %s", pod.Name)
+		throughput, err := frameworkutil.ParseDDThroughput(output)
+		framework.ExpectNoError(err)
+		throughputMiB := throughput / (1 << 20)
+		framework.Logf("Pod %s wrote %d MiB to volume %s of StorageClass %s at %.1f MiB/s", pod.Name, scratchStorage.WriteMiB, claim.Spec.VolumeName, storageClass, throughputMiB)
+		if scratchStorage.MinThroughput > 0 {
+			gomega.Expect(throughputMiB).To(gomega.BeNumerically(">=", scratchStorage.MinThroughput), "pod %s should write to volume of StorageClass %s at %d MiB/s at least", pod.Name, storageClass, scratchStorage.MinThroughput)
+		}
+	})
+})
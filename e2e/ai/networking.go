@@ -2,12 +2,24 @@ package ai
 
 import (
 	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/onsi/ginkgo/v2"
 	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
 	apiextclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/kubernetes/test/e2e/framework"
+	e2econfig "k8s.io/kubernetes/test/e2e/framework/config"
+	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
+	e2eskipper "k8s.io/kubernetes/test/e2e/framework/skipper"
 
 	frameworkutil "github.com/carlory/ai-conformance/e2e/util/framework"
 	e2ecrd "github.com/carlory/ai-conformance/e2e/util/framework/crd"
@@ -20,34 +32,248 @@ var _ = WGDescribe("AI Inference", func() {
 	/*
 		Release: v1.33
 		Testname: Kubernetes Gateway API Support
-		Description: Kubernetes Gateway API MUST be installed, including gatewayclasses, gateways, httproutes, grpcroutes,
-		and referencegrants in the gateways.networking.k8s.io group. And these CRDs MUST have NamesAccepted and Established
+		Description: Kubernetes Gateway API MUST be installed, including gatewayclasses, gateways, httproutes and grpcroutes
+		served at v1, and referencegrants served at v1beta1 in the gateway.networking.k8s.io group, or the resources
+		given by -ai.gatewayAPI.resources. If -ai.gatewayAPI.experimental is set, tcproutes and tlsroutes of the
+		experimental channel MUST be served at v1alpha2 as well. And these CRDs MUST have NamesAccepted and Established
 		conditions with True status.
 	*/
 	frameworkutil.AIConformanceIt("gateway crds should be available", func(ctx context.Context) {
+		required := frameworkutil.StandardGatewayAPIResources
+		if gatewayAPI.Resources != "" {
+			var err error
+			required, err = frameworkutil.ParseGatewayAPIResources(gatewayAPI.Resources)
+			framework.ExpectNoError(err, "error when parsing -ai.gatewayAPI.resources")
+		}
+		if gatewayAPI.Experimental {
+			required = append(slices.Clone(required), frameworkutil.ExperimentalGatewayAPIResources...)
+		}
+		framework.Logf("Required Gateway API resources: %v", required)
+
 		apiExtensionClient, err := apiextclientset.NewForConfig(f.ClientConfig())
 		framework.ExpectNoError(err)
 
 		crds, err := apiExtensionClient.ApiextensionsV1().CustomResourceDefinitions().List(ctx, metav1.ListOptions{})
 		framework.ExpectNoError(err)
 
-		expectedCrds := sets.New(
-			"gatewayclasses.gateway.networking.k8s.io",
-			"gateways.gateway.networking.k8s.io",
-			"httproutes.gateway.networking.k8s.io",
-			"grpcroutes.gateway.networking.k8s.io",
-			"referencegrants.gateway.networking.k8s.io")
-
-		foundCrds := sets.New[string]()
+		expectedCrds := sets.New[string]()
+		for _, r := range required {
+			expectedCrds.Insert(r.Resource + "." + frameworkutil.GatewayAPIGroup)
+		}
 		for _, crd := range crds.Items {
 			if !expectedCrds.Has(crd.Name) {
 				continue
 			}
-			foundCrds.Insert(crd.Name)
 			// Check if the CRD has accepted and established conditions which means Gateway APIs is ready to use
 			err = e2ecrd.WaitForCrdEstablishedAndNamesAccepted(ctx, apiExtensionClient, crd.GetName())
 			framework.ExpectNoError(err, "error when waiting for CRD %s to be established and names accepted", crd.GetName())
 		}
-		gomega.Expect(foundCrds).To(gomega.Equal(expectedCrds), "missing gateway crds: %v", sets.List(expectedCrds.Difference(foundCrds)))
+		missing := frameworkutil.MissingGatewayAPIResources(required, crds.Items)
+		gomega.Expect(missing).To(gomega.BeEmpty(), "missing Gateway API resources: %s", strings.Join(missing, ", "))
 	})
 })
+
+var _ = WGDescribe("Gateway Route Acceptance", func() {
+	f := framework.NewDefaultFramework("gateway-route")
+
+	/*
+		Release: v1.34
+		Testname: Gateway API HTTPRoute Acceptance
+		Description: Create a Gateway of an accepted GatewayClass, the one given by -ai.gatewayAPI.gatewayClass or the
+		first accepted one, with an HTTP listener, a Service, and an HTTPRoute attached to the Gateway forwarding to the
+		Service. The status.parents entry of the HTTPRoute for the Gateway MUST have the Accepted and ResolvedRefs
+		conditions with True status, which proves that the Gateway controller reconciles the routes. The test is skipped
+		if no GatewayClass is accepted.
+	*/
+	frameworkutil.AIConformanceIt("httproute should be accepted by the gateway controller", func(ctx context.Context) {
+		className := skipUnlessGatewayClassAccepted(ctx, f)
+
+		ns := f.Namespace.Name
+		name := "inference"
+		svc := &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: v1.ServiceSpec{
+				Selector: map[string]string{"app": name},
+				Ports: []v1.ServicePort{
+					{
+						Name:     "http",
+						Protocol: v1.ProtocolTCP,
+						Port:     8080,
+					},
+				},
+			},
+		}
+		_, err := f.ClientSet.CoreV1().Services(ns).Create(ctx, svc, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "error when creating service")
+
+		createGateway(ctx, f, name, className)
+		createHTTPRoute(ctx, f, name, name, name, 8080)
+
+		err = frameworkutil.WaitForHTTPRouteAccepted(ctx, f.DynamicClient, ns, name, name, 2*time.Minute)
+		framework.ExpectNoError(err, "error when waiting for httproute to be accepted")
+	})
+
+	// Bounding the inference requests with a timeout keeps a stuck model server from holding the clients, but the
+	// timeouts of HTTPRoute are an extended feature of the Gateway API. The Gateway MUST respond to a request which
+	// takes longer than the request timeout of its route with 504 at about the timeout, and MUST still serve the
+	// requests which complete in time. The test is skipped if the installed HTTPRoute CRD prunes the timeouts or the
+	// Gateway controller doesn't accept the route with them.
+	frameworkutil.AIConformanceShouldIt("httproute request timeout should terminate the slow inference requests", func(ctx context.Context) {
+		className := skipUnlessGatewayClassAccepted(ctx, f)
+
+		ns := f.Namespace.Name
+		name := "inference"
+		requestTimeout := 2 * time.Second
+		slowDuration := 10 * time.Second
+
+		ginkgo.By("Creating an inference backend which takes as long as requested to respond")
+		pod := e2epod.NewAgnhostPod(ns, name, nil, nil, []v1.ContainerPort{{Name: "http", ContainerPort: 8080}}, "netexec", "--http-port=8080")
+		pod.Labels = map[string]string{"app": name}
+		e2epod.NewPodClient(f).CreateSync(ctx, e2epod.MustMixinRestrictedPodSecurity(pod))
+		svc := &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: v1.ServiceSpec{
+				Selector: map[string]string{"app": name},
+				Ports: []v1.ServicePort{
+					{
+						Name:     "http",
+						Protocol: v1.ProtocolTCP,
+						Port:     8080,
+					},
+				},
+			},
+		}
+		_, err := f.ClientSet.CoreV1().Services(ns).Create(ctx, svc, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "error when creating service")
+
+		ginkgo.By(fmt.Sprintf("Routing the inference requests to the backend with a request timeout of %v", requestTimeout))
+		createGateway(ctx, f, name, className)
+		route := createHTTPRouteWithTimeout(ctx, f, name, name, name, 8080, requestTimeout.String())
+		rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+		if len(rules) == 0 || rules[0].(map[string]interface{})["timeouts"] == nil {
+			e2eskipper.Skipf("The timeouts of httproute %s were pruned, the installed HTTPRoute CRD doesn't support them", name)
+		}
+		if err := frameworkutil.WaitForHTTPRouteAccepted(ctx, f.DynamicClient, ns, name, name, 2*time.Minute); err != nil {
+			e2eskipper.Skipf("The httproute with a request timeout is not accepted, the Gateway controller may not support it: %v", err)
+		}
+		address, err := frameworkutil.ResolveGatewayAddress(ctx, f.DynamicClient, ns, name)
+		framework.ExpectNoError(err, "error when resolving the address of the gateway")
+		url := "http://" + net.JoinHostPort(address, "80")
+
+		ginkgo.By("Sending the fast inference requests through the Gateway")
+		fast, err := frameworkutil.RunHTTPRequestGenerator(ctx, f.ClientSet, ns, frameworkutil.HTTPRequestGenerator{
+			Name:     "fast-requests",
+			URL:      url + "/hostname",
+			Requests: 5,
+			Interval: time.Second,
+		})
+		framework.ExpectNoError(err, "error when sending the fast requests through the gateway")
+		gomega.Expect(fast.StatusCodes).To(gomega.Equal(map[int]int{http.StatusOK: 5}), "the fast requests should be served within the request timeout")
+
+		ginkgo.By(fmt.Sprintf("Sending a slow inference request which takes %v through the Gateway", slowDuration))
+		slow, err := frameworkutil.RunHTTPRequestGenerator(ctx, f.ClientSet, ns, frameworkutil.HTTPRequestGenerator{
+			Name:    "slow-request",
+			URL:     fmt.Sprintf("%s/shell?cmd=sleep%%20%d", url, int(slowDuration.Seconds())),
+			Timeout: slowDuration + 5*time.Second,
+		})
+		framework.ExpectNoError(err, "error when sending the slow request through the gateway")
+		gomega.Expect(slow.StatusCodes).To(gomega.Equal(map[int]int{http.StatusGatewayTimeout: 1}), "the slow request should be terminated by the gateway")
+		gomega.Expect(slow.Durations).To(gomega.HaveLen(1))
+		gomega.Expect(slow.Durations[0]).To(gomega.BeNumerically("<", slowDuration), "the slow request should be terminated at the request timeout %v", requestTimeout)
+		framework.Logf("The slow request was terminated after %v", slow.Durations[0])
+	})
+})
+
+// skipUnlessGatewayClassAccepted skips the spec unless the Gateway API is served and a GatewayClass is accepted by
+// its controller, and returns the one given by -ai.gatewayAPI.gatewayClass or the first accepted one.
+func skipUnlessGatewayClassAccepted(ctx context.Context, f *framework.Framework) string {
+	frameworkutil.SkipIfGroupVersionUnavaliable(ctx, frameworkutil.CachedDiscovery(f.ClientSet), frameworkutil.HTTPRouteGVR.GroupVersion().String())
+	classes, err := f.DynamicClient.Resource(frameworkutil.GatewayClassGVR).List(ctx, metav1.ListOptions{})
+	framework.ExpectNoError(err, "error when listing GatewayClasses")
+	className := frameworkutil.AcceptedGatewayClass(classes.Items, gatewayAPI.GatewayClass)
+	if className == "" {
+		e2eskipper.Skipf("None of the %d GatewayClasses is accepted by its controller", len(classes.Items))
+	}
+	framework.Logf("Using GatewayClass %s", className)
+	return className
+}
+
+// createGateway creates a Gateway of the GatewayClass with an HTTP listener on port 80 in the namespace of the
+// framework.
+func createGateway(ctx context.Context, f *framework.Framework, name, className string) {
+	gateway := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": frameworkutil.GatewayGVR.GroupVersion().String(),
+			"kind":       "Gateway",
+			"metadata": map[string]interface{}{
+				"name": name,
+			},
+			"spec": map[string]interface{}{
+				"gatewayClassName": className,
+				"listeners": []interface{}{
+					map[string]interface{}{
+						"name":     "http",
+						"protocol": "HTTP",
+						"port":     int64(80),
+					},
+				},
+			},
+		},
+	}
+	_, err := f.DynamicClient.Resource(frameworkutil.GatewayGVR).Namespace(f.Namespace.Name).Create(ctx, gateway, metav1.CreateOptions{})
+	framework.ExpectNoError(err, "error when creating gateway")
+	frameworkutil.DeferCleanup(f.DynamicClient.Resource(frameworkutil.GatewayGVR).Namespace(f.Namespace.Name).Delete, name, metav1.DeleteOptions{})
+}
+
+// createHTTPRoute creates an HTTPRoute attached to the Gateway which forwards all requests to the port of the
+// Service in the namespace of the framework.
+func createHTTPRoute(ctx context.Context, f *framework.Framework, name, gatewayName, serviceName string, port int64) {
+	createHTTPRouteWithTimeout(ctx, f, name, gatewayName, serviceName, port, "")
+}
+
+// createHTTPRouteWithTimeout is like createHTTPRoute, but the rule of the route has the given request timeout, a
+// Gateway API duration, unless it's empty. It returns the created route, whose timeout is pruned if the installed
+// HTTPRoute CRD doesn't support it.
+func createHTTPRouteWithTimeout(ctx context.Context, f *framework.Framework, name, gatewayName, serviceName string, port int64, requestTimeout string) *unstructured.Unstructured {
+	rule := map[string]interface{}{
+		"backendRefs": []interface{}{
+			map[string]interface{}{
+				"name": serviceName,
+				"port": port,
+			},
+		},
+	}
+	if requestTimeout != "" {
+		rule["timeouts"] = map[string]interface{}{
+			"request": requestTimeout,
+		}
+	}
+	route := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": frameworkutil.HTTPRouteGVR.GroupVersion().String(),
+			"kind":       "HTTPRoute",
+			"metadata": map[string]interface{}{
+				"name": name,
+			},
+			"spec": map[string]interface{}{
+				"parentRefs": []interface{}{
+					map[string]interface{}{
+						"name": gatewayName,
+					},
+				},
+				"rules": []interface{}{rule},
+			},
+		},
+	}
+	route, err := f.DynamicClient.Resource(frameworkutil.HTTPRouteGVR).Namespace(f.Namespace.Name).Create(ctx, route, metav1.CreateOptions{})
+	framework.ExpectNoError(err, "error when creating httproute")
+	frameworkutil.DeferCleanup(f.DynamicClient.Resource(frameworkutil.HTTPRouteGVR).Namespace(f.Namespace.Name).Delete, name, metav1.DeleteOptions{})
+	return route
+}
+
+var gatewayAPI struct {
+	Resources    string `default:"" usage:"comma-separated <resource>/<version> entries of the Gateway API resources which MUST be served, e.g. httproutes/v1,referencegrants/v1beta1. If unspecified, gatewayclasses, gateways, httproutes and grpcroutes at v1 and referencegrants at v1beta1 of the standard channel are required"`
+	GatewayClass string `default:"" usage:"GatewayClass of the Gateway created by the Gateway Route Acceptance spec. If unspecified, the first GatewayClass accepted by its controller is used"`
+	Experimental bool   `default:"false" usage:"if true, the platform claims the support of the experimental channel of the Gateway API, and tcproutes and tlsroutes MUST be served at v1alpha2 as well"`
+}
+var _ = e2econfig.AddOptions(&gatewayAPI, "ai.gatewayAPI")
@@ -3,26 +3,50 @@ package ai
 import (
 	"context"
 	"fmt"
-	"strings"
+	"slices"
 	"time"
 
 	"github.com/onsi/ginkgo/v2"
 	"github.com/onsi/gomega"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	monitoring "github.com/prometheus-operator/prometheus-operator/pkg/client/versioned"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	e2eservice "k8s.io/kubernetes/test/e2e/framework/service"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	clientset "k8s.io/client-go/kubernetes"
+	aggregatorclient "k8s.io/kube-aggregator/pkg/client/clientset_generated/clientset"
 	admissionapi "k8s.io/pod-security-admission/api"
 
 	"k8s.io/kubernetes/test/e2e/framework"
-	e2egpu "k8s.io/kubernetes/test/e2e/framework/gpu"
+	e2econfig "k8s.io/kubernetes/test/e2e/framework/config"
+	e2edeployment "k8s.io/kubernetes/test/e2e/framework/deployment"
 	e2enode "k8s.io/kubernetes/test/e2e/framework/node"
+	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
 	e2eskipper "k8s.io/kubernetes/test/e2e/framework/skipper"
+	imageutils "k8s.io/kubernetes/test/utils/image"
 
 	frameworkutil "github.com/carlory/ai-conformance/e2e/util/framework"
-	e2eautoscaling "github.com/carlory/ai-conformance/e2e/util/framework/autoscaling"
+	lokiutil "github.com/carlory/ai-conformance/e2e/util/loki"
 	prometheusutil "github.com/carlory/ai-conformance/e2e/util/prometheus"
 )
 
+var prometheus struct {
+	Name      string `default:"" usage:"name of the Prometheus instance to query. If unspecified, the only instance which can select the created ServiceMonitors is used"`
+	Namespace string `default:"" usage:"namespace of the Prometheus instance to query. If unspecified, instances in all namespaces are considered"`
+}
+var _ = e2econfig.AddOptions(&prometheus, "ai.prometheus")
+
+var acceleratorMetrics struct {
+	RequireTLS      bool    `default:"false" usage:"if true, the exporters of the accelerator metrics are required to be scraped over HTTPS, e.g. on the platforms which mandate TLS on the metrics endpoints. Most exporters serve HTTP by default, so the check is skipped unless it's set"`
+	ExpectedMemory  string  `default:"" usage:"comma-separated <product>=<quantity> entries of the total memory of each GPU of the products, e.g. NVIDIA-A100-SXM4-80GB=80Gi, which the exporters must report. Spaces in the product names are equivalent to dashes. If unspecified, the total memory of the GPUs of the same product only needs to be non-zero and consistent"`
+	MemoryTolerance float64 `default:"0.05" usage:"fraction of the expected total memory of a GPU, or of the largest one of its product, which the total memory reported by the exporter may differ by"`
+}
+var _ = e2econfig.AddOptions(&acceleratorMetrics, "ai.acceleratorMetrics")
+
 var _ = WGDescribe("Accelerator Metrics", func() {
 	f := framework.NewDefaultFramework("accelerator-metrics")
 	f.SkipNamespaceCreation = true
@@ -30,74 +54,488 @@ var _ = WGDescribe("Accelerator Metrics", func() {
 
 	framework.Context("nvidia gpu", func() {
 		ginkgo.BeforeEach(func(ctx context.Context) {
-			nodes, err := e2enode.GetReadyNodesIncludingTainted(ctx, f.ClientSet)
-			framework.ExpectNoError(err)
+			skipUnlessAcceleratorAllocatable(ctx, f.ClientSet, frameworkutil.NVIDIA)
+		})
 
-			capacity := 0
-			allocatable := 0
-			for _, node := range nodes.Items {
-				val, ok := node.Status.Capacity[e2egpu.NVIDIAGPUResourceName]
-				if !ok {
-					continue
+		/*
+			Release: v1.33
+			Testname: Nvidia GPU Metrics
+			Description: Query the prometheus and verify that the gpu deivce metrics MUST be collected. The utilization
+			and framebuffer usage metrics of the DCGM exporter, i.e. DCGM_FI_DEV_GPU_UTIL and DCGM_FI_DEV_FB_USED, MUST
+			be collected.
+		*/
+		frameworkutil.AIConformanceIt("metrics should be collected from the GPU node", func(ctx context.Context) {
+			vendor := withExpectedMetrics(frameworkutil.NVIDIA)
+			verifyAcceleratorMetricsCollected(ctx, f, vendor, vendor.MissingCoreMetrics, timeToWait)
+		})
+
+		// A partially failing DCGM exporter, e.g. one which can't read some of the GPUs, only emits the metrics of
+		// the others, which the check of the collected metrics above doesn't catch.
+		frameworkutil.AIConformanceShouldIt("metrics should be collected for every GPU of the ready nodes", func(ctx context.Context) {
+			verifyAcceleratorMetricsComplete(ctx, f, frameworkutil.NVIDIA, timeToWait)
+		})
+
+		// An exporter reporting zero or garbage memory, e.g. one which can't read the framebuffer of some GPUs,
+		// misleads the capacity planning based on the metrics.
+		frameworkutil.AIConformanceShouldIt("the total memory reported for every GPU should match its product", func(ctx context.Context) {
+			verifyAcceleratorMemoryTotal(ctx, f, frameworkutil.NVIDIA, timeToWait)
+		})
+	})
+
+	framework.Context("amd gpu", func() {
+		ginkgo.BeforeEach(func(ctx context.Context) {
+			skipUnlessAcceleratorAllocatable(ctx, f.ClientSet, frameworkutil.AMD)
+		})
+
+		/*
+			Release: v1.34
+			Testname: AMD GPU Metrics
+			Description: Query the prometheus and verify that the gpu deivce metrics MUST be collected. The utilization
+			and memory usage metrics of the AMD SMI exporter or the AMD device metrics exporter MUST be collected.
+		*/
+		frameworkutil.AIConformanceIt("metrics should be collected from the AMD GPU node", func(ctx context.Context) {
+			vendor := withExpectedMetrics(frameworkutil.AMD)
+			verifyAcceleratorMetricsCollected(ctx, f, vendor, vendor.MissingCoreMetrics, timeToWait)
+		})
+	})
+
+	framework.Context("recommended gpu metrics", func() {
+		var vendor *frameworkutil.AcceleratorVendor
+
+		ginkgo.BeforeEach(func(ctx context.Context) {
+			vendor = skipUnlessAcceleratorAllocatable(ctx, f.ClientSet)
+		})
+
+		// The temperature and power usage metrics are recommended for the health monitoring of the accelerators,
+		// but they are not required by the conformance.
+		frameworkutil.AIConformanceShouldIt("temperature and power usage metrics should be collected from the GPU node", func(ctx context.Context) {
+			verifyAcceleratorMetricsCollected(ctx, f, *vendor, vendor.MissingRecommendedMetrics, timeToWait)
+		})
+	})
+
+	framework.Context("secured gpu metrics", func() {
+		var vendor *frameworkutil.AcceleratorVendor
+
+		ginkgo.BeforeEach(func(ctx context.Context) {
+			if !acceleratorMetrics.RequireTLS {
+				e2eskipper.Skipf("TLS on the metrics endpoints of the accelerator exporters is not required, set -ai.acceleratorMetrics.requireTLS to check it")
+			}
+			vendor = skipUnlessAcceleratorAllocatable(ctx, f.ClientSet)
+		})
+
+		// Some platforms mandate TLS on the metrics endpoints, so that the metrics can't be read or tampered with
+		// in transit. Every target which the accelerator metrics are scraped from is checked, whether it's
+		// configured by a ServiceMonitor, a PodMonitor or a scrape config.
+		frameworkutil.AIConformanceShouldIt("metrics should be scraped from the GPU exporters over HTTPS", func(ctx context.Context) {
+			ginkgo.By("Getting the Prometheus instance")
+			promOpClient, err := monitoring.NewForConfig(f.ClientConfig())
+			framework.ExpectNoError(err, "error when creating prometheus operator client")
+			prom, err := prometheusutil.SelectPrometheus(ctx, promOpClient, f.ClientSet, prometheus.Namespace, prometheus.Name, "")
+			framework.ExpectNoError(err, "error when selecting the Prometheus instance")
+
+			ginkgo.By(fmt.Sprintf("Query the prometheus for the jobs scraping the %s gpu metrics", vendor.Name))
+			query := fmt.Sprintf(`count by (job) ({__name__=~"%s"})`, vendor.MetricPrefixRegex())
+			var jobs []string
+			err = framework.Gomega().Eventually(ctx, func(ctx context.Context) error {
+				resp, err := prometheusutil.Query(ctx, f.ClientSet, prom, query)
+				if err != nil {
+					return err
 				}
-				capacity += int(val.Value())
-				val, ok = node.Status.Allocatable[e2egpu.NVIDIAGPUResourceName]
-				if !ok {
-					continue
+				if jobs = resp.LabelValues("job"); len(jobs) == 0 {
+					return fmt.Errorf("metrics with prefixes %v not found", vendor.MetricPrefixes)
 				}
-				allocatable += int(val.Value())
-			}
+				return nil
+			}).WithTimeout(timeToWait).WithPolling(15 * time.Second).Should(gomega.Succeed())
+			framework.ExpectNoError(err, "error when waiting for the metrics to be collected")
 
-			if capacity == 0 {
-				e2eskipper.Skipf("%d ready nodes do not have any Nvidia GPU(s). Skipping...", len(nodes.Items))
-			}
-			if allocatable == 0 {
-				e2eskipper.Skipf("%d ready nodes do not have any allocatable Nvidia GPU(s). Skipping...", len(nodes.Items))
+			ginkgo.By(fmt.Sprintf("Verifying the targets of jobs %v are scraped over HTTPS", jobs))
+			targets, err := prometheusutil.ActiveTargets(ctx, f.ClientSet, prom)
+			framework.ExpectNoError(err, "error when getting the targets of the Prometheus instance")
+			targets = prometheusutil.JobTargets(targets, jobs)
+			gomega.Expect(targets).NotTo(gomega.BeEmpty(), "no active target of jobs %v", jobs)
+			var plaintext []string
+			for _, target := range targets {
+				framework.Logf("Target %s of scrape pool %s is %s", target.ScrapeURL, target.ScrapePool, target.Health)
+				if !target.IsHTTPS() {
+					plaintext = append(plaintext, target.ScrapeURL)
+				}
 			}
+			gomega.Expect(plaintext).To(gomega.BeEmpty(), "the %s gpu metrics should be scraped over HTTPS", vendor.Name)
+		})
+	})
+
+	framework.Context("gpu workload", func() {
+		// The workload needs a namespace, which is skipped by the framework of the parent container.
+		f := framework.NewDefaultFramework("accelerator-metrics-workload")
+		f.NamespacePodSecurityLevel = admissionapi.LevelBaseline
+		const step = 15 * time.Second
+		// continuityWindow is the window in which every series is expected to have a sample. It's larger than the
+		// common scrape intervals of the exporters, and much smaller than the 5m lookback of Prometheus which
+		// would hide short gaps.
+		const continuityWindow = time.Minute
+		var vendor *frameworkutil.AcceleratorVendor
+
+		ginkgo.BeforeEach(func(ctx context.Context) {
+			vendor = skipUnlessAcceleratorAllocatable(ctx, f.ClientSet)
 		})
 
 		/*
-			Release: v1.33
-			Testname: Nvidia GPU Metrics
-			Description: Query the prometheus and verify that the gpu deivce metrics MUST be collected.
+			Release: v1.34
+			Testname: GPU Metrics, workload churn
+			Description: Create a Deployment whose pod requests 1 GPU and verify that the gpu device metrics
+			attributed to the pod MUST be collected. Delete the pod and verify that the metrics attributed to the
+			recreated pod MUST be collected within 15 minutes, and the gpu device metrics of the cluster MUST NOT
+			be absent for more than 1 minute in the meantime.
 		*/
-		frameworkutil.AIConformanceIt("metrics should be collected from the GPU node", func(ctx context.Context) {
+		frameworkutil.AIConformanceIt("metrics should be collected for the recreated workload pod", func(ctx context.Context) {
+			ns := f.Namespace.Name
+			name := "gpu-workload"
+			// The utilization metric is the first core metric of the vendor.
+			metricRegex := vendor.CoreMetrics[0]
+
 			ginkgo.By("Getting the Prometheus instance")
 			promOpClient, err := monitoring.NewForConfig(f.ClientConfig())
 			framework.ExpectNoError(err, "error when creating prometheus operator client")
-			promList, err := promOpClient.MonitoringV1().Prometheuses(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
-			framework.ExpectNoError(err, "error when getting Prometheus list")
-			gomega.Expect(promList.Items).ToNot(gomega.BeEmpty(), "at least one Prometheus should be found")
-			prom := promList.Items[0]
-
-			ginkgo.By("Query the prometheus and verify that the metrics are collected")
-			metricNamePrefix := "DCGM_FI_DEV"
-			query := fmt.Sprintf(`count by (__name__) ({__name__=~"^%s.*"})`, metricNamePrefix)
+			prom, err := prometheusutil.SelectPrometheus(ctx, promOpClient, f.ClientSet, prometheus.Namespace, prometheus.Name, "")
+			framework.ExpectNoError(err, "error when selecting the Prometheus instance")
+
+			lockAccelerators(ctx, f, vendor.ResourceName)
+
+			ginkgo.By(fmt.Sprintf("Creating a Deployment requesting 1 %s", vendor.ResourceName))
+			podLabels := map[string]string{"app": name}
+			d := e2edeployment.NewDeployment(name, 1, podLabels, "main", imageutils.GetE2EImage(imageutils.BusyBox), appsv1.RecreateDeploymentStrategyType)
+			d.Spec.Template.Spec.Containers[0].Command = []string{"/bin/sh", "-c", "trap exit TERM; while true; do sleep 1; done"}
+			d.Spec.Template.Spec.Containers[0].Resources.Limits = v1.ResourceList{
+				vendor.ResourceName: resource.MustParse("1"),
+			}
+			requireAcceleratorNode(ctx, f.ClientSet, &d.Spec.Template.Spec, vendor.ResourceName)
+			d, err = f.ClientSet.AppsV1().Deployments(ns).Create(ctx, d, metav1.CreateOptions{})
+			framework.ExpectNoError(err, "error when creating deployment")
+			frameworkutil.DeferCleanup(f.ClientSet.AppsV1().Deployments(ns).Delete, d.Name, metav1.DeleteOptions{})
+			err = frameworkutil.WaitForDeploymentComplete(ctx, f.ClientSet, d, f.Timeouts.PodStart)
+			framework.ExpectNoError(err, "error when waiting for deployment to complete")
+			pods, err := e2edeployment.GetPodsForDeployment(ctx, f.ClientSet, d)
+			framework.ExpectNoError(err, "error when getting pods of deployment")
+			gomega.Expect(pods.Items).To(gomega.HaveLen(1), "deployment should have exactly 1 pod")
+			oldPod := pods.Items[0]
+
+			waitForPodMetrics := func(podName string) {
+				// The exporter attributes the metrics to the pod with the pod label. It's renamed to exported_pod
+				// if the exporter is scraped without honorLabels, so both labels are checked.
+				query := fmt.Sprintf(`{__name__=~"%[1]s", namespace="%[2]s", pod="%[3]s"} or {__name__=~"%[1]s", exported_namespace="%[2]s", exported_pod="%[3]s"}`, metricRegex, ns, podName)
+				err := framework.Gomega().Eventually(ctx, func(ctx context.Context) error {
+					resp, err := prometheusutil.Query(ctx, f.ClientSet, prom, query)
+					if err != nil {
+						return err
+					}
+					if len(resp.Data.Result) == 0 {
+						return fmt.Errorf("metric %q of pod %s not found", metricRegex, podName)
+					}
+					return nil
+				}).WithTimeout(timeToWait).WithPolling(step).Should(gomega.Succeed())
+				framework.ExpectNoError(err, "error when waiting for the metrics of pod %s to be collected", podName)
+			}
+
+			ginkgo.By("Waiting for the metrics of the workload pod to be collected")
+			waitForPodMetrics(oldPod.Name)
+			start := time.Now()
+
+			ginkgo.By("Deleting the workload pod and waiting for it to be recreated")
+			err = f.ClientSet.CoreV1().Pods(ns).Delete(ctx, oldPod.Name, metav1.DeleteOptions{})
+			framework.ExpectNoError(err, "error when deleting pod %s", oldPod.Name)
+			// The status of the deployment may not reflect the deletion yet, so the recreated pod is identified by
+			// its UID instead.
+			var newPod *v1.Pod
 			err = framework.Gomega().Eventually(ctx, func(ctx context.Context) error {
-				proxyRequest, err := e2eservice.GetServicesProxyRequest(f.ClientSet, f.ClientSet.CoreV1().RESTClient().Get())
+				pods, err := f.ClientSet.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{LabelSelector: labels.SelectorFromSet(podLabels).String()})
 				if err != nil {
 					return err
 				}
-				req := proxyRequest.Namespace(prom.Namespace).
-					Name(fmt.Sprintf("%s:http-web", prom.Name)).
-					Suffix("/api/v1/query").
-					Param("query", query)
-				framework.Logf("Query URL: %v", *req.URL())
-				data, err := req.DoRaw(ctx)
+				for i := range pods.Items {
+					pod := &pods.Items[i]
+					if pod.UID != oldPod.UID && pod.DeletionTimestamp == nil {
+						newPod = pod
+						return nil
+					}
+				}
+				return fmt.Errorf("pod %s is not recreated yet", oldPod.Name)
+			}).WithTimeout(f.Timeouts.PodStart).WithPolling(framework.Poll).Should(gomega.Succeed())
+			framework.ExpectNoError(err, "error when waiting for pod %s to be recreated", oldPod.Name)
+			err = e2epod.WaitForPodRunningInNamespace(ctx, f.ClientSet, newPod)
+			framework.ExpectNoError(err, "error when waiting for the recreated pod %s to be running", newPod.Name)
+
+			ginkgo.By("Waiting for the metrics of the recreated workload pod to be collected")
+			waitForPodMetrics(newPod.Name)
+
+			ginkgo.By(fmt.Sprintf("Verifying the gpu device metrics of the cluster were never absent for more than %v", continuityWindow))
+			end := time.Now()
+			query := fmt.Sprintf(`absent_over_time({__name__=~"%s"}[%ds])`, metricRegex, int(continuityWindow.Seconds()))
+			resp, err := prometheusutil.QueryRange(ctx, f.ClientSet, prom, query, start, end, step)
+			framework.ExpectNoError(err, "error when querying the absence of the gpu device metrics over time")
+			// absent_over_time only returns a value at the steps when no series had a sample within the window.
+			var absentAt []string
+			for _, sample := range resp.Data.Result {
+				for _, value := range sample.Values {
+					absentAt = append(absentAt, time.Unix(int64(value.Timestamp), 0).UTC().Format(time.RFC3339))
+				}
+			}
+			gomega.Expect(absentAt).To(gomega.BeEmpty(), "gpu device metrics %q should be collected between %v and %v", metricRegex, start, end)
+		})
+	})
+
+	framework.Context("shared gpu workloads", func() {
+		f := framework.NewDefaultFramework("accelerator-metrics-shared")
+		f.NamespacePodSecurityLevel = admissionapi.LevelBaseline
+		const step = 15 * time.Second
+		const pods = 2
+
+		// Attributing the metrics of a shared GPU, e.g. time-sliced or MIG, to each of the consuming pods is
+		// recommended for the troubleshooting and the chargeback of the inference workloads, but it's not required
+		// by the conformance. The spec is skipped if the exporter attributes the metrics to none of the pods.
+		frameworkutil.AIConformanceShouldIt("metrics should be attributed to each pod sharing the GPU", func(ctx context.Context) {
+			ns := f.Namespace.Name
+			// The fractional accelerators are exposed by the device plugins of NVIDIA, whose utilization metric is
+			// the first core metric.
+			metricRegex := withExpectedMetrics(frameworkutil.NVIDIA).CoreMetrics[0]
+			nodes, err := e2enode.GetReadyNodesIncludingTainted(ctx, f.ClientSet)
+			framework.ExpectNoError(err, "error when listing ready nodes")
+			resources, resourceNames := skipUnlessFractionalAcceleratorAllocatable(nodes.Items)
+			node := frameworkutil.SelectSharedAcceleratorNode(nodes.Items, resources, pods)
+			if node == nil {
+				e2eskipper.Skipf("None of the %d ready nodes can run %d pods requesting %v", len(nodes.Items), pods, resources)
+			}
+
+			ginkgo.By("Getting the Prometheus instance")
+			promOpClient, err := monitoring.NewForConfig(f.ClientConfig())
+			framework.ExpectNoError(err, "error when creating prometheus operator client")
+			prom, err := prometheusutil.SelectPrometheus(ctx, promOpClient, f.ClientSet, prometheus.Namespace, prometheus.Name, "")
+			framework.ExpectNoError(err, "error when selecting the Prometheus instance")
+
+			lockAccelerators(ctx, f, resourceNames[0])
+
+			ginkgo.By(fmt.Sprintf("Creating %d pods requesting %v on node %s", pods, resources, node.Name))
+			var podNames []string
+			for range pods {
+				pod := e2epod.MakePod(ns, nil, nil, f.NamespacePodSecurityLevel, "")
+				pod.Spec.NodeName = node.Name
+				pod.Spec.Tolerations = []v1.Toleration{
+					{
+						Effect:   v1.TaintEffectNoSchedule,
+						Operator: v1.TolerationOpExists,
+					},
+				}
+				pod.Spec.Containers[0].Resources.Limits = resources
+				pod, err := f.ClientSet.CoreV1().Pods(ns).Create(ctx, pod, metav1.CreateOptions{})
+				framework.ExpectNoError(err, "error when creating pod")
+				frameworkutil.DeferCleanup(f.ClientSet.CoreV1().Pods(ns).Delete, pod.Name, metav1.DeleteOptions{})
+				err = e2epod.WaitForPodRunningInNamespace(ctx, f.ClientSet, pod)
+				framework.ExpectNoError(err, "error when waiting for pod to be running")
+				podNames = append(podNames, pod.Name)
+			}
+
+			ginkgo.By("Waiting for the metrics of each pod to be collected")
+			// The exporter attributes the metrics to the pod with the pod label. It's renamed to exported_pod if
+			// the exporter is scraped without honorLabels, so both labels are checked.
+			query := fmt.Sprintf(`{__name__=~"%[1]s", namespace="%[2]s"} or {__name__=~"%[1]s", exported_namespace="%[2]s"}`, metricRegex, ns)
+			var attributed map[string][]prometheusutil.Sample
+			err = framework.Gomega().Eventually(ctx, func(ctx context.Context) error {
+				resp, err := prometheusutil.Query(ctx, f.ClientSet, prom, query)
 				if err != nil {
 					return err
 				}
-				framework.Logf("Query result: %s", string(data))
-				if !strings.Contains(string(data), metricNamePrefix) {
-					return fmt.Errorf("metrics with prefix %q not found: %s", metricNamePrefix, string(data))
+				attributed = resp.GroupByLabel("exported_pod", "pod")
+				var missing []string
+				for _, podName := range podNames {
+					if _, ok := attributed[podName]; !ok {
+						missing = append(missing, podName)
+					}
+				}
+				if len(missing) > 0 {
+					return fmt.Errorf("metric %q of pods %v not found", metricRegex, missing)
 				}
 				return nil
-			}).WithTimeout(timeToWait).WithPolling(15 * time.Second).Should(gomega.Succeed())
-			framework.ExpectNoError(err, "error when waiting for the metrics to be collected")
+			}).WithTimeout(timeToWait).WithPolling(step).Should(gomega.Succeed())
+			if err != nil && len(attributed) == 0 {
+				e2eskipper.Skipf("The exporter doesn't attribute metric %q of the shared GPUs to the pods: %v", metricRegex, err)
+			}
+			framework.ExpectNoError(err, "error when waiting for the metrics of each pod sharing the GPU to be collected")
+			for _, podName := range podNames {
+				framework.Logf("Metric %q of pod %s: %v", metricRegex, podName, attributed[podName])
+			}
 		})
 	})
 })
 
+var _ = WGDescribe("Accelerator Metrics", func() {
+	f := framework.NewDefaultFramework("kube-state-metrics")
+	f.NamespacePodSecurityLevel = admissionapi.LevelBaseline
+	const timeToWait = 5 * time.Minute
+
+	// The accelerators allocatable on the nodes and requested by the pods should be exposed by kube-state-metrics
+	// for capacity planning, in addition to the device metrics of the exporters. It's not required by the
+	// conformance. kube_node_status_allocatable of every accelerator node MUST match its allocatable accelerators,
+	// and kube_pod_container_resource_requests MUST report the accelerator requested by a pod. The spec is skipped
+	// if kube-state-metrics isn't deployed.
+	frameworkutil.AIConformanceShouldIt("kube-state-metrics should expose the allocatable and requested accelerators", func(ctx context.Context) {
+		ns := f.Namespace.Name
+		vendor := skipUnlessAcceleratorAllocatable(ctx, f.ClientSet)
+		svcList, err := f.ClientSet.CoreV1().Services("").List(ctx, metav1.ListOptions{LabelSelector: prometheusutil.KubeStateMetricsSelector})
+		framework.ExpectNoError(err, "error when listing kube-state-metrics Services")
+		if len(svcList.Items) == 0 {
+			e2eskipper.Skipf("kube-state-metrics is not deployed, no Service is labeled %s", prometheusutil.KubeStateMetricsSelector)
+		}
+
+		ginkgo.By("Getting the Prometheus instance")
+		promOpClient, err := monitoring.NewForConfig(f.ClientConfig())
+		framework.ExpectNoError(err, "error when creating prometheus operator client")
+		prom, err := prometheusutil.SelectPrometheus(ctx, promOpClient, f.ClientSet, prometheus.Namespace, prometheus.Name, "")
+		framework.ExpectNoError(err, "error when selecting the Prometheus instance")
+		resourceLabel := prometheusutil.KubeStateMetricsResource(vendor.ResourceName)
+
+		ginkgo.By(fmt.Sprintf("Verifying kube_node_status_allocatable of %s matches the allocatable accelerators of the nodes", resourceLabel))
+		// kube-state-metrics labels the series with the node, namespace and pod of the objects. They're renamed to
+		// exported_* if it's scraped without honorLabels, so both labels are checked.
+		query := fmt.Sprintf(`kube_node_status_allocatable{resource="%s"}`, resourceLabel)
+		err = framework.Gomega().Eventually(ctx, func(ctx context.Context) error {
+			nodes, err := e2enode.GetReadyNodesIncludingTainted(ctx, f.ClientSet)
+			if err != nil {
+				return err
+			}
+			want := map[string]float64{}
+			for _, node := range nodes.Items {
+				if val, ok := node.Status.Allocatable[vendor.ResourceName]; ok {
+					want[node.Name] = float64(val.Value())
+				}
+			}
+			resp, err := prometheusutil.Query(ctx, f.ClientSet, prom, query)
+			if err != nil {
+				return err
+			}
+			if mismatched := resp.MismatchedValues(want, "exported_node", "node"); len(mismatched) > 0 {
+				return fmt.Errorf("%s is inconsistent with the nodes: %v", query, mismatched)
+			}
+			return nil
+		}).WithTimeout(timeToWait).WithPolling(15 * time.Second).Should(gomega.Succeed())
+		framework.ExpectNoError(err, "error when waiting for the allocatable accelerators to be collected")
+
+		lockAccelerators(ctx, f, vendor.ResourceName)
+		verifyAcceleratorsReleased(ctx, f, vendor.ResourceName)
+
+		ginkgo.By(fmt.Sprintf("Creating a pod requesting 1 %s", vendor.ResourceName))
+		pod := e2epod.MakePod(ns, nil, nil, f.NamespacePodSecurityLevel, "")
+		pod.Spec.Containers[0].Resources.Limits = v1.ResourceList{
+			vendor.ResourceName: resource.MustParse("1"),
+		}
+		requireAcceleratorNode(ctx, f.ClientSet, &pod.Spec, vendor.ResourceName)
+		pod, err = f.ClientSet.CoreV1().Pods(ns).Create(ctx, pod, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "error when creating pod")
+		frameworkutil.DeferCleanup(f.ClientSet.CoreV1().Pods(ns).Delete, pod.Name, metav1.DeleteOptions{})
+		err = e2epod.WaitForPodRunningInNamespace(ctx, f.ClientSet, pod)
+		framework.ExpectNoError(err, "error when waiting for pod to be running")
+
+		ginkgo.By(fmt.Sprintf("Verifying kube_pod_container_resource_requests of %s reports the request of the pod", resourceLabel))
+		query = fmt.Sprintf(`kube_pod_container_resource_requests{resource="%[1]s", namespace="%[2]s"} or kube_pod_container_resource_requests{resource="%[1]s", exported_namespace="%[2]s"}`, resourceLabel, ns)
+		stopTiming := frameworkutil.StartTiming("kube-state-metrics collection")
+		err = framework.Gomega().Eventually(ctx, func(ctx context.Context) error {
+			resp, err := prometheusutil.Query(ctx, f.ClientSet, prom, query)
+			if err != nil {
+				return err
+			}
+			if mismatched := resp.MismatchedValues(map[string]float64{pod.Name: 1}, "exported_pod", "pod"); len(mismatched) > 0 {
+				return fmt.Errorf("%s doesn't report the request of pod %s: %v", query, pod.Name, mismatched)
+			}
+			return nil
+		}).WithTimeout(timeToWait).WithPolling(15 * time.Second).Should(gomega.Succeed())
+		stopTiming()
+		framework.ExpectNoError(err, "error when waiting for the requested accelerators to be collected")
+	})
+})
+
+var draMetrics struct {
+	MetricNames string `default:"dra_.+|resourceclaim_controller_.+" usage:"regular expression of the names of the metrics of the DRA control plane, e.g. the ones of the kubelet, of the resource claim controller of kube-controller-manager or of the DRA drivers, which are looked up in Prometheus by the DRA metrics spec. The spec is skipped if none is collected"`
+}
+var _ = e2econfig.AddOptions(&draMetrics, "ai.draMetrics")
+
+var _ = WGDescribe("DRA Support", func() {
+	f := framework.NewDefaultFramework("dra-metrics")
+	f.NamespacePodSecurityLevel = admissionapi.LevelBaseline
+	const timeToWait = 5 * time.Minute
+
+	ginkgo.BeforeEach(func(ctx context.Context) {
+		e2eskipper.SkipUnlessServerVersionGTE(utilversion.MustParseSemantic("v1.34.0"), f.ClientSet.Discovery())
+		// Check if Prometheus Operator is installed by trying to get its API resources.
+		frameworkutil.SkipIfGroupVersionUnavaliable(ctx, frameworkutil.CachedDiscovery(f.ClientSet), "monitoring.coreos.com/v1")
+	})
+
+	// The DRA control plane, i.e. the kubelet and the resource claim controller of kube-controller-manager, exposes
+	// metrics of the ResourceClaims, which make the device allocations observable next to the accelerator metrics.
+	// It's not required by the conformance. Create a ResourceClaim requesting a device of a DeviceClass of the
+	// installed DRA drivers and a pod using it. The metrics matching -ai.draMetrics.metricNames which count the
+	// allocated ResourceClaims, i.e. resourceclaim_controller_resource_claims and dra_resource_claims_in_use, MUST
+	// count at least 1 if they are collected. The spec is skipped if no metric matching -ai.draMetrics.metricNames is
+	// collected by Prometheus.
+	frameworkutil.AIConformanceShouldIt("the allocated ResourceClaims should be observable via the metrics of the DRA control plane", func(ctx context.Context) {
+		ns := f.Namespace.Name
+		request := newDRARequest(ctx, f)
+
+		ginkgo.By("Getting the Prometheus instance")
+		promOpClient, err := monitoring.NewForConfig(f.ClientConfig())
+		framework.ExpectNoError(err, "error when creating prometheus operator client")
+		prom, err := prometheusutil.SelectPrometheus(ctx, promOpClient, f.ClientSet, prometheus.Namespace, prometheus.Name, "")
+		framework.ExpectNoError(err, "error when selecting the Prometheus instance")
+
+		ginkgo.By(fmt.Sprintf("Listing the DRA metrics matching %s", draMetrics.MetricNames))
+		resp, err := prometheusutil.Query(ctx, f.ClientSet, prom, fmt.Sprintf(`count by (__name__) ({__name__=~"%s"})`, draMetrics.MetricNames))
+		framework.ExpectNoError(err, "error when querying the DRA metrics")
+		names := resp.MetricNames()
+		if len(names) == 0 {
+			e2eskipper.Skipf("No metric matching %s is collected by Prometheus %s/%s", draMetrics.MetricNames, prom.Namespace, prom.Name)
+		}
+		slices.Sort(names)
+		framework.Logf("DRA metrics collected by Prometheus %s/%s: %v", prom.Namespace, prom.Name, names)
+
+		ginkgo.By("Creating a pod using a ResourceClaim requesting a device of the DeviceClass")
+		claim, err := f.ClientSet.ResourceV1().ResourceClaims(ns).Create(ctx, newDeviceClassClaim("accelerator", request), metav1.CreateOptions{})
+		framework.ExpectNoError(err, "error when creating ResourceClaim")
+		frameworkutil.DeferCleanup(f.ClientSet.ResourceV1().ResourceClaims(ns).Delete, claim.Name, metav1.DeleteOptions{})
+		pod, err := f.ClientSet.CoreV1().Pods(ns).Create(ctx, newClaimPod(ns, f.NamespacePodSecurityLevel, claim.Name), metav1.CreateOptions{})
+		framework.ExpectNoError(err, "error when creating pod")
+		frameworkutil.DeferCleanup(f.ClientSet.CoreV1().Pods(ns).Delete, pod.Name, metav1.DeleteOptions{})
+		err = e2epod.WaitForPodRunningInNamespace(ctx, f.ClientSet, pod)
+		framework.ExpectNoError(err, "error when waiting for pod to be running")
+
+		queries := prometheusutil.DRAAllocationQueries(names)
+		if len(queries) == 0 {
+			framework.Logf("None of the collected DRA metrics counts the allocated ResourceClaims, skipping the allocation assertion")
+			return
+		}
+		for _, query := range queries {
+			ginkgo.By(fmt.Sprintf("Verifying %s counts the allocated ResourceClaim %s", query, claim.Name))
+			err = framework.Gomega().Eventually(ctx, func(ctx context.Context) error {
+				resp, err := prometheusutil.Query(ctx, f.ClientSet, prom, query)
+				if err != nil {
+					return err
+				}
+				if len(resp.Data.Result) == 0 || resp.Data.Result[0].Value == nil || resp.Data.Result[0].Value.Value < 1 {
+					return fmt.Errorf("%s counts no allocated ResourceClaim: %v", query, resp.Data.Result)
+				}
+				return nil
+			}).WithTimeout(timeToWait).WithPolling(15 * time.Second).Should(gomega.Succeed())
+			framework.ExpectNoError(err, "error when waiting for the allocated ResourceClaim to be counted")
+		}
+	})
+})
+
+var aiServiceMetrics struct {
+	Endpoints      string `default:"" usage:"comma-separated <port>[:<path>] endpoints, e.g. http:/metrics,system:/system/metrics, of the Service named ai.aiServiceMetrics.job in ai.aiServiceMetrics.namespace, which MUST all be scraped via a ServiceMonitor created for them. The path defaults to /metrics. If unspecified, the endpoint assertion is skipped"`
+	ExpectedLabels string `default:"" usage:"comma-separated key=value labels, e.g. model_name=llama,engine=vllm, which at least one series of the AI service selected by ai.aiServiceMetrics.job MUST carry. An empty value only requires the label to be present. If unspecified, the label assertion is skipped"`
+	Namespace      string `default:"" usage:"namespace of the AI service whose series are checked for the expected labels. If unspecified, series in all namespaces are considered. Required if ai.aiServiceMetrics.endpoints is specified"`
+	Job            string `default:"" usage:"job label of the series of the AI service which are checked for the expected labels, e.g. the name of its Service. Required if ai.aiServiceMetrics.endpoints, ai.aiServiceMetrics.expectedLabels or ai.aiServiceMetrics.server is specified"`
+	MetricName     string `default:"" usage:"regular expression of the metric names of the AI service which are checked for the expected labels, e.g. vllm:.*. If unspecified, all metrics of the job are considered"`
+	Server         string `default:"" usage:"inference server of the AI service selected by ai.aiServiceMetrics.job, e.g. vllm, whose required metrics in the expected metrics, see ai.expectedMetrics.file, MUST be emitted by the AI service. If unspecified, the metric assertion is skipped"`
+}
+var _ = e2econfig.AddOptions(&aiServiceMetrics, "ai.aiServiceMetrics")
+
 var _ = WGDescribe("AI Service Metrics", func() {
 	f := framework.NewDefaultFramework("ai-service-metrics")
 	f.NamespacePodSecurityLevel = admissionapi.LevelBaseline
@@ -105,14 +543,18 @@ var _ = WGDescribe("AI Service Metrics", func() {
 
 	ginkgo.BeforeEach(func(ctx context.Context) {
 		// Check if Prometheus Operator is installed by trying to get its API resources.
-		frameworkutil.SkipIfGroupVersionUnavaliable(ctx, f.ClientSet.Discovery(), "monitoring.coreos.com/v1")
+		frameworkutil.SkipIfGroupVersionUnavaliable(ctx, frameworkutil.CachedDiscovery(f.ClientSet), "monitoring.coreos.com/v1")
 	})
 
 	/*
 		Release: v1.33
 		Testname: AI Service Metrics
 		Description: Create a Deployment and exposes a custom metric via a ServiceMonitor. Query the prometheus
-		and verify that the metric MUST be collected.
+		and verify that the metric MUST be collected. If the inference server of the configured AI service job is
+		given by -ai.aiServiceMetrics.server, the job MUST emit its required metrics in the expected metrics. If the
+		endpoints of the AI service are given by -ai.aiServiceMetrics.endpoints, each of them MUST be scraped
+		successfully. If the expected labels are configured, at least one series of the configured AI service job
+		MUST carry them.
 	*/
 	frameworkutil.AIConformanceIt("metrics should be collected from the AI service", func(ctx context.Context) {
 		ns := f.Namespace.Name
@@ -122,43 +564,463 @@ var _ = WGDescribe("AI Service Metrics", func() {
 		ginkgo.By("Getting the Prometheus instance")
 		promOpClient, err := monitoring.NewForConfig(f.ClientConfig())
 		framework.ExpectNoError(err, "error when creating prometheus operator client")
-		promList, err := promOpClient.MonitoringV1().Prometheuses(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
-		framework.ExpectNoError(err, "error when getting Prometheus list")
-		gomega.Expect(promList.Items).ToNot(gomega.BeEmpty(), "at least one Prometheus should be found")
-		prom := promList.Items[0]
+		prom, err := prometheusutil.SelectPrometheus(ctx, promOpClient, f.ClientSet, prometheus.Namespace, prometheus.Name, ns)
+		framework.ExpectNoError(err, "error when selecting the Prometheus instance")
 
-		ginkgo.By("Create a resource consumer and initialize the custom metric value")
-		rc := e2eautoscaling.NewDynamicResourceConsumer(ctx, name, ns, e2eautoscaling.KindDeployment, 1, 0, 0,
-			150, 0, 0, metricName, f.ClientSet, f.ScalesGetter, e2eautoscaling.Disable, e2eautoscaling.Idle, nil)
-		ginkgo.DeferCleanup(rc.CleanUp)
+		ginkgo.By("Create a metric server and initialize the custom metric value")
+		_, err = frameworkutil.CreateMetricServer(ctx, f.ClientSet, ns, name, metricName, 150, 1)
+		framework.ExpectNoError(err, "error when creating the metric server")
 
 		ginkgo.By("Create a service monitor")
 		sm := prometheusutil.CreateServiceMonitor(ctx, promOpClient, prom, f.ClientSet, ns, name, map[string]string{"name": name}, "http")
 		framework.ExpectNoError(err, "error when creating service monitor")
-		ginkgo.DeferCleanup(promOpClient.MonitoringV1().ServiceMonitors(sm.Namespace).Delete, sm.Name, metav1.DeleteOptions{})
+		frameworkutil.DeferCleanup(promOpClient.MonitoringV1().ServiceMonitors(sm.Namespace).Delete, sm.Name, metav1.DeleteOptions{})
+		// Query the instance which scrapes the monitor, which may not be the selected one if multiple instances
+		// select the same labels.
+		prom, err = prometheusutil.PrometheusForServiceMonitor(ctx, promOpClient, f.ClientSet, sm, prom)
+		framework.ExpectNoError(err, "error when finding the Prometheus instance which selects the service monitor")
 
 		ginkgo.By("Wait for the metrics to be collected")
 		query := fmt.Sprintf(`count by (__name__) ({job="%s", namespace="%s"})`, name, ns)
+		stopTiming := frameworkutil.StartTiming("service metric collection")
 		err = framework.Gomega().Eventually(ctx, func(ctx context.Context) error {
-			proxyRequest, err := e2eservice.GetServicesProxyRequest(f.ClientSet, f.ClientSet.CoreV1().RESTClient().Get())
+			resp, err := prometheusutil.Query(ctx, f.ClientSet, prom, query)
 			if err != nil {
 				return err
 			}
-			req := proxyRequest.Namespace(prom.Namespace).
-				Name(fmt.Sprintf("%s:http-web", prom.Name)).
-				Suffix("/api/v1/query").
-				Param("query", query)
-			framework.Logf("Query URL: %v", *req.URL())
-			data, err := req.DoRaw(ctx)
+			if !slices.Contains(resp.MetricNames(), metricName) {
+				return fmt.Errorf("metric %q not found", metricName)
+			}
+			return nil
+		}).WithTimeout(timeToWait).WithPolling(15 * time.Second).Should(gomega.Succeed())
+		stopTiming()
+		framework.ExpectNoError(err, "error when waiting for the metrics to be collected")
+
+		if aiServiceMetrics.Server != "" {
+			verifyAIServiceExpectedMetrics(ctx, f, prom, timeToWait)
+		}
+		if aiServiceMetrics.Endpoints != "" {
+			verifyAIServiceEndpointsScraped(ctx, f, promOpClient, prom, timeToWait)
+		}
+
+		if aiServiceMetrics.ExpectedLabels == "" {
+			framework.Logf("No expected labels are configured via ai.aiServiceMetrics.expectedLabels, skipping the label assertion")
+			return
+		}
+		// The metric server only emits the synthetic custom metric, so the labels are asserted against the
+		// series of the real AI service running in the cluster.
+		if aiServiceMetrics.Job == "" {
+			framework.Failf("ai.aiServiceMetrics.job must be specified to select the series of the AI service which carry ai.aiServiceMetrics.expectedLabels")
+		}
+		ginkgo.By(fmt.Sprintf("Verify at least one series of the AI service job %q carries the expected labels", aiServiceMetrics.Job))
+		expectedLabels, err := labels.ConvertSelectorToLabelsMap(aiServiceMetrics.ExpectedLabels)
+		framework.ExpectNoError(err, "error when parsing expected labels %q", aiServiceMetrics.ExpectedLabels)
+		metricNameRegex := aiServiceMetrics.MetricName
+		if metricNameRegex == "" {
+			metricNameRegex = ".+"
+		}
+		selector := fmt.Sprintf(`__name__=~"%s", job="%s"`, metricNameRegex, aiServiceMetrics.Job)
+		if aiServiceMetrics.Namespace != "" {
+			selector += fmt.Sprintf(`, namespace="%s"`, aiServiceMetrics.Namespace)
+		}
+		err = framework.Gomega().Eventually(ctx, func(ctx context.Context) error {
+			resp, err := prometheusutil.Query(ctx, f.ClientSet, prom, fmt.Sprintf(`{%s}`, selector))
 			if err != nil {
 				return err
 			}
-			framework.Logf("Query result: %s", string(data))
-			if !strings.Contains(string(data), "e2e:custom_metric") {
-				return fmt.Errorf("metric %q not found: %s", metricName, string(data))
+			if len(resp.Data.Result) == 0 {
+				return fmt.Errorf("no series of the AI service matches {%s}", selector)
+			}
+			if matched := resp.SeriesWithLabels(expectedLabels); len(matched) == 0 {
+				return fmt.Errorf("none of the %d series matching {%s} carries the labels %v, metrics: %v", len(resp.Data.Result), selector, expectedLabels, resp.MetricNames())
 			}
 			return nil
 		}).WithTimeout(timeToWait).WithPolling(15 * time.Second).Should(gomega.Succeed())
-		framework.ExpectNoError(err, "error when waiting for the metrics to be collected")
+		framework.ExpectNoError(err, "error when verifying the labels of the AI service series")
+	})
+})
+
+// verifyAIServiceExpectedMetrics waits until the series of the AI service job include all the required metrics of
+// the inference server given by -ai.aiServiceMetrics.server, and logs its missing optional metrics.
+func verifyAIServiceExpectedMetrics(ctx context.Context, f *framework.Framework, prom monitoringv1.Prometheus, timeout time.Duration) {
+	if aiServiceMetrics.Job == "" {
+		framework.Failf("ai.aiServiceMetrics.job must be specified to select the series of the AI service which emit the metrics of ai.aiServiceMetrics.server")
+	}
+	expected, err := frameworkutil.LoadExpectedMetrics()
+	framework.ExpectNoError(err, "error when loading the expected metrics")
+	names, ok := expected.AIService[aiServiceMetrics.Server]
+	if !ok {
+		framework.Failf("inference server %q of ai.aiServiceMetrics.server has no expected metrics", aiServiceMetrics.Server)
+	}
+
+	ginkgo.By(fmt.Sprintf("Verify the AI service job %q emits the required metrics of %s", aiServiceMetrics.Job, aiServiceMetrics.Server))
+	selector := fmt.Sprintf(`job="%s"`, aiServiceMetrics.Job)
+	if aiServiceMetrics.Namespace != "" {
+		selector += fmt.Sprintf(`, namespace="%s"`, aiServiceMetrics.Namespace)
+	}
+	var collected []string
+	err = framework.Gomega().Eventually(ctx, func(ctx context.Context) error {
+		resp, err := prometheusutil.Query(ctx, f.ClientSet, prom, fmt.Sprintf(`count by (__name__) ({%s})`, selector))
+		if err != nil {
+			return err
+		}
+		collected = resp.MetricNames()
+		if missing := names.MissingRequired(collected); len(missing) > 0 {
+			return fmt.Errorf("required metrics %v of %s not found in %v", missing, aiServiceMetrics.Server, collected)
+		}
+		return nil
+	}).WithTimeout(timeout).WithPolling(15 * time.Second).Should(gomega.Succeed())
+	framework.ExpectNoError(err, "error when waiting for the required metrics of the AI service to be collected")
+	if missing := names.MissingOptional(collected); len(missing) > 0 {
+		framework.Logf("Optional metrics %v of %s are not emitted by the AI service", missing, aiServiceMetrics.Server)
+	}
+}
+
+// verifyAIServiceEndpointsScraped creates a ServiceMonitor with the endpoints of -ai.aiServiceMetrics.endpoints for
+// the Service of the AI service job, and waits until each endpoint has a healthy target. Inference servers may
+// expose e.g. the model metrics and the system metrics on different ports or paths.
+func verifyAIServiceEndpointsScraped(ctx context.Context, f *framework.Framework, promOpClient monitoring.Interface, prom monitoringv1.Prometheus, timeout time.Duration) {
+	if aiServiceMetrics.Job == "" || aiServiceMetrics.Namespace == "" {
+		framework.Failf("ai.aiServiceMetrics.job and ai.aiServiceMetrics.namespace must be specified to select the Service of the AI service which exposes ai.aiServiceMetrics.endpoints")
+	}
+	endpoints, err := prometheusutil.ParseServiceMonitorEndpoints(aiServiceMetrics.Endpoints)
+	framework.ExpectNoError(err, "error when parsing ai.aiServiceMetrics.endpoints")
+	// The job label of the targets of a ServiceMonitor is the name of the Service by default.
+	svc, err := f.ClientSet.CoreV1().Services(aiServiceMetrics.Namespace).Get(ctx, aiServiceMetrics.Job, metav1.GetOptions{})
+	framework.ExpectNoError(err, "error when getting the Service of the AI service job %q", aiServiceMetrics.Job)
+	if len(svc.Labels) == 0 {
+		framework.Failf("Service %s/%s of the AI service has no labels which a ServiceMonitor can select", svc.Namespace, svc.Name)
+	}
+
+	ginkgo.By(fmt.Sprintf("Create a service monitor scraping the endpoints %v of the AI service %s/%s", endpoints, svc.Namespace, svc.Name))
+	sm := prometheusutil.CreateServiceMonitorWithEndpoints(ctx, promOpClient, prom, f.ClientSet, svc.Namespace, f.UniqueName, svc.Labels, endpoints)
+	frameworkutil.DeferCleanup(promOpClient.MonitoringV1().ServiceMonitors(sm.Namespace).Delete, sm.Name, metav1.DeleteOptions{})
+	prom, err = prometheusutil.PrometheusForServiceMonitor(ctx, promOpClient, f.ClientSet, sm, prom)
+	framework.ExpectNoError(err, "error when finding the Prometheus instance which selects the service monitor")
+
+	ginkgo.By("Wait for all the endpoints of the AI service to be scraped")
+	err = framework.Gomega().Eventually(ctx, func(ctx context.Context) error {
+		targets, err := prometheusutil.ActiveTargets(ctx, f.ClientSet, prom)
+		if err != nil {
+			return err
+		}
+		if unscraped := prometheusutil.UnscrapedEndpoints(targets, sm, svc.Name); len(unscraped) > 0 {
+			return fmt.Errorf("endpoints %v of the AI service %s/%s have no healthy target", unscraped, svc.Namespace, svc.Name)
+		}
+		return nil
+	}).WithTimeout(timeout).WithPolling(15 * time.Second).Should(gomega.Succeed())
+	framework.ExpectNoError(err, "error when waiting for the endpoints of the AI service to be scraped")
+}
+
+var loki struct {
+	Name           string `default:"" usage:"name of the Loki Service to query. If unspecified, the only Service labeled app.kubernetes.io/name=loki which serves the query API is used"`
+	Namespace      string `default:"" usage:"namespace of the Loki Service to query. It's required if ai.loki.name is specified. If unspecified, Services in all namespaces are considered"`
+	Port           string `default:"3100" usage:"port number or name of the Loki Service which serves the query API"`
+	NamespaceLabel string `default:"namespace" usage:"label of the log streams which carries the namespace of the pod, e.g. namespace for Promtail and Grafana Alloy, or kubernetes_namespace_name for Fluent Bit"`
+}
+var _ = e2econfig.AddOptions(&loki, "ai.loki")
+
+var _ = WGDescribe("AI Service Logs", func() {
+	f := framework.NewDefaultFramework("ai-service-logs")
+	f.NamespacePodSecurityLevel = admissionapi.LevelBaseline
+	const timeToWait = 5 * time.Minute
+	var svc *v1.Service
+
+	ginkgo.BeforeEach(func(ctx context.Context) {
+		var err error
+		svc, err = lokiutil.SelectLoki(ctx, f.ClientSet, loki.Namespace, loki.Name, loki.Port)
+		framework.ExpectNoError(err, "error when selecting the Loki Service")
+		if svc == nil {
+			e2eskipper.Skipf("No Loki Service is found, the only supported log backend")
+		}
+	})
+
+	/*
+		Release: v1.34
+		Testname: AI Service Logs
+		Description: Create a pod emitting structured JSON logs of an AI service. Query Loki for the logs of the
+		namespace and verify that the log lines MUST be collected, and MUST still be JSON objects carrying the fields
+		of the pod.
+	*/
+	frameworkutil.AIConformanceIt("structured logs should be collected from the AI service", func(ctx context.Context) {
+		ns := f.Namespace.Name
+		requestID := string(uuid.NewUUID())
+		start := time.Now()
+
+		ginkgo.By("Creating a pod emitting structured JSON logs")
+		logLine := fmt.Sprintf(`{"level":"info","msg":"inference request served","model":"e2e","request_id":"%s"}`, requestID)
+		pod := e2epod.MakePod(ns, nil, nil, f.NamespacePodSecurityLevel, fmt.Sprintf("while true; do echo '%s'; sleep 5; done", logLine))
+		pod.Name = "ai-service-logs"
+		pod, err := f.ClientSet.CoreV1().Pods(ns).Create(ctx, pod, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "error when creating pod")
+		frameworkutil.DeferCleanup(f.ClientSet.CoreV1().Pods(ns).Delete, pod.Name, metav1.DeleteOptions{})
+		err = e2epod.WaitForPodRunningInNamespace(ctx, f.ClientSet, pod)
+		framework.ExpectNoError(err, "error when waiting for pod to be running")
+
+		ginkgo.By("Wait for the structured logs to be collected")
+		query := fmt.Sprintf(`{%s=%q} |= %q`, loki.NamespaceLabel, ns, requestID)
+		err = framework.Gomega().Eventually(ctx, func(ctx context.Context) error {
+			resp, err := lokiutil.QueryRange(ctx, f.ClientSet, svc, loki.Port, query, start, time.Now(), 100)
+			if err != nil {
+				return err
+			}
+			lines := resp.Lines()
+			if len(lines) == 0 {
+				return fmt.Errorf("no log line matches %s", query)
+			}
+			if len(lokiutil.StructuredLines(lines, "request_id", requestID)) == 0 {
+				return fmt.Errorf("none of the %d log lines matching %s is a JSON object with request_id %s: %v", len(lines), query, requestID, lines)
+			}
+			return nil
+		}).WithTimeout(timeToWait).WithPolling(15 * time.Second).Should(gomega.Succeed())
+		framework.ExpectNoError(err, "error when waiting for the structured logs to be collected")
+	})
+})
+
+var _ = WGDescribe("Resource Metrics", func() {
+	f := framework.NewDefaultFramework("resource-metrics")
+	f.NamespacePodSecurityLevel = admissionapi.LevelBaseline
+	const timeToWait = 5 * time.Minute
+	var vendor *frameworkutil.AcceleratorVendor
+
+	ginkgo.BeforeEach(func(ctx context.Context) {
+		aggrclient, err := aggregatorclient.NewForConfig(f.ClientConfig())
+		framework.ExpectNoError(err, "error when creating aggregator client")
+		frameworkutil.SkipUnlessAPIServiceExists(ctx, aggrclient, "v1beta1.metrics.k8s.io")
+		vendor = skipUnlessAcceleratorAllocatable(ctx, f.ClientSet)
+	})
+
+	/*
+		Release: v1.34
+		Testname: Resource Metrics of Accelerator Nodes
+		Description: The resource metrics API, metrics.k8s.io/v1beta1, MUST return the cpu and memory usage of every
+		ready node with accelerators. Create a pod on each of those nodes, the API MUST return the cpu and memory usage
+		of the pods. Autoscaling, including autoscaling on custom metrics, relies on the resource metrics pipeline.
+	*/
+	frameworkutil.AIConformanceIt("resource metrics should be served for the accelerator nodes and their pods", func(ctx context.Context) {
+		ns := f.Namespace.Name
+		nodes, err := e2enode.GetReadyNodesIncludingTainted(ctx, f.ClientSet)
+		framework.ExpectNoError(err)
+
+		for _, node := range nodes.Items {
+			if capacity := node.Status.Capacity[vendor.ResourceName]; capacity.IsZero() {
+				continue
+			}
+
+			ginkgo.By(fmt.Sprintf("Waiting for the resource metrics of node %s", node.Name))
+			err = framework.Gomega().Eventually(ctx, func(ctx context.Context) error {
+				metrics, err := frameworkutil.GetNodeResourceMetrics(ctx, f.ClientSet, node.Name)
+				if err != nil {
+					return err
+				}
+				return verifyResourceUsage(metrics)
+			}).WithTimeout(timeToWait).WithPolling(framework.Poll).Should(gomega.Succeed())
+			framework.ExpectNoError(err, "error when waiting for the resource metrics of node %s", node.Name)
+
+			ginkgo.By(fmt.Sprintf("Creating a pod on node %s and waiting for its resource metrics", node.Name))
+			pod := e2epod.MakePod(ns, nil, nil, f.NamespacePodSecurityLevel, "")
+			pod.Spec.NodeName = node.Name
+			pod.Spec.Tolerations = []v1.Toleration{
+				{
+					Effect:   v1.TaintEffectNoSchedule,
+					Operator: v1.TolerationOpExists,
+				},
+			}
+			pod, err = f.ClientSet.CoreV1().Pods(ns).Create(ctx, pod, metav1.CreateOptions{})
+			framework.ExpectNoError(err, "error when creating pod")
+			frameworkutil.DeferCleanup(f.ClientSet.CoreV1().Pods(ns).Delete, pod.Name, metav1.DeleteOptions{})
+			err = e2epod.WaitForPodRunningInNamespace(ctx, f.ClientSet, pod)
+			framework.ExpectNoError(err, "error when waiting for pod to be running")
+
+			err = framework.Gomega().Eventually(ctx, func(ctx context.Context) error {
+				metrics, err := frameworkutil.GetPodResourceMetrics(ctx, f.ClientSet, ns, pod.Name)
+				if err != nil {
+					return err
+				}
+				return verifyResourceUsage(metrics)
+			}).WithTimeout(timeToWait).WithPolling(framework.Poll).Should(gomega.Succeed())
+			framework.ExpectNoError(err, "error when waiting for the resource metrics of pod %s on node %s", pod.Name, node.Name)
+		}
 	})
 })
+
+// verifyAcceleratorMetricsCollected waits until the metrics of the vendor, including the ones checked by
+// missingMetrics, e.g. its core metrics, are collected by the selected Prometheus instance.
+func verifyAcceleratorMetricsCollected(ctx context.Context, f *framework.Framework, vendor frameworkutil.AcceleratorVendor, missingMetrics func(names []string) []string, timeout time.Duration) {
+	ginkgo.By("Getting the Prometheus instance")
+	promOpClient, err := monitoring.NewForConfig(f.ClientConfig())
+	framework.ExpectNoError(err, "error when creating prometheus operator client")
+	prom, err := prometheusutil.SelectPrometheus(ctx, promOpClient, f.ClientSet, prometheus.Namespace, prometheus.Name, "")
+	framework.ExpectNoError(err, "error when selecting the Prometheus instance")
+
+	ginkgo.By(fmt.Sprintf("Query the prometheus and verify that the %s gpu metrics are collected", vendor.Name))
+	query := fmt.Sprintf(`count by (__name__) ({__name__=~"%s"})`, vendor.MetricPrefixRegex())
+	stopTiming := frameworkutil.StartTiming("accelerator metric collection")
+	err = framework.Gomega().Eventually(ctx, func(ctx context.Context) error {
+		resp, err := prometheusutil.Query(ctx, f.ClientSet, prom, query)
+		if err != nil {
+			return err
+		}
+		names := resp.MetricNames()
+		if len(names) == 0 {
+			return fmt.Errorf("metrics with prefixes %v not found", vendor.MetricPrefixes)
+		}
+		if missing := missingMetrics(names); len(missing) > 0 {
+			return fmt.Errorf("metrics %v not found in %v", missing, names)
+		}
+		return nil
+	}).WithTimeout(timeout).WithPolling(15 * time.Second).Should(gomega.Succeed())
+	stopTiming()
+	framework.ExpectNoError(err, "error when waiting for the metrics to be collected")
+}
+
+// verifyAcceleratorMetricsComplete waits until the utilization metric of the vendor is collected by the selected
+// Prometheus instance for as many accelerators as the physical accelerators of the ready nodes.
+func verifyAcceleratorMetricsComplete(ctx context.Context, f *framework.Framework, vendor frameworkutil.AcceleratorVendor, timeout time.Duration) {
+	nodes, err := e2enode.GetReadyNodesIncludingTainted(ctx, f.ClientSet)
+	framework.ExpectNoError(err)
+	want, err := vendor.PhysicalAccelerators(nodes.Items)
+	framework.ExpectNoError(err, "error when counting the %s gpus", vendor.Name)
+	framework.Logf("%d ready nodes have %d %s gpus", len(nodes.Items), want, vendor.Name)
+
+	ginkgo.By("Getting the Prometheus instance")
+	promOpClient, err := monitoring.NewForConfig(f.ClientConfig())
+	framework.ExpectNoError(err, "error when creating prometheus operator client")
+	prom, err := prometheusutil.SelectPrometheus(ctx, promOpClient, f.ClientSet, prometheus.Namespace, prometheus.Name, "")
+	framework.ExpectNoError(err, "error when selecting the Prometheus instance")
+
+	// The utilization metric is the first core metric of the vendor.
+	metricRegex := vendor.CoreMetrics[0]
+	ginkgo.By(fmt.Sprintf("Query the prometheus and verify that metric %q is collected for %d gpus", metricRegex, want))
+	query := fmt.Sprintf(`{__name__=~"%s"}`, metricRegex)
+	err = framework.Gomega().Eventually(ctx, func(ctx context.Context) error {
+		resp, err := prometheusutil.Query(ctx, f.ClientSet, prom, query)
+		if err != nil {
+			return err
+		}
+		devices := resp.LabelValues(vendor.DeviceMetricLabel)
+		if len(devices) != want {
+			return fmt.Errorf("metric %q is collected for %d gpus %v identified by label %s, want %d", metricRegex, len(devices), devices, vendor.DeviceMetricLabel, want)
+		}
+		return nil
+	}).WithTimeout(timeout).WithPolling(15 * time.Second).Should(gomega.Succeed())
+	framework.ExpectNoError(err, "error when waiting for the metrics of every gpu to be collected")
+}
+
+// verifyAcceleratorMemoryTotal waits until the total memory of the accelerators of the vendor is collected by the
+// selected Prometheus instance, and verifies it's valid for every accelerator, see
+// frameworkutil.InvalidAcceleratorMemory.
+func verifyAcceleratorMemoryTotal(ctx context.Context, f *framework.Framework, vendor frameworkutil.AcceleratorVendor, timeout time.Duration) {
+	expected, err := frameworkutil.ParseAcceleratorMemory(acceleratorMetrics.ExpectedMemory)
+	framework.ExpectNoError(err, "error when parsing -ai.acceleratorMetrics.expectedMemory")
+
+	ginkgo.By("Getting the Prometheus instance")
+	promOpClient, err := monitoring.NewForConfig(f.ClientConfig())
+	framework.ExpectNoError(err, "error when creating prometheus operator client")
+	prom, err := prometheusutil.SelectPrometheus(ctx, promOpClient, f.ClientSet, prometheus.Namespace, prometheus.Name, "")
+	framework.ExpectNoError(err, "error when selecting the Prometheus instance")
+
+	ginkgo.By(fmt.Sprintf("Query the prometheus for the total memory of the %s gpus", vendor.Name))
+	query := fmt.Sprintf("max by (%s, %s) (%s)", vendor.DeviceMetricLabel, vendor.ProductMetricLabel, vendor.MemoryTotalQuery)
+	var memory []frameworkutil.AcceleratorMemory
+	err = framework.Gomega().Eventually(ctx, func(ctx context.Context) error {
+		resp, err := prometheusutil.Query(ctx, f.ClientSet, prom, query)
+		if err != nil {
+			return err
+		}
+		memory = nil
+		for device, samples := range resp.GroupByLabel(vendor.DeviceMetricLabel) {
+			if samples[0].Value == nil {
+				continue
+			}
+			memory = append(memory, frameworkutil.AcceleratorMemory{
+				Device:  device,
+				Product: samples[0].Metric[vendor.ProductMetricLabel],
+				MiB:     samples[0].Value.Value,
+			})
+		}
+		if len(memory) == 0 {
+			return fmt.Errorf("the total memory %q of the gpus is not collected", vendor.MemoryTotalQuery)
+		}
+		return nil
+	}).WithTimeout(timeout).WithPolling(15 * time.Second).Should(gomega.Succeed())
+	framework.ExpectNoError(err, "error when waiting for the total memory of the gpus to be collected")
+
+	for _, m := range memory {
+		framework.Logf("GPU %s (%s) has %g MiB memory", m.Device, m.Product, m.MiB)
+	}
+	problems := frameworkutil.InvalidAcceleratorMemory(memory, expected, acceleratorMetrics.MemoryTolerance)
+	gomega.Expect(problems).To(gomega.BeEmpty(), "the total memory of the %s gpus should match their products", vendor.Name)
+}
+
+// skipUnlessAcceleratorAllocatable skips the test if the ready nodes do not have any allocatable accelerator of
+// the given vendors, or of the candidate vendors of -ai.accelerator.resourceNames if none is given. Otherwise it
+// returns the detected vendor.
+func skipUnlessAcceleratorAllocatable(ctx context.Context, client clientset.Interface, vendors ...frameworkutil.AcceleratorVendor) *frameworkutil.AcceleratorVendor {
+	nodes, err := e2enode.GetReadyNodesIncludingTainted(ctx, client)
+	framework.ExpectNoError(err)
+
+	vendor := frameworkutil.DetectAcceleratorVendor(nodes.Items, vendors...)
+	if vendor == nil {
+		e2eskipper.Skipf("%d ready nodes do not have any GPU(s) of the supported vendors. Skipping...", len(nodes.Items))
+	}
+
+	allocatable := 0
+	for _, node := range nodes.Items {
+		val, ok := node.Status.Allocatable[vendor.ResourceName]
+		if !ok {
+			continue
+		}
+		allocatable += int(val.Value())
+	}
+	if allocatable == 0 {
+		e2eskipper.Skipf("%d ready nodes do not have any allocatable %s GPU(s). Skipping...", len(nodes.Items), vendor.Name)
+	}
+	framework.Logf("Using %d allocatable %s of vendor %s", allocatable, vendor.ResourceName, vendor.Name)
+	withMetrics := withExpectedMetrics(*vendor)
+	return &withMetrics
+}
+
+// withExpectedMetrics returns the vendor with the metrics expected from its exporter, see -ai.expectedMetrics.file.
+func withExpectedMetrics(vendor frameworkutil.AcceleratorVendor) frameworkutil.AcceleratorVendor {
+	expected, err := frameworkutil.LoadExpectedMetrics()
+	framework.ExpectNoError(err, "error when loading the expected metrics")
+	return expected.AcceleratorVendor(vendor)
+}
+
+// requireAcceleratorNode pins the pods of the given spec to the ready nodes which advertise the accelerator resource.
+func requireAcceleratorNode(ctx context.Context, client clientset.Interface, spec *v1.PodSpec, resourceName v1.ResourceName) {
+	nodes, err := e2enode.GetReadyNodesIncludingTainted(ctx, client)
+	framework.ExpectNoError(err)
+	err = frameworkutil.RequireAcceleratorNode(spec, nodes.Items, resourceName)
+	framework.ExpectNoError(err, "error when pinning the pods to the %s nodes", resourceName)
+}
+
+// lockAccelerators waits for the specs consuming the accelerator resource in parallel to finish if the
+// accelerators are scarce, and holds the lock until the spec and its cleanups finish. It must be called before
+// the resources consuming the accelerators are created.
+func lockAccelerators(ctx context.Context, f *framework.Framework, resourceName v1.ResourceName) {
+	ginkgo.By(fmt.Sprintf("Locking the %s if they are scarce", resourceName))
+	lock, err := frameworkutil.LockAccelerators(ctx, f.ClientSet, resourceName, f.UniqueName)
+	framework.ExpectNoError(err, "error when locking %s", resourceName)
+	ginkgo.DeferCleanup(lock.Release)
+}
+
+// verifyAcceleratorsReleased verifies that the accelerator resource returns to the available count before the spec
+// once the workloads of the spec are deleted if -ai.accelerator.verifyRelease is set. It must be called before the
+// cleanups of the workloads are registered.
+func verifyAcceleratorsReleased(ctx context.Context, f *framework.Framework, resourceName v1.ResourceName) {
+	err := frameworkutil.VerifyAcceleratorsReleased(ctx, f.ClientSet, resourceName)
+	framework.ExpectNoError(err, "error when counting %s before the spec", resourceName)
+}
+
+// verifyResourceUsage returns an error if the cpu or memory usage is not reported in the resource metrics.
+func verifyResourceUsage(metrics *frameworkutil.ResourceMetrics) error {
+	for _, name := range []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory} {
+		if _, ok := metrics.Usage[name]; !ok {
+			return fmt.Errorf("%s usage is not reported in the resource metrics of %s: %v", name, metrics.Name, metrics.Usage)
+		}
+	}
+	framework.Logf("Resource usage of %s is %v in the window of %v", metrics.Name, metrics.Usage, metrics.Window.Duration)
+	return nil
+}
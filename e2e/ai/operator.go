@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/onsi/ginkgo/v2"
 	"github.com/onsi/gomega"
@@ -12,16 +15,18 @@ import (
 	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/cli-runtime/pkg/resource"
 	clientset "k8s.io/client-go/kubernetes"
 	admissionapi "k8s.io/pod-security-admission/api"
 
 	"k8s.io/kubernetes/test/e2e/framework"
 	e2econfig "k8s.io/kubernetes/test/e2e/framework/config"
-	e2ekubectl "k8s.io/kubernetes/test/e2e/framework/kubectl"
 	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
 
 	frameworkutil "github.com/carlory/ai-conformance/e2e/util/framework"
@@ -29,11 +34,18 @@ import (
 )
 
 var operator struct {
-	Filename    string `default:"" usage:"filename, directory, or URL to files to use to install the operator"`
-	Chart       string `default:"" usage:"chart name where to locate the requested chart"`
-	Repo        string `default:"" usage:"chart repository url where to locate the requested chart"`
-	Namespace   string `default:"" usage:"namespace scope for this request. If unspecified, a random namespace will be used"`
-	ReleaseName string `default:"" usage:"release name to create with this request. If unspecified, a random release name will be used"`
+	Filename              string `default:"" usage:"filename, directory, or URL to files to use to install the operator"`
+	Chart                 string `default:"" usage:"chart name where to locate the requested chart"`
+	Repo                  string `default:"" usage:"chart repository url where to locate the requested chart"`
+	Namespace             string `default:"" usage:"namespace scope for this request. If unspecified, a random namespace will be used"`
+	ReleaseName           string `default:"" usage:"release name to create with this request. If unspecified, a random release name will be used"`
+	RepoCredentialsSecret string `default:"" usage:"<namespace>/<name> of the Secret whose username and password keys are the credentials of the chart repository given by -ai.operator.repo. If unspecified, the HELM_REPO_USERNAME and HELM_REPO_PASSWORD environment variables are used if set"`
+	PassCredentials       bool   `default:"false" usage:"if true, the credentials of the chart repository are passed to all domains, e.g. when the repository redirects the chart downloads to another host"`
+	InstallRetries        int    `default:"0" usage:"how many times the helm install of the chart is retried with exponential backoff if it fails transiently, e.g. on timeouts or a webhook refusing connections. Chart errors are not retried"`
+	Repos                 string `default:"" usage:"comma-separated name=url entries of the chart repositories which the dependencies of the chart refer to, e.g. bitnami=https://charts.bitnami.com/bitnami. They are added via helm repo add before the chart is rendered, and the dependencies are updated if the chart declares any"`
+	CrdFilename           string `default:"" usage:"filename, directory, or URL to files of the CRDs of the operator, which are applied server-side and established before the operator is installed, for operators which install their CRDs separately from the controller"`
+	CrdChart              string `default:"" usage:"chart name of the CRDs of the operator in the chart repository given by -ai.operator.repo, which is installed with release name <release name>-crds and whose CRDs are established before the operator is installed, for operators which ship their CRDs in a separate chart"`
+	VerifyConversion      bool   `default:"false" usage:"if true, the CRDs of the operator which serve multiple versions must configure a conversion webhook unless the versions share the same schema, and a custom resource created in the storage version must be readable in every served version. Custom resources which can't be created without a spec are not verified"`
 }
 
 var _ = e2econfig.AddOptions(&operator, "ai.operator")
@@ -46,13 +58,17 @@ var _ = WGDescribe("Robust Controller", func() {
 		Release: v1.33
 		Testname: Robust Controller
 		Description: Deploy the given operator with filename or helm chart. All the pods of the operator MUST be
-		running. If the operator has webhooks, all the pods of the webhooks MUST be running. The CRDs of the operator
-		MUST have NamesAccepted and Established conditions with True status. And at least one CRD should have status
+		running. If the operator has webhooks, all the pods of the webhooks MUST be running, and the services of the
+		webhooks MUST serve TLS with certificates valid for the services and signed by the CA bundles of the
+		webhooks. The CRDs of the operator MUST have NamesAccepted and Established conditions with True status. And at least one CRD should have status
 		or scale subresource to approve it can be reconciled by
+		If -ai.operator.verifyConversion is set, the CRDs which serve multiple versions MUST configure a conversion
+		webhook unless the versions share the same schema, and a custom resource created in the storage version MUST
+		be readable in every served version.
 	*/
 
 	frameworkutil.AIConformanceIt("All pods of the operator and its webhooks should be running and its crds should be ready for use", func(ctx context.Context) {
-		if operator.Chart != "" && operator.ReleaseName == "" {
+		if (operator.Chart != "" || operator.CrdChart != "") && operator.ReleaseName == "" {
 			operator.ReleaseName = f.UniqueName
 		}
 		if operator.Namespace == "" {
@@ -60,22 +76,54 @@ var _ = WGDescribe("Robust Controller", func() {
 		}
 
 		// Create a builder
-		builder := resource.NewBuilder(frameworkutil.NewClientGetter(f)).
-			Unstructured().
-			// Accumulate as many items as possible
-			ContinueOnError().
-			// The namespace might not be populated to the generated manifests, so we need to set it manually.
-			NamespaceParam(operator.Namespace).DefaultNamespace().
-			// Flatten items contained in List objects
-			Flatten()
+		builder := newOperatorResourceBuilder(f)
+
+		// Resolve the dependencies of the chart, e.g. subcharts, for both rendering and installing it.
+		var repoArgs []string
+		chart, crdChart, chartRepo := operator.Chart, operator.CrdChart, operator.Repo
+		if operator.Chart != "" || operator.CrdChart != "" {
+			creds := chartRepoCredentials(ctx, f)
+			if creds != nil && chartRepo != "" {
+				// The charts are referred to via the authenticated repository instead of --repo, which would need the
+				// password in the args.
+				repo := frameworkutil.HelmRepo{Name: operator.ReleaseName, URL: chartRepo}
+				err := frameworkutil.AddAuthenticatedHelmRepo(repo, creds, operator.PassCredentials)
+				framework.ExpectNoError(err)
+				frameworkutil.DeferCleanup(frameworkutil.RunHelm, "", "repo", "remove", repo.Name)
+				chart, crdChart, chartRepo = repo.Name+"/"+operator.Chart, repo.Name+"/"+operator.CrdChart, ""
+			}
+			if chartRepo != "" {
+				repoArgs = append(repoArgs, "--repo", chartRepo)
+			}
+		}
+		chartArgs := slices.Clone(repoArgs)
+		if operator.Chart != "" {
+			repos, err := frameworkutil.ParseHelmRepos(operator.Repos)
+			framework.ExpectNoError(err, "error when parsing chart repositories")
+			err = frameworkutil.AddHelmRepos(repos)
+			framework.ExpectNoError(err, "error when adding chart repositories")
+			dependencies, err := frameworkutil.HelmChartDependencies(chart, chartRepo)
+			framework.ExpectNoError(err, "error when getting dependencies of chart %s", operator.Chart)
+			if len(dependencies) > 0 {
+				framework.Logf("chart %s depends on %v, updating the dependencies", operator.Chart, dependencies)
+				chartArgs = append(chartArgs, "--dependency-update")
+			}
+		}
+
+		// Install the CRDs of the operator first if they are installed separately, so that the manifests of the
+		// operator referring to them, e.g. its custom resources, can be resolved and installed.
+		crdInfos := installOperatorCRDs(ctx, f, crdChart, repoArgs)
 
 		// set resource sources for the builder
+		var manifests string
 		if operator.Chart != "" {
 			// Provide the generated manifests via a Reader.
-			manifests, err := frameworkutil.RunHelm(operator.Namespace, "template", operator.ReleaseName, operator.Chart, "--include-crds", "--repo", operator.Repo)
+			var err error
+			manifests, err = frameworkutil.RunHelm(operator.Namespace, append([]string{"template", operator.ReleaseName, chart, "--include-crds"}, chartArgs...)...)
 			framework.ExpectNoError(err)
 			builder = builder.Stream(bytes.NewBufferString(manifests), operator.Chart)
 			framework.Logf("generated manifests from chart %s with release name %s: %s", operator.Chart, operator.ReleaseName, manifests)
+			frameworkutil.SaveHelmManifests(operator.ReleaseName, "template", manifests)
 		}
 		if operator.Filename != "" {
 			// As an alternative, could call Path(false, "/path/to/file") to read from a file.
@@ -86,16 +134,21 @@ var _ = WGDescribe("Robust Controller", func() {
 		infos, err := builder.Do().Infos()
 		framework.ExpectNoError(err)
 		gomega.Expect(infos).ToNot(gomega.BeEmpty(), "at least one resource should be found from filename %s or chart %s", operator.Filename, operator.Chart)
+		infos = append(infos, crdInfos...)
 
 		// Install the operator
 		if operator.Filename != "" {
-			_, err := e2ekubectl.RunKubectl(operator.Namespace, "apply", "-f", operator.Filename)
-			ginkgo.DeferCleanup(e2ekubectl.RunKubectl, operator.Namespace, "delete", "-f", operator.Filename)
+			_, err := frameworkutil.RunKubectl(operator.Namespace, "apply", "-f", operator.Filename)
+			frameworkutil.DeferCleanup(frameworkutil.RunKubectl, operator.Namespace, "delete", "-f", operator.Filename)
 			framework.ExpectNoError(err, "error when applying operator from filename %s", operator.Filename)
 		}
 		if operator.Chart != "" {
-			_, err := frameworkutil.RunHelm(operator.Namespace, "install", operator.ReleaseName, operator.Chart, "--create-namespace", "--debug", "--wait", "--timeout", "15m", "--repo", operator.Repo)
-			ginkgo.DeferCleanup(frameworkutil.RunHelm, operator.Namespace, "uninstall", operator.ReleaseName, "--ignore-not-found")
+			_, err := frameworkutil.InstallHelmChart(operator.Namespace, operator.ReleaseName, chart, operator.InstallRetries,
+				append([]string{"--create-namespace", "--debug", "--wait", "--timeout", "15m"}, chartArgs...)...)
+			frameworkutil.DeferCleanup(frameworkutil.RunHelm, operator.Namespace, "uninstall", operator.ReleaseName, "--ignore-not-found")
+			if err != nil {
+				frameworkutil.DiffInstalledHelmManifests(operator.Namespace, operator.ReleaseName, manifests)
+			}
 			framework.ExpectNoError(err, "error when installing operator from chart %s with release name %s", operator.Chart, operator.ReleaseName)
 		}
 
@@ -152,6 +205,7 @@ var _ = WGDescribe("Robust Controller", func() {
 						framework.ExpectNoError(err, "error when checking service %s/%s for ValidatingWebhookConfiguration %s", svcNamespace, svcName, config.GetName())
 					}
 				}
+				verifyWebhookTLS(ctx, f, "validatingwebhookconfigurations", config.GetName())
 				framework.Logf("ValidatingWebhookConfiguration %s is ready", config.GetName())
 			case admissionregistrationv1.SchemeGroupVersion.WithResource("mutatingwebhookconfigurations"):
 				config := &admissionregistrationv1.MutatingWebhookConfiguration{}
@@ -165,6 +219,7 @@ var _ = WGDescribe("Robust Controller", func() {
 						framework.ExpectNoError(err, "error when checking service %s/%s for MutatingWebhookConfiguration %s", svcNamespace, svcName, config.GetName())
 					}
 				}
+				verifyWebhookTLS(ctx, f, "mutatingwebhookconfigurations", config.GetName())
 				framework.Logf("MutatingWebhookConfiguration %s is ready", config.GetName())
 			}
 		}
@@ -179,6 +234,14 @@ var _ = WGDescribe("Robust Controller", func() {
 			return false
 		}, gomega.BeTrue())), "at least one CRD should have status or scale subresource to approve it can be reconciled", format.Object(crds, 1))
 
+		if operator.VerifyConversion {
+			apiExtensionClient, err := apiextclientset.NewForConfig(f.ClientConfig())
+			framework.ExpectNoError(err, "error when creating api extension client")
+			for _, crd := range crds {
+				verifyCRDConversion(ctx, f, apiExtensionClient, crd.Name)
+			}
+		}
+
 		// check if the operator pods are running
 		pods, err := f.ClientSet.CoreV1().Pods(operator.Namespace).List(ctx, metav1.ListOptions{})
 		framework.ExpectNoError(err)
@@ -189,3 +252,162 @@ var _ = WGDescribe("Robust Controller", func() {
 		}
 	})
 })
+
+// verifyWebhookTLS verifies that the services of the webhooks of the validating or mutating webhook configuration
+// serve TLS with certificates signed by their CA bundles. The configuration is read from the API server in each
+// attempt, as the CA bundles may be injected after it's installed, e.g. by cert-manager.
+func verifyWebhookTLS(ctx context.Context, f *framework.Framework, configResource, name string) {
+	ginkgo.By(fmt.Sprintf("Verifying the TLS of the webhooks of %s %s", configResource, name))
+	err := framework.Gomega().Eventually(ctx, func(ctx context.Context) error {
+		webhooks := map[string]admissionregistrationv1.WebhookClientConfig{}
+		switch configResource {
+		case "validatingwebhookconfigurations":
+			config, err := f.ClientSet.AdmissionregistrationV1().ValidatingWebhookConfigurations().Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			for _, webhook := range config.Webhooks {
+				webhooks[webhook.Name] = webhook.ClientConfig
+			}
+		case "mutatingwebhookconfigurations":
+			config, err := f.ClientSet.AdmissionregistrationV1().MutatingWebhookConfigurations().Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			for _, webhook := range config.Webhooks {
+				webhooks[webhook.Name] = webhook.ClientConfig
+			}
+		}
+		for webhookName, clientConfig := range webhooks {
+			if clientConfig.Service == nil {
+				continue
+			}
+			if err := frameworkutil.VerifyWebhookTLS(ctx, f.ClientSet, f.Namespace.Name, clientConfig); err != nil {
+				return fmt.Errorf("error when verifying the TLS of webhook %s of %s %s: %w", webhookName, configResource, name, err)
+			}
+			framework.Logf("Webhook %s of %s %s serves TLS with a certificate signed by its CA bundle", webhookName, configResource, name)
+		}
+		return nil
+	}).WithTimeout(f.Timeouts.PodStart).WithPolling(10 * time.Second).Should(gomega.Succeed())
+	framework.ExpectNoError(err, "the webhooks of %s %s should serve TLS with a certificate signed by their CA bundle", configResource, name)
+}
+
+// chartRepoCredentials returns the credentials of the chart repository in the Secret given by
+// -ai.operator.repoCredentialsSecret, or in the environment if it's unspecified. Nil is returned if none is given.
+func chartRepoCredentials(ctx context.Context, f *framework.Framework) *frameworkutil.HelmRepoCredentials {
+	if operator.RepoCredentialsSecret == "" {
+		return frameworkutil.HelmRepoCredentialsFromEnv()
+	}
+	creds, err := frameworkutil.HelmRepoCredentialsFromSecret(ctx, f.ClientSet, operator.RepoCredentialsSecret)
+	framework.ExpectNoError(err)
+	return creds
+}
+
+// newOperatorResourceBuilder returns a builder of the resources of the operator in its namespace.
+func newOperatorResourceBuilder(f *framework.Framework) *resource.Builder {
+	return resource.NewBuilder(frameworkutil.NewClientGetter(f)).
+		Unstructured().
+		// Accumulate as many items as possible
+		ContinueOnError().
+		// The namespace might not be populated to the generated manifests, so we need to set it manually.
+		NamespaceParam(operator.Namespace).DefaultNamespace().
+		// Flatten items contained in List objects
+		Flatten()
+}
+
+// installOperatorCRDs installs the CRDs of the operator given by -ai.operator.crdFilename or the given chart of
+// -ai.operator.crdChart, and waits for them to be established. It returns the installed resources, or nil if
+// neither is given.
+func installOperatorCRDs(ctx context.Context, f *framework.Framework, chart string, repoArgs []string) []*resource.Info {
+	if operator.CrdFilename == "" && operator.CrdChart == "" {
+		return nil
+	}
+	builder := newOperatorResourceBuilder(f)
+	if operator.CrdChart != "" {
+		releaseName := operator.ReleaseName + "-crds"
+		manifests, err := frameworkutil.RunHelm(operator.Namespace, append([]string{"template", releaseName, chart, "--include-crds"}, repoArgs...)...)
+		framework.ExpectNoError(err)
+		builder = builder.Stream(bytes.NewBufferString(manifests), operator.CrdChart)
+		framework.Logf("generated manifests from CRD chart %s with release name %s: %s", operator.CrdChart, releaseName, manifests)
+		frameworkutil.SaveHelmManifests(releaseName, "template", manifests)
+
+		ginkgo.By(fmt.Sprintf("Installing the CRDs of the operator from chart %s", operator.CrdChart))
+		_, err = frameworkutil.InstallHelmChart(operator.Namespace, releaseName, chart, operator.InstallRetries,
+			append([]string{"--create-namespace", "--debug", "--wait", "--timeout", "15m"}, repoArgs...)...)
+		frameworkutil.DeferCleanup(frameworkutil.RunHelm, operator.Namespace, "uninstall", releaseName, "--ignore-not-found")
+		if err != nil {
+			frameworkutil.DiffInstalledHelmManifests(operator.Namespace, releaseName, manifests)
+		}
+		framework.ExpectNoError(err, "error when installing CRDs from chart %s with release name %s", operator.CrdChart, releaseName)
+	}
+	if operator.CrdFilename != "" {
+		builder = builder.FilenameParam(false, &resource.FilenameOptions{Filenames: []string{operator.CrdFilename}})
+
+		// The large CRDs exceed the size limit of the last-applied-configuration annotation of client-side apply.
+		ginkgo.By(fmt.Sprintf("Applying the CRDs of the operator from filename %s", operator.CrdFilename))
+		_, err := frameworkutil.RunKubectl(operator.Namespace, "apply", "--server-side", "-f", operator.CrdFilename)
+		frameworkutil.DeferCleanup(frameworkutil.RunKubectl, operator.Namespace, "delete", "-f", operator.CrdFilename, "--ignore-not-found")
+		framework.ExpectNoError(err, "error when applying CRDs from filename %s", operator.CrdFilename)
+	}
+
+	infos, err := builder.Do().Infos()
+	framework.ExpectNoError(err)
+	apiExtensionClient, err := apiextclientset.NewForConfig(f.ClientConfig())
+	framework.ExpectNoError(err, "error when creating api extension client")
+	established := 0
+	for _, info := range infos {
+		if info.Mapping.Resource != apiextensionsv1.SchemeGroupVersion.WithResource("customresourcedefinitions") {
+			continue
+		}
+		err := e2ecrd.WaitForCrdEstablishedAndNamesAccepted(ctx, apiExtensionClient, info.Name)
+		framework.ExpectNoError(err, "error when waiting for CRD %s to be established and names accepted", info.Name)
+		established++
+	}
+	gomega.Expect(established).ToNot(gomega.BeZero(), "at least one CRD should be found from filename %s or chart %s", operator.CrdFilename, operator.CrdChart)
+	framework.Logf("%d CustomResourceDefinitions of the operator are established", established)
+	return infos
+}
+
+// verifyCRDConversion verifies that the CRD, if it serves multiple versions, can convert its custom resources between
+// them, i.e. a custom resource created in the storage version can be read in every served version. The CRD is read
+// from the API server, as the CA bundle of its conversion webhook may be injected after it's installed.
+func verifyCRDConversion(ctx context.Context, f *framework.Framework, client apiextclientset.Interface, name string) {
+	crd, err := client.ApiextensionsV1().CustomResourceDefinitions().Get(ctx, name, metav1.GetOptions{})
+	framework.ExpectNoError(err, "error when getting CRD %s", name)
+	served := e2ecrd.ServedVersions(crd)
+	if len(served) <= 1 {
+		framework.Logf("CRD %s serves versions %v, skipping the conversion verification", name, served)
+		return
+	}
+	ginkgo.By(fmt.Sprintf("Verifying the conversion of CRD %s between versions %s", name, strings.Join(served, ", ")))
+	gomega.Expect(e2ecrd.ConversionProblem(crd)).To(gomega.BeEmpty(), "CRD %s should convert its custom resources between the served versions", name)
+
+	namespace := ""
+	if crd.Spec.Scope == apiextensionsv1.NamespaceScoped {
+		namespace = f.Namespace.Name
+	}
+	storage := e2ecrd.StorageVersion(crd)
+	gvr := schema.GroupVersionResource{Group: crd.Spec.Group, Version: storage, Resource: crd.Spec.Names.Plural}
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(gvr.GroupVersion().String())
+	obj.SetKind(crd.Spec.Names.Kind)
+	obj.SetGenerateName("conversion-")
+	obj.SetLabels(map[string]string{"e2e-conversion": f.UniqueName})
+	obj, err = f.DynamicClient.Resource(gvr).Namespace(namespace).Create(ctx, obj, metav1.CreateOptions{})
+	if apierrors.IsInvalid(err) || apierrors.IsBadRequest(err) || apierrors.IsForbidden(err) {
+		framework.Logf("A custom resource of CRD %s without a spec is rejected, skipping the conversion verification: %v", name, err)
+		return
+	}
+	framework.ExpectNoError(err, "error when creating a custom resource of CRD %s in version %s", name, storage)
+	frameworkutil.DeferCleanup(f.DynamicClient.Resource(gvr).Namespace(namespace).Delete, obj.GetName(), metav1.DeleteOptions{})
+
+	for _, version := range served {
+		versionGVR := gvr.GroupResource().WithVersion(version)
+		converted, err := f.DynamicClient.Resource(versionGVR).Namespace(namespace).Get(ctx, obj.GetName(), metav1.GetOptions{})
+		framework.ExpectNoError(err, "error when reading custom resource %s of CRD %s in version %s", obj.GetName(), name, version)
+		gomega.Expect(converted.GetAPIVersion()).To(gomega.Equal(versionGVR.GroupVersion().String()))
+		gomega.Expect(converted.GetUID()).To(gomega.Equal(obj.GetUID()), "custom resource %s of CRD %s read in version %s should be the created one", obj.GetName(), name, version)
+		gomega.Expect(converted.GetLabels()).To(gomega.HaveKeyWithValue("e2e-conversion", f.UniqueName), "labels of custom resource %s of CRD %s should be preserved in version %s", obj.GetName(), name, version)
+		framework.Logf("Custom resource %s of CRD %s is read in version %s", obj.GetName(), name, version)
+	}
+}
@@ -0,0 +1,242 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/onsi/ginkgo/v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	aggregatorclient "k8s.io/kube-aggregator/pkg/client/clientset_generated/clientset"
+
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2enode "k8s.io/kubernetes/test/e2e/framework/node"
+
+	frameworkutil "github.com/carlory/ai-conformance/e2e/util/framework"
+	lokiutil "github.com/carlory/ai-conformance/e2e/util/loki"
+)
+
+// preflightCheck reports whether an optional component is present in the cluster.
+type preflightCheck struct {
+	// Component is the name of the component.
+	Component string `json:"component"`
+	// Areas are the conformance areas which are skipped without the component.
+	Areas []string `json:"areas"`
+	// Present is true if the component is detected.
+	Present bool `json:"present"`
+	// Details describes what is detected, or the error if the detection failed.
+	Details string `json:"details,omitempty"`
+}
+
+var _ = WGDescribe("Preflight", func() {
+	f := framework.NewDefaultFramework("preflight")
+	f.SkipNamespaceCreation = true
+
+	// It's not a conformance requirement, it always passes and reports which conformance areas will run.
+	framework.It("should report the optional components and the accelerator inventory", framework.WithLabel("AIConformance"), func(ctx context.Context) {
+		aggrclient, err := aggregatorclient.NewForConfig(f.ClientConfig())
+		framework.ExpectNoError(err, "error when creating aggregator client")
+		discoveryClient := frameworkutil.CachedDiscovery(f.ClientSet)
+
+		groupVersion := func(groupVersion string) func() (bool, string, error) {
+			return func() (bool, string, error) {
+				available, err := frameworkutil.IsGroupVersionAvailable(discoveryClient, groupVersion)
+				return available, groupVersion, err
+			}
+		}
+		apiService := func(name string) func() (bool, string, error) {
+			return func() (bool, string, error) {
+				exists, err := frameworkutil.APIServiceExists(ctx, aggrclient, name)
+				return exists, "APIService " + name, err
+			}
+		}
+
+		checks := []struct {
+			component string
+			areas     []string
+			detect    func() (bool, string, error)
+		}{
+			{
+				component: "accelerator device plugin",
+				areas:     []string{"Accelerator Checkpoint", "Accelerator Descheduling", "Accelerator Health", "Accelerator Isolation", "Accelerator Metrics", "Accelerator Scratch Storage", "Accelerator Topology", "Device Plugin Registration", "Device Plugin Resilience", "Fractional Accelerators", "Gang Scheduling", "Graceful Termination", "Inference Autoscaling", "Pod Autoscaling", "Resource Metrics", "Secure Accelerator Access"},
+				detect: func() (bool, string, error) {
+					nodes, err := e2enode.GetReadyNodesIncludingTainted(ctx, f.ClientSet)
+					if err != nil {
+						return false, "", err
+					}
+					vendor := frameworkutil.DetectAcceleratorVendor(nodes.Items)
+					if vendor == nil {
+						return false, fmt.Sprintf("none of the %d ready nodes advertises %v", len(nodes.Items), acceleratorResourceNames()), nil
+					}
+					count, err := frameworkutil.CountAccelerators(ctx, f.ClientSet, vendor.ResourceName)
+					if err != nil {
+						return true, "", err
+					}
+					details := fmt.Sprintf("%s: capacity %d, allocatable %d, used %d, available %d on %d ready nodes",
+						vendor.ResourceName, count.Capacity, count.Allocatable, count.Used, count.Available(), count.Nodes)
+					// The vendors of a heterogeneous cluster are reported, as the specs which are not specific to a
+					// vendor only test the first one detected, see -ai.vendor to test another one.
+					if byVendor := frameworkutil.AcceleratorNodesByVendor(nodes.Items); len(byVendor) > 1 {
+						var vendors []string
+						for _, name := range sets.List(sets.KeySet(byVendor)) {
+							vendors = append(vendors, fmt.Sprintf("%s %d", name, len(byVendor[name])))
+						}
+						details += fmt.Sprintf(", accelerator nodes by vendor: %s", strings.Join(vendors, ", "))
+					}
+					return true, details, nil
+				},
+			},
+			{
+				component: "StorageClass of the scratch storage",
+				areas:     []string{"Accelerator Scratch Storage"},
+				detect: func() (bool, string, error) {
+					if scratchStorage.StorageClass != "" {
+						_, err := f.ClientSet.StorageV1().StorageClasses().Get(ctx, scratchStorage.StorageClass, metav1.GetOptions{})
+						if apierrors.IsNotFound(err) {
+							return false, fmt.Sprintf("StorageClass %s of -ai.scratchStorage.storageClass doesn't exist", scratchStorage.StorageClass), nil
+						}
+						return err == nil, "StorageClass " + scratchStorage.StorageClass, err
+					}
+					classes, err := f.ClientSet.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+					if err != nil {
+						return false, "", err
+					}
+					storageClass := frameworkutil.DefaultStorageClass(classes.Items)
+					if storageClass == "" {
+						return false, fmt.Sprintf("none of the %d StorageClasses is the default one", len(classes.Items)), nil
+					}
+					return true, "default StorageClass " + storageClass, nil
+				},
+			},
+			{
+				component: "DRA drivers",
+				areas:     []string{"DRA Support", "Secure Accelerator Access"},
+				detect: func() (bool, string, error) {
+					available, err := frameworkutil.IsGroupVersionAvailable(discoveryClient, "resource.k8s.io/v1")
+					if err != nil || !available {
+						return false, "resource.k8s.io/v1 is not served", err
+					}
+					slices, err := f.ClientSet.ResourceV1().ResourceSlices().List(ctx, metav1.ListOptions{})
+					if err != nil {
+						return false, "", err
+					}
+					drivers := sets.New[string]()
+					for _, slice := range slices.Items {
+						drivers.Insert(slice.Spec.Driver)
+					}
+					if drivers.Len() == 0 {
+						return false, "no ResourceSlice is published", nil
+					}
+					return true, fmt.Sprintf("drivers %v", sets.List(drivers)), nil
+				},
+			},
+			{
+				component: "Prometheus Operator",
+				areas:     []string{"Accelerator Metrics", "AI Service Metrics", "Inference Autoscaling", "Pod Autoscaling"},
+				detect:    groupVersion("monitoring.coreos.com/v1"),
+			},
+			{
+				component: "custom metrics API",
+				areas:     []string{"Inference Autoscaling", "Pod Autoscaling"},
+				detect:    apiService("v1beta1.custom.metrics.k8s.io"),
+			},
+			{
+				component: "resource metrics API",
+				areas:     []string{"Resource Metrics"},
+				detect:    apiService("v1beta1.metrics.k8s.io"),
+			},
+			{
+				component: "Loki",
+				areas:     []string{"AI Service Logs"},
+				detect: func() (bool, string, error) {
+					svc, err := lokiutil.SelectLoki(ctx, f.ClientSet, loki.Namespace, loki.Name, loki.Port)
+					if err != nil {
+						return false, "", err
+					}
+					if svc == nil {
+						return false, "no Service labeled app.kubernetes.io/name=loki", nil
+					}
+					return true, fmt.Sprintf("Service %s/%s", svc.Namespace, svc.Name), nil
+				},
+			},
+			{
+				component: "Gateway API",
+				areas:     []string{"AI Inference", "Gateway Route Acceptance", "Inference Autoscaling"},
+				detect:    groupVersion("gateway.networking.k8s.io/v1"),
+			},
+			{
+				component: "Kueue",
+				areas:     []string{"Gang Scheduling"},
+				detect:    groupVersion("kueue.x-k8s.io/v1beta1"),
+			},
+			{
+				component: "JobSet",
+				areas:     []string{"Gang Scheduling"},
+				detect:    groupVersion(jobSetGVR.GroupVersion().String()),
+			},
+			// No spec depends on Volcano yet, it's reported as an alternative gang scheduler.
+			{
+				component: "Volcano",
+				detect:    groupVersion("scheduling.volcano.sh/v1beta1"),
+			},
+			{
+				component: "descheduler",
+				areas:     []string{"Accelerator Descheduling"},
+				detect: func() (bool, string, error) {
+					descheduler, err := frameworkutil.DetectDescheduler(ctx, f.ClientSet, descheduling.Selector)
+					if err != nil {
+						return false, "", err
+					}
+					if descheduler == "" {
+						return false, "no Deployment or CronJob of the descheduler is found", nil
+					}
+					return true, descheduler, nil
+				},
+			},
+			{
+				component: "cluster autoscaler",
+				areas:     []string{"Cluster Autoscaling"},
+				detect: func() (bool, string, error) {
+					autoscalers := frameworkutil.DetectClusterAutoscalers(ctx, f.ClientSet)
+					return len(autoscalers) > 0, strings.Join(autoscalers, ", "), nil
+				},
+			},
+		}
+
+		var report []preflightCheck
+		for _, check := range checks {
+			present, details, err := check.detect()
+			if err != nil {
+				details = fmt.Sprintf("detection failed: %v", err)
+			}
+			report = append(report, preflightCheck{Component: check.component, Areas: check.areas, Present: present, Details: details})
+		}
+		ginkgo.AddReportEntry("preflight", report)
+		framework.Logf("Preflight summary:\n%s", formatPreflightReport(report))
+	})
+})
+
+// acceleratorResourceNames returns the accelerator resources of the candidate vendors.
+func acceleratorResourceNames() []string {
+	var names []string
+	for _, vendor := range frameworkutil.CandidateAcceleratorVendors() {
+		names = append(names, string(vendor.ResourceName))
+	}
+	return names
+}
+
+// formatPreflightReport formats the report as a table.
+func formatPreflightReport(report []preflightCheck) string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "COMPONENT\tPRESENT\tAREAS\tDETAILS")
+	for _, check := range report {
+		fmt.Fprintf(w, "%s\t%t\t%s\t%s\n", check.Component, check.Present, strings.Join(check.Areas, ", "), check.Details)
+	}
+	w.Flush()
+	return buf.String()
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"net"
+	"net/http"
 	"strconv"
 	"sync"
 	"time"
@@ -12,22 +14,34 @@ import (
 	"github.com/onsi/gomega"
 	monitoring "github.com/prometheus-operator/prometheus-operator/pkg/client/versioned"
 	"github.com/samber/lo"
+	appsv1 "k8s.io/api/apps/v1"
 	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
 	clientset "k8s.io/client-go/kubernetes"
-	resourcehelper "k8s.io/component-helpers/resource"
 	aggregatorclient "k8s.io/kube-aggregator/pkg/client/clientset_generated/clientset"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
 	"k8s.io/kubernetes/test/e2e/framework"
 	e2econfig "k8s.io/kubernetes/test/e2e/framework/config"
-	e2egpu "k8s.io/kubernetes/test/e2e/framework/gpu"
+	e2edeployment "k8s.io/kubernetes/test/e2e/framework/deployment"
 	e2ejob "k8s.io/kubernetes/test/e2e/framework/job"
 	e2enode "k8s.io/kubernetes/test/e2e/framework/node"
 	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
+	e2ereplicaset "k8s.io/kubernetes/test/e2e/framework/replicaset"
 	e2eskipper "k8s.io/kubernetes/test/e2e/framework/skipper"
+	imageutils "k8s.io/kubernetes/test/utils/image"
 	admissionapi "k8s.io/pod-security-admission/api"
 	"k8s.io/utils/ptr"
 	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
@@ -38,65 +52,34 @@ import (
 	prometheusutil "github.com/carlory/ai-conformance/e2e/util/prometheus"
 )
 
+var gangScheduling struct {
+	KueueConfig                string        `default:"kueue-system/kueue-manager-config" usage:"<namespace>/<name> of the ConfigMap of the Kueue controller manager, which is read to detect whether waitForPodsReady is enabled"`
+	PartialSchedulingTolerance time.Duration `default:"10m" usage:"how long the pods of a gang are allowed to be partially scheduled, e.g. while the scheduler binds the pods of an admitted gang one by one. It must be longer than waitForPodsReady.timeout of Kueue, 5m by default, because Kueue only evicts a partially scheduled gang after the timeout"`
+	OverlapTolerance           time.Duration `default:"30s" usage:"how long the pods of the gangs which the quota admits one at a time are allowed to run at the same time, e.g. because of the clock skew between the nodes reporting their start and termination times"`
+}
+var _ = e2econfig.AddOptions(&gangScheduling, "ai.gangScheduling")
+
 var _ = WGDescribe("Gang Scheduling", func() {
 	f := framework.NewDefaultFramework("gang-autoscaling")
 	f.NamespacePodSecurityLevel = admissionapi.LevelBaseline
 	var ns string
+	var resourceName corev1.ResourceName
 	var avaliableGPUs int
 
 	ginkgo.BeforeEach(func(ctx context.Context) {
 		ns = f.Namespace.Name
 
-		nodes, err := e2enode.GetReadyNodesIncludingTainted(ctx, f.ClientSet)
-		framework.ExpectNoError(err)
-
-		capacity := 0
-		allocatable := 0
-		for _, node := range nodes.Items {
-			val, ok := node.Status.Capacity[e2egpu.NVIDIAGPUResourceName]
-			if !ok {
-				continue
-			}
-			capacity += int(val.Value())
-			val, ok = node.Status.Allocatable[e2egpu.NVIDIAGPUResourceName]
-			if !ok {
-				continue
-			}
-			allocatable += int(val.Value())
-		}
-
-		if capacity == 0 {
-			e2eskipper.Skipf("%d ready nodes do not have any Nvidia GPU(s). Skipping...", len(nodes.Items))
-		}
-		if allocatable == 0 {
-			e2eskipper.Skipf("%d ready nodes do not have any allocatable Nvidia GPU(s). Skipping...", len(nodes.Items))
-		}
-
-		used := 0
-		pods, err := f.ClientSet.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
-		framework.ExpectNoError(err)
-		for _, pod := range pods.Items {
-			if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
-				continue
-			}
-			for resource, val := range resourcehelper.PodLimits(&pod, resourcehelper.PodResourcesOptions{}) {
-				if string(resource) == e2egpu.NVIDIAGPUResourceName {
-					used += int(val.Value())
-				}
-			}
-		}
-
-		avaliableGPUs = allocatable - used
-		if avaliableGPUs < 2 {
-			e2eskipper.Skipf("At least 2 Nvidia GPU(s) are required. Only %d/%d are available", avaliableGPUs, allocatable)
-		}
+		resourceName = skipUnlessAcceleratorAllocatable(ctx, f.ClientSet).ResourceName
+		// The nodes provisioned by an autoscaler may be removed while the members of a gang wait for each other.
+		avaliableGPUs = frameworkutil.RequireAvailableAccelerators(ctx, f.ClientSet, resourceName, 2, true).Available()
+		verifyAcceleratorsReleased(ctx, f, resourceName)
 	})
 
 	framework.Context("kueue", func() {
 		var kueueClient kueueclient.Interface
 		var err error
 		ginkgo.BeforeEach(func(ctx context.Context) {
-			frameworkutil.SkipIfGroupVersionUnavaliable(ctx, f.ClientSet.Discovery(), "kueue.x-k8s.io/v1beta1")
+			frameworkutil.SkipIfGroupVersionUnavaliable(ctx, frameworkutil.CachedDiscovery(f.ClientSet), "kueue.x-k8s.io/v1beta1")
 			kueueClient, err = kueueclient.NewForConfig(f.ClientConfig())
 			framework.ExpectNoError(err, "error when creating kueue client")
 		})
@@ -104,10 +87,12 @@ var _ = WGDescribe("Gang Scheduling", func() {
 		/*
 			Release: v1.33
 			Testname: Gang Scheduling with Kueue and Job workload
-			Description: Create two jobs with the same template and each replica requests 1 Nvidia GPU. Also, pay attention
+			Description: Create two jobs with the same template and each replica requests 1 accelerator. Also, pay attention
 			to configure the parallelism and completions to be the same as the jobSize, which is 80% of the total avaliable GPUs
 			per job. In this scenario there is not enough resources to run all pods for both jobs at the same time, but all jobs
-			MUST be scheduled and succeed eventually.
+			MUST be scheduled and succeed eventually. The pods of a job MUST NOT be partially scheduled, i.e. between 1 and
+			jobSize-1 pods, for a sustained period.
+			The usage of the ClusterQueue MUST NOT exceed its nominal quota.
 		*/
 		frameworkutil.AIConformanceIt("2 jobs should be scheduled and succeed one by one when there are not enough resources", framework.WithSerial(), func(ctx context.Context) {
 			// We configure the gpu flavor by doubling the total gpu allocatable in our cluster,
@@ -116,74 +101,145 @@ var _ = WGDescribe("Gang Scheduling", func() {
 			// https://kueue.sigs.k8s.io/docs/tasks/manage/setup_wait_for_pods_ready/
 			nominalQuota := avaliableGPUs * 2
 
-			// We create two jobs with the same template and each replica requests 1 Nvidia GPU. Also, pay attention
+			// We create two jobs with the same template and each replica requests 1 accelerator. Also, pay attention
 			// to configure the parallelism and completions to be the same as the jobSize, which is 80% of the total
 			// avaliable GPUs per job.
 			// In this scenario there is not enough resources to run all pods for both jobs at the same time, risking
 			// deadlock.
 			jobSize := int32(math.Ceil(float64(avaliableGPUs) * 0.8))
 
-			ginkgo.By("Creating a resource flavor")
-			rf := &kueuev1beta1.ResourceFlavor{ObjectMeta: metav1.ObjectMeta{Name: f.UniqueName}}
-			_, err = kueueClient.KueueV1beta1().ResourceFlavors().Create(ctx, rf, metav1.CreateOptions{})
-			framework.ExpectNoError(err, "error when creating resource flavor")
-			ginkgo.DeferCleanup(kueueClient.KueueV1beta1().ResourceFlavors().Delete, rf.Name, metav1.DeleteOptions{})
-
-			ginkgo.By("Creating a cluster queue")
-			clusterQueue := &kueuev1beta1.ClusterQueue{
-				ObjectMeta: metav1.ObjectMeta{Name: f.UniqueName},
-				Spec: kueuev1beta1.ClusterQueueSpec{
-					NamespaceSelector: &metav1.LabelSelector{},
-					ResourceGroups: []kueuev1beta1.ResourceGroup{
-						{
-							CoveredResources: []corev1.ResourceName{e2egpu.NVIDIAGPUResourceName},
-							Flavors: []kueuev1beta1.FlavorQuotas{
-								{
-									Name: kueuev1beta1.ResourceFlavorReference(rf.Name),
-									Resources: []kueuev1beta1.ResourceQuota{
-										{
-											Name:         e2egpu.NVIDIAGPUResourceName,
-											NominalQuota: resource.MustParse(strconv.Itoa(nominalQuota)),
-										},
-									},
-								},
-							},
-						},
-					},
-				},
-			}
-			_, err = kueueClient.KueueV1beta1().ClusterQueues().Create(ctx, clusterQueue, metav1.CreateOptions{})
-			framework.ExpectNoError(err, "error when creating cluster queue")
-			ginkgo.DeferCleanup(kueueClient.KueueV1beta1().ClusterQueues().Delete, clusterQueue.Name, metav1.DeleteOptions{})
-
-			ginkgo.By("Creating a local queue")
-			localQueue := &kueuev1beta1.LocalQueue{
-				ObjectMeta: metav1.ObjectMeta{Name: f.UniqueName},
-				Spec: kueuev1beta1.LocalQueueSpec{
-					ClusterQueue: kueuev1beta1.ClusterQueueReference(clusterQueue.Name),
-				},
+			// The quota fits both jobs.
+			runJobsForGangScheduling(ctx, f, kueueClient, resourceName, nominalQuota, jobSize, 2)
+		})
+
+		/*
+			Release: v1.34
+			Testname: Gang Scheduling with Kueue waitForPodsReady and Job workload
+			Description: If the waitForPodsReady feature of Kueue is enabled in the ConfigMap given by
+			-ai.gangScheduling.kueueConfig, create two jobs with the same template and each replica requests 1
+			accelerator. The parallelism and completions of each job are the jobSize, which is 80% of the total avaliable GPUs,
+			and the quota of the ClusterQueue is exactly the total avaliable GPUs. Both jobs MUST be scheduled and
+			succeed eventually without a deadlock. The pods of a job MUST NOT be partially scheduled, i.e. between 1
+			and jobSize-1 pods, for a sustained period. The ClusterQueue MUST NOT admit both jobs at the same time and
+			its usage MUST NOT exceed its nominal quota. The pods of the jobs MUST run one job after the other, i.e.
+			their running windows MUST NOT overlap beyond -ai.gangScheduling.overlapTolerance. The test is skipped if
+			waitForPodsReady is not enabled.
+		*/
+		frameworkutil.AIConformanceIt("2 jobs should succeed one by one within the exact quota when waitForPodsReady is enabled", framework.WithSerial(), func(ctx context.Context) {
+			config, err := frameworkutil.GetKueueConfig(ctx, f.ClientSet, gangScheduling.KueueConfig)
+			framework.ExpectNoError(err)
+			if config == nil || !config.WaitForPodsReadyEnabled() {
+				e2eskipper.Skipf("waitForPodsReady of Kueue is not enabled in ConfigMap %s", gangScheduling.KueueConfig)
 			}
-			_, err = kueueClient.KueueV1beta1().LocalQueues(ns).Create(ctx, localQueue, metav1.CreateOptions{})
-			framework.ExpectNoError(err, "error when creating local queue")
-			ginkgo.DeferCleanup(kueueClient.KueueV1beta1().LocalQueues(ns).Delete, localQueue.Name, metav1.DeleteOptions{})
+			framework.Logf("waitForPodsReady of Kueue is enabled: %+v", *config.WaitForPodsReady)
+
+			// This is the recommended configuration of Kueue, the quota is the total avaliable GPUs and Kueue
+			// evicts an admitted job whose pods are not ready in time instead of letting it hold the quota.
+			nominalQuota := avaliableGPUs
+			jobSize := int32(math.Ceil(float64(avaliableGPUs) * 0.8))
+
+			// The quota fits only one job.
+			runJobsForGangScheduling(ctx, f, kueueClient, resourceName, nominalQuota, jobSize, 1)
+		})
+	})
+
+	framework.Context("kueue and jobset", func() {
+		var kueueClient kueueclient.Interface
+		var dynamicClient dynamic.Interface
+		ginkgo.BeforeEach(func(ctx context.Context) {
+			frameworkutil.SkipIfGroupVersionUnavaliable(ctx, frameworkutil.CachedDiscovery(f.ClientSet), "kueue.x-k8s.io/v1beta1")
+			frameworkutil.SkipIfGroupVersionUnavaliable(ctx, frameworkutil.CachedDiscovery(f.ClientSet), jobSetGVR.GroupVersion().String())
+			var err error
+			kueueClient, err = kueueclient.NewForConfig(f.ClientConfig())
+			framework.ExpectNoError(err, "error when creating kueue client")
+			dynamicClient = f.DynamicClient
+		})
+
+		/*
+			Release: v1.34
+			Testname: Gang Scheduling with Kueue and JobSet workload
+			Description: Create two JobSets with the same template, each has a driver and workers and every pod requests
+			1 accelerator. The total pods of each JobSet is the jobSize, which is 80% of the total avaliable GPUs, and the
+			quota of the ClusterQueue is the total avaliable GPUs, so the quota can't admit both JobSets at the same time.
+			Each JobSet MUST be admitted with all of its pods at once, and all JobSets MUST be scheduled and succeed
+			eventually. The pods of a JobSet MUST NOT be partially scheduled, i.e. between 1 and jobSize-1 pods, for a
+			sustained period.
+			The ClusterQueue MUST NOT admit both JobSets at the same time and its usage MUST NOT exceed its nominal quota.
+			The pods of the JobSets MUST run one JobSet after the other, i.e. their running windows MUST NOT overlap
+			beyond -ai.gangScheduling.overlapTolerance.
+		*/
+		frameworkutil.AIConformanceIt("2 jobsets should be admitted all-or-nothing and succeed one by one when there are not enough resources", framework.WithSerial(), func(ctx context.Context) {
+			// Unlike the Job workload, the quota is the total avaliable GPUs, so Kueue admits one JobSet at a time
+			// and the other one waits in the queue until the admitted one completes and releases the quota.
+			nominalQuota := avaliableGPUs
+			jobSize := int32(math.Ceil(float64(avaliableGPUs) * 0.8))
 
-			ginkgo.By("Creating 2 jobs with the same template but different names and wait for them to complete")
+			clusterQueue, localQueue := createKueueQueues(ctx, kueueClient, ns, f.UniqueName, resourceName, nominalQuota)
+
+			ginkgo.By("Creating 2 jobsets with the same template but different names and wait for them to complete")
+			jobSetNames := []string{"jobset1", "jobset2"}
+			stopMonitor := startPartialSchedulingMonitor(ctx, f.ClientSet, ns, jobSetNameLabel, jobSetNames, jobSize)
+			// The quota fits only one jobset.
+			stopQueueMonitor := startClusterQueueMonitor(ctx, kueueClient, clusterQueue.Name, resourceName, nominalQuota, 1)
+			stopTiming := frameworkutil.StartTiming("gang jobsets completion")
 			wg := sync.WaitGroup{}
-			for _, jobName := range []string{"job1", "job2"} {
+			for _, jobSetName := range jobSetNames {
 				wg.Add(1)
-				go func(jobName string) {
+				go func(jobSetName string) {
 					defer ginkgo.GinkgoRecover()
 					defer wg.Done()
-					createJobForGangScheduling(ctx, f.ClientSet, ns, jobName, jobSize, localQueue.Name)
-					err = e2ejob.WaitForJobComplete(ctx, f.ClientSet, f.Namespace.Name, jobName, batchv1.JobReasonCompletionsReached, jobSize)
-					framework.ExpectNoError(err, "failed to ensure that job %s completed", jobName)
-				}(jobName)
+					createJobSetForGangScheduling(ctx, f.ClientSet, dynamicClient, ns, jobSetName, resourceName, jobSize, localQueue.Name)
+
+					// Wait for the workload of the jobset to be admitted, which happens after the other jobset
+					// completes if it's admitted first. The admission MUST cover all pods of the jobset.
+					var admittedPods int32
+					err := framework.Gomega().Eventually(ctx, func(ctx context.Context) error {
+						workloads, err := kueueClient.KueueV1beta1().Workloads(ns).List(ctx, metav1.ListOptions{})
+						if err != nil {
+							return err
+						}
+						for _, wl := range workloads.Items {
+							if !isOwnedByJobSet(wl.OwnerReferences, jobSetName) || wl.Status.Admission == nil {
+								continue
+							}
+							admittedPods = 0
+							for _, assignment := range wl.Status.Admission.PodSetAssignments {
+								admittedPods += ptr.Deref(assignment.Count, 0)
+							}
+							return nil
+						}
+						return fmt.Errorf("workload of jobset %s is not admitted yet", jobSetName)
+					}).WithTimeout(e2ejob.JobTimeout).WithPolling(framework.Poll).Should(gomega.Succeed())
+					framework.ExpectNoError(err, "failed to ensure that jobset %s is admitted", jobSetName)
+					gomega.Expect(admittedPods).To(gomega.Equal(jobSize), "all pods of jobset %s should be admitted at once", jobSetName)
+
+					err = waitForJobSetCompleted(ctx, dynamicClient, ns, jobSetName, e2ejob.JobTimeout)
+					framework.ExpectNoError(err, "failed to ensure that jobset %s completed", jobSetName)
+				}(jobSetName)
 			}
 			wg.Wait()
+			stopTiming()
+
+			ginkgo.By("Ensuring that the cluster queue admitted one jobset at a time within the quota")
+			framework.ExpectNoError(stopQueueMonitor(), "jobsets were not admitted within the quota")
+
+			ginkgo.By("Ensuring that the pods of each jobset were scheduled all-or-nothing")
+			framework.ExpectNoError(stopMonitor(), "jobsets were not gang scheduled")
+
+			ginkgo.By("Ensuring that the jobsets ran one after the other")
+			verifyGangsSerialized(ctx, f.ClientSet, ns, jobSetNameLabel, jobSetNames)
 		})
 	})
 })
 
+var clusterAutoscaling struct {
+	ProductLabel string        `default:"" usage:"node label with the product name of the accelerators, which the pod of the accelerator type-specific scaling selects, e.g. nvidia.com/gpu.product. It must be known to the cluster autoscaler for the node groups to scale, e.g. via the labels of the node group templates or the requirements of the Karpenter NodePools. If unspecified, the well-known label of the detected vendor is used"`
+	Product      string        `default:"" usage:"value of -ai.clusterAutoscaling.productLabel which the pod of the accelerator type-specific scaling selects. If unspecified, the most common one on the accelerator nodes is used"`
+	MaxPods      int           `default:"0" usage:"maximum number of pods requesting an accelerator which the cluster autoscaling specs create one by one until one of them is pending. The specs fail if none is pending after them. If 0, 1 more than the accelerators allocatable on the existing nodes"`
+	PodInterval  time.Duration `default:"1s" usage:"pause between the creations of the pods requesting an accelerator by the cluster autoscaling specs, so that they don't overwhelm the scheduler and the cluster autoscaler on the large clusters"`
+}
+var _ = e2econfig.AddOptions(&clusterAutoscaling, "ai.clusterAutoscaling")
+
 var _ = WGDescribe("Cluster Autoscaling", func() {
 	f := framework.NewDefaultFramework("cluster-autoscaling")
 	f.NamespacePodSecurityLevel = admissionapi.LevelRestricted
@@ -200,70 +256,296 @@ var _ = WGDescribe("Cluster Autoscaling", func() {
 		becomes Running. Delete the pod and verify the node MUST be reclaimed within 15 minutes.
 	*/
 	frameworkutil.AIConformanceIt("should provision an suitable node for a pending pod requesting an accelerator via resource limits", func(ctx context.Context) {
-		ns := f.Namespace.Name
-		client := f.ClientSet
-
 		ginkgo.By("Getting the current node names")
-		nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+		nodes, err := f.ClientSet.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+		framework.ExpectNoError(err, "Failed to get node list")
+
+		resourceName := frameworkutil.PreferredAcceleratorResource(nodes.Items)
+		lockAccelerators(ctx, f, resourceName)
+
+		pod, _ := provisionNodeForPendingPod(ctx, f, nodes.Items, resourceName, nil)
+		reclaimNodeOfPod(ctx, f, pod)
+	})
+
+	/*
+		Release: v1.34
+		Testname: Cluster Autoscaling of specific accelerator types
+		Description: Create N pods requesting an accelerator via resource limits and selecting a product of the
+		accelerators via the node label given by -ai.clusterAutoscaling.productLabel, or the well-known label of the
+		detected vendor, until the last one is pending and marked as unschedulable. The product is given by
+		-ai.clusterAutoscaling.product, or the most common one on the accelerator nodes. The spec is skipped if no
+		product is found. The cluster autoscaler MUST provision a node of the product for the pending pod. Check the pod
+		status becomes Running. Delete the pod and verify the node MUST be reclaimed within 15 minutes.
+	*/
+	frameworkutil.AIConformanceIt("should provision a node of the accelerator type selected by a pending pod", func(ctx context.Context) {
+		ginkgo.By("Getting the current nodes and the accelerator products")
+		nodes, err := f.ClientSet.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
 		framework.ExpectNoError(err, "Failed to get node list")
-		nodeNames := lo.Map(nodes.Items, func(node corev1.Node, _ int) string { return node.Name })
-		framework.Logf("current node names: %v", nodeNames)
-
-		ginkgo.By("Creating N pods requesting an accelerator until the last one is pending and marked as unschedulable")
-		var pendingPod *corev1.Pod
-		for pendingPod == nil {
-			pod := e2epod.MakePod(ns, nil, nil, f.NamespacePodSecurityLevel, "")
-			pod.Spec.Containers[0].Resources.Limits = map[corev1.ResourceName]resource.Quantity{
-				// TODO: make it configurable
-				corev1.ResourceName(e2egpu.NVIDIAGPUResourceName): resource.MustParse("1"),
+
+		resourceName := frameworkutil.PreferredAcceleratorResource(nodes.Items)
+		label := clusterAutoscaling.ProductLabel
+		if label == "" {
+			vendor := frameworkutil.DetectAcceleratorVendor(nodes.Items)
+			if vendor == nil || vendor.ProductLabel == "" {
+				e2eskipper.Skipf("No well-known product label of %s, specify one via -ai.clusterAutoscaling.productLabel", resourceName)
 			}
-			pod, err = client.CoreV1().Pods(f.Namespace.Name).Create(ctx, pod, metav1.CreateOptions{})
-			framework.ExpectNoError(err, "Failed to create pod")
-			ginkgo.DeferCleanup(client.CoreV1().Pods(ns).Delete, pod.Name, metav1.DeleteOptions{})
-			err = e2epod.WaitForPodCondition(ctx, client, ns, pod.Name, "PodScheduled", f.Timeouts.PodStartShort, func(pod *corev1.Pod) (bool, error) {
-				if pod.Status.Phase == corev1.PodPending {
-					for _, cond := range pod.Status.Conditions {
-						if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse && cond.Reason == corev1.PodReasonUnschedulable {
-							pendingPod = pod
-							return true, nil
-						}
-						if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionTrue {
-							return true, nil
-						}
-					}
+			label = vendor.ProductLabel
+		}
+		product := clusterAutoscaling.Product
+		if product == "" {
+			products := frameworkutil.AcceleratorProducts(nodes.Items, label, resourceName)
+			if len(products) == 0 {
+				e2eskipper.Skipf("None of the %d nodes advertising %s is labeled %s, specify the product via -ai.clusterAutoscaling.product", len(nodes.Items), resourceName, label)
+			}
+			product = products[0]
+		}
+		framework.Logf("Selecting the accelerator product %s=%s", label, product)
+		lockAccelerators(ctx, f, resourceName)
+
+		pod, node := provisionNodeForPendingPod(ctx, f, nodes.Items, resourceName, map[string]string{label: product})
+		gomega.Expect(node.Labels).To(gomega.HaveKeyWithValue(label, product), "The provisioned node %s should be of the selected accelerator product", node.Name)
+		reclaimNodeOfPod(ctx, f, pod)
+	})
+})
+
+// provisionNodeForPendingPod creates pods requesting 1 accelerator of the resource and selecting the nodes by the
+// given node selector until the last one is pending and marked as unschedulable, then waits for the pending pod to
+// be running on a node which isn't one of the given existing nodes. It returns the pending pod and its node. The
+// pods are created -ai.clusterAutoscaling.podInterval apart, and at most -ai.clusterAutoscaling.maxPods of them, 1
+// more than the accelerators allocatable on the existing nodes by default.
+func provisionNodeForPendingPod(ctx context.Context, f *framework.Framework, existingNodes []corev1.Node, resourceName corev1.ResourceName, nodeSelector map[string]string) (*corev1.Pod, *corev1.Node) {
+	ns := f.Namespace.Name
+	client := f.ClientSet
+	nodeNames := lo.Map(existingNodes, func(node corev1.Node, _ int) string { return node.Name })
+	framework.Logf("current node names: %v", nodeNames)
+
+	maxPods := frameworkutil.PendingPodCap(existingNodes, resourceName, clusterAutoscaling.MaxPods)
+	ginkgo.By(fmt.Sprintf("Creating at most %d pods requesting %s until the last one is pending and marked as unschedulable", maxPods, resourceName))
+	var pendingPod *corev1.Pod
+	for created := 0; pendingPod == nil; created++ {
+		if created == maxPods {
+			framework.Failf("None of the %d pods requesting %s is pending, the accelerators may be released by other workloads meanwhile, "+
+				"or -ai.clusterAutoscaling.maxPods is too small", maxPods, resourceName)
+		}
+		if created > 0 {
+			select {
+			case <-ctx.Done():
+				framework.ExpectNoError(ctx.Err(), "interrupted while creating the pods requesting %s", resourceName)
+			case <-time.After(clusterAutoscaling.PodInterval):
+			}
+		}
+		pod := e2epod.MakePod(ns, nodeSelector, nil, f.NamespacePodSecurityLevel, "")
+		pod.Spec.Containers[0].Resources.Limits = map[corev1.ResourceName]resource.Quantity{
+			resourceName: resource.MustParse("1"),
+		}
+		pod, err := client.CoreV1().Pods(f.Namespace.Name).Create(ctx, pod, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "Failed to create pod")
+		frameworkutil.DeferCleanup(client.CoreV1().Pods(ns).Delete, pod.Name, metav1.DeleteOptions{})
+		// The pod may already be running when it's observed, which means it's scheduled as well.
+		err = e2epod.WaitForPodCondition(ctx, client, ns, pod.Name, "PodScheduled", f.Timeouts.PodStartShort, func(pod *corev1.Pod) (bool, error) {
+			if frameworkutil.IsPodUnschedulable(pod) {
+				pendingPod = pod
+				return true, nil
+			}
+			return frameworkutil.IsPodScheduled(pod), nil
+		})
+		framework.ExpectNoError(err, "error when getting the scheduling status of pod %s", pod.Name)
+	}
+	framework.Logf("the pending pod is made: %s", pendingPod.Name)
+
+	ginkgo.By("Waiting for the pending pod to be running and not scheduled on an existing node")
+	stopTiming := frameworkutil.StartTiming("node provisioning")
+	err := e2epod.WaitForPodRunningInNamespaceSlow(ctx, client, ns, pendingPod.Name)
+	stopTiming()
+	framework.ExpectNoError(err, "error when waiting for the pod %s to be running", pendingPod.Name)
+	pod, err := client.CoreV1().Pods(ns).Get(ctx, pendingPod.Name, metav1.GetOptions{})
+	framework.ExpectNoError(err, "error when retrieving the pod %s", pendingPod.Name)
+	gomega.Expect(pod.Spec.NodeName).ToNot(gomega.BeElementOf(nodeNames), "The pod should not be scheduled on an existing node")
+	node, err := client.CoreV1().Nodes().Get(ctx, pod.Spec.NodeName, metav1.GetOptions{})
+	framework.ExpectNoError(err, "error when retrieving the node %s", pod.Spec.NodeName)
+	return pod, node
+}
+
+// reclaimNodeOfPod deletes the pod and waits for its node to be reclaimed by the cluster autoscaler.
+func reclaimNodeOfPod(ctx context.Context, f *framework.Framework, pod *corev1.Pod) {
+	ns := f.Namespace.Name
+	client := f.ClientSet
+	nodeName := pod.Spec.NodeName
+
+	ginkgo.By("Deleting the pending pod and waiting for the node to be reclaimed")
+	err := client.CoreV1().Pods(ns).Delete(ctx, pod.Name, metav1.DeleteOptions{})
+	framework.ExpectNoError(err, "error when deleting the pod %s", pod.Name)
+	err = e2epod.WaitForPodNotFoundInNamespace(ctx, client, pod.Name, ns, f.Timeouts.PodStartShort)
+	framework.ExpectNoError(err, "error when waiting for the pod %s to be deleted", pod.Name)
+	stopTiming := frameworkutil.StartTiming("node reclaim")
+	err = framework.Gomega().Eventually(ctx, framework.HandleRetry(func(ctx context.Context) (*corev1.Node, error) {
+		node, err := client.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return node, err
+	})).WithTimeout(15 * time.Minute).Should(gomega.BeNil())
+	stopTiming()
+	framework.ExpectNoError(err, "error when waiting for the node %s to be reclaimed", nodeName)
+}
+
+var descheduling struct {
+	Selector string        `default:"" usage:"label selector of the Deployment or the CronJob which runs the descheduler, e.g. app=descheduler. If unspecified, the labels of the descheduler manifests and helm chart, app=descheduler and app.kubernetes.io/name=descheduler, are tried"`
+	Timeout  time.Duration `default:"10m" usage:"how long the descheduler is allowed to take to rebalance the imbalanced accelerator workload. It must be longer than the descheduling interval of the descheduler, 5m by default"`
+}
+var _ = e2econfig.AddOptions(&descheduling, "ai.descheduling")
+
+var _ = WGDescribe("Accelerator Descheduling", func() {
+	f := framework.NewDefaultFramework("accelerator-descheduling")
+	f.NamespacePodSecurityLevel = admissionapi.LevelBaseline
+
+	// The descheduler rebalances the running workloads rather than only the scheduler placing the new ones. Create a
+	// ReplicaSet of 2 replicas requesting 1 accelerator each, bound to the accelerator node with the most available
+	// accelerators, and a PodDisruptionBudget with minAvailable 1 for it. Then replace the binding in the template of
+	// the ReplicaSet with a topology spread constraint on the hostnames, so that the replicas are duplicated on the
+	// node while another accelerator node has an available accelerator. The descheduler MUST evict a replica within
+	// -ai.descheduling.timeout, so that the replicas run on different accelerator nodes, and at least 1 replica MUST
+	// stay ready meanwhile. The test is skipped if no descheduler is found, see -ai.descheduling.selector, or if no
+	// other accelerator node has an available accelerator.
+	frameworkutil.AIConformanceShouldIt("the descheduler should rebalance the replicas of an accelerator workload duplicated on a node", func(ctx context.Context) {
+		ns := f.Namespace.Name
+		name := "imbalanced"
+		const replicas = 2
+		descheduler, err := frameworkutil.DetectDescheduler(ctx, f.ClientSet, descheduling.Selector)
+		framework.ExpectNoError(err)
+		if descheduler == "" {
+			e2eskipper.Skipf("No descheduler is found, specify the label selector of its Deployment or CronJob via -ai.descheduling.selector")
+		}
+		framework.Logf("Found the descheduler of %s", descheduler)
+		vendor := skipUnlessAcceleratorAllocatable(ctx, f.ClientSet)
+		lockAccelerators(ctx, f, vendor.ResourceName)
+		verifyAcceleratorsReleased(ctx, f, vendor.ResourceName)
+
+		ginkgo.By("Selecting the accelerator node to bind the replicas to")
+		nodes, err := e2enode.GetReadyNodesIncludingTainted(ctx, f.ClientSet)
+		framework.ExpectNoError(err)
+		acceleratorNodes, err := frameworkutil.AcceleratorNodes(nodes.Items, vendor.ResourceName)
+		framework.ExpectNoError(err)
+		acceleratorNodes = lo.Filter(acceleratorNodes, func(node corev1.Node, _ int) bool {
+			return !node.Spec.Unschedulable && !frameworkutil.IsAutoscalerNode(&node)
+		})
+		count, err := frameworkutil.CountSchedulableAccelerators(ctx, f.ClientSet, vendor.ResourceName, true)
+		framework.ExpectNoError(err)
+		source, targets := frameworkutil.ImbalancedAcceleratorNodes(acceleratorNodes, count, vendor.ResourceName, replicas)
+		if source == "" {
+			e2eskipper.Skipf("None of the %d schedulable %s nodes has %d available %s while another one has an available %s",
+				len(acceleratorNodes), vendor.Name, replicas, vendor.ResourceName, vendor.ResourceName)
+		}
+		framework.Logf("Binding the replicas to node %s, the nodes %v have an available %s", source, targets, vendor.ResourceName)
+
+		ginkgo.By(fmt.Sprintf("Creating a ReplicaSet of %d replicas requesting 1 %s on node %s", replicas, vendor.ResourceName, source))
+		podLabels := map[string]string{"app": name}
+		rs := &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: appsv1.ReplicaSetSpec{
+				Replicas: ptr.To[int32](replicas),
+				Selector: &metav1.LabelSelector{MatchLabels: podLabels},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: podLabels},
+					Spec: corev1.PodSpec{
+						// The replicas are bound to the node bypassing the scheduler, rather than selecting it, so that
+						// the descheduler finds the other accelerator nodes fitting them.
+						NodeName: source,
+						Containers: []corev1.Container{
+							{
+								Name:    "main",
+								Image:   imageutils.GetE2EImage(imageutils.BusyBox),
+								Command: []string{"/bin/sh", "-c", "trap exit TERM; while true; do sleep 1; done"},
+								Resources: corev1.ResourceRequirements{
+									Limits: corev1.ResourceList{vendor.ResourceName: resource.MustParse("1")},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		requireAcceleratorNode(ctx, f.ClientSet, &rs.Spec.Template.Spec, vendor.ResourceName)
+		_, err = f.ClientSet.AppsV1().ReplicaSets(ns).Create(ctx, rs, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "error when creating replicaset")
+		frameworkutil.DeferCleanup(f.ClientSet.AppsV1().ReplicaSets(ns).Delete, name, metav1.DeleteOptions{})
+		err = e2ereplicaset.WaitForReadyReplicaSet(ctx, f.ClientSet, ns, name)
+		framework.ExpectNoError(err, "error when waiting for replicaset to be ready")
+		pods, err := f.ClientSet.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{LabelSelector: labels.Set(podLabels).String()})
+		framework.ExpectNoError(err, "error when listing pods of replicaset")
+		bound := lo.Map(pods.Items, func(pod corev1.Pod, _ int) string { return pod.Name })
+
+		ginkgo.By("Creating a PodDisruptionBudget with minAvailable 1 for the ReplicaSet")
+		pdb := &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				MinAvailable: ptr.To(intstr.FromInt32(1)),
+				Selector:     &metav1.LabelSelector{MatchLabels: podLabels},
+			},
+		}
+		_, err = f.ClientSet.PolicyV1().PodDisruptionBudgets(ns).Create(ctx, pdb, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "error when creating pod disruption budget")
+		_, err = frameworkutil.WaitForPodDisruptionBudgetObserved(ctx, f.ClientSet, ns, name, f.Timeouts.PodStart)
+		framework.ExpectNoError(err)
+
+		ginkgo.By("Replacing the binding of the ReplicaSet with a topology spread constraint on the hostnames")
+		_, err = e2ereplicaset.UpdateReplicaSetWithRetries(f.ClientSet, ns, name, func(rs *appsv1.ReplicaSet) {
+			rs.Spec.Template.Spec.NodeName = ""
+			rs.Spec.Template.Spec.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{
+				{
+					MaxSkew:           1,
+					TopologyKey:       corev1.LabelHostname,
+					WhenUnsatisfiable: corev1.DoNotSchedule,
+					LabelSelector:     &metav1.LabelSelector{MatchLabels: podLabels},
+				},
+			}
+		})
+		framework.ExpectNoError(err, "error when updating replicaset")
+
+		ginkgo.By(fmt.Sprintf("Waiting for the descheduler to rebalance the replicas within %v", descheduling.Timeout))
+		minReady := replicas
+		err = framework.Gomega().Eventually(ctx, func(ctx context.Context) error {
+			pods, err := f.ClientSet.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{LabelSelector: labels.Set(podLabels).String()})
+			if err != nil {
+				return err
+			}
+			evicted := sets.New(bound...)
+			readyNodes := sets.New[string]()
+			ready := 0
+			for _, pod := range pods.Items {
+				evicted.Delete(pod.Name)
+				if pod.DeletionTimestamp == nil && podutil.IsPodReady(&pod) {
+					ready++
+					readyNodes.Insert(pod.Spec.NodeName)
 				}
-				return false, nil
-			})
-			framework.ExpectNoError(err, "error when getting the scheduling status of pod %s", pod.Name)
-		}
-		framework.Logf("the pending pod is made: %s", pendingPod.Name)
-
-		ginkgo.By("Waiting for the pending pod to be running and not scheduled on an existing node")
-		err = e2epod.WaitForPodRunningInNamespaceSlow(ctx, client, ns, pendingPod.Name)
-		framework.ExpectNoError(err, "error when waiting for the pod %s to be running", pendingPod.Name)
-		pod, err := client.CoreV1().Pods(ns).Get(ctx, pendingPod.Name, metav1.GetOptions{})
-		framework.ExpectNoError(err, "error when retrieving the pod %s", pendingPod.Name)
-		nodeName := pod.Spec.NodeName
-		gomega.Expect(nodeName).ToNot(gomega.BeElementOf(nodeNames), "The pod should not be scheduled on an existing node")
-
-		ginkgo.By("Deleting the pending pod and waiting for the node to be reclaimed")
-		err = client.CoreV1().Pods(ns).Delete(ctx, pendingPod.Name, metav1.DeleteOptions{})
-		framework.ExpectNoError(err, "error when deleting the pod %s", pendingPod.Name)
-		err = e2epod.WaitForPodNotFoundInNamespace(ctx, client, pendingPod.Name, ns, f.Timeouts.PodStartShort)
-		framework.ExpectNoError(err, "error when waiting for the pod %s to be deleted", pendingPod.Name)
-		err = framework.Gomega().Eventually(ctx, framework.HandleRetry(func(ctx context.Context) (*corev1.Node, error) {
-			node, err := f.ClientSet.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
-			if apierrors.IsNotFound(err) {
-				return nil, nil
 			}
-			return node, err
-		})).WithTimeout(15 * time.Minute).Should(gomega.BeNil())
-		framework.ExpectNoError(err, "error when waiting for the node %s to be reclaimed", nodeName)
+			minReady = min(minReady, ready)
+			if evicted.Len() == 0 {
+				return fmt.Errorf("none of the replicas %v on node %s is evicted", bound, source)
+			}
+			if readyNodes.Len() < 2 {
+				return fmt.Errorf("%d ready replicas run on nodes %v", ready, sets.List(readyNodes))
+			}
+			framework.Logf("Replicas %v are evicted, the ready replicas run on nodes %v", sets.List(evicted), sets.List(readyNodes))
+			return nil
+		}).WithTimeout(descheduling.Timeout).WithPolling(framework.Poll).Should(gomega.Succeed())
+		framework.ExpectNoError(err, "the descheduler should rebalance the replicas within %v", descheduling.Timeout)
+		gomega.Expect(minReady).To(gomega.BeNumerically(">=", 1), "at least 1 replica should stay ready as the pod disruption budget requires")
 	})
 })
 
 var podAutoscaling struct {
-	MetricName string `default:"" usage:"metric name to use for the HorizontalPodAutoscaler"`
+	MetricName              string        `default:"" usage:"metric name to use for the HorizontalPodAutoscaler"`
+	AcceleratorResourceName string        `default:"" usage:"accelerator resource requested by each replica of the workload, e.g. nvidia.com/gpu. If unspecified, the resource of the vendor detected from the ready nodes is used"`
+	LoadFactor              float64       `default:"2" usage:"how many times the target value of the custom metric per replica the load drives it to, for every replica the HorizontalPodAutoscaler can scale to. It must be greater than 1 to trigger the scale up"`
+	LoadDuration            time.Duration `default:"30s" usage:"how long each load request keeps the custom metric bumped"`
+	LoadConcurrency         int           `default:"0" usage:"number of concurrent load requests the load is split into. If 0, the load is split into requests bumping the custom metric by 10 each"`
+	LoadMode                string        `default:"sustained" usage:"how the load drives the custom metric above the target, either sustained, i.e. concurrent requests lasting for ai.podAutoscaling.loadDuration and renewed together like long-lived connections, or rate, i.e. a steady rate of requests each lasting for ai.podAutoscaling.loadDuration like the requests in flight of a service"`
+	CustomMetricsTimeout    time.Duration `default:"5m" usage:"how long the v1beta1.custom.metrics.k8s.io APIService is allowed to take to become available, e.g. while the metrics adapter serving it starts, before the autoscaling specs fail"`
+	UtilizationMetricName   string        `default:"DCGM_FI_DEV_GPU_UTIL" usage:"custom metric of the pods which the metrics adapter serves from the utilization of their accelerators, e.g. DCGM_FI_DEV_GPU_UTIL by prometheus-adapter rules mapping the DCGM exporter metrics to the pods using the GPUs. The accelerator utilization spec is skipped unless the custom metrics API exposes it"`
+	UtilizationTarget       string        `default:"40" usage:"target average value of ai.podAutoscaling.utilizationMetricName per replica, in the unit of the metric, e.g. percent for DCGM_FI_DEV_GPU_UTIL. The utilization of a replica keeping its accelerator busy must exceed it by more than the tolerance of the HorizontalPodAutoscaler, 10% by default"`
+	UtilizationLoadImage    string        `default:"docker.io/pytorch/pytorch:2.5.1-cuda12.4-cudnn9-runtime" usage:"image of the workload of the accelerator utilization spec, which runs ai.podAutoscaling.utilizationLoadCommand, e.g. an image with ROCm for the AMD GPUs"`
+	UtilizationLoadCommand  string        `default:"python3 -c 'import itertools, torch; a = torch.randn(8192, 8192, device=0); any(a @ a is None for _ in itertools.count())'" usage:"shell command of the workload of the accelerator utilization spec, which keeps the accelerator allocated to the replica busy until it's killed"`
 }
 var _ = e2econfig.AddOptions(&podAutoscaling, "ai.podAutoscaling")
 
@@ -275,31 +557,29 @@ var _ = WGDescribe("Pod Autoscaling", func() {
 	ginkgo.BeforeEach(func(ctx context.Context) {
 		aggrclient, err := aggregatorclient.NewForConfig(f.ClientConfig())
 		framework.ExpectNoError(err, "error when creating aggregator client")
-		_, err = aggrclient.ApiregistrationV1().APIServices().Get(ctx, "v1beta1.custom.metrics.k8s.io", metav1.GetOptions{})
-		if err != nil {
-			if apierrors.IsNotFound(err) {
-				e2eskipper.Skipf("The APIService v1beta1.custom.metrics.k8s.io does not exist")
-			}
-			framework.Failf("error when getting APIService v1beta1.custom.metrics.k8s.io: %v", err)
-		}
+		frameworkutil.SkipUnlessAPIServiceExists(ctx, aggrclient, "v1beta1.custom.metrics.k8s.io")
+		err = frameworkutil.WaitForAPIServiceAvailable(ctx, aggrclient, "v1beta1.custom.metrics.k8s.io", podAutoscaling.CustomMetricsTimeout)
+		framework.ExpectNoError(err)
 
 		// Check if Prometheus Operator is installed by trying to get its API resources.
-		frameworkutil.SkipIfGroupVersionUnavaliable(ctx, f.ClientSet.Discovery(), "monitoring.coreos.com/v1")
+		frameworkutil.SkipIfGroupVersionUnavaliable(ctx, frameworkutil.CachedDiscovery(f.ClientSet), "monitoring.coreos.com/v1")
 	})
 
 	/*
 		Release: v1.33
 		Testname: Pod Autoscaling
-		Description: Create a Deployment and exposes a custom metric via a ServiceMonitor. Create an HorizontalPodAutoscaler
-		targeting the Deployment. Introduce load to the sample application, causing the average custom metric value to
-		significantly exceed the target, triggering a scale up. Then remove the load to trigger a scale down.
+		Description: Create a Deployment whose replicas request 1 accelerator each and exposes a custom metric via a
+		ServiceMonitor. Create an HorizontalPodAutoscaler targeting the Deployment whose maxReplicas is 1 more than the
+		accelerators the workload can use. The HorizontalPodAutoscaler MUST scale on the custom metric, not on a resource
+		metric such as cpu. Introduce load to the sample application, causing the average custom metric
+		value to significantly exceed the target, triggering a scale up. The ready replicas MUST scale up to the available
+		accelerators and MUST NOT exceed them, the replica beyond the available accelerators MUST stay unschedulable.
+		Then remove the load to trigger a scale down.
 	*/
 	frameworkutil.AIConformanceIt("should scale up and down the workload based on the custom metrics", func(ctx context.Context) {
 		ns := f.Namespace.Name
 		replicas := 1
 		minReplicas := 1
-		maxReplicas := 2
-		fristScale := 2
 		secondScale := 1
 		initCustomMetric := 150
 		metricTargetValue := 50
@@ -308,39 +588,530 @@ var _ = WGDescribe("Pod Autoscaling", func() {
 		kind := e2eautoscaling.KindDeployment
 		name := "resource-consumer"
 
+		ginkgo.By("Getting the accelerator resource requested by the workload")
+		acceleratorResourceName := corev1.ResourceName(podAutoscaling.AcceleratorResourceName)
+		if acceleratorResourceName == "" {
+			acceleratorResourceName = skipUnlessAcceleratorAllocatable(ctx, f.ClientSet).ResourceName
+		}
+		lockAccelerators(ctx, f, acceleratorResourceName)
+		verifyAcceleratorsReleased(ctx, f, acceleratorResourceName)
+
 		ginkgo.By("Getting the Prometheus instance")
 		promOpClient, err := monitoring.NewForConfig(f.ClientConfig())
 		framework.ExpectNoError(err, "error when creating prometheus operator client")
-		promList, err := promOpClient.MonitoringV1().Prometheuses(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
-		framework.ExpectNoError(err, "error when getting Prometheus list")
-		gomega.Expect(promList.Items).ToNot(gomega.BeEmpty(), "at least one Prometheus should be found")
-		prom := promList.Items[0]
+		prom, err := prometheusutil.SelectPrometheus(ctx, promOpClient, f.ClientSet, prometheus.Namespace, prometheus.Name, ns)
+		framework.ExpectNoError(err, "error when selecting the Prometheus instance")
 
 		ginkgo.By("Create a resource consumer and initialize the custom metric value")
-		rc := e2eautoscaling.NewDynamicResourceConsumer(ctx, name, ns, kind, replicas, 0, 0,
-			initCustomMetric, 0, 0, metricName, f.ClientSet, f.ScalesGetter, e2eautoscaling.Disable, e2eautoscaling.Idle, nil)
-		ginkgo.DeferCleanup(rc.CleanUp)
+		loadProfile := e2eautoscaling.LoadProfile{
+			Mode:        e2eautoscaling.LoadMode(podAutoscaling.LoadMode),
+			Duration:    podAutoscaling.LoadDuration,
+			Concurrency: podAutoscaling.LoadConcurrency,
+		}
+		framework.ExpectNoError(loadProfile.Validate(), "invalid load profile")
+		if podAutoscaling.LoadFactor <= 1 {
+			framework.Failf("-ai.podAutoscaling.loadFactor must be greater than 1, got %v", podAutoscaling.LoadFactor)
+		}
+		rc := e2eautoscaling.NewDynamicResourceConsumerWithLoadProfile(ctx, name, ns, kind, replicas, 0, 0,
+			initCustomMetric, 0, 0, metricName, f.ClientSet, f.ScalesGetter, e2eautoscaling.Disable, e2eautoscaling.Idle, nil, loadProfile)
+		frameworkutil.DeferCleanup(rc.CleanUp)
+
+		ginkgo.By(fmt.Sprintf("Mutate the workload to request 1 %s per replica", acceleratorResourceName))
+		requestAcceleratorForDeployment(ctx, f.ClientSet, ns, name, name, acceleratorResourceName)
+
+		// The accelerators are counted after the workload requests them, so the count is not stale and the
+		// accelerator used by the running replica is given back to the workload.
+		ginkgo.By("Counting the accelerators the workload can use")
+		// The running replica already uses one of the accelerators, so one more is required to scale up.
+		count := frameworkutil.RequireAvailableAccelerators(ctx, f.ClientSet, acceleratorResourceName, 2-replicas, false)
+		schedulableReplicas := count.Available() + replicas
+		fristScale := schedulableReplicas
+		maxReplicas := schedulableReplicas + 1
+		// Make sure the custom metric asks for more replicas than the accelerators can run.
+		rc.ConsumeCustomMetric(int(math.Ceil(float64(metricTargetValue*maxReplicas) * podAutoscaling.LoadFactor)))
 
 		ginkgo.By("Create a service monitor")
-		framework.ExpectNoError(err, "error when creating prometheus operator client")
 		sm := prometheusutil.CreateServiceMonitor(ctx, promOpClient, prom, f.ClientSet, ns, name, map[string]string{"name": name}, "http")
-		framework.ExpectNoError(err, "error when creating service monitor")
-		ginkgo.DeferCleanup(promOpClient.MonitoringV1().ServiceMonitors(sm.Namespace).Delete, sm.Name, metav1.DeleteOptions{})
+		frameworkutil.DeferCleanup(promOpClient.MonitoringV1().ServiceMonitors(sm.Namespace).Delete, sm.Name, metav1.DeleteOptions{})
 
-		ginkgo.By("Create an HorizontalPodAutoscaler")
+		ginkgo.By(fmt.Sprintf("Wait for the custom metric %s to be served by the custom metrics API", metricName))
+		stopTiming := frameworkutil.StartTiming("custom metric collection")
+		err = frameworkutil.WaitForCustomPodMetric(ctx, f.ClientSet, ns, metricName, labels.SelectorFromSet(labels.Set{"name": name}), timeToWait)
+		stopTiming()
+		framework.ExpectNoError(err, "error when waiting for the custom metric %s", metricName)
+
+		ginkgo.By(fmt.Sprintf("Create an HorizontalPodAutoscaler with maxReplicas %d", maxReplicas))
 		hpa := e2eautoscaling.CreatePodsHorizontalPodAutoscaler(ctx, rc, ns, metricName, metricTargetType, int32(metricTargetValue), int32(minReplicas), int32(maxReplicas))
-		ginkgo.DeferCleanup(e2eautoscaling.DeleteHorizontalPodAutoscaler, rc, hpa.Name)
+		frameworkutil.DeferCleanup(e2eautoscaling.DeleteHorizontalPodAutoscaler, rc, hpa.Name)
+
+		ginkgo.By(fmt.Sprintf("Ensuring that the HorizontalPodAutoscaler scales on the custom metric %s", metricName))
+		hpa, err = f.ClientSet.AutoscalingV2().HorizontalPodAutoscalers(ns).Get(ctx, hpa.Name, metav1.GetOptions{})
+		framework.ExpectNoError(err, "error when getting the HorizontalPodAutoscaler")
+		framework.ExpectNoError(frameworkutil.VerifyHPACustomMetric(hpa, metricName), "the HorizontalPodAutoscaler doesn't scale on the custom metric")
 
-		ginkgo.By("Wait for the workload to be scaled up")
+		ginkgo.By(fmt.Sprintf("Wait for the workload to be scaled up to the %d available %s", fristScale, acceleratorResourceName))
+		stopTiming = frameworkutil.StartTiming("scale-up")
 		rc.WaitForReplicas(ctx, fristScale, timeToWait)
+		stopTiming()
+
+		ginkgo.By("Ensuring that the replica beyond the available accelerators stays unschedulable")
+		var pendingPods []string
+		err = framework.Gomega().Eventually(ctx, func(ctx context.Context) error {
+			pods, err := f.ClientSet.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{LabelSelector: labels.Set{"name": name}.String()})
+			if err != nil {
+				return err
+			}
+			pendingPods = nil
+			for _, pod := range pods.Items {
+				if pod.DeletionTimestamp != nil {
+					continue
+				}
+				container := lo.FindOrElse(pod.Spec.Containers, corev1.Container{}, func(c corev1.Container) bool { return c.Name == name })
+				if limit := container.Resources.Limits[acceleratorResourceName]; limit.Value() != 1 {
+					return gomega.StopTrying(fmt.Sprintf("pod %s requests %s %s, expected 1", pod.Name, limit.String(), acceleratorResourceName))
+				}
+				if frameworkutil.IsPodUnschedulable(&pod) {
+					pendingPods = append(pendingPods, pod.Name)
+				}
+			}
+			if len(pendingPods) != maxReplicas-fristScale {
+				return fmt.Errorf("expected %d unschedulable pods, got %v", maxReplicas-fristScale, pendingPods)
+			}
+			return nil
+		}).WithTimeout(timeToWait).WithPolling(framework.Poll).Should(gomega.Succeed())
+		framework.ExpectNoError(err, "error when waiting for the replica beyond the available %s to be unschedulable", acceleratorResourceName)
+		rc.EnsureDesiredReplicasInRange(ctx, fristScale, fristScale, time.Minute, hpa.Name)
 
 		rc.Pause()
 		ginkgo.By("Wait for the workload to be scaled down")
+		stopTiming = frameworkutil.StartTiming("scale-down")
 		rc.WaitForReplicas(ctx, secondScale, timeToWait)
+		stopTiming()
+	})
+	// The custom metric of the resource consumer is synthetic, so the spec above proves the pipeline from a
+	// ServiceMonitor to the HorizontalPodAutoscaler but not that the utilization of the accelerators reaches it.
+	// Autoscaling an accelerator workload on the real utilization, e.g. the DCGM exporter metrics served by
+	// prometheus-adapter, is recommended but requires the rules of the metrics adapter, so the spec is skipped
+	// unless the custom metrics API exposes -ai.podAutoscaling.utilizationMetricName. An idle replica MUST NOT be
+	// scaled up, and a replica keeping its accelerator busy MUST be.
+	frameworkutil.AIConformanceShouldIt("should scale up the workload on the utilization of its accelerators", func(ctx context.Context) {
+		ns := f.Namespace.Name
+		name := "accelerator-utilization"
+		metricName := podAutoscaling.UtilizationMetricName
+		target, err := resource.ParseQuantity(podAutoscaling.UtilizationTarget)
+		framework.ExpectNoError(err, "invalid -ai.podAutoscaling.utilizationTarget")
+
+		ginkgo.By(fmt.Sprintf("Checking that the custom metrics API exposes the accelerator utilization %s of the pods", metricName))
+		exposed, err := frameworkutil.CustomMetricExposed(ctx, f.ClientSet, "pods", metricName)
+		framework.ExpectNoError(err)
+		if !exposed {
+			e2eskipper.Skipf("custom metric %s of the pods is not exposed by the custom metrics API, the rules of the metrics adapter, e.g. prometheus-adapter, must map the accelerator utilization to the pods", metricName)
+		}
+
+		ginkgo.By("Getting the accelerator resource requested by the workload")
+		acceleratorResourceName := corev1.ResourceName(podAutoscaling.AcceleratorResourceName)
+		if acceleratorResourceName == "" {
+			acceleratorResourceName = skipUnlessAcceleratorAllocatable(ctx, f.ClientSet).ResourceName
+		}
+		lockAccelerators(ctx, f, acceleratorResourceName)
+		verifyAcceleratorsReleased(ctx, f, acceleratorResourceName)
+		frameworkutil.RequireAvailableAccelerators(ctx, f.ClientSet, acceleratorResourceName, 2, false)
+
+		ginkgo.By(fmt.Sprintf("Create an idle workload whose replicas request 1 %s each", acceleratorResourceName))
+		deployment := newAcceleratorUtilizationDeployment(ns, name, acceleratorResourceName)
+		requireAcceleratorNode(ctx, f.ClientSet, &deployment.Spec.Template.Spec, acceleratorResourceName)
+		deployment, err = f.ClientSet.AppsV1().Deployments(ns).Create(ctx, deployment, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "error when creating deployment %s", name)
+		frameworkutil.DeferCleanup(f.ClientSet.AppsV1().Deployments(ns).Delete, deployment.Name, metav1.DeleteOptions{})
+		err = frameworkutil.WaitForDeploymentComplete(ctx, f.ClientSet, deployment, framework.PodStartTimeout)
+		framework.ExpectNoError(err, "error when waiting for deployment %s to complete", name)
+
+		ginkgo.By(fmt.Sprintf("Wait for the custom metric %s to be served by the custom metrics API", metricName))
+		selector := labels.SelectorFromSet(labels.Set{"name": name})
+		stopTiming := frameworkutil.StartTiming("accelerator utilization metric collection")
+		err = frameworkutil.WaitForCustomPodMetric(ctx, f.ClientSet, ns, metricName, selector, timeToWait)
+		stopTiming()
+		framework.ExpectNoError(err, "error when waiting for the custom metric %s", metricName)
+
+		ginkgo.By(fmt.Sprintf("Create an HorizontalPodAutoscaler targeting the average %s of %s", metricName, target.String()))
+		hpa := frameworkutil.NewPodsMetricHPA(ns, name, metricName, target, 1, 2)
+		hpa, err = f.ClientSet.AutoscalingV2().HorizontalPodAutoscalers(ns).Create(ctx, hpa, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "error when creating the HorizontalPodAutoscaler")
+		frameworkutil.DeferCleanup(f.ClientSet.AutoscalingV2().HorizontalPodAutoscalers(ns).Delete, hpa.Name, metav1.DeleteOptions{})
+
+		ginkgo.By("Ensuring that the idle workload is not scaled up")
+		err = framework.Gomega().Eventually(ctx, func(ctx context.Context) error {
+			return verifyHPAUtilization(ctx, f.ClientSet, ns, name, metricName, func(current resource.Quantity) error {
+				if current.Cmp(target) >= 0 {
+					return gomega.StopTrying(fmt.Sprintf("the idle workload reports %s %s, expected it below the target %s", metricName, current.String(), target.String()))
+				}
+				return nil
+			})
+		}).WithTimeout(timeToWait).WithPolling(framework.Poll).Should(gomega.Succeed())
+		framework.ExpectNoError(err, "error when waiting for the HorizontalPodAutoscaler to observe %s", metricName)
+		err = framework.Gomega().Consistently(ctx, func(ctx context.Context) error {
+			d, err := f.ClientSet.AppsV1().Deployments(ns).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			if replicas := ptr.Deref(d.Spec.Replicas, 1); replicas != 1 {
+				return fmt.Errorf("the idle workload is scaled to %d replicas", replicas)
+			}
+			return nil
+		}).WithTimeout(time.Minute).WithPolling(framework.Poll).Should(gomega.Succeed())
+		framework.ExpectNoError(err, "the idle workload should not be scaled up")
+
+		ginkgo.By("Keeping the accelerator of the replica busy")
+		pods, err := e2edeployment.GetPodsForDeployment(ctx, f.ClientSet, deployment)
+		framework.ExpectNoError(err, "error when getting the pods of deployment %s", name)
+		gomega.Expect(pods.Items).To(gomega.HaveLen(1), "the idle workload should have 1 pod")
+		e2epod.ExecShellInPod(ctx, f, pods.Items[0].Name, "touch /tmp/load")
+
+		ginkgo.By(fmt.Sprintf("Wait for the workload to be scaled up on the %s of the busy replica", metricName))
+		stopTiming = frameworkutil.StartTiming("accelerator utilization scale-up")
+		err = framework.Gomega().Eventually(ctx, func(ctx context.Context) error {
+			return verifyHPAUtilization(ctx, f.ClientSet, ns, name, metricName, func(current resource.Quantity) error {
+				if current.Cmp(target) <= 0 {
+					return fmt.Errorf("the busy workload reports %s %s, expected it above the target %s", metricName, current.String(), target.String())
+				}
+				d, err := f.ClientSet.AppsV1().Deployments(ns).Get(ctx, name, metav1.GetOptions{})
+				if err != nil {
+					return err
+				}
+				if d.Status.ReadyReplicas != 2 {
+					return fmt.Errorf("%d/2 replicas are ready", d.Status.ReadyReplicas)
+				}
+				return nil
+			})
+		}).WithTimeout(timeToWait).WithPolling(framework.Poll).Should(gomega.Succeed())
+		stopTiming()
+		framework.ExpectNoError(err, "error when waiting for the workload to be scaled up on %s", metricName)
+	})
+})
+
+var inferenceAutoscaling struct {
+	TrafficDuration time.Duration `default:"5m" usage:"how long the inference requests are sent through the Gateway by the Inference Autoscaling spec. It must be long enough for the custom metric to be scraped and the HorizontalPodAutoscaler to scale up the model server"`
+}
+var _ = e2econfig.AddOptions(&inferenceAutoscaling, "ai.inferenceAutoscaling")
+
+var _ = WGDescribe("Inference Autoscaling", func() {
+	f := framework.NewDefaultFramework("inference-autoscaling")
+	f.NamespacePodSecurityLevel = admissionapi.LevelBaseline
+	const timeToWait = 15 * time.Minute
+
+	ginkgo.BeforeEach(func(ctx context.Context) {
+		aggrclient, err := aggregatorclient.NewForConfig(f.ClientConfig())
+		framework.ExpectNoError(err, "error when creating aggregator client")
+		frameworkutil.SkipUnlessAPIServiceExists(ctx, aggrclient, "v1beta1.custom.metrics.k8s.io")
+		err = frameworkutil.WaitForAPIServiceAvailable(ctx, aggrclient, "v1beta1.custom.metrics.k8s.io", podAutoscaling.CustomMetricsTimeout)
+		framework.ExpectNoError(err)
+		frameworkutil.SkipIfGroupVersionUnavaliable(ctx, frameworkutil.CachedDiscovery(f.ClientSet), "monitoring.coreos.com/v1")
+	})
+
+	// Serving the inference requests routed by a Gateway and scaling the model server on its request queue, i.e.
+	// the requests in flight, is a realistic AI serving scenario combining the networking and the autoscaling
+	// requirements, but it's not required by the conformance. Each request bumps the custom metric of the replica
+	// serving it for -ai.podAutoscaling.loadDuration, so the HorizontalPodAutoscaler MUST scale up the model server
+	// under the sustained traffic and scale it down once the traffic stops.
+	frameworkutil.AIConformanceShouldIt("should scale the model server on the request queue of the inference traffic routed by a gateway", func(ctx context.Context) {
+		className := skipUnlessGatewayClassAccepted(ctx, f)
+		ns := f.Namespace.Name
+		replicas := 1
+		maxReplicas := 2
+		metricTargetValue := 50
+		// The same amount as the dynamic ResourceConsumer bumps by each request.
+		requestDelta := 10
+		metricName := podAutoscaling.MetricName
+		name := "model-server"
+
+		ginkgo.By("Getting the accelerator resource requested by the model server")
+		acceleratorResourceName := corev1.ResourceName(podAutoscaling.AcceleratorResourceName)
+		if acceleratorResourceName == "" {
+			acceleratorResourceName = skipUnlessAcceleratorAllocatable(ctx, f.ClientSet).ResourceName
+		}
+		lockAccelerators(ctx, f, acceleratorResourceName)
+		verifyAcceleratorsReleased(ctx, f, acceleratorResourceName)
+
+		ginkgo.By("Getting the Prometheus instance")
+		promOpClient, err := monitoring.NewForConfig(f.ClientConfig())
+		framework.ExpectNoError(err, "error when creating prometheus operator client")
+		prom, err := prometheusutil.SelectPrometheus(ctx, promOpClient, f.ClientSet, prometheus.Namespace, prometheus.Name, ns)
+		framework.ExpectNoError(err, "error when selecting the Prometheus instance")
+
+		ginkgo.By("Creating a model server which exposes its request queue as a custom metric")
+		if podAutoscaling.LoadFactor <= 1 {
+			framework.Failf("-ai.podAutoscaling.loadFactor must be greater than 1, got %v", podAutoscaling.LoadFactor)
+		}
+		rc := e2eautoscaling.NewDynamicResourceConsumer(ctx, name, ns, e2eautoscaling.KindDeployment, replicas, 0, 0,
+			0, 0, 0, metricName, f.ClientSet, f.ScalesGetter, e2eautoscaling.Disable, e2eautoscaling.Idle, nil)
+		frameworkutil.DeferCleanup(rc.CleanUp)
+		requestAcceleratorForDeployment(ctx, f.ClientSet, ns, name, name, acceleratorResourceName)
+
+		// The running replicas already use some of the accelerators.
+		frameworkutil.RequireAvailableAccelerators(ctx, f.ClientSet, acceleratorResourceName, maxReplicas-replicas, false)
+
+		ginkgo.By("Routing the inference requests to the model server through a Gateway")
+		createGateway(ctx, f, name, className)
+		createHTTPRoute(ctx, f, name, name, name, 80)
+		err = frameworkutil.WaitForHTTPRouteAccepted(ctx, f.DynamicClient, ns, name, name, 2*time.Minute)
+		framework.ExpectNoError(err, "error when waiting for httproute to be accepted")
+		address, err := frameworkutil.ResolveGatewayAddress(ctx, f.DynamicClient, ns, name)
+		framework.ExpectNoError(err, "error when resolving the address of the gateway")
+
+		ginkgo.By("Create a service monitor")
+		sm := prometheusutil.CreateServiceMonitor(ctx, promOpClient, prom, f.ClientSet, ns, name, map[string]string{"name": name}, "http")
+		frameworkutil.DeferCleanup(promOpClient.MonitoringV1().ServiceMonitors(sm.Namespace).Delete, sm.Name, metav1.DeleteOptions{})
+
+		ginkgo.By(fmt.Sprintf("Create an HorizontalPodAutoscaler with maxReplicas %d", maxReplicas))
+		hpa := e2eautoscaling.CreatePodsHorizontalPodAutoscaler(ctx, rc, ns, metricName, autoscalingv2.AverageValueMetricType, int32(metricTargetValue), int32(replicas), int32(maxReplicas))
+		frameworkutil.DeferCleanup(e2eautoscaling.DeleteHorizontalPodAutoscaler, rc, hpa.Name)
+
+		// The requests in flight sum up to the load factor times the target value of all replicas, and each of them
+		// lasts for the load duration, so one request is sent every interval.
+		queue := int(math.Ceil(float64(metricTargetValue*maxReplicas) * podAutoscaling.LoadFactor))
+		interval := podAutoscaling.LoadDuration * time.Duration(requestDelta) / time.Duration(queue)
+		generator := frameworkutil.HTTPRequestGenerator{
+			Name: "inference-traffic",
+			URL: fmt.Sprintf("http://%s/BumpMetric?metric=%s&delta=%d&durationSec=%d",
+				net.JoinHostPort(address, "80"), metricName, requestDelta, int(podAutoscaling.LoadDuration.Seconds())),
+			// The resource consumer only accepts the POST requests.
+			Method:   http.MethodPost,
+			Requests: int(inferenceAutoscaling.TrafficDuration / interval),
+			Interval: interval,
+		}
+		ginkgo.By(fmt.Sprintf("Sending %d inference requests through the Gateway at %s, one every %v", generator.Requests, address, interval))
+		type generatorResult struct {
+			result *frameworkutil.HTTPRequestResult
+			err    error
+		}
+		done := make(chan generatorResult, 1)
+		go func() {
+			defer ginkgo.GinkgoRecover()
+			result, err := frameworkutil.RunHTTPRequestGenerator(ctx, f.ClientSet, ns, generator)
+			done <- generatorResult{result: result, err: err}
+		}()
+
+		ginkgo.By(fmt.Sprintf("Wait for the custom metric %s to be served by the custom metrics API", metricName))
+		stopTiming := frameworkutil.StartTiming("custom metric collection")
+		err = frameworkutil.WaitForCustomPodMetric(ctx, f.ClientSet, ns, metricName, labels.SelectorFromSet(labels.Set{"name": name}), timeToWait)
+		stopTiming()
+		framework.ExpectNoError(err, "error when waiting for the custom metric %s", metricName)
+
+		ginkgo.By(fmt.Sprintf("Ensuring that the HorizontalPodAutoscaler scales on the custom metric %s", metricName))
+		hpa, err = f.ClientSet.AutoscalingV2().HorizontalPodAutoscalers(ns).Get(ctx, hpa.Name, metav1.GetOptions{})
+		framework.ExpectNoError(err, "error when getting the HorizontalPodAutoscaler")
+		framework.ExpectNoError(frameworkutil.VerifyHPACustomMetric(hpa, metricName), "the HorizontalPodAutoscaler doesn't scale on the custom metric")
+
+		ginkgo.By(fmt.Sprintf("Wait for the model server to be scaled up to %d replicas under the inference traffic", maxReplicas))
+		stopTiming = frameworkutil.StartTiming("scale-up")
+		rc.WaitForReplicas(ctx, maxReplicas, inferenceAutoscaling.TrafficDuration)
+		stopTiming()
+
+		ginkgo.By("Waiting for the inference traffic to stop")
+		generated := <-done
+		framework.ExpectNoError(generated.err, "error when sending the inference requests through the gateway")
+		gomega.Expect(generated.result.StatusCodes).To(gomega.HaveKey(http.StatusOK), "none of the inference requests was served through the gateway")
+
+		ginkgo.By("Wait for the model server to be scaled down")
+		stopTiming = frameworkutil.StartTiming("scale-down")
+		rc.WaitForReplicas(ctx, replicas, timeToWait)
+		stopTiming()
 	})
 })
 
-func createJobForGangScheduling(ctx context.Context, client clientset.Interface, ns string, name string, jobSize int32, queueName string) {
+// runJobsForGangScheduling creates a ClusterQueue with the given nominal quota of accelerators and 2 jobs of the
+// given size in the queue, and waits for them to complete. It fails if the pods of a job are partially scheduled
+// for a sustained period, or the ClusterQueue admits more than maxAdmitted jobs at the same time or exceeds the
+// quota.
+func runJobsForGangScheduling(ctx context.Context, f *framework.Framework, kueueClient kueueclient.Interface, resourceName corev1.ResourceName, nominalQuota int, jobSize int32, maxAdmitted int32) {
+	ns := f.Namespace.Name
+	clusterQueue, localQueue := createKueueQueues(ctx, kueueClient, ns, f.UniqueName, resourceName, nominalQuota)
+
+	ginkgo.By("Creating 2 jobs with the same template but different names and wait for them to complete")
+	jobNames := []string{"job1", "job2"}
+	stopMonitor := startPartialSchedulingMonitor(ctx, f.ClientSet, ns, batchv1.JobNameLabel, jobNames, jobSize)
+	stopQueueMonitor := startClusterQueueMonitor(ctx, kueueClient, clusterQueue.Name, resourceName, nominalQuota, maxAdmitted)
+	stopTiming := frameworkutil.StartTiming("gang jobs completion")
+	wg := sync.WaitGroup{}
+	for _, jobName := range jobNames {
+		wg.Add(1)
+		go func(jobName string) {
+			defer ginkgo.GinkgoRecover()
+			defer wg.Done()
+			createJobForGangScheduling(ctx, f.ClientSet, ns, jobName, resourceName, jobSize, localQueue.Name)
+			err := waitForGangJobComplete(ctx, f.ClientSet, ns, jobName, jobSize)
+			framework.ExpectNoError(err, "failed to ensure that job %s completed", jobName)
+		}(jobName)
+	}
+	wg.Wait()
+	stopTiming()
+
+	ginkgo.By("Ensuring that the cluster queue admitted the jobs within the quota")
+	framework.ExpectNoError(stopQueueMonitor(), "jobs were not admitted within the quota")
+
+	ginkgo.By("Ensuring that the pods of each job were scheduled all-or-nothing")
+	framework.ExpectNoError(stopMonitor(), "jobs were not gang scheduled")
+
+	// The jobs only have to run one after the other if the quota can't admit them at the same time.
+	if maxAdmitted == 1 {
+		ginkgo.By("Ensuring that the jobs ran one after the other")
+		verifyGangsSerialized(ctx, f.ClientSet, ns, batchv1.JobNameLabel, jobNames)
+	}
+}
+
+// verifyGangsSerialized verifies that the pods of the gangs labeled with the given names ran one gang after the
+// other, i.e. the running windows of the gangs overlap by at most -ai.gangScheduling.overlapTolerance. It must be
+// called after the gangs complete.
+func verifyGangsSerialized(ctx context.Context, client clientset.Interface, ns, labelKey string, names []string) {
+	now := time.Now()
+	windows := map[string]frameworkutil.RunningWindow{}
+	for _, name := range names {
+		pods, err := client.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{LabelSelector: labels.Set{labelKey: name}.String()})
+		framework.ExpectNoError(err, "error when listing pods of %s", name)
+		window, ok := frameworkutil.PodsRunningWindow(pods.Items, now)
+		if !ok {
+			framework.Failf("None of the %d pods of %s has started", len(pods.Items), name)
+		}
+		framework.Logf("The pods of %s ran in %v", name, window)
+		windows[name] = window
+	}
+	for i, name := range names {
+		for _, other := range names[i+1:] {
+			if overlap := windows[name].Overlap(windows[other]); overlap > gangScheduling.OverlapTolerance {
+				framework.Failf("The pods of %s in %v and the pods of %s in %v ran at the same time for %v, more than the tolerance %v",
+					name, windows[name], other, windows[other], overlap, gangScheduling.OverlapTolerance)
+			}
+		}
+	}
+}
+
+// createKueueQueues creates a ResourceFlavor, a ClusterQueue with the given nominal quota of accelerators and a
+// LocalQueue pointing to the ClusterQueue in the given namespace. All of them are named after the given name.
+func createKueueQueues(ctx context.Context, kueueClient kueueclient.Interface, ns, name string, resourceName corev1.ResourceName, nominalQuota int) (*kueuev1beta1.ClusterQueue, *kueuev1beta1.LocalQueue) {
+	ginkgo.By("Creating a resource flavor")
+	rf := &kueuev1beta1.ResourceFlavor{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	_, err := kueueClient.KueueV1beta1().ResourceFlavors().Create(ctx, rf, metav1.CreateOptions{})
+	framework.ExpectNoError(err, "error when creating resource flavor")
+	frameworkutil.DeferCleanup(kueueClient.KueueV1beta1().ResourceFlavors().Delete, rf.Name, metav1.DeleteOptions{})
+
+	ginkgo.By("Creating a cluster queue")
+	clusterQueue := &kueuev1beta1.ClusterQueue{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: kueuev1beta1.ClusterQueueSpec{
+			NamespaceSelector: &metav1.LabelSelector{},
+			ResourceGroups: []kueuev1beta1.ResourceGroup{
+				{
+					CoveredResources: []corev1.ResourceName{resourceName},
+					Flavors: []kueuev1beta1.FlavorQuotas{
+						{
+							Name: kueuev1beta1.ResourceFlavorReference(rf.Name),
+							Resources: []kueuev1beta1.ResourceQuota{
+								{
+									Name:         resourceName,
+									NominalQuota: resource.MustParse(strconv.Itoa(nominalQuota)),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	_, err = kueueClient.KueueV1beta1().ClusterQueues().Create(ctx, clusterQueue, metav1.CreateOptions{})
+	framework.ExpectNoError(err, "error when creating cluster queue")
+	frameworkutil.DeferCleanup(kueueClient.KueueV1beta1().ClusterQueues().Delete, clusterQueue.Name, metav1.DeleteOptions{})
+
+	ginkgo.By("Creating a local queue")
+	localQueue := &kueuev1beta1.LocalQueue{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: kueuev1beta1.LocalQueueSpec{
+			ClusterQueue: kueuev1beta1.ClusterQueueReference(clusterQueue.Name),
+		},
+	}
+	_, err = kueueClient.KueueV1beta1().LocalQueues(ns).Create(ctx, localQueue, metav1.CreateOptions{})
+	framework.ExpectNoError(err, "error when creating local queue")
+	frameworkutil.DeferCleanup(kueueClient.KueueV1beta1().LocalQueues(ns).Delete, localQueue.Name, metav1.DeleteOptions{})
+	// The Workloads left in the LocalQueue, e.g. by a spec failing before its workloads are deleted, hold the quota
+	// of the ClusterQueue and block its deletion, so they are deleted before the queues.
+	frameworkutil.DeferCleanup(frameworkutil.DeleteQueuedWorkloads, kueueClient, ns, localQueue.Name, framework.PodDeleteTimeout)
+
+	return clusterQueue, localQueue
+}
+
+// clusterQueueUsage returns the quota of the resource used by the admitted workloads of the ClusterQueue, summed
+// over its flavors.
+func clusterQueueUsage(clusterQueue *kueuev1beta1.ClusterQueue, resourceName corev1.ResourceName) resource.Quantity {
+	var used resource.Quantity
+	for _, flavor := range clusterQueue.Status.FlavorsUsage {
+		for _, usage := range flavor.Resources {
+			if usage.Name == resourceName {
+				used.Add(usage.Total)
+			}
+		}
+	}
+	return used
+}
+
+// startClusterQueueMonitor periodically gets the status of the ClusterQueue and logs its admitted and pending
+// workloads and the usage of the accelerators whenever they change. It returns a function which stops the monitor
+// and returns an error if no workload was admitted, more than maxAdmitted workloads were admitted at the same time,
+// or the usage exceeded the nominal quota, i.e. Kueue didn't gate the workloads on the quota.
+func startClusterQueueMonitor(ctx context.Context, kueueClient kueueclient.Interface, name string, resourceName corev1.ResourceName, nominalQuota int, maxAdmitted int32) func() error {
+	quota := resource.MustParse(strconv.Itoa(nominalQuota))
+	ctx, cancel := context.WithCancel(ctx)
+	// Make sure the monitor doesn't outlive a failed spec.
+	ginkgo.DeferCleanup(cancel)
+	done := make(chan struct{})
+	var violation error
+	var admitted bool
+	go func() {
+		defer ginkgo.GinkgoRecover()
+		defer close(done)
+		var last string
+		wait.UntilWithContext(ctx, func(ctx context.Context) {
+			if violation != nil {
+				return
+			}
+			clusterQueue, err := kueueClient.KueueV1beta1().ClusterQueues().Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				if ctx.Err() == nil {
+					framework.Logf("Failed to get cluster queue %s: %v", name, err)
+				}
+				return
+			}
+			status := clusterQueue.Status
+			used := clusterQueueUsage(clusterQueue, resourceName)
+			current := fmt.Sprintf("%d admitted and %d pending workloads, %s/%s %s used",
+				status.AdmittedWorkloads, status.PendingWorkloads, used.String(), quota.String(), resourceName)
+			if current != last {
+				framework.Logf("Cluster queue %s has %s", name, current)
+				last = current
+			}
+			if status.AdmittedWorkloads > 0 {
+				admitted = true
+			}
+			switch {
+			case status.AdmittedWorkloads > maxAdmitted:
+				violation = fmt.Errorf("cluster queue %s admitted %d workloads at the same time, expected at most %d", name, status.AdmittedWorkloads, maxAdmitted)
+			case used.Cmp(quota) > 0:
+				violation = fmt.Errorf("cluster queue %s used %s %s, more than the nominal quota %s", name, used.String(), resourceName, quota.String())
+			}
+		}, framework.Poll)
+	}()
+	return func() error {
+		cancel()
+		<-done
+		if violation == nil && !admitted {
+			return fmt.Errorf("no workload was observed to be admitted by cluster queue %s", name)
+		}
+		return violation
+	}
+}
+
+// createJobForGangScheduling creates an indexed Job of jobSize pods and a headless Service for them, every pod
+// requests 1 accelerator. The pod of index 0 is the driver, which waits until the other pods, i.e. the workers, are
+// reachable, then asks them to exit.
+func createJobForGangScheduling(ctx context.Context, client clientset.Interface, ns string, name string, resourceName corev1.ResourceName, jobSize int32, queueName string) {
 	labels := map[string]string{"job": name}
 	// Create a headless service for pod-to-pod communication
 	svc := &corev1.Service{
@@ -359,7 +1130,7 @@ func createJobForGangScheduling(ctx context.Context, client clientset.Interface,
 	}
 	_, err := client.CoreV1().Services(ns).Create(ctx, svc, metav1.CreateOptions{})
 	framework.ExpectNoError(err, "error when creating service")
-	ginkgo.DeferCleanup(client.CoreV1().Services(ns).Delete, svc.Name, metav1.DeleteOptions{})
+	frameworkutil.DeferCleanup(client.CoreV1().Services(ns).Delete, svc.Name, metav1.DeleteOptions{})
 
 	// Create a config map to store the script code
 	cm := &corev1.ConfigMap{
@@ -368,7 +1139,7 @@ func createJobForGangScheduling(ctx context.Context, client clientset.Interface,
 			"main.py": fmt.Sprintf(`
 from http.server import BaseHTTPRequestHandler, HTTPServer
 from urllib.request import urlopen
-import sys, os, time, logging
+import sys, os, time, logging, threading
 
 logging.basicConfig(stream=sys.stdout, level=logging.DEBUG)
 serverPort = 8080
@@ -400,7 +1171,11 @@ def call_until_success(url):
 		time.sleep(1)
 
 if __name__ == "__main__":
+	webServer = HTTPServer(("", serverPort), WorkerServer)
+	logger.info("Server started at port %%s" %% serverPort)
 	if index == 0:
+		# The driver serves the probes as well.
+		threading.Thread(target=webServer.serve_forever, daemon=True).start()
 		for i in range(1, INDEX_COUNT):
 			call_until_success("http://%[1]s-%%d.%[2]s:8080/ping" %% i)
 		logger.info("All workers running")
@@ -411,14 +1186,12 @@ if __name__ == "__main__":
 			call_until_success("http://%[1]s-%%d.%[2]s:8080/exit" %% i)
 		logger.info("All workers stopped")
 	else:
-		webServer = HTTPServer(("", serverPort), WorkerServer)
-		logger.info("Server started at port %%s" %% serverPort)
 		webServer.serve_forever()`, name, name),
 		},
 	}
 	_, err = client.CoreV1().ConfigMaps(ns).Create(ctx, cm, metav1.CreateOptions{})
 	framework.ExpectNoError(err, "error when creating config map")
-	ginkgo.DeferCleanup(client.CoreV1().ConfigMaps(ns).Delete, cm.Name, metav1.DeleteOptions{})
+	frameworkutil.DeferCleanup(client.CoreV1().ConfigMaps(ns).Delete, cm.Name, metav1.DeleteOptions{})
 	job := &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: name,
@@ -449,12 +1222,6 @@ if __name__ == "__main__":
 							},
 						},
 					},
-					Tolerations: []corev1.Toleration{
-						{
-							Effect:   corev1.TaintEffectNoSchedule,
-							Operator: corev1.TolerationOpExists,
-						},
-					},
 					Containers: []corev1.Container{
 						{
 							Name:            "main",
@@ -464,9 +1231,13 @@ if __name__ == "__main__":
 							Args:            []string{"/script-path/main.py", strconv.Itoa(int(jobSize))},
 							Ports:           []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}},
 							VolumeMounts:    []corev1.VolumeMount{{Name: "script-volume", MountPath: "/script-path"}},
+							// A worker whose server crashed is killed instead of leaving the driver calling it
+							// until the job times out, see waitForGangJobComplete.
+							ReadinessProbe: gangWorkerProbe(),
+							LivenessProbe:  gangWorkerProbe(),
 							Resources: corev1.ResourceRequirements{
 								Limits: map[corev1.ResourceName]resource.Quantity{
-									corev1.ResourceName(e2egpu.NVIDIAGPUResourceName): resource.MustParse("1"),
+									resourceName: resource.MustParse("1"),
 								},
 							},
 						},
@@ -475,7 +1246,389 @@ if __name__ == "__main__":
 			},
 		},
 	}
+	requireAcceleratorNode(ctx, client, &job.Spec.Template.Spec, resourceName)
 	_, err = client.BatchV1().Jobs(ns).Create(ctx, job, metav1.CreateOptions{})
 	framework.ExpectNoError(err, "error when creating job")
-	ginkgo.DeferCleanup(client.BatchV1().Jobs(ns).Delete, job.Name, metav1.DeleteOptions{})
+	frameworkutil.DeferCleanup(client.BatchV1().Jobs(ns).Delete, job.Name, metav1.DeleteOptions{})
+}
+
+// gangWorkerProbe returns the probe of the HTTP server of the pods created by createJobForGangScheduling.
+func gangWorkerProbe() *corev1.Probe {
+	return &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			HTTPGet: &corev1.HTTPGetAction{Path: "/healthz", Port: intstr.FromString("http")},
+		},
+		PeriodSeconds:    5,
+		FailureThreshold: 3,
+	}
+}
+
+// waitForGangJobComplete waits for the Job created by createJobForGangScheduling to have jobSize succeeded pods. It
+// fails immediately with the logs of the pods of the Job if the Job fails or any of its containers crashes, e.g. a
+// worker killed after failing its liveness probe, instead of waiting for the driver until the timeout.
+func waitForGangJobComplete(ctx context.Context, client clientset.Interface, ns, name string, jobSize int32) error {
+	return framework.Gomega().Eventually(ctx, func(ctx context.Context) error {
+		job, err := client.BatchV1().Jobs(ns).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if job.Status.Succeeded == jobSize {
+			return nil
+		}
+		for _, cond := range job.Status.Conditions {
+			if cond.Type == batchv1.JobFailed && cond.Status == corev1.ConditionTrue {
+				return gomega.StopTrying(fmt.Sprintf("job %s failed: %s: %s", name, cond.Reason, cond.Message))
+			}
+		}
+		pods, err := client.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{LabelSelector: labels.Set{batchv1.JobNameLabel: name}.String()})
+		if err != nil {
+			return err
+		}
+		if crashed := frameworkutil.CrashedContainers(pods.Items); len(crashed) > 0 {
+			for _, pod := range pods.Items {
+				logs, err := e2epod.GetPodLogs(ctx, client, ns, pod.Name, pod.Spec.Containers[0].Name)
+				if err != nil {
+					framework.Logf("Failed to get the logs of pod %s: %v", pod.Name, err)
+					continue
+				}
+				framework.Logf("Logs of pod %s:\n%s", pod.Name, logs)
+			}
+			return gomega.StopTrying(fmt.Sprintf("containers of job %s crashed: %v", name, crashed))
+		}
+		return fmt.Errorf("job %s has %d/%d succeeded pods", name, job.Status.Succeeded, jobSize)
+	}).WithTimeout(e2ejob.JobTimeout).WithPolling(framework.Poll).Should(gomega.Succeed())
+}
+
+var jobSetGVR = schema.GroupVersionResource{Group: "jobset.x-k8s.io", Version: "v1alpha2", Resource: "jobsets"}
+
+// jobSetNameLabel is the label added by the JobSet controller to the pods of a JobSet.
+const jobSetNameLabel = "jobset.sigs.k8s.io/jobset-name"
+
+// createJobSetForGangScheduling creates a JobSet with a driver and jobSize-1 workers, every pod requests 1
+// accelerator. The driver waits until all workers are reachable, then asks them to exit.
+func createJobSetForGangScheduling(ctx context.Context, client clientset.Interface, dynamicClient dynamic.Interface, ns string, name string, resourceName corev1.ResourceName, jobSize int32, queueName string) {
+	// Create a config map to store the script code
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Data: map[string]string{
+			"main.py": fmt.Sprintf(`
+from http.server import BaseHTTPRequestHandler, HTTPServer
+from urllib.request import urlopen
+import sys, time, logging
+
+logging.basicConfig(stream=sys.stdout, level=logging.DEBUG)
+serverPort = 8080
+ROLE = sys.argv[1]
+logger = logging.getLogger(ROLE)
+
+class WorkerServer(BaseHTTPRequestHandler):
+	def do_GET(self):
+		self.send_response(200)
+		self.end_headers()
+		if "exit" in self.path:
+			self.wfile.write(bytes("Exiting", "utf-8"))
+			self.wfile.close()
+			sys.exit(0)
+		else:
+			self.wfile.write(bytes("Running", "utf-8"))
+
+def call_until_success(url):
+	while True:
+		try:
+			logger.info("Calling URL: " + url)
+			with urlopen(url) as response:
+				response_content = response.read().decode('utf-8')
+				logger.info("Response content from %%s: %%s" %% (url, response_content))
+				return
+		except Exception as e:
+			logger.warning("Got exception when calling %%s: %%s" %% (url, e))
+		time.sleep(1)
+
+if __name__ == "__main__":
+	if ROLE == "driver":
+		WORKER_COUNT = int(sys.argv[2])
+		for i in range(WORKER_COUNT):
+			call_until_success("http://%[1]s-workers-0-%%d.%[1]s:8080/ping" %% i)
+		logger.info("All workers running")
+
+		time.sleep(10) # sleep 10s to simulate doing something
+
+		for i in range(WORKER_COUNT):
+			call_until_success("http://%[1]s-workers-0-%%d.%[1]s:8080/exit" %% i)
+		logger.info("All workers stopped")
+	else:
+		webServer = HTTPServer(("", serverPort), WorkerServer)
+		logger.info("Server started at port %%s" %% serverPort)
+		webServer.serve_forever()`, name),
+		},
+	}
+	_, err := client.CoreV1().ConfigMaps(ns).Create(ctx, cm, metav1.CreateOptions{})
+	framework.ExpectNoError(err, "error when creating config map")
+	frameworkutil.DeferCleanup(client.CoreV1().ConfigMaps(ns).Delete, cm.Name, metav1.DeleteOptions{})
+
+	jobTemplate := func(parallelism int32, args ...string) batchv1.JobTemplateSpec {
+		return batchv1.JobTemplateSpec{
+			Spec: batchv1.JobSpec{
+				Parallelism:    &parallelism,
+				Completions:    &parallelism,
+				CompletionMode: ptr.To(batchv1.IndexedCompletion),
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						RestartPolicy: corev1.RestartPolicyNever,
+						Volumes: []corev1.Volume{
+							{
+								Name: "script-volume",
+								VolumeSource: corev1.VolumeSource{
+									ConfigMap: &corev1.ConfigMapVolumeSource{
+										LocalObjectReference: corev1.LocalObjectReference{
+											Name: name,
+										},
+									},
+								},
+							},
+						},
+						Containers: []corev1.Container{
+							{
+								Name:            "main",
+								Image:           "docker.io/library/python:bullseye",
+								ImagePullPolicy: corev1.PullIfNotPresent,
+								Command:         []string{"python"},
+								Args:            append([]string{"/script-path/main.py"}, args...),
+								Ports:           []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}},
+								VolumeMounts:    []corev1.VolumeMount{{Name: "script-volume", MountPath: "/script-path"}},
+								Resources: corev1.ResourceRequirements{
+									Limits: map[corev1.ResourceName]resource.Quantity{
+										resourceName: resource.MustParse("1"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	replicatedJobs := []interface{}{}
+	for _, replicatedJob := range []struct {
+		name     string
+		template batchv1.JobTemplateSpec
+	}{
+		{name: "driver", template: jobTemplate(1, "driver", strconv.Itoa(int(jobSize-1)))},
+		{name: "workers", template: jobTemplate(jobSize-1, "worker")},
+	} {
+		requireAcceleratorNode(ctx, client, &replicatedJob.template.Spec.Template.Spec, resourceName)
+		template, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&replicatedJob.template)
+		framework.ExpectNoError(err, "error when converting job template to unstructured")
+		replicatedJobs = append(replicatedJobs, map[string]interface{}{
+			"name":     replicatedJob.name,
+			"replicas": int64(1),
+			"template": template,
+		})
+	}
+	jobSet := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": jobSetGVR.GroupVersion().String(),
+			"kind":       "JobSet",
+			"metadata": map[string]interface{}{
+				"name": name,
+				"labels": map[string]interface{}{
+					"kueue.x-k8s.io/queue-name": queueName,
+				},
+			},
+			"spec": map[string]interface{}{
+				// Pods are reachable via <jobset>-<replicatedJob>-<jobIndex>-<podIndex>.<jobset>
+				"network": map[string]interface{}{
+					"enableDNSHostnames": true,
+				},
+				"replicatedJobs": replicatedJobs,
+			},
+		},
+	}
+	_, err = dynamicClient.Resource(jobSetGVR).Namespace(ns).Create(ctx, jobSet, metav1.CreateOptions{})
+	framework.ExpectNoError(err, "error when creating jobset")
+	frameworkutil.DeferCleanup(dynamicClient.Resource(jobSetGVR).Namespace(ns).Delete, name, metav1.DeleteOptions{PropagationPolicy: ptr.To(metav1.DeletePropagationBackground)})
+}
+
+// waitForJobSetCompleted waits for the JobSet to have the Completed condition with True status. It returns
+// an error immediately if the JobSet has the Failed condition with True status.
+func waitForJobSetCompleted(ctx context.Context, dynamicClient dynamic.Interface, ns, name string, timeout time.Duration) error {
+	return framework.Gomega().Eventually(ctx, func(ctx context.Context) error {
+		jobSet, err := dynamicClient.Resource(jobSetGVR).Namespace(ns).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		conditions, _, _ := unstructured.NestedSlice(jobSet.Object, "status", "conditions")
+		for _, item := range conditions {
+			cond, ok := item.(map[string]interface{})
+			if !ok || cond["status"] != string(metav1.ConditionTrue) {
+				continue
+			}
+			switch cond["type"] {
+			case "Completed":
+				return nil
+			case "Failed":
+				return gomega.StopTrying(fmt.Sprintf("jobset %s failed: %v", name, cond["message"]))
+			}
+		}
+		return fmt.Errorf("jobset %s is not completed yet", name)
+	}).WithTimeout(timeout).WithPolling(framework.Poll).Should(gomega.Succeed())
+}
+
+// isOwnedByJobSet returns true if the owner references contain the JobSet with the given name.
+func isOwnedByJobSet(ownerReferences []metav1.OwnerReference, name string) bool {
+	for _, ref := range ownerReferences {
+		if ref.Kind == "JobSet" && ref.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// startPartialSchedulingMonitor periodically counts the scheduled pods of each gang, which is identified by the
+// given label key and one of the names, until all pods of the gang succeed. It returns a function which stops the
+// monitor and returns an error if the pods of any gang were partially scheduled, i.e. between 1 and size-1 pods,
+// for longer than the configured tolerance. This is what distinguishes gang scheduling from plain scheduling,
+// because both of them complete all gangs eventually. A gang is re-checked after it's fully scheduled, so a later
+// eviction followed by a partial re-admission is detected as well.
+func startPartialSchedulingMonitor(ctx context.Context, client clientset.Interface, ns, labelKey string, names []string, size int32) func() error {
+	tolerance := gangScheduling.PartialSchedulingTolerance
+	ctx, cancel := context.WithCancel(ctx)
+	// Make sure the monitor doesn't outlive a failed spec.
+	ginkgo.DeferCleanup(cancel)
+	done := make(chan struct{})
+	var violation error
+	go func() {
+		defer ginkgo.GinkgoRecover()
+		defer close(done)
+		partialSince := map[string]time.Time{}
+		fullyScheduled := sets.New[string]()
+		completed := sets.New[string]()
+		wait.UntilWithContext(ctx, func(ctx context.Context) {
+			for _, name := range names {
+				if completed.Has(name) || violation != nil {
+					continue
+				}
+				pods, err := client.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{LabelSelector: labels.Set{labelKey: name}.String()})
+				if err != nil {
+					if ctx.Err() == nil {
+						framework.Logf("Failed to list pods of %s: %v", name, err)
+					}
+					continue
+				}
+				var scheduled, succeeded int32
+				for _, pod := range pods.Items {
+					// Pods being deleted, e.g. by an eviction of the gang, don't count for the gang any more.
+					if pod.DeletionTimestamp != nil {
+						continue
+					}
+					if pod.Status.Phase == corev1.PodSucceeded {
+						succeeded++
+					}
+					if pod.Spec.NodeName != "" && pod.Status.Phase != corev1.PodFailed {
+						scheduled++
+					}
+				}
+				switch {
+				case succeeded >= size:
+					framework.Logf("All %d pods of %s have succeeded", size, name)
+					completed.Insert(name)
+					delete(partialSince, name)
+				case scheduled >= size:
+					if !fullyScheduled.Has(name) {
+						framework.Logf("All %d pods of %s are scheduled", size, name)
+						fullyScheduled.Insert(name)
+					}
+					delete(partialSince, name)
+				case scheduled == 0:
+					if fullyScheduled.Has(name) {
+						framework.Logf("No pod of %s is scheduled any more, it may have been evicted", name)
+						fullyScheduled.Delete(name)
+					}
+					delete(partialSince, name)
+				default:
+					fullyScheduled.Delete(name)
+					since, ok := partialSince[name]
+					if !ok {
+						partialSince[name] = time.Now()
+						continue
+					}
+					if time.Since(since) > tolerance {
+						violation = fmt.Errorf("only %d/%d pods of %s have been scheduled for more than %v", scheduled, size, name, tolerance)
+					}
+				}
+			}
+		}, framework.Poll)
+	}()
+	return func() error {
+		cancel()
+		<-done
+		return violation
+	}
+}
+
+// newAcceleratorUtilizationDeployment returns the Deployment of the accelerator utilization spec, whose replicas
+// request 1 accelerator each and keep it idle until /tmp/load is created in them, then run
+// -ai.podAutoscaling.utilizationLoadCommand to keep it busy. The replicas created by the scale up stay idle.
+func newAcceleratorUtilizationDeployment(ns, name string, resourceName corev1.ResourceName) *appsv1.Deployment {
+	podLabels := map[string]string{"name": name}
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name, Labels: podLabels},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To[int32](1),
+			Selector: &metav1.LabelSelector{MatchLabels: podLabels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: podLabels},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:    "load",
+							Image:   podAutoscaling.UtilizationLoadImage,
+							Command: []string{"/bin/sh", "-c", "until [ -e /tmp/load ]; do sleep 1; done; " + podAutoscaling.UtilizationLoadCommand},
+							Resources: corev1.ResourceRequirements{
+								Limits: corev1.ResourceList{resourceName: resource.MustParse("1")},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// verifyHPAUtilization verifies the current average value of the custom metric which the HorizontalPodAutoscaler
+// last observed with the given function, and returns an error if it hasn't observed one yet.
+func verifyHPAUtilization(ctx context.Context, client clientset.Interface, ns, name, metricName string, verify func(current resource.Quantity) error) error {
+	hpa, err := client.AutoscalingV2().HorizontalPodAutoscalers(ns).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	current, ok := frameworkutil.HPACurrentPodsMetric(hpa, metricName)
+	if !ok {
+		return fmt.Errorf("HorizontalPodAutoscaler %s/%s has not observed %s yet", ns, name, metricName)
+	}
+	framework.Logf("HorizontalPodAutoscaler %s/%s observes the average %s of %s with %d desired replicas", ns, name, metricName, current.String(), hpa.Status.DesiredReplicas)
+	return verify(current)
+}
+
+// requestAcceleratorForDeployment mutates the given container of the Deployment to request 1 accelerator of the
+// given resource and pins the replicas to the accelerator nodes. Other containers, e.g. sidecars, are left
+// untouched so that each replica requests exactly 1 accelerator. It waits for the rollout to complete.
+func requestAcceleratorForDeployment(ctx context.Context, client clientset.Interface, ns, name, containerName string, resourceName corev1.ResourceName) {
+	deployment, err := e2edeployment.UpdateDeploymentWithRetries(client, ns, name, func(d *appsv1.Deployment) {
+		for i := range d.Spec.Template.Spec.Containers {
+			container := &d.Spec.Template.Spec.Containers[i]
+			if container.Name != containerName {
+				continue
+			}
+			if container.Resources.Limits == nil {
+				container.Resources.Limits = corev1.ResourceList{}
+			}
+			container.Resources.Limits[resourceName] = resource.MustParse("1")
+		}
+		requireAcceleratorNode(ctx, client, &d.Spec.Template.Spec, resourceName)
+	})
+	framework.ExpectNoError(err, "error when updating deployment %s", name)
+	err = frameworkutil.WaitForDeploymentComplete(ctx, client, deployment, framework.PodStartTimeout)
+	framework.ExpectNoError(err, "error when waiting for deployment %s to complete", name)
 }
@@ -2,16 +2,22 @@ package ai
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/onsi/ginkgo/v2"
 	"github.com/onsi/gomega"
 	v1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	admissionapi "k8s.io/pod-security-admission/api"
+	"k8s.io/utils/ptr"
 
 	drautils "k8s.io/kubernetes/test/e2e/dra/utils"
 	"k8s.io/kubernetes/test/e2e/framework"
+	e2econfig "k8s.io/kubernetes/test/e2e/framework/config"
 	e2egpu "k8s.io/kubernetes/test/e2e/framework/gpu"
 	e2enode "k8s.io/kubernetes/test/e2e/framework/node"
 	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
@@ -83,7 +89,7 @@ var _ = WGDescribe("Secure Accelerator Access", func() {
 			}
 			pod, err := f.ClientSet.CoreV1().Pods(ns).Create(ctx, pod, metav1.CreateOptions{})
 			framework.ExpectNoError(err, "error when creating pod")
-			ginkgo.DeferCleanup(f.ClientSet.CoreV1().Pods(ns).Delete, pod.Name, metav1.DeleteOptions{})
+			frameworkutil.DeferCleanup(f.ClientSet.CoreV1().Pods(ns).Delete, pod.Name, metav1.DeleteOptions{})
 			err = e2epod.WaitForPodRunningInNamespace(ctx, f.ClientSet, pod)
 			framework.ExpectNoError(err, "error when waiting for pod to be running")
 			err = e2epod.VerifyExecInPodFail(ctx, f, pod, "nvidia-smi", 127)
@@ -94,9 +100,13 @@ var _ = WGDescribe("Secure Accelerator Access", func() {
 			Release: v1.33
 			Testname: Secure Accelerator Access, device plugin
 			Description: Create two pods with 1 Nvidia GPU request per each pod and verify that the devices MUST be mapped to the right pods.
-			And the devices MUST be different.
+			And the devices MUST be different. If nvidia-smi is unavailable in the pods, the device files of the GPUs under /dev
+			of the pods MUST be different instead. If the kubelet serves the pod-resources API, the device assigned to each pod
+			by the kubelet MUST be the one seen by the pod.
 		*/
 		frameworkutil.AIConformanceIt("must map devices to the right pods", func(ctx context.Context) {
+			lockAccelerators(ctx, f, e2egpu.NVIDIAGPUResourceName)
+			verifyAcceleratorsReleased(ctx, f, e2egpu.NVIDIAGPUResourceName)
 			pod := e2epod.MakePod(ns, nil, nil, f.NamespacePodSecurityLevel, "")
 			pod.Spec.NodeName = selectedNode.Name
 			pod.Spec.Tolerations = []v1.Toleration{
@@ -122,32 +132,268 @@ var _ = WGDescribe("Secure Accelerator Access", func() {
 			pod2 := pod.DeepCopy()
 			pod, err := f.ClientSet.CoreV1().Pods(ns).Create(ctx, pod, metav1.CreateOptions{})
 			framework.ExpectNoError(err, "error when creating pod")
-			ginkgo.DeferCleanup(f.ClientSet.CoreV1().Pods(ns).Delete, pod.Name, metav1.DeleteOptions{})
+			frameworkutil.DeferCleanup(f.ClientSet.CoreV1().Pods(ns).Delete, pod.Name, metav1.DeleteOptions{})
 			err = e2epod.WaitForPodRunningInNamespace(ctx, f.ClientSet, pod)
 			framework.ExpectNoError(err, "error when waiting for pod to be running")
 			pod2, err = f.ClientSet.CoreV1().Pods(ns).Create(ctx, pod2, metav1.CreateOptions{})
 			framework.ExpectNoError(err, "error when creating pod")
-			ginkgo.DeferCleanup(f.ClientSet.CoreV1().Pods(ns).Delete, pod2.Name, metav1.DeleteOptions{})
+			frameworkutil.DeferCleanup(f.ClientSet.CoreV1().Pods(ns).Delete, pod2.Name, metav1.DeleteOptions{})
 			err = e2epod.WaitForPodRunningInNamespace(ctx, f.ClientSet, pod2)
 			framework.ExpectNoError(err, "error when waiting for pod to be running")
 
-			pod0out := e2epod.ExecShellInPod(ctx, f, pod.Name, "nvidia-smi -L")
-			pod1out := e2epod.ExecShellInPod(ctx, f, pod2.Name, "nvidia-smi -L")
-			framework.Logf("pod %s output:\n %s", pod.Name, pod0out)
-			framework.Logf("pod %s output:\n %s", pod2.Name, pod1out)
-			gomega.Expect(pod0out).NotTo(gomega.Equal(pod1out), "should have different devices assigned")
+			// nvidia-smi is injected by the NVIDIA container toolkit, the device files are checked if it's absent.
+			var pod0out, pod1out string
+			if _, _, err := e2epod.ExecShellInPodWithFullOutput(ctx, f, pod.Name, "command -v nvidia-smi"); err == nil {
+				pod0out = e2epod.ExecShellInPod(ctx, f, pod.Name, "nvidia-smi -L")
+				pod1out = e2epod.ExecShellInPod(ctx, f, pod2.Name, "nvidia-smi -L")
+				framework.Logf("pod %s output:\n %s", pod.Name, pod0out)
+				framework.Logf("pod %s output:\n %s", pod2.Name, pod1out)
+				gomega.Expect(pod0out).NotTo(gomega.Equal(pod1out), "should have different devices assigned")
+			} else {
+				ginkgo.By("Verifying the pods see different device files as nvidia-smi is unavailable")
+				pod0files, err := frameworkutil.ListAcceleratorDeviceFiles(ctx, f, pod.Name)
+				framework.ExpectNoError(err)
+				pod1files, err := frameworkutil.ListAcceleratorDeviceFiles(ctx, f, pod2.Name)
+				framework.ExpectNoError(err)
+				gomega.Expect(pod0files).NotTo(gomega.BeEmpty(), "pod %s should see the device file of the allocated GPU", pod.Name)
+				gomega.Expect(pod1files).NotTo(gomega.BeEmpty(), "pod %s should see the device file of the allocated GPU", pod2.Name)
+				gomega.Expect(frameworkutil.SharedDeviceFiles(pod0files, pod1files)).To(gomega.BeEmpty(), "should have different devices assigned")
+			}
+
+			ginkgo.By("Verifying the devices assigned by the kubelet match the devices seen by the pods")
+			podResources, err := frameworkutil.ListPodResources(ctx, f.ClientSet, ns, selectedNode.Name)
+			framework.ExpectNoError(err, "error when listing the pod resources on node %s", selectedNode.Name)
+			if podResources == nil {
+				framework.Logf("The kubelet pod-resources API is not served on node %s, skipping the verification", selectedNode.Name)
+				return
+			}
+			var assigned []string
+			for name, out := range map[string]string{pod.Name: pod0out, pod2.Name: pod1out} {
+				ids := podResources.DeviceIDs(ns, name, e2egpu.NVIDIAGPUResourceName)
+				gomega.Expect(ids).To(gomega.HaveLen(1), "pod %s should be assigned 1 device by the kubelet", name)
+				if out != "" {
+					gomega.Expect(out).To(gomega.ContainSubstring(ids[0]), "pod %s should see the device %s assigned by the kubelet", name, ids[0])
+				}
+				assigned = append(assigned, ids[0])
+			}
+			gomega.Expect(assigned[0]).NotTo(gomega.Equal(assigned[1]), "the kubelet should assign different devices to the pods")
 
 		})
 	})
 })
 
+var _ = WGDescribe("Secure Accelerator Access", func() {
+	f := framework.NewDefaultFramework("visible-devices")
+	f.NamespacePodSecurityLevel = admissionapi.LevelPrivileged
+
+	// The environment variable injected by the device plugin, e.g. NVIDIA_VISIBLE_DEVICES, scopes the devices seen
+	// by the container runtime hooks of some vendors, so it's recommended to list only the allocated accelerators.
+	// It's not required by the conformance, as the device plugins may mount the devices or use CDI instead.
+	frameworkutil.AIConformanceShouldIt("the environment of a pod should list only the allocated accelerators", func(ctx context.Context) {
+		ns := f.Namespace.Name
+		vendor := skipUnlessAcceleratorAllocatable(ctx, f.ClientSet)
+		envName := vendor.VisibleDevicesEnvName()
+		if envName == "" {
+			e2eskipper.Skipf("The device plugin of %s doesn't inject an environment variable of the allocated accelerators, specify one via -ai.accelerator.visibleDevicesEnv", vendor.Name)
+		}
+		lockAccelerators(ctx, f, vendor.ResourceName)
+		verifyAcceleratorsReleased(ctx, f, vendor.ResourceName)
+
+		ginkgo.By(fmt.Sprintf("Creating a pod requesting 1 %s", vendor.ResourceName))
+		pod := e2epod.MakePod(ns, nil, nil, f.NamespacePodSecurityLevel, "")
+		pod.Spec.Containers[0].Resources.Limits = v1.ResourceList{
+			vendor.ResourceName: resource.MustParse("1"),
+		}
+		requireAcceleratorNode(ctx, f.ClientSet, &pod.Spec, vendor.ResourceName)
+		pod, err := f.ClientSet.CoreV1().Pods(ns).Create(ctx, pod, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "error when creating pod")
+		frameworkutil.DeferCleanup(f.ClientSet.CoreV1().Pods(ns).Delete, pod.Name, metav1.DeleteOptions{})
+		err = e2epod.WaitForPodRunningInNamespace(ctx, f.ClientSet, pod)
+		framework.ExpectNoError(err, "error when waiting for pod to be running")
+
+		ginkgo.By(fmt.Sprintf("Verifying %s of the pod lists only the allocated accelerator", envName))
+		out := e2epod.ExecShellInPod(ctx, f, pod.Name, "env")
+		err = frameworkutil.VerifyVisibleDevices(out, envName, 1)
+		framework.ExpectNoError(err, "pod %s should see only the allocated accelerator", pod.Name)
+	})
+})
+
+var _ = WGDescribe("Secure Accelerator Access", func() {
+	f := framework.NewDefaultFramework("dra-admin-access")
+	f.NamespacePodSecurityLevel = admissionapi.LevelBaseline
+
+	ginkgo.BeforeEach(func(ctx context.Context) {
+		frameworkutil.SkipIfGroupVersionUnavaliable(ctx, frameworkutil.CachedDiscovery(f.ClientSet), "resource.k8s.io/v1")
+	})
+
+	// A ResourceClaim with admin access can access the devices in use by other claims, e.g. for monitoring, so it
+	// should only be allowed in the namespaces labeled resource.kubernetes.io/admin-access=true by the cluster
+	// administrators. The claim in an unlabeled namespace MUST be rejected. The spec is skipped if the
+	// DRAAdminAccess feature gate is disabled, i.e. the API server drops the adminAccess field.
+	frameworkutil.AIConformanceShouldIt("a ResourceClaim with admin access should be rejected in an unprivileged namespace", func(ctx context.Context) {
+		ns := f.Namespace.Name
+		gomega.Expect(f.Namespace.Labels).NotTo(gomega.HaveKey(resourceapi.DRAAdminNamespaceLabelKey), "the test namespace should not grant admin access")
+
+		ginkgo.By("Creating a ResourceClaim with admin access")
+		claim := &resourceapi.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "admin-access"},
+			Spec: resourceapi.ResourceClaimSpec{
+				Devices: resourceapi.DeviceClaim{
+					Requests: []resourceapi.DeviceRequest{
+						{
+							Name: "admin",
+							Exactly: &resourceapi.ExactDeviceRequest{
+								// The DeviceClass is not resolved until the claim is allocated.
+								DeviceClassName: "admin-access.example.com",
+								AllocationMode:  resourceapi.DeviceAllocationModeAll,
+								AdminAccess:     ptr.To(true),
+							},
+						},
+					},
+				},
+			},
+		}
+		created, err := f.ClientSet.ResourceV1().ResourceClaims(ns).Create(ctx, claim, metav1.CreateOptions{})
+		if err == nil {
+			frameworkutil.DeferCleanup(f.ClientSet.ResourceV1().ResourceClaims(ns).Delete, created.Name, metav1.DeleteOptions{})
+			if created.Spec.Devices.Requests[0].Exactly.AdminAccess == nil {
+				e2eskipper.Skipf("The adminAccess field of ResourceClaim %s is dropped, the DRAAdminAccess feature gate is disabled", created.Name)
+			}
+			framework.Failf("ResourceClaim %s with admin access was accepted in namespace %s without the %s=true label", created.Name, ns, resourceapi.DRAAdminNamespaceLabelKey)
+		}
+		if !apierrors.IsInvalid(err) && !apierrors.IsForbidden(err) {
+			framework.ExpectNoError(err, "error when creating ResourceClaim")
+		}
+		framework.Logf("ResourceClaim with admin access is rejected as expected: %v", err)
+	})
+})
+
+var _ = WGDescribe("Secure Accelerator Access", func() {
+	f := framework.NewDefaultFramework("host-device")
+	f.NamespacePodSecurityLevel = admissionapi.LevelRestricted
+
+	// The allocation-based isolation is bypassed if a pod can mount the device files of the host, so the Pod Security
+	// admission of a restricted namespace should reject the hostPath volume. If the pod is admitted anyway, e.g. the
+	// Pod Security admission is disabled, it still must not be able to access the device, as the container runtime
+	// only allows the allocated devices.
+	frameworkutil.AIConformanceShouldIt("a pod mounting an accelerator device via hostPath should be rejected in a restricted namespace", func(ctx context.Context) {
+		ns := f.Namespace.Name
+		const devicePath = "/dev/nvidia0"
+
+		ginkgo.By(fmt.Sprintf("Creating a pod mounting %s via hostPath", devicePath))
+		pod := e2epod.MakePod(ns, nil, nil, f.NamespacePodSecurityLevel, "")
+		pod.Spec.Volumes = append(pod.Spec.Volumes, v1.Volume{
+			Name: "device",
+			VolumeSource: v1.VolumeSource{
+				HostPath: &v1.HostPathVolumeSource{Path: devicePath, Type: ptr.To(v1.HostPathCharDev)},
+			},
+		})
+		pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, v1.VolumeMount{Name: "device", MountPath: devicePath})
+		created, err := f.ClientSet.CoreV1().Pods(ns).Create(ctx, pod, metav1.CreateOptions{})
+		if err != nil {
+			gomega.Expect(apierrors.IsForbidden(err)).To(gomega.BeTrue(), "pod should be rejected by the Pod Security admission: %v", err)
+			gomega.Expect(err.Error()).To(gomega.ContainSubstring("hostPath"), "pod should be rejected for its hostPath volume")
+			framework.Logf("Pod mounting %s via hostPath is rejected as expected: %v", devicePath, err)
+			return
+		}
+		frameworkutil.DeferCleanup(f.ClientSet.CoreV1().Pods(ns).Delete, created.Name, metav1.DeleteOptions{})
+		framework.Logf("Pod %s mounting %s via hostPath is admitted in namespace %s enforcing the %s Pod Security level", created.Name, devicePath, ns, f.NamespacePodSecurityLevel)
+
+		ginkgo.By(fmt.Sprintf("Verifying pod %s can't access %s", created.Name, devicePath))
+		err = e2epod.WaitTimeoutForPodRunningInNamespace(ctx, f.ClientSet, created.Name, ns, framework.PodStartShortTimeout)
+		if err != nil {
+			// The kubelet refuses to mount the path if it's not a character device on the node.
+			framework.Logf("Pod %s is not running, so it can't access %s: %v", created.Name, devicePath, err)
+			return
+		}
+		stdout, stderr, err := e2epod.ExecShellInPodWithFullOutput(ctx, f, created.Name, ": < "+devicePath)
+		gomega.Expect(err).To(gomega.HaveOccurred(), "pod %s should not be able to open %s, stdout: %s, stderr: %s", created.Name, devicePath, stdout, stderr)
+		framework.Logf("Pod %s can't open %s as expected: %s", created.Name, devicePath, stderr)
+	})
+})
+
+var acceleratorQuota struct {
+	ResourceName string `default:"" usage:"accelerator resource limited by the ResourceQuota of the Accelerator Quota spec, e.g. nvidia.com/gpu. If unspecified, the resource of the vendor detected from the ready nodes is used, or the first of -ai.accelerator.resourceNames, nvidia.com/gpu by default, if none is detected"`
+}
+
+var _ = e2econfig.AddOptions(&acceleratorQuota, "ai.acceleratorQuota")
+
+var _ = WGDescribe("Accelerator Quota", func() {
+	f := framework.NewDefaultFramework("accelerator-quota")
+	f.NamespacePodSecurityLevel = admissionapi.LevelBaseline
+
+	/*
+		Release: v1.34
+		Testname: Accelerator Quota, ResourceQuota enforcement
+		Description: Create a ResourceQuota limiting the requests of the accelerator resource in the namespace to 2, and
+		3 pods requesting 1 accelerator each. The first 2 pods MUST be admitted and the third one MUST be rejected by the
+		ResourceQuota admission. The pods are scheduling gated, so no accelerator is needed.
+	*/
+	frameworkutil.AIConformanceIt("should reject the pods exceeding the accelerator quota", func(ctx context.Context) {
+		ns := f.Namespace.Name
+		quotaLimit := 2
+
+		resourceName := v1.ResourceName(acceleratorQuota.ResourceName)
+		if resourceName == "" {
+			nodes, err := e2enode.GetReadyNodesIncludingTainted(ctx, f.ClientSet)
+			framework.ExpectNoError(err)
+			resourceName = frameworkutil.PreferredAcceleratorResource(nodes.Items)
+		}
+
+		ginkgo.By(fmt.Sprintf("Creating a ResourceQuota limiting the requests of %s to %d", resourceName, quotaLimit))
+		quotaResourceName := v1.ResourceName(v1.DefaultResourceRequestsPrefix + string(resourceName))
+		quota := &v1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "accelerator-quota"},
+			Spec: v1.ResourceQuotaSpec{
+				Hard: v1.ResourceList{quotaResourceName: *resource.NewQuantity(int64(quotaLimit), resource.DecimalSI)},
+			},
+		}
+		quota, err := f.ClientSet.CoreV1().ResourceQuotas(ns).Create(ctx, quota, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "error when creating ResourceQuota")
+		frameworkutil.DeferCleanup(f.ClientSet.CoreV1().ResourceQuotas(ns).Delete, quota.Name, metav1.DeleteOptions{})
+
+		// The ResourceQuota admission rejects the pods until the quota controller calculates the status.
+		ginkgo.By("Waiting for the status of the ResourceQuota to be calculated")
+		gomega.Eventually(ctx, framework.GetObject(f.ClientSet.CoreV1().ResourceQuotas(ns).Get, quota.Name, metav1.GetOptions{})).
+			WithTimeout(framework.PodStartShortTimeout).
+			Should(gomega.HaveField("Status.Hard", gomega.HaveKey(quotaResourceName)))
+
+		newPod := func(name string) *v1.Pod {
+			pod := e2epod.MakePod(ns, nil, nil, f.NamespacePodSecurityLevel, "")
+			pod.Name = name
+			// The pod is admitted, and counted by the quota, but never scheduled, so no accelerator is consumed.
+			pod.Spec.SchedulingGates = []v1.PodSchedulingGate{{Name: "ai-conformance.carlory.github.io/quota"}}
+			pod.Spec.Containers[0].Resources.Limits = v1.ResourceList{resourceName: resource.MustParse("1")}
+			return pod
+		}
+
+		ginkgo.By(fmt.Sprintf("Creating %d pods requesting 1 %s each within the quota", quotaLimit, resourceName))
+		for i := range quotaLimit {
+			pod, err := f.ClientSet.CoreV1().Pods(ns).Create(ctx, newPod(fmt.Sprintf("within-quota-%d", i)), metav1.CreateOptions{})
+			framework.ExpectNoError(err, "error when creating pod within the quota")
+			frameworkutil.DeferCleanup(f.ClientSet.CoreV1().Pods(ns).Delete, pod.Name, metav1.DeleteOptions{})
+		}
+
+		ginkgo.By(fmt.Sprintf("Creating a pod requesting 1 %s exceeding the quota", resourceName))
+		pod, err := f.ClientSet.CoreV1().Pods(ns).Create(ctx, newPod("exceeding-quota"), metav1.CreateOptions{})
+		if err == nil {
+			frameworkutil.DeferCleanup(f.ClientSet.CoreV1().Pods(ns).Delete, pod.Name, metav1.DeleteOptions{})
+			framework.Failf("pod %s exceeding the quota of %d %s was admitted", pod.Name, quotaLimit, resourceName)
+		}
+		if !apierrors.IsForbidden(err) || !strings.Contains(err.Error(), "exceeded quota") {
+			framework.Failf("pod exceeding the quota should be rejected by the ResourceQuota admission, got: %v", err)
+		}
+		framework.Logf("Pod exceeding the quota is rejected: %v", err)
+	})
+})
+
 // https://github.com/kubernetes-sigs/wg-ai-conformance/issues/27#issuecomment-3356364245
 // Remove it once the test is included in k/k conformance tests.
 var _ = WGDescribe("Secure Accelerator Access", func() {
 	f := framework.NewDefaultFramework("dra")
 
 	ginkgo.BeforeEach(func(ctx context.Context) {
-		frameworkutil.SkipIfGroupVersionUnavaliable(ctx, f.ClientSet.Discovery(), "resource.k8s.io/v1")
+		frameworkutil.SkipIfGroupVersionUnavaliable(ctx, frameworkutil.CachedDiscovery(f.ClientSet), "resource.k8s.io/v1")
 	})
 
 	// The driver containers have to run with sufficient privileges to
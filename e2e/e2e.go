@@ -108,6 +108,18 @@ func RunE2ETests(t *testing.T) {
 
 	// Run tests through the Ginkgo runner with output to console + JUnit for Jenkins
 	suiteConfig, reporterConfig := framework.CreateGinkgoConfig()
+	labelFilter, err := frameworkutil.LevelLabelFilter(suiteConfig.LabelFilter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	suiteConfig.LabelFilter = labelFilter
+	if err := frameworkutil.ValidateVendorFilter(); err != nil {
+		t.Fatal(err)
+	}
+	if frameworkutil.ListingSpecs() {
+		// The specs filtered out are reported as skipped, so they are listed as well.
+		suiteConfig.DryRun = true
+	}
 	klog.Infof("Starting e2e run %q on Ginkgo node %d", framework.RunID, suiteConfig.ParallelProcess)
 	ginkgo.RunSpecs(t, "Extended Kubernetes e2e suite with AI Conformance", suiteConfig, reporterConfig)
 }
@@ -201,8 +213,8 @@ func setupSuite(ctx context.Context) {
 		if err != nil {
 			framework.Failf("Error deleting orphaned namespaces: %v", err)
 		}
-		if err := framework.WaitForNamespacesDeleted(ctx, c, deleted, namespaceCleanupTimeout); err != nil {
-			framework.Failf("Failed to delete orphaned namespaces %v: %v", deleted, err)
+		if stuck, err := frameworkutil.WaitForNamespacesDeleted(ctx, c, deleted, namespaceCleanupTimeout); err != nil {
+			framework.Failf("Failed to delete orphaned namespaces %v: %v", stuck, err)
 		}
 	}
 
@@ -384,6 +396,9 @@ func setupSuitePerGinkgoNode(ctx context.Context) {
 	framework.ExpectNoError(err, "Error loading client")
 	framework.TestContext.IPFamily = getDefaultClusterIPFamily(ctx, c)
 	framework.Logf("Cluster IP family: %s", framework.TestContext.IPFamily)
+
+	// Discover the APIs installed before the suite once on each Ginkgo node.
+	frameworkutil.InitDiscoveryCache(c)
 }
 
 func prepullImages(ctx context.Context, c clientset.Interface) {
@@ -68,6 +68,7 @@ import (
 
 	// ai test sources
 	_ "github.com/carlory/ai-conformance/e2e/ai"
+	frameworkutil "github.com/carlory/ai-conformance/e2e/util/framework"
 
 	// reconfigure framework
 	_ "k8s.io/kubernetes/test/e2e/framework/debug/init"
@@ -160,6 +161,12 @@ var _ = ginkgo.ReportBeforeSuite(func(report ginkgo.Report) {
 	progressReporter.SetTestsTotal(report.PreRunStats.SpecsThatWillRun)
 })
 
+var _ = ginkgo.ReportAfterSuite("AI conformance area summary", frameworkutil.ReportAreas)
+
+var _ = ginkgo.ReportAfterSuite("AI conformance timings", frameworkutil.ReportTimings)
+
+var _ = ginkgo.ReportAfterSuite("AI conformance spec list", frameworkutil.ReportSpecList)
+
 var _ = ginkgo.ReportAfterSuite("Kubernetes e2e suite report", func(report ginkgo.Report) {
 	var err error
 	// The DetailsRepoerter will output details about every test (name, files, lines, etc) which helps
@@ -0,0 +1,784 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
+	resourcehelper "k8s.io/component-helpers/resource"
+
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2econfig "k8s.io/kubernetes/test/e2e/framework/config"
+	e2egpu "k8s.io/kubernetes/test/e2e/framework/gpu"
+	e2enode "k8s.io/kubernetes/test/e2e/framework/node"
+)
+
+var accelerator struct {
+	CapacityTimeout      time.Duration `default:"5m" usage:"how long an accelerator node is allowed to be Ready before its device plugin advertises the accelerators in the node status. Pods requesting accelerators can't be scheduled to the node in the meantime. It's only verified on the nodes selected by -ai.accelerator.nodeSelector"`
+	NodeSelector         string        `default:"" usage:"label selector of the accelerator nodes, e.g. cloud.google.com/gke-accelerator=nvidia-tesla-t4, which the workloads requesting accelerators are pinned to and whose accelerators are counted. If unspecified, all ready nodes are considered"`
+	ExclusiveThreshold   int           `default:"4" usage:"if the ready accelerator nodes have fewer allocatable accelerators than it, the specs consuming the accelerators run one at a time, coordinated by a Lease in the default namespace, so that the specs running in parallel don't oversubscribe them. 0 disables the coordination"`
+	VerifyRelease        bool          `default:"false" usage:"if true, the specs consuming the accelerators verify that the available accelerators return to the count before the spec once its workloads are deleted, which catches the accelerators leaked by the device plugin. It's meant for serial runs, the accelerators consumed by the specs running in parallel are not released in time"`
+	ReleaseTimeout       time.Duration `default:"2m" usage:"how long the available accelerators are allowed to take to return to the count before the spec if -ai.accelerator.verifyRelease is set"`
+	DevicePluginSelector string        `default:"" usage:"label selector of the DaemonSet of the accelerator device plugin, matched against its labels or the labels of its pods, e.g. app=nvidia-device-plugin-daemonset. If unspecified, the well-known labels of the device plugins of the detected vendor are tried"`
+	ResourceNames        string        `default:"" usage:"comma-separated accelerator resources in the order of preference, e.g. nvidia.com/gpu,example.com/gpu. The specs which are not specific to a vendor use the first one allocatable on the ready nodes. If unspecified, the resources of the supported vendors are tried, nvidia.com/gpu first"`
+	VisibleDevicesEnv    string        `default:"" usage:"environment variable which the device plugin injects into the containers with the IDs of the allocated accelerators, e.g. NVIDIA_VISIBLE_DEVICES. If unspecified, the well-known variable of the detected vendor is used"`
+}
+var _ = e2econfig.AddOptions(&accelerator, "ai.accelerator")
+
+var vendorFilter struct {
+	Vendor string `default:"" usage:"comma-separated names of the accelerator vendors to run the tests for, e.g. amd on a cluster with NVIDIA and AMD GPUs. The names are nvidia, amd, or the resources of -ai.accelerator.resourceNames of the other vendors. The accelerators of the other vendors are neither detected nor used, and the tests specific to them are skipped. If unspecified, all the vendors are considered"`
+}
+var _ = e2econfig.AddOptions(&vendorFilter, "ai")
+
+// acceleratorNodeSelector returns the selector of the accelerator nodes configured by -ai.accelerator.nodeSelector.
+func acceleratorNodeSelector() (labels.Selector, error) {
+	selector, err := labels.Parse(accelerator.NodeSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -ai.accelerator.nodeSelector %q: %w", accelerator.NodeSelector, err)
+	}
+	return selector, nil
+}
+
+// selectNodes returns the nodes matching the selector.
+func selectNodes(nodes []v1.Node, selector labels.Selector) []v1.Node {
+	var selected []v1.Node
+	for _, node := range nodes {
+		if selector.Matches(labels.Set(node.Labels)) {
+			selected = append(selected, node)
+		}
+	}
+	return selected
+}
+
+// autoscalerNodeLabels are the labels of the nodes provisioned by the autoscalers, i.e. the NodePool of Karpenter and
+// the Provisioner of its older releases.
+var autoscalerNodeLabels = []string{"karpenter.sh/nodepool", "karpenter.sh/provisioner-name"}
+
+// IsAutoscalerNode returns true if the node is provisioned by an autoscaler which removes it once it's idle or
+// underutilized, e.g. Karpenter.
+func IsAutoscalerNode(node *v1.Node) bool {
+	for _, label := range autoscalerNodeLabels {
+		if _, ok := node.Labels[label]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// AcceleratorVendor describes how the accelerators of a vendor are exposed to Kubernetes.
+type AcceleratorVendor struct {
+	// Name is the lowercase name of the vendor.
+	Name string
+	// ResourceName is the extended resource advertised by the device plugin of the vendor.
+	ResourceName v1.ResourceName
+	// MetricPrefixes are the name prefixes of the metrics emitted by the exporters of the vendor.
+	MetricPrefixes []string
+	// CoreMetrics are the regular expressions of the metric names which MUST be emitted by the exporter.
+	// The first one is the utilization of the accelerator. They are the required metrics of the vendor in the
+	// expected metrics, see ExpectedMetrics.AcceleratorVendor.
+	CoreMetrics []string
+	// RecommendedMetrics are the regular expressions of the metric names which SHOULD be emitted by the exporter.
+	// They are the optional metrics of the vendor in the expected metrics.
+	RecommendedMetrics []string
+	// DevicePluginSelectors are the label selectors of the DaemonSets of the well-known device plugins of the vendor.
+	DevicePluginSelectors []string
+	// DeviceCheckCommand is the shell command which succeeds in a container only if it can access the allocated
+	// accelerators.
+	DeviceCheckCommand string
+	// VisibleDevicesEnv is the environment variable which the device plugin of the vendor injects into the
+	// containers with the IDs of the allocated accelerators, or empty if it injects none.
+	VisibleDevicesEnv string
+	// ProductLabel is the node label with the product name of the accelerators of the vendor, which is added by
+	// the node feature discovery of the vendor, or empty if there is none.
+	ProductLabel string
+	// CountLabel is the node label with the number of the physical accelerators of the vendor, which is added by
+	// the node feature discovery of the vendor, or empty if there is none. Unlike the capacity of the resource, it
+	// doesn't count the replicas of the shared accelerators.
+	CountLabel string
+	// DeviceMetricLabel is the label of the metrics of the exporter which identifies an accelerator in the
+	// cluster, or empty if it's unknown.
+	DeviceMetricLabel string
+	// ProductMetricLabel is the label of the metrics of the exporter with the product name of the accelerator, or
+	// empty if it's unknown.
+	ProductMetricLabel string
+	// MemoryTotalQuery is the PromQL expression of the total memory in MiB of each accelerator reported by the
+	// exporter, or empty if it's unknown.
+	MemoryTotalQuery string
+}
+
+var (
+	// NVIDIA is exposed by the NVIDIA device plugin and the DCGM exporter.
+	NVIDIA = AcceleratorVendor{
+		Name:           "nvidia",
+		ResourceName:   e2egpu.NVIDIAGPUResourceName,
+		MetricPrefixes: []string{"DCGM_FI_DEV"},
+		// The Helm chart of the device plugin, the GPU operator, and the static manifest of the device plugin.
+		DevicePluginSelectors: []string{"app.kubernetes.io/name=nvidia-device-plugin", "app=nvidia-device-plugin-daemonset", "name=nvidia-device-plugin-ds"},
+		DeviceCheckCommand:    "nvidia-smi -L",
+		VisibleDevicesEnv:     "NVIDIA_VISIBLE_DEVICES",
+		// Added by the GPU feature discovery, see https://github.com/NVIDIA/k8s-device-plugin#catalog-of-labels
+		ProductLabel: "nvidia.com/gpu.product",
+		CountLabel:   "nvidia.com/gpu.count",
+		// The index in the gpu label is only unique on a node.
+		DeviceMetricLabel:  "UUID",
+		ProductMetricLabel: "modelName",
+		// The reserved framebuffer, which is a small fraction of the total, is reported by neither of them.
+		MemoryTotalQuery: "DCGM_FI_DEV_FB_USED + DCGM_FI_DEV_FB_FREE",
+	}
+	// AMD is exposed by the AMD GPU device plugin and either the AMD SMI exporter, see
+	// https://github.com/amd/amd_smi_exporter, or the AMD device metrics exporter, see
+	// https://github.com/ROCm/device-metrics-exporter
+	AMD = AcceleratorVendor{
+		Name:           "amd",
+		ResourceName:   "amd.com/gpu",
+		MetricPrefixes: []string{"amd_gpu_", "gpu_"},
+		// The static manifest of the device plugin, see https://github.com/ROCm/k8s-device-plugin
+		DevicePluginSelectors: []string{"name=amdgpu-dp-ds"},
+		DeviceCheckCommand:    "ls /dev/kfd /dev/dri/renderD*",
+		// Added by the node labeller of the device plugin.
+		ProductLabel: "amd.com/gpu.product-name",
+	}
+)
+
+// AcceleratorVendors are the vendors supported by the tests, in the order of detection.
+var AcceleratorVendors = []AcceleratorVendor{NVIDIA, AMD}
+
+// selectedVendorNames returns the names of the vendors selected by -ai.vendor, which is empty if it's unspecified.
+func selectedVendorNames() sets.Set[string] {
+	names := sets.New[string]()
+	for _, name := range strings.Split(vendorFilter.Vendor, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names.Insert(name)
+		}
+	}
+	return names
+}
+
+// selectVendors returns the given vendors which are selected by -ai.vendor, in the same order.
+func selectVendors(vendors []AcceleratorVendor) []AcceleratorVendor {
+	names := selectedVendorNames()
+	if names.Len() == 0 {
+		return vendors
+	}
+	var selected []AcceleratorVendor
+	for _, vendor := range vendors {
+		if names.Has(vendor.Name) {
+			selected = append(selected, vendor)
+		}
+	}
+	return selected
+}
+
+// ValidateVendorFilter returns an error if -ai.vendor names a vendor which is none of the candidate vendors, so that
+// a typo doesn't skip all the tests using the accelerators.
+func ValidateVendorFilter() error {
+	candidates := sets.New[string]()
+	for _, vendor := range allCandidateAcceleratorVendors() {
+		candidates.Insert(vendor.Name)
+	}
+	if unknown := selectedVendorNames().Difference(candidates); unknown.Len() > 0 {
+		return fmt.Errorf("invalid -ai.vendor %q, unknown vendors %v, must be in %v", vendorFilter.Vendor, sets.List(unknown), sets.List(candidates))
+	}
+	return nil
+}
+
+// CandidateAcceleratorVendors returns the vendors of the resources of -ai.accelerator.resourceNames in the order of
+// preference, or the supported vendors if it's unspecified, which are selected by -ai.vendor. A resource of none of
+// the supported vendors is a vendor named after the resource, which has no metrics, device plugin or device check
+// command.
+func CandidateAcceleratorVendors() []AcceleratorVendor {
+	return selectVendors(allCandidateAcceleratorVendors())
+}
+
+// allCandidateAcceleratorVendors is like CandidateAcceleratorVendors, but it ignores -ai.vendor.
+func allCandidateAcceleratorVendors() []AcceleratorVendor {
+	var vendors []AcceleratorVendor
+	for _, name := range strings.Split(accelerator.ResourceNames, ",") {
+		resourceName := v1.ResourceName(strings.TrimSpace(name))
+		if resourceName == "" {
+			continue
+		}
+		i := slices.IndexFunc(AcceleratorVendors, func(vendor AcceleratorVendor) bool { return vendor.ResourceName == resourceName })
+		if i >= 0 {
+			vendors = append(vendors, AcceleratorVendors[i])
+		} else {
+			vendors = append(vendors, AcceleratorVendor{Name: string(resourceName), ResourceName: resourceName})
+		}
+	}
+	if len(vendors) == 0 {
+		return AcceleratorVendors
+	}
+	return vendors
+}
+
+// DetectAcceleratorVendor returns the first of the given vendors, or of the candidate vendors if none is given,
+// whose accelerator resource is allocatable on any of the given nodes, or present in their capacity if none is
+// allocatable. The vendors which are not selected by -ai.vendor are ignored. Nil is returned if there is none.
+func DetectAcceleratorVendor(nodes []v1.Node, vendors ...AcceleratorVendor) *AcceleratorVendor {
+	if len(vendors) == 0 {
+		vendors = CandidateAcceleratorVendors()
+	} else {
+		vendors = selectVendors(vendors)
+	}
+	for _, vendor := range vendors {
+		if slices.ContainsFunc(nodes, func(node v1.Node) bool { return hasResource(node.Status.Allocatable, vendor.ResourceName) }) {
+			return &vendor
+		}
+	}
+	for _, vendor := range vendors {
+		if slices.ContainsFunc(nodes, func(node v1.Node) bool { return hasResource(node.Status.Capacity, vendor.ResourceName) }) {
+			return &vendor
+		}
+	}
+	return nil
+}
+
+// AcceleratorNodesByVendor returns the names of the given nodes advertising the accelerators of each of the
+// candidate vendors in their capacity, regardless of -ai.vendor, so that the vendors of a heterogeneous cluster can
+// be reported. A node advertising the accelerators of multiple vendors is listed for each of them.
+func AcceleratorNodesByVendor(nodes []v1.Node) map[string][]string {
+	byVendor := map[string][]string{}
+	for _, vendor := range allCandidateAcceleratorVendors() {
+		for _, node := range nodes {
+			if hasResource(node.Status.Capacity, vendor.ResourceName) {
+				byVendor[vendor.Name] = append(byVendor[vendor.Name], node.Name)
+			}
+		}
+	}
+	return byVendor
+}
+
+// hasResource returns true if the resource is in the list with a non-zero quantity.
+func hasResource(resources v1.ResourceList, resourceName v1.ResourceName) bool {
+	val, ok := resources[resourceName]
+	return ok && !val.IsZero()
+}
+
+// PreferredAcceleratorResource returns the resource of the vendor detected from the given nodes, or the first of
+// the candidate vendors if none is detected, e.g. because the accelerator nodes are provisioned on demand.
+func PreferredAcceleratorResource(nodes []v1.Node) v1.ResourceName {
+	vendors := CandidateAcceleratorVendors()
+	if vendor := DetectAcceleratorVendor(nodes, vendors...); vendor != nil {
+		return vendor.ResourceName
+	}
+	return vendors[0].ResourceName
+}
+
+// PhysicalAccelerators returns the number of the physical accelerators of the vendor on the given nodes, which is
+// the value of the count label of a node, or the capacity of the resource if the node isn't labeled.
+func (v AcceleratorVendor) PhysicalAccelerators(nodes []v1.Node) (int, error) {
+	total := 0
+	for _, node := range nodes {
+		if value, ok := node.Labels[v.CountLabel]; ok && v.CountLabel != "" {
+			count, err := strconv.Atoi(value)
+			if err != nil {
+				return 0, fmt.Errorf("invalid label %s=%s of node %s: %w", v.CountLabel, value, node.Name, err)
+			}
+			total += count
+			continue
+		}
+		if val, ok := node.Status.Capacity[v.ResourceName]; ok {
+			total += int(val.Value())
+		}
+	}
+	return total, nil
+}
+
+// AcceleratorProducts returns the values of the product label on the given nodes which advertise the resource in
+// their capacity, the most common first and the ties in alphabetical order.
+func AcceleratorProducts(nodes []v1.Node, label string, resourceName v1.ResourceName) []string {
+	counts := map[string]int{}
+	for _, node := range nodes {
+		if product := node.Labels[label]; product != "" && hasResource(node.Status.Capacity, resourceName) {
+			counts[product]++
+		}
+	}
+	products := make([]string, 0, len(counts))
+	for product := range counts {
+		products = append(products, product)
+	}
+	slices.SortFunc(products, func(a, b string) int {
+		if counts[a] != counts[b] {
+			return counts[b] - counts[a]
+		}
+		return strings.Compare(a, b)
+	})
+	return products
+}
+
+// VisibleDevicesEnvName returns the environment variable of the allocated accelerators configured by
+// -ai.accelerator.visibleDevicesEnv, or the one of the vendor if it's unspecified.
+func (v AcceleratorVendor) VisibleDevicesEnvName() string {
+	if accelerator.VisibleDevicesEnv != "" {
+		return accelerator.VisibleDevicesEnv
+	}
+	return v.VisibleDevicesEnv
+}
+
+// VerifyVisibleDevices returns an error unless the environment variable in the output of env lists exactly count
+// devices. Exposing all the devices of the node, e.g. NVIDIA_VISIBLE_DEVICES=all, or none of them is an error.
+func VerifyVisibleDevices(output, name string, count int) error {
+	var value string
+	found := false
+	for _, line := range strings.Split(output, "\n") {
+		if key, val, ok := strings.Cut(strings.TrimSpace(line), "="); ok && key == name {
+			value, found = val, true
+		}
+	}
+	if !found {
+		return fmt.Errorf("environment variable %s is not set", name)
+	}
+	switch value {
+	case "all":
+		return fmt.Errorf("environment variable %s exposes all the devices of the node", name)
+	case "", "none", "void":
+		return fmt.Errorf("environment variable %s=%q exposes no device", name, value)
+	}
+	if devices := strings.Split(value, ","); len(devices) != count {
+		return fmt.Errorf("environment variable %s=%s lists %d devices, want %d", name, value, len(devices), count)
+	}
+	return nil
+}
+
+// MetricPrefixRegex returns a PromQL regular expression which matches the names of the metrics of the vendor.
+func (v AcceleratorVendor) MetricPrefixRegex() string {
+	return "^(" + strings.Join(v.MetricPrefixes, "|") + ").*"
+}
+
+// MissingCoreMetrics returns the core metrics of the vendor which are not matched by any of the given names.
+func (v AcceleratorVendor) MissingCoreMetrics(names []string) []string {
+	return missingMetrics(v.CoreMetrics, names)
+}
+
+// MissingRecommendedMetrics returns the recommended metrics of the vendor which are not matched by any of the given
+// names.
+func (v AcceleratorVendor) MissingRecommendedMetrics(names []string) []string {
+	return missingMetrics(v.RecommendedMetrics, names)
+}
+
+// missingMetrics returns the regular expressions of the metric names which are not matched by any of the given names.
+func missingMetrics(metrics []string, names []string) []string {
+	var missing []string
+	for _, metric := range metrics {
+		re := regexp.MustCompile("^(" + metric + ")$")
+		found := false
+		for _, name := range names {
+			if re.MatchString(name) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, metric)
+		}
+	}
+	return missing
+}
+
+// AcceleratorCount is the number of accelerators of a resource in the cluster.
+type AcceleratorCount struct {
+	// Nodes is the number of counted ready nodes selected by -ai.accelerator.nodeSelector, including the ones
+	// without the accelerators.
+	Nodes int
+	// Capacity is the sum of the capacity of the counted nodes.
+	Capacity int
+	// Allocatable is the sum of the allocatable of the counted nodes.
+	Allocatable int
+	// Used is the sum of the limits of the pods which are not terminated. If -ai.accelerator.nodeSelector is
+	// specified or any ready node is not counted, only the pods bound to the counted nodes are counted.
+	Used int
+	// Allocations are the accelerators used by each of the counted pods.
+	Allocations []AcceleratorAllocation
+}
+
+// AcceleratorAllocation is the number of accelerators used by a pod.
+type AcceleratorAllocation struct {
+	// Pod is the namespace/name of the pod.
+	Pod string
+	// Node is the node the pod is bound to.
+	Node string
+	// Count is the limit of the accelerator resource of the pod.
+	Count int
+}
+
+// Available returns the number of accelerators which can be allocated to new pods.
+func (c *AcceleratorCount) Available() int {
+	return c.Allocatable - c.Used
+}
+
+// ShortageReason returns why n accelerators of the resource can't be allocated to new pods with the capacity, the
+// allocatable, the used and the available accelerators, or an empty string if they can. It's the skip reason of
+// RequireAvailableAccelerators, so that the skip reasons of the specs are comparable.
+func (c *AcceleratorCount) ShortageReason(resourceName v1.ResourceName, n int) string {
+	if c.Available() >= n {
+		return ""
+	}
+	return fmt.Sprintf("At least %d %s are required, but only %d are available on %d ready nodes: capacity %d, allocatable %d, used %d",
+		n, resourceName, c.Available(), c.Nodes, c.Capacity, c.Allocatable, c.Used)
+}
+
+// CountAccelerators counts the accelerators of the given resource on the ready nodes, including the tainted ones,
+// and the accelerators used by the pods in all namespaces. Only the nodes selected by -ai.accelerator.nodeSelector
+// and the pods bound to them are counted if it's specified. It's the total of the cluster, see
+// CountSchedulableAccelerators for the accelerators which new pods can be scheduled to.
+func CountAccelerators(ctx context.Context, client clientset.Interface, resourceName v1.ResourceName) (*AcceleratorCount, error) {
+	return countAccelerators(ctx, client, resourceName, nil)
+}
+
+// CountSchedulableAccelerators is like CountAccelerators, but it only counts the nodes which new pods can be
+// scheduled to, i.e. the ones not cordoned, and the pods bound to them. The nodes provisioned by an autoscaler are
+// excluded too if excludeAutoscalerNodes is true, as they may be removed while a test waits for its pods, e.g. the
+// members of a gang, see IsAutoscalerNode.
+func CountSchedulableAccelerators(ctx context.Context, client clientset.Interface, resourceName v1.ResourceName, excludeAutoscalerNodes bool) (*AcceleratorCount, error) {
+	return countAccelerators(ctx, client, resourceName, func(node *v1.Node) bool {
+		return isSchedulableAcceleratorNode(node, excludeAutoscalerNodes)
+	})
+}
+
+// isSchedulableAcceleratorNode returns true if new pods can be scheduled to the node, which is not provisioned by an
+// autoscaler if excludeAutoscalerNodes is true.
+func isSchedulableAcceleratorNode(node *v1.Node, excludeAutoscalerNodes bool) bool {
+	return !node.Spec.Unschedulable && !(excludeAutoscalerNodes && IsAutoscalerNode(node))
+}
+
+// countAccelerators counts the accelerators on the ready nodes selected by -ai.accelerator.nodeSelector for which
+// include returns true, or all of them if it's nil. If any node is excluded, only the pods bound to the counted
+// nodes are counted.
+func countAccelerators(ctx context.Context, client clientset.Interface, resourceName v1.ResourceName, include func(node *v1.Node) bool) (*AcceleratorCount, error) {
+	selector, err := acceleratorNodeSelector()
+	if err != nil {
+		return nil, err
+	}
+	nodeList, err := e2enode.GetReadyNodesIncludingTainted(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("error when listing ready nodes: %w", err)
+	}
+	var nodes []v1.Node
+	for _, node := range selectNodes(nodeList.Items, selector) {
+		if include == nil || include(&node) {
+			nodes = append(nodes, node)
+		}
+	}
+	excluded := !selector.Empty() || len(nodes) < len(nodeList.Items)
+	nodeNames := sets.New[string]()
+
+	count := &AcceleratorCount{Nodes: len(nodes)}
+	for _, node := range nodes {
+		nodeNames.Insert(node.Name)
+		if val, ok := node.Status.Capacity[resourceName]; ok {
+			count.Capacity += int(val.Value())
+		}
+		if val, ok := node.Status.Allocatable[resourceName]; ok {
+			count.Allocatable += int(val.Value())
+		}
+	}
+
+	pods, err := client.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error when listing pods: %w", err)
+	}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+		if excluded && !nodeNames.Has(pod.Spec.NodeName) {
+			continue
+		}
+		if val, ok := resourcehelper.PodLimits(&pod, resourcehelper.PodResourcesOptions{})[resourceName]; ok && !val.IsZero() {
+			count.Used += int(val.Value())
+			count.Allocations = append(count.Allocations, AcceleratorAllocation{
+				Pod:   pod.Namespace + "/" + pod.Name,
+				Node:  pod.Spec.NodeName,
+				Count: int(val.Value()),
+			})
+		}
+	}
+	return count, nil
+}
+
+// UnhealthyAccelerators returns the number of unhealthy accelerators of the resource on each of the given nodes
+// selected by -ai.accelerator.nodeSelector, i.e. the capacity minus the allocatable, as the kubelet excludes the
+// devices reported unhealthy by the device plugin from the allocatable. Only the nodes which advertise the resource
+// and have unhealthy accelerators are returned.
+func UnhealthyAccelerators(nodes []v1.Node, resourceName v1.ResourceName) (map[string]int, error) {
+	selector, err := acceleratorNodeSelector()
+	if err != nil {
+		return nil, err
+	}
+	unhealthy := map[string]int{}
+	for _, node := range selectNodes(nodes, selector) {
+		capacity, ok := node.Status.Capacity[resourceName]
+		if !ok || capacity.IsZero() {
+			continue
+		}
+		allocatable := node.Status.Allocatable[resourceName]
+		if n := int(capacity.Value() - allocatable.Value()); n > 0 {
+			unhealthy[node.Name] = n
+		}
+	}
+	return unhealthy, nil
+}
+
+// MalformedAcceleratorQuantities returns why the capacity or the allocatable of the resource is malformed on each of
+// the given nodes selected by -ai.accelerator.nodeSelector, e.g. as reported by a broken device plugin. The
+// accelerators are counted as whole devices, so both of them must be non-negative integers, and the allocatable must
+// not exceed the capacity. Only the nodes which advertise the resource and violate any of them are returned.
+func MalformedAcceleratorQuantities(nodes []v1.Node, resourceName v1.ResourceName) (map[string]string, error) {
+	selector, err := acceleratorNodeSelector()
+	if err != nil {
+		return nil, err
+	}
+	malformed := map[string]string{}
+	for _, node := range selectNodes(nodes, selector) {
+		capacity, hasCapacity := node.Status.Capacity[resourceName]
+		allocatable, hasAllocatable := node.Status.Allocatable[resourceName]
+		if !hasCapacity && !hasAllocatable {
+			continue
+		}
+		var reasons []string
+		for _, q := range []struct {
+			name     string
+			val      resource.Quantity
+			reported bool
+		}{{"capacity", capacity, hasCapacity}, {"allocatable", allocatable, hasAllocatable}} {
+			n, ok := q.val.AsInt64()
+			switch {
+			case !q.reported:
+				reasons = append(reasons, q.name+" is missing")
+			case !ok:
+				reasons = append(reasons, fmt.Sprintf("%s %s is not an integer", q.name, q.val.String()))
+			case n < 0:
+				reasons = append(reasons, fmt.Sprintf("%s %s is negative", q.name, q.val.String()))
+			}
+		}
+		if hasCapacity && hasAllocatable && allocatable.Cmp(capacity) > 0 {
+			reasons = append(reasons, fmt.Sprintf("allocatable %s exceeds capacity %s", allocatable.String(), capacity.String()))
+		}
+		if len(reasons) > 0 {
+			malformed[node.Name] = strings.Join(reasons, ", ")
+		}
+	}
+	return malformed, nil
+}
+
+// AcceleratorNodeSelectorSpecified returns true if the accelerator nodes are selected by -ai.accelerator.nodeSelector.
+func AcceleratorNodeSelectorSpecified() bool {
+	return accelerator.NodeSelector != ""
+}
+
+// IsAcceleratorNodeCandidate returns true if the node is expected to advertise accelerators, i.e. it's selected by
+// -ai.accelerator.nodeSelector if it's specified, or it advertises the accelerator resource otherwise.
+func IsAcceleratorNodeCandidate(node *v1.Node, resourceName v1.ResourceName) (bool, error) {
+	if accelerator.NodeSelector == "" {
+		val, ok := node.Status.Capacity[resourceName]
+		return ok && !val.IsZero(), nil
+	}
+	selector, err := acceleratorNodeSelector()
+	if err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(node.Labels)), nil
+}
+
+// nodeReadySince returns when the node became Ready, or nil if it's not Ready.
+func nodeReadySince(node *v1.Node) *metav1.Time {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == v1.NodeReady && cond.Status == v1.ConditionTrue {
+			return &cond.LastTransitionTime
+		}
+	}
+	return nil
+}
+
+// checkAcceleratorCapacity returns true if the node advertises the accelerator resource in its capacity and
+// allocatable. An error is returned if the node has been Ready for longer than the timeout without advertising it.
+func checkAcceleratorCapacity(node *v1.Node, resourceName v1.ResourceName, timeout time.Duration, now time.Time) (bool, error) {
+	capacity := node.Status.Capacity[resourceName]
+	allocatable := node.Status.Allocatable[resourceName]
+	if !capacity.IsZero() && !allocatable.IsZero() {
+		return true, nil
+	}
+	readySince := nodeReadySince(node)
+	if readySince == nil {
+		return false, nil
+	}
+	if readyFor := now.Sub(readySince.Time); readyFor > timeout {
+		return false, fmt.Errorf("node %s has been Ready for %v, but its capacity %s and allocatable %s of %s are still zero",
+			node.Name, readyFor.Round(time.Second), capacity.String(), allocatable.String(), resourceName)
+	}
+	return false, nil
+}
+
+// WaitForAcceleratorCapacity waits until the node advertises the accelerator resource in its capacity and
+// allocatable. An error is returned if the node has been Ready for longer than -ai.accelerator.capacityTimeout
+// without advertising it, i.e. the device plugin doesn't register the accelerators in time.
+func WaitForAcceleratorCapacity(ctx context.Context, client clientset.Interface, nodeName string, resourceName v1.ResourceName) error {
+	timeout := accelerator.CapacityTimeout
+	var node *v1.Node
+	err := wait.PollUntilContextTimeout(ctx, framework.Poll, timeout, true, func(ctx context.Context) (bool, error) {
+		var err error
+		node, err = client.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return checkAcceleratorCapacity(node, resourceName, timeout, time.Now())
+	})
+	if err != nil {
+		return fmt.Errorf("error when waiting for node %s to advertise %s: %w", nodeName, resourceName, err)
+	}
+	if readySince := nodeReadySince(node); readySince != nil {
+		framework.Logf("Node %s advertises %s, it has been Ready since %v", nodeName, resourceName, readySince.Time)
+	}
+	return nil
+}
+
+// unreleasedAccelerators describes why the available accelerators of the current count are fewer than the ones of
+// the baseline count: the accelerators still allocated to the pods which didn't hold them at the baseline, and the
+// accelerators which are no longer allocatable.
+func unreleasedAccelerators(baseline, current *AcceleratorCount) []string {
+	held := sets.New[string]()
+	for _, allocation := range baseline.Allocations {
+		held.Insert(allocation.Pod)
+	}
+	var unreleased []string
+	for _, allocation := range current.Allocations {
+		if !held.Has(allocation.Pod) {
+			unreleased = append(unreleased, fmt.Sprintf("%d allocated to pod %s on node %s", allocation.Count, allocation.Pod, allocation.Node))
+		}
+	}
+	if current.Allocatable < baseline.Allocatable {
+		unreleased = append(unreleased, fmt.Sprintf("%d no longer allocatable", baseline.Allocatable-current.Allocatable))
+	}
+	return unreleased
+}
+
+// WaitForAcceleratorsReleased waits until the available accelerators of the resource return to the baseline count,
+// i.e. the accelerators allocated to the pods deleted by the spec are released. An error listing the accelerators
+// which are still allocated is returned if they are not released within -ai.accelerator.releaseTimeout.
+func WaitForAcceleratorsReleased(ctx context.Context, client clientset.Interface, resourceName v1.ResourceName, baseline *AcceleratorCount) error {
+	var count *AcceleratorCount
+	err := wait.PollUntilContextTimeout(ctx, framework.Poll, accelerator.ReleaseTimeout, true, func(ctx context.Context) (bool, error) {
+		var err error
+		count, err = CountAccelerators(ctx, client, resourceName)
+		if err != nil {
+			return false, err
+		}
+		return count.Available() >= baseline.Available(), nil
+	})
+	if err != nil {
+		if count == nil {
+			return fmt.Errorf("error when waiting for %s to be released: %w", resourceName, err)
+		}
+		return fmt.Errorf("only %d of %d %s are available after %v, still allocated: %v: %w",
+			count.Available(), baseline.Available(), resourceName, accelerator.ReleaseTimeout, unreleasedAccelerators(baseline, count), err)
+	}
+	framework.Logf("%d %s are available, the same as before the spec", count.Available(), resourceName)
+	return nil
+}
+
+// VerifyAcceleratorsReleased counts the accelerators of the resource as the baseline and verifies that they are
+// released after the cleanups registered later, i.e. the deletion of the workloads of the spec, if
+// -ai.accelerator.verifyRelease is set. The verification is skipped if the resources of the failed spec are retained.
+func VerifyAcceleratorsReleased(ctx context.Context, client clientset.Interface, resourceName v1.ResourceName) error {
+	if !accelerator.VerifyRelease {
+		return nil
+	}
+	baseline, err := CountAccelerators(ctx, client, resourceName)
+	if err != nil {
+		return err
+	}
+	ginkgo.DeferCleanup(func(ctx context.Context) error {
+		if retainedOnFailure() {
+			return nil
+		}
+		return WaitForAcceleratorsReleased(ctx, client, resourceName, baseline)
+	}, ginkgo.Offset(1))
+	return nil
+}
+
+// AcceleratorNodes returns the nodes which advertise the accelerator resource in their capacity and are selected
+// by -ai.accelerator.nodeSelector.
+func AcceleratorNodes(nodes []v1.Node, resourceName v1.ResourceName) ([]v1.Node, error) {
+	selector, err := acceleratorNodeSelector()
+	if err != nil {
+		return nil, err
+	}
+	var result []v1.Node
+	for _, node := range selectNodes(nodes, selector) {
+		if val, ok := node.Status.Capacity[resourceName]; ok && !val.IsZero() {
+			result = append(result, node)
+		}
+	}
+	return result, nil
+}
+
+// acceleratorNodeToleration tolerates the NoSchedule taints which are commonly used to reserve the accelerator nodes.
+var acceleratorNodeToleration = v1.Toleration{
+	Effect:   v1.TaintEffectNoSchedule,
+	Operator: v1.TolerationOpExists,
+}
+
+// RequireAcceleratorNode mutates the pod spec to require the given nodes which advertise the accelerator resource
+// in their capacity and are selected by -ai.accelerator.nodeSelector, via a node affinity on their hostname
+// labels, and to tolerate the NoSchedule taints of the accelerator nodes. The existing required node affinity
+// terms are kept and narrowed down to the accelerator nodes. An error is returned if none of the nodes qualifies.
+func RequireAcceleratorNode(spec *v1.PodSpec, nodes []v1.Node, resourceName v1.ResourceName) error {
+	selector, err := acceleratorNodeSelector()
+	if err != nil {
+		return err
+	}
+	var hostnames []string
+	for _, node := range selectNodes(nodes, selector) {
+		if val, ok := node.Status.Capacity[resourceName]; !ok || val.IsZero() {
+			continue
+		}
+		if hostname := node.Labels[v1.LabelHostname]; hostname != "" {
+			hostnames = append(hostnames, hostname)
+		}
+	}
+	if len(hostnames) == 0 {
+		return fmt.Errorf("none of the %d nodes selected by %q advertises %s with label %s", len(nodes), selector, resourceName, v1.LabelHostname)
+	}
+	requirement := v1.NodeSelectorRequirement{
+		Key:      v1.LabelHostname,
+		Operator: v1.NodeSelectorOpIn,
+		Values:   hostnames,
+	}
+
+	if spec.Affinity == nil {
+		spec.Affinity = &v1.Affinity{}
+	}
+	if spec.Affinity.NodeAffinity == nil {
+		spec.Affinity.NodeAffinity = &v1.NodeAffinity{}
+	}
+	nodeAffinity := spec.Affinity.NodeAffinity
+	if nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &v1.NodeSelector{}
+	}
+	// The terms are ORed, so the requirement is added to each of them.
+	required := nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if len(required.NodeSelectorTerms) == 0 {
+		required.NodeSelectorTerms = []v1.NodeSelectorTerm{{}}
+	}
+	for i := range required.NodeSelectorTerms {
+		term := &required.NodeSelectorTerms[i]
+		term.MatchExpressions = append(term.MatchExpressions, requirement)
+	}
+
+	if !slices.Contains(spec.Tolerations, acceleratorNodeToleration) {
+		spec.Tolerations = append(spec.Tolerations, acceleratorNodeToleration)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
+
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+const (
+	// acceleratorLockNamespace is the namespace of the Leases which are shared by all the test processes.
+	acceleratorLockNamespace = metav1.NamespaceDefault
+	// acceleratorLockDuration is how long a Lease is held without being renewed, e.g. when the test process
+	// holding it is killed.
+	acceleratorLockDuration = time.Minute
+)
+
+// AcceleratorLock is a Lease held by a spec which consumes the accelerators, so that the specs running in
+// parallel don't oversubscribe the accelerators and all fail when they are scarce.
+type AcceleratorLock struct {
+	client clientset.Interface
+	name   string
+	holder string
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// acceleratorLockName returns the name of the Lease guarding the accelerator resource, e.g.
+// ai-conformance-nvidia.com-gpu for nvidia.com/gpu.
+func acceleratorLockName(resourceName v1.ResourceName) string {
+	return "ai-conformance-" + strings.ReplaceAll(string(resourceName), "/", "-")
+}
+
+// leaseHeldByOthers returns true if the Lease is held by another holder and has not expired at the given time.
+func leaseHeldByOthers(lease *coordinationv1.Lease, holder string, now time.Time) bool {
+	spec := lease.Spec
+	if spec.HolderIdentity == nil || *spec.HolderIdentity == "" || *spec.HolderIdentity == holder {
+		return false
+	}
+	if spec.RenewTime == nil || spec.LeaseDurationSeconds == nil {
+		return false
+	}
+	return now.Before(spec.RenewTime.Add(time.Duration(*spec.LeaseDurationSeconds) * time.Second))
+}
+
+// LockAccelerators blocks until the holder, e.g. the unique name of the framework, acquires the lock of the
+// accelerator resource if the ready nodes selected by -ai.accelerator.nodeSelector have fewer allocatable
+// accelerators than -ai.accelerator.exclusiveThreshold. The lock is renewed in the background until it's
+// released. A nil lock is returned if the accelerators are not scarce.
+func LockAccelerators(ctx context.Context, client clientset.Interface, resourceName v1.ResourceName, holder string) (*AcceleratorLock, error) {
+	count, err := CountAccelerators(ctx, client, resourceName)
+	if err != nil {
+		return nil, err
+	}
+	if count.Allocatable >= accelerator.ExclusiveThreshold {
+		return nil, nil
+	}
+
+	l := &AcceleratorLock{client: client, name: acceleratorLockName(resourceName), holder: holder}
+	framework.Logf("Only %d %s are allocatable, waiting for Lease %s/%s to run exclusively", count.Allocatable, resourceName, acceleratorLockNamespace, l.name)
+	var lastHolder string
+	err = wait.PollUntilContextCancel(ctx, framework.Poll, true, func(ctx context.Context) (bool, error) {
+		acquired, currentHolder, err := l.tryAcquire(ctx)
+		if err != nil {
+			return false, err
+		}
+		if !acquired && currentHolder != lastHolder {
+			framework.Logf("Lease %s/%s is held by %s", acceleratorLockNamespace, l.name, currentHolder)
+			lastHolder = currentHolder
+		}
+		return acquired, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error when acquiring Lease %s/%s: %w", acceleratorLockNamespace, l.name, err)
+	}
+
+	renewCtx, cancel := context.WithCancel(context.Background())
+	l.cancel = cancel
+	l.done = make(chan struct{})
+	go l.renew(renewCtx)
+	return l, nil
+}
+
+// tryAcquire acquires the Lease if it's not held by others, and returns the current holder otherwise.
+func (l *AcceleratorLock) tryAcquire(ctx context.Context) (bool, string, error) {
+	leases := l.client.CoordinationV1().Leases(acceleratorLockNamespace)
+	now := metav1.NewMicroTime(time.Now())
+	lease, err := leases.Get(ctx, l.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		lease = &coordinationv1.Lease{ObjectMeta: metav1.ObjectMeta{Name: l.name}}
+		l.hold(lease, now)
+		_, err = leases.Create(ctx, lease, metav1.CreateOptions{})
+		if apierrors.IsAlreadyExists(err) {
+			return false, "", nil
+		}
+		return err == nil, "", err
+	}
+	if err != nil {
+		return false, "", err
+	}
+	if leaseHeldByOthers(lease, l.holder, now.Time) {
+		return false, *lease.Spec.HolderIdentity, nil
+	}
+	l.hold(lease, now)
+	_, err = leases.Update(ctx, lease, metav1.UpdateOptions{})
+	if apierrors.IsConflict(err) {
+		return false, "", nil
+	}
+	return err == nil, "", err
+}
+
+// hold sets the holder of the Lease to the lock.
+func (l *AcceleratorLock) hold(lease *coordinationv1.Lease, now metav1.MicroTime) {
+	lease.Spec.HolderIdentity = ptr.To(l.holder)
+	lease.Spec.LeaseDurationSeconds = ptr.To(int32(acceleratorLockDuration.Seconds()))
+	lease.Spec.AcquireTime = &now
+	lease.Spec.RenewTime = &now
+}
+
+// renew renews the Lease until the context is canceled.
+func (l *AcceleratorLock) renew(ctx context.Context) {
+	defer close(l.done)
+	leases := l.client.CoordinationV1().Leases(acceleratorLockNamespace)
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		lease, err := leases.Get(ctx, l.name, metav1.GetOptions{})
+		if err == nil {
+			lease.Spec.RenewTime = ptr.To(metav1.NewMicroTime(time.Now()))
+			_, err = leases.Update(ctx, lease, metav1.UpdateOptions{})
+		}
+		if err != nil && ctx.Err() == nil {
+			framework.Logf("error when renewing Lease %s/%s: %v", acceleratorLockNamespace, l.name, err)
+		}
+	}, acceleratorLockDuration/3)
+}
+
+// Release stops renewing the Lease and deletes it if it's still held by the lock. It's a no-op on a nil lock.
+func (l *AcceleratorLock) Release(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	l.cancel()
+	<-l.done
+
+	leases := l.client.CoordinationV1().Leases(acceleratorLockNamespace)
+	lease, err := leases.Get(ctx, l.name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error when getting Lease %s/%s: %w", acceleratorLockNamespace, l.name, err)
+	}
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != l.holder {
+		framework.Logf("Lease %s/%s has been taken over, it's not released", acceleratorLockNamespace, l.name)
+		return nil
+	}
+	err = leases.Delete(ctx, l.name, metav1.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &lease.UID, ResourceVersion: &lease.ResourceVersion}})
+	if err != nil && !apierrors.IsNotFound(err) && !apierrors.IsConflict(err) {
+		return fmt.Errorf("error when deleting Lease %s/%s: %w", acceleratorLockNamespace, l.name, err)
+	}
+	return nil
+}
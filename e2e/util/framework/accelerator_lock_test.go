@@ -0,0 +1,67 @@
+package framework
+
+import (
+	"testing"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestLeaseHeldByOthers(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	newLease := func(holder string, renewTime time.Time) *coordinationv1.Lease {
+		lease := &coordinationv1.Lease{}
+		if holder != "" {
+			lease.Spec.HolderIdentity = ptr.To(holder)
+			lease.Spec.LeaseDurationSeconds = ptr.To[int32](60)
+			lease.Spec.RenewTime = ptr.To(metav1.NewMicroTime(renewTime))
+		}
+		return lease
+	}
+
+	tests := []struct {
+		name  string
+		lease *coordinationv1.Lease
+		want  bool
+	}{
+		{
+			name:  "released",
+			lease: newLease("", time.Time{}),
+		},
+		{
+			name:  "held by self",
+			lease: newLease("self", now),
+		},
+		{
+			name:  "held by others",
+			lease: newLease("other", now.Add(-30*time.Second)),
+			want:  true,
+		},
+		{
+			name:  "expired",
+			lease: newLease("other", now.Add(-2*time.Minute)),
+		},
+		{
+			name: "renew time is missing",
+			lease: &coordinationv1.Lease{Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       ptr.To("other"),
+				LeaseDurationSeconds: ptr.To[int32](60),
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := leaseHeldByOthers(tt.lease, "self", now); got != tt.want {
+				t.Errorf("leaseHeldByOthers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAcceleratorLockName(t *testing.T) {
+	if got, want := acceleratorLockName(NVIDIA.ResourceName), "ai-conformance-nvidia.com-gpu"; got != want {
+		t.Errorf("acceleratorLockName() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,80 @@
+package framework
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// AcceleratorMemory is the total memory of an accelerator reported by the exporter of its vendor.
+type AcceleratorMemory struct {
+	// Device identifies the accelerator in the cluster, e.g. its UUID.
+	Device string
+	// Product is the product name of the accelerator.
+	Product string
+	// MiB is the total memory of the accelerator in MiB.
+	MiB float64
+}
+
+// ParseAcceleratorMemory parses the comma-separated <product>=<quantity> entries of the total memory of the
+// accelerators of each product, e.g. NVIDIA-A100-SXM4-80GB=80Gi, and returns the totals in MiB keyed by the
+// normalized product names, see normalizeProduct.
+func ParseAcceleratorMemory(s string) (map[string]float64, error) {
+	memory := map[string]float64{}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		product, value, ok := strings.Cut(entry, "=")
+		if !ok || product == "" {
+			return nil, fmt.Errorf("invalid accelerator memory %q, must be <product>=<quantity>", entry)
+		}
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid memory of product %q: %w", product, err)
+		}
+		if quantity.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid memory of product %q: %s is not positive", product, value)
+		}
+		memory[normalizeProduct(product)] = quantity.AsApproximateFloat64() / (1 << 20)
+	}
+	return memory, nil
+}
+
+// normalizeProduct replaces the spaces in the product name with dashes, as the node feature discovery of NVIDIA does
+// for the node labels, so that the product names of the node labels and of the metrics match.
+func normalizeProduct(product string) string {
+	return strings.ReplaceAll(strings.TrimSpace(product), " ", "-")
+}
+
+// InvalidAcceleratorMemory returns the problems of the total memory of the accelerators, sorted. A total must not be
+// zero. If the expected total of its product is given, it must not differ from it by more than the tolerance, which
+// is a fraction of the expected total. Otherwise, it must not differ from the largest total of the accelerators of
+// the same product by more than the tolerance, as the exporter reporting garbage for some of them would.
+func InvalidAcceleratorMemory(memory []AcceleratorMemory, expected map[string]float64, tolerance float64) []string {
+	largest := map[string]float64{}
+	for _, m := range memory {
+		product := normalizeProduct(m.Product)
+		largest[product] = math.Max(largest[product], m.MiB)
+	}
+
+	var problems []string
+	for _, m := range memory {
+		product := normalizeProduct(m.Product)
+		switch want, ok := expected[product]; {
+		case m.MiB <= 0:
+			problems = append(problems, fmt.Sprintf("%s (%s): total memory is %g MiB", m.Device, m.Product, m.MiB))
+		case ok && math.Abs(m.MiB-want) > want*tolerance:
+			problems = append(problems, fmt.Sprintf("%s (%s): total memory is %g MiB, want %g MiB", m.Device, m.Product, m.MiB, want))
+		case !ok && largest[product]-m.MiB > largest[product]*tolerance:
+			problems = append(problems, fmt.Sprintf("%s (%s): total memory is %g MiB, but it's %g MiB for another accelerator of the product",
+				m.Device, m.Product, m.MiB, largest[product]))
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
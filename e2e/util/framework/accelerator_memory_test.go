@@ -0,0 +1,112 @@
+package framework
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAcceleratorMemory(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    map[string]float64
+		wantErr bool
+	}{
+		{
+			name: "empty",
+			s:    "",
+			want: map[string]float64{},
+		},
+		{
+			name: "products",
+			s:    "NVIDIA A100-SXM4-80GB=80Gi, NVIDIA-L4=23034Mi",
+			want: map[string]float64{"NVIDIA-A100-SXM4-80GB": 81920, "NVIDIA-L4": 23034},
+		},
+		{
+			name:    "missing quantity",
+			s:       "NVIDIA-L4",
+			wantErr: true,
+		},
+		{
+			name:    "invalid quantity",
+			s:       "NVIDIA-L4=24GiB",
+			wantErr: true,
+		},
+		{
+			name:    "zero quantity",
+			s:       "NVIDIA-L4=0",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAcceleratorMemory(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAcceleratorMemory() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseAcceleratorMemory() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvalidAcceleratorMemory(t *testing.T) {
+	tests := []struct {
+		name     string
+		memory   []AcceleratorMemory
+		expected map[string]float64
+		want     []string
+	}{
+		{
+			name: "consistent",
+			memory: []AcceleratorMemory{
+				{Device: "GPU-0", Product: "NVIDIA A100-SXM4-80GB", MiB: 81559},
+				{Device: "GPU-1", Product: "NVIDIA A100-SXM4-80GB", MiB: 81559},
+				{Device: "GPU-2", Product: "NVIDIA L4", MiB: 23034},
+			},
+		},
+		{
+			name: "zero",
+			memory: []AcceleratorMemory{
+				{Device: "GPU-0", Product: "NVIDIA L4", MiB: 23034},
+				{Device: "GPU-1", Product: "NVIDIA L4", MiB: 0},
+			},
+			want: []string{"GPU-1 (NVIDIA L4): total memory is 0 MiB"},
+		},
+		{
+			name: "inconsistent within the product",
+			memory: []AcceleratorMemory{
+				{Device: "GPU-0", Product: "NVIDIA L4", MiB: 23034},
+				{Device: "GPU-1", Product: "NVIDIA L4", MiB: 1024},
+			},
+			want: []string{"GPU-1 (NVIDIA L4): total memory is 1024 MiB, but it's 23034 MiB for another accelerator of the product"},
+		},
+		{
+			name: "within the tolerance of the expected total",
+			memory: []AcceleratorMemory{
+				{Device: "GPU-0", Product: "NVIDIA A100-SXM4-80GB", MiB: 81559},
+			},
+			expected: map[string]float64{"NVIDIA-A100-SXM4-80GB": 81920},
+		},
+		{
+			name: "differs from the expected total",
+			memory: []AcceleratorMemory{
+				{Device: "GPU-0", Product: "NVIDIA A100-SXM4-80GB", MiB: 40960},
+				{Device: "GPU-1", Product: "NVIDIA A100-SXM4-80GB", MiB: 40960},
+			},
+			expected: map[string]float64{"NVIDIA-A100-SXM4-80GB": 81920},
+			want: []string{
+				"GPU-0 (NVIDIA A100-SXM4-80GB): total memory is 40960 MiB, want 81920 MiB",
+				"GPU-1 (NVIDIA A100-SXM4-80GB): total memory is 40960 MiB, want 81920 MiB",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := InvalidAcceleratorMemory(tt.memory, tt.expected, 0.05); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("InvalidAcceleratorMemory() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,983 @@
+package framework
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newNodeWithCapacity(capacity v1.ResourceList) v1.Node {
+	return v1.Node{Status: v1.NodeStatus{Capacity: capacity}}
+}
+
+func TestDetectAcceleratorVendor(t *testing.T) {
+	nvidiaNode := newNodeWithCapacity(v1.ResourceList{NVIDIA.ResourceName: resource.MustParse("8")})
+	amdNode := newNodeWithCapacity(v1.ResourceList{AMD.ResourceName: resource.MustParse("4")})
+	cpuNode := newNodeWithCapacity(v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")})
+	zeroNode := newNodeWithCapacity(v1.ResourceList{NVIDIA.ResourceName: resource.MustParse("0")})
+	exampleNode := newNodeWithCapacity(v1.ResourceList{"example.com/gpu": resource.MustParse("2")})
+	exhaustedNVIDIANode := newNodeWithCapacity(v1.ResourceList{NVIDIA.ResourceName: resource.MustParse("8")})
+	exhaustedNVIDIANode.Status.Allocatable = v1.ResourceList{NVIDIA.ResourceName: resource.MustParse("0")}
+	allocatableAMDNode := newNodeWithCapacity(v1.ResourceList{AMD.ResourceName: resource.MustParse("4")})
+	allocatableAMDNode.Status.Allocatable = v1.ResourceList{AMD.ResourceName: resource.MustParse("4")}
+
+	tests := []struct {
+		name          string
+		nodes         []v1.Node
+		vendors       []AcceleratorVendor
+		resourceNames string
+		vendor        string
+		want          string
+	}{
+		{
+			name:  "no nodes",
+			nodes: nil,
+		},
+		{
+			name:  "no accelerator",
+			nodes: []v1.Node{cpuNode},
+		},
+		{
+			name:  "zero capacity is ignored",
+			nodes: []v1.Node{zeroNode},
+		},
+		{
+			name:  "nvidia",
+			nodes: []v1.Node{cpuNode, nvidiaNode},
+			want:  "nvidia",
+		},
+		{
+			name:  "amd",
+			nodes: []v1.Node{amdNode, cpuNode},
+			want:  "amd",
+		},
+		{
+			name:  "nvidia is detected first",
+			nodes: []v1.Node{amdNode, nvidiaNode},
+			want:  "nvidia",
+		},
+		{
+			name:    "only the given vendors are detected",
+			nodes:   []v1.Node{amdNode, nvidiaNode},
+			vendors: []AcceleratorVendor{AMD},
+			want:    "amd",
+		},
+		{
+			name:    "the given vendors are absent",
+			nodes:   []v1.Node{nvidiaNode},
+			vendors: []AcceleratorVendor{AMD},
+		},
+		{
+			name:  "allocatable is preferred over capacity",
+			nodes: []v1.Node{exhaustedNVIDIANode, allocatableAMDNode},
+			want:  "amd",
+		},
+		{
+			name:  "capacity if none is allocatable",
+			nodes: []v1.Node{exhaustedNVIDIANode, amdNode},
+			want:  "nvidia",
+		},
+		{
+			name:          "candidate resources in the order of preference",
+			nodes:         []v1.Node{nvidiaNode, amdNode, exampleNode},
+			resourceNames: "example.com/gpu, amd.com/gpu",
+			want:          "example.com/gpu",
+		},
+		{
+			name:          "supported vendor in the candidate resources",
+			nodes:         []v1.Node{nvidiaNode, amdNode},
+			resourceNames: "example.com/gpu,amd.com/gpu",
+			want:          "amd",
+		},
+		{
+			name:          "none of the candidate resources",
+			nodes:         []v1.Node{nvidiaNode},
+			resourceNames: "example.com/gpu",
+		},
+		{
+			name:   "selected vendor",
+			nodes:  []v1.Node{nvidiaNode, allocatableAMDNode},
+			vendor: "amd",
+			want:   "amd",
+		},
+		{
+			name:   "selected vendor is absent",
+			nodes:  []v1.Node{nvidiaNode},
+			vendor: "amd",
+		},
+		{
+			name:    "given vendors are not selected",
+			nodes:   []v1.Node{nvidiaNode, amdNode},
+			vendors: []AcceleratorVendor{NVIDIA},
+			vendor:  "amd",
+		},
+		{
+			name:          "selected vendor of a candidate resource",
+			nodes:         []v1.Node{nvidiaNode, exampleNode},
+			resourceNames: "nvidia.com/gpu,example.com/gpu",
+			vendor:        "example.com/gpu",
+			want:          "example.com/gpu",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accelerator.ResourceNames = tt.resourceNames
+			vendorFilter.Vendor = tt.vendor
+			defer func() { accelerator.ResourceNames, vendorFilter.Vendor = "", "" }()
+			got := DetectAcceleratorVendor(tt.nodes, tt.vendors...)
+			if tt.want == "" {
+				if got != nil {
+					t.Errorf("DetectAcceleratorVendor() = %s, want nil", got.Name)
+				}
+				return
+			}
+			if got == nil || got.Name != tt.want {
+				t.Errorf("DetectAcceleratorVendor() = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateVendorFilter(t *testing.T) {
+	tests := []struct {
+		name          string
+		vendor        string
+		resourceNames string
+		wantErr       bool
+	}{
+		{
+			name: "unspecified",
+		},
+		{
+			name:   "supported vendors",
+			vendor: "amd, nvidia",
+		},
+		{
+			name:          "vendor of a candidate resource",
+			vendor:        "example.com/gpu",
+			resourceNames: "example.com/gpu",
+		},
+		{
+			name:    "unknown vendor",
+			vendor:  "intel",
+			wantErr: true,
+		},
+		{
+			name:          "supported vendor which is not a candidate",
+			vendor:        "nvidia",
+			resourceNames: "amd.com/gpu",
+			wantErr:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accelerator.ResourceNames = tt.resourceNames
+			vendorFilter.Vendor = tt.vendor
+			defer func() { accelerator.ResourceNames, vendorFilter.Vendor = "", "" }()
+			if err := ValidateVendorFilter(); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateVendorFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAcceleratorNodesByVendor(t *testing.T) {
+	newNode := func(name string, capacity v1.ResourceList) v1.Node {
+		node := newNodeWithCapacity(capacity)
+		node.Name = name
+		return node
+	}
+	nodes := []v1.Node{
+		newNode("gpu-a", v1.ResourceList{NVIDIA.ResourceName: resource.MustParse("8")}),
+		newNode("gpu-b", v1.ResourceList{AMD.ResourceName: resource.MustParse("4")}),
+		newNode("gpu-c", v1.ResourceList{NVIDIA.ResourceName: resource.MustParse("8"), AMD.ResourceName: resource.MustParse("0")}),
+		newNode("cpu", v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")}),
+	}
+	// The vendors selected by -ai.vendor don't matter.
+	vendorFilter.Vendor = "amd"
+	defer func() { vendorFilter.Vendor = "" }()
+	want := map[string][]string{"nvidia": {"gpu-a", "gpu-c"}, "amd": {"gpu-b"}}
+	if got := AcceleratorNodesByVendor(nodes); !reflect.DeepEqual(got, want) {
+		t.Errorf("AcceleratorNodesByVendor() = %v, want %v", got, want)
+	}
+}
+
+func TestPreferredAcceleratorResource(t *testing.T) {
+	amdNode := newNodeWithCapacity(v1.ResourceList{AMD.ResourceName: resource.MustParse("4")})
+	cpuNode := newNodeWithCapacity(v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")})
+
+	tests := []struct {
+		name          string
+		nodes         []v1.Node
+		resourceNames string
+		want          v1.ResourceName
+	}{
+		{
+			name:  "detected vendor",
+			nodes: []v1.Node{cpuNode, amdNode},
+			want:  AMD.ResourceName,
+		},
+		{
+			name:  "nvidia if none is detected",
+			nodes: []v1.Node{cpuNode},
+			want:  NVIDIA.ResourceName,
+		},
+		{
+			name:          "first candidate if none is detected",
+			nodes:         []v1.Node{cpuNode},
+			resourceNames: "example.com/gpu,nvidia.com/gpu",
+			want:          "example.com/gpu",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accelerator.ResourceNames = tt.resourceNames
+			defer func() { accelerator.ResourceNames = "" }()
+			if got := PreferredAcceleratorResource(tt.nodes); got != tt.want {
+				t.Errorf("PreferredAcceleratorResource() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAcceleratorProducts(t *testing.T) {
+	newNode := func(product string, capacity v1.ResourceList) v1.Node {
+		node := newNodeWithCapacity(capacity)
+		if product != "" {
+			node.Labels = map[string]string{NVIDIA.ProductLabel: product}
+		}
+		return node
+	}
+	gpus := v1.ResourceList{NVIDIA.ResourceName: resource.MustParse("8")}
+
+	tests := []struct {
+		name  string
+		nodes []v1.Node
+		want  []string
+	}{
+		{
+			name:  "most common first",
+			nodes: []v1.Node{newNode("NVIDIA-A100-SXM4-40GB", gpus), newNode("Tesla-T4", gpus), newNode("Tesla-T4", gpus)},
+			want:  []string{"Tesla-T4", "NVIDIA-A100-SXM4-40GB"},
+		},
+		{
+			name:  "ties in alphabetical order",
+			nodes: []v1.Node{newNode("Tesla-T4", gpus), newNode("NVIDIA-L4", gpus)},
+			want:  []string{"NVIDIA-L4", "Tesla-T4"},
+		},
+		{
+			name: "nodes without the resource are ignored",
+			nodes: []v1.Node{
+				newNode("Tesla-T4", v1.ResourceList{NVIDIA.ResourceName: resource.MustParse("0")}),
+				newNode("NVIDIA-L4", v1.ResourceList{AMD.ResourceName: resource.MustParse("4")}),
+				newNode("NVIDIA-A100-SXM4-40GB", gpus),
+			},
+			want: []string{"NVIDIA-A100-SXM4-40GB"},
+		},
+		{
+			name:  "no product label",
+			nodes: []v1.Node{newNode("", gpus)},
+			want:  []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AcceleratorProducts(tt.nodes, NVIDIA.ProductLabel, NVIDIA.ResourceName); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AcceleratorProducts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAcceleratorVendorMissingCoreMetrics(t *testing.T) {
+	nvidia := withDefaultExpectedMetrics(t, NVIDIA)
+	amd := withDefaultExpectedMetrics(t, AMD)
+	tests := []struct {
+		name   string
+		vendor AcceleratorVendor
+		names  []string
+		want   []string
+	}{
+		{
+			name:   "nvidia core metrics are present",
+			vendor: nvidia,
+			names:  []string{"DCGM_FI_DEV_FB_FREE", "DCGM_FI_DEV_FB_USED", "DCGM_FI_DEV_GPU_UTIL"},
+		},
+		{
+			name:   "no metrics",
+			vendor: nvidia,
+			want:   []string{"DCGM_FI_DEV_GPU_UTIL", "DCGM_FI_DEV_FB_USED"},
+		},
+		{
+			name:   "names must match exactly",
+			vendor: nvidia,
+			names:  []string{"DCGM_FI_DEV_GPU_UTIL_TOTAL", "DCGM_FI_DEV_FB_USED"},
+			want:   []string{"DCGM_FI_DEV_GPU_UTIL"},
+		},
+		{
+			name:   "amd smi exporter",
+			vendor: amd,
+			names:  []string{"amd_gpu_use_percent", "amd_gpu_memory_use_percent"},
+		},
+		{
+			name:   "amd device metrics exporter",
+			vendor: amd,
+			names:  []string{"gpu_gfx_activity", "gpu_used_vram", "gpu_total_vram"},
+		},
+		{
+			name:   "alternatives of different exporters can be mixed",
+			vendor: amd,
+			names:  []string{"amd_gpu_use_percent", "gpu_used_vram"},
+		},
+		{
+			name:   "amd memory usage is missing",
+			vendor: amd,
+			names:  []string{"gpu_gfx_activity", "gpu_total_vram"},
+			want:   []string{"amd_gpu_memory_use_percent|gpu_used_vram"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.vendor.MissingCoreMetrics(tt.names); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MissingCoreMetrics() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAcceleratorVendorMissingRecommendedMetrics(t *testing.T) {
+	nvidia := withDefaultExpectedMetrics(t, NVIDIA)
+	amd := withDefaultExpectedMetrics(t, AMD)
+	tests := []struct {
+		name   string
+		vendor AcceleratorVendor
+		names  []string
+		want   []string
+	}{
+		{
+			name:   "nvidia recommended metrics are present",
+			vendor: nvidia,
+			names:  []string{"DCGM_FI_DEV_GPU_TEMP", "DCGM_FI_DEV_GPU_UTIL", "DCGM_FI_DEV_POWER_USAGE"},
+		},
+		{
+			name:   "nvidia power usage is missing",
+			vendor: nvidia,
+			names:  []string{"DCGM_FI_DEV_GPU_TEMP", "DCGM_FI_DEV_GPU_UTIL"},
+			want:   []string{"DCGM_FI_DEV_POWER_USAGE"},
+		},
+		{
+			name:   "amd device metrics exporter",
+			vendor: amd,
+			names:  []string{"gpu_edge_temperature", "gpu_power_usage"},
+		},
+		{
+			name:   "amd temperature is missing",
+			vendor: amd,
+			names:  []string{"amd_gpu_power"},
+			want:   []string{"amd_gpu_edge_temperature|gpu_edge_temperature"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.vendor.MissingRecommendedMetrics(tt.names); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MissingRecommendedMetrics() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequireAcceleratorNode(t *testing.T) {
+	newNode := func(hostname string, capacity v1.ResourceList) v1.Node {
+		node := newNodeWithCapacity(capacity)
+		node.Labels = map[string]string{v1.LabelHostname: hostname}
+		return node
+	}
+	nodes := []v1.Node{
+		newNode("gpu-a", v1.ResourceList{NVIDIA.ResourceName: resource.MustParse("8")}),
+		newNode("cpu", v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")}),
+		newNode("gpu-b", v1.ResourceList{NVIDIA.ResourceName: resource.MustParse("8")}),
+	}
+	acceleratorNodes := v1.NodeSelectorRequirement{Key: v1.LabelHostname, Operator: v1.NodeSelectorOpIn, Values: []string{"gpu-a", "gpu-b"}}
+	zone := v1.NodeSelectorRequirement{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"a"}}
+	arch := v1.NodeSelectorRequirement{Key: v1.LabelArchStable, Operator: v1.NodeSelectorOpIn, Values: []string{"amd64"}}
+	withTerms := func(terms ...v1.NodeSelectorTerm) *v1.Affinity {
+		return &v1.Affinity{NodeAffinity: &v1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{NodeSelectorTerms: terms},
+		}}
+	}
+
+	tests := []struct {
+		name            string
+		spec            v1.PodSpec
+		nodes           []v1.Node
+		resourceName    v1.ResourceName
+		nodeSelector    string
+		wantAffinity    *v1.Affinity
+		wantTolerations []v1.Toleration
+		wantErr         bool
+	}{
+		{
+			name:            "empty spec",
+			nodes:           nodes,
+			resourceName:    NVIDIA.ResourceName,
+			wantAffinity:    withTerms(v1.NodeSelectorTerm{MatchExpressions: []v1.NodeSelectorRequirement{acceleratorNodes}}),
+			wantTolerations: []v1.Toleration{acceleratorNodeToleration},
+		},
+		{
+			name: "existing terms are narrowed down",
+			spec: v1.PodSpec{
+				Affinity: withTerms(
+					v1.NodeSelectorTerm{MatchExpressions: []v1.NodeSelectorRequirement{zone}},
+					v1.NodeSelectorTerm{MatchExpressions: []v1.NodeSelectorRequirement{arch}},
+				),
+				Tolerations: []v1.Toleration{acceleratorNodeToleration},
+			},
+			nodes:        nodes,
+			resourceName: NVIDIA.ResourceName,
+			wantAffinity: withTerms(
+				v1.NodeSelectorTerm{MatchExpressions: []v1.NodeSelectorRequirement{zone, acceleratorNodes}},
+				v1.NodeSelectorTerm{MatchExpressions: []v1.NodeSelectorRequirement{arch, acceleratorNodes}},
+			),
+			wantTolerations: []v1.Toleration{acceleratorNodeToleration},
+		},
+		{
+			name:         "no node advertises the resource",
+			nodes:        nodes,
+			resourceName: AMD.ResourceName,
+			wantErr:      true,
+		},
+		{
+			name:         "only the selected nodes are required",
+			nodes:        nodes,
+			resourceName: NVIDIA.ResourceName,
+			nodeSelector: v1.LabelHostname + "=gpu-b",
+			wantAffinity: withTerms(v1.NodeSelectorTerm{MatchExpressions: []v1.NodeSelectorRequirement{
+				{Key: v1.LabelHostname, Operator: v1.NodeSelectorOpIn, Values: []string{"gpu-b"}},
+			}}),
+			wantTolerations: []v1.Toleration{acceleratorNodeToleration},
+		},
+		{
+			name:         "no selected node advertises the resource",
+			nodes:        nodes,
+			resourceName: NVIDIA.ResourceName,
+			nodeSelector: v1.LabelHostname + "=cpu",
+			wantErr:      true,
+		},
+		{
+			name:         "invalid node selector",
+			nodes:        nodes,
+			resourceName: NVIDIA.ResourceName,
+			nodeSelector: "a=b=c",
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accelerator.NodeSelector = tt.nodeSelector
+			defer func() { accelerator.NodeSelector = "" }()
+			err := RequireAcceleratorNode(&tt.spec, tt.nodes, tt.resourceName)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RequireAcceleratorNode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(tt.spec.Affinity, tt.wantAffinity) {
+				t.Errorf("affinity = %+v, want %+v", tt.spec.Affinity, tt.wantAffinity)
+			}
+			if !reflect.DeepEqual(tt.spec.Tolerations, tt.wantTolerations) {
+				t.Errorf("tolerations = %v, want %v", tt.spec.Tolerations, tt.wantTolerations)
+			}
+		})
+	}
+}
+
+func TestUnhealthyAccelerators(t *testing.T) {
+	newNode := func(name string, capacity, allocatable string) v1.Node {
+		node := v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"pool": "gpu"}}}
+		if capacity != "" {
+			node.Status.Capacity = v1.ResourceList{NVIDIA.ResourceName: resource.MustParse(capacity)}
+		}
+		if allocatable != "" {
+			node.Status.Allocatable = v1.ResourceList{NVIDIA.ResourceName: resource.MustParse(allocatable)}
+		}
+		return node
+	}
+	cpuNode := newNode("cpu", "", "")
+	cpuNode.Labels = nil
+
+	tests := []struct {
+		name         string
+		nodes        []v1.Node
+		nodeSelector string
+		want         map[string]int
+		wantErr      bool
+	}{
+		{
+			name:  "healthy",
+			nodes: []v1.Node{newNode("gpu-a", "8", "8"), cpuNode},
+			want:  map[string]int{},
+		},
+		{
+			name:  "unhealthy devices are excluded from allocatable",
+			nodes: []v1.Node{newNode("gpu-a", "8", "6"), newNode("gpu-b", "8", "8")},
+			want:  map[string]int{"gpu-a": 2},
+		},
+		{
+			name:  "allocatable is missing",
+			nodes: []v1.Node{newNode("gpu-a", "4", "")},
+			want:  map[string]int{"gpu-a": 4},
+		},
+		{
+			name:         "only the selected nodes are checked",
+			nodes:        []v1.Node{newNode("gpu-a", "8", "6"), cpuNode},
+			nodeSelector: "pool!=gpu",
+			want:         map[string]int{},
+		},
+		{
+			name:         "invalid node selector",
+			nodes:        []v1.Node{newNode("gpu-a", "8", "6")},
+			nodeSelector: "a=b=c",
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accelerator.NodeSelector = tt.nodeSelector
+			defer func() { accelerator.NodeSelector = "" }()
+			got, err := UnhealthyAccelerators(tt.nodes, NVIDIA.ResourceName)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UnhealthyAccelerators() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UnhealthyAccelerators() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckAcceleratorCapacity(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	newNode := func(readySince *time.Time, capacity, allocatable string) *v1.Node {
+		node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "gpu"}}
+		if readySince != nil {
+			node.Status.Conditions = []v1.NodeCondition{
+				{Type: v1.NodeReady, Status: v1.ConditionTrue, LastTransitionTime: metav1.NewTime(*readySince)},
+			}
+		}
+		if capacity != "" {
+			node.Status.Capacity = v1.ResourceList{NVIDIA.ResourceName: resource.MustParse(capacity)}
+		}
+		if allocatable != "" {
+			node.Status.Allocatable = v1.ResourceList{NVIDIA.ResourceName: resource.MustParse(allocatable)}
+		}
+		return node
+	}
+	recently := now.Add(-time.Minute)
+	longAgo := now.Add(-time.Hour)
+
+	tests := []struct {
+		name    string
+		node    *v1.Node
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "advertised",
+			node: newNode(&longAgo, "8", "8"),
+			want: true,
+		},
+		{
+			name: "not ready yet",
+			node: newNode(nil, "", ""),
+		},
+		{
+			name: "ready recently",
+			node: newNode(&recently, "", ""),
+		},
+		{
+			name: "allocatable is not updated yet",
+			node: newNode(&recently, "8", "0"),
+		},
+		{
+			name:    "ready for too long",
+			node:    newNode(&longAgo, "", ""),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := checkAcceleratorCapacity(tt.node, NVIDIA.ResourceName, 5*time.Minute, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkAcceleratorCapacity() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("checkAcceleratorCapacity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsAcceleratorNodeCandidate(t *testing.T) {
+	gpuNode := newNodeWithCapacity(v1.ResourceList{NVIDIA.ResourceName: resource.MustParse("8")})
+	poolNode := v1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"pool": "gpu"}}}
+
+	tests := []struct {
+		name         string
+		node         v1.Node
+		nodeSelector string
+		want         bool
+		wantErr      bool
+	}{
+		{
+			name: "advertises the resource",
+			node: gpuNode,
+			want: true,
+		},
+		{
+			name: "doesn't advertise the resource",
+			node: poolNode,
+		},
+		{
+			name:         "selected before advertising the resource",
+			node:         poolNode,
+			nodeSelector: "pool=gpu",
+			want:         true,
+		},
+		{
+			name:         "not selected",
+			node:         gpuNode,
+			nodeSelector: "pool=gpu",
+		},
+		{
+			name:         "invalid node selector",
+			node:         gpuNode,
+			nodeSelector: "a=b=c",
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accelerator.NodeSelector = tt.nodeSelector
+			defer func() { accelerator.NodeSelector = "" }()
+			got, err := IsAcceleratorNodeCandidate(&tt.node, NVIDIA.ResourceName)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("IsAcceleratorNodeCandidate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("IsAcceleratorNodeCandidate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsSchedulableAcceleratorNode(t *testing.T) {
+	node := v1.Node{}
+	cordoned := v1.Node{Spec: v1.NodeSpec{Unschedulable: true}}
+	karpenterNode := v1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"karpenter.sh/nodepool": "gpu"}}}
+	provisionerNode := v1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"karpenter.sh/provisioner-name": "gpu"}}}
+
+	tests := []struct {
+		name                   string
+		node                   v1.Node
+		excludeAutoscalerNodes bool
+		want                   bool
+	}{
+		{
+			name: "schedulable",
+			node: node,
+			want: true,
+		},
+		{
+			name: "cordoned",
+			node: cordoned,
+		},
+		{
+			name: "autoscaler node is included",
+			node: karpenterNode,
+			want: true,
+		},
+		{
+			name:                   "autoscaler node is excluded",
+			node:                   karpenterNode,
+			excludeAutoscalerNodes: true,
+		},
+		{
+			name:                   "node of a provisioner is excluded",
+			node:                   provisionerNode,
+			excludeAutoscalerNodes: true,
+		},
+		{
+			name:                   "static node is not excluded",
+			node:                   node,
+			excludeAutoscalerNodes: true,
+			want:                   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isSchedulableAcceleratorNode(&tt.node, tt.excludeAutoscalerNodes); got != tt.want {
+				t.Errorf("isSchedulableAcceleratorNode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnreleasedAccelerators(t *testing.T) {
+	baseline := &AcceleratorCount{
+		Allocatable: 8,
+		Used:        2,
+		Allocations: []AcceleratorAllocation{{Pod: "other/trainer", Node: "gpu-a", Count: 2}},
+	}
+
+	tests := []struct {
+		name    string
+		current *AcceleratorCount
+		want    []string
+	}{
+		{
+			name:    "released",
+			current: baseline,
+		},
+		{
+			name: "allocated to a pod of the spec",
+			current: &AcceleratorCount{
+				Allocatable: 8,
+				Used:        3,
+				Allocations: []AcceleratorAllocation{
+					{Pod: "other/trainer", Node: "gpu-a", Count: 2},
+					{Pod: "e2e/gpu-pod", Node: "gpu-b", Count: 1},
+				},
+			},
+			want: []string{"1 allocated to pod e2e/gpu-pod on node gpu-b"},
+		},
+		{
+			name: "no longer allocatable",
+			current: &AcceleratorCount{
+				Allocatable: 7,
+				Used:        2,
+				Allocations: []AcceleratorAllocation{{Pod: "other/trainer", Node: "gpu-a", Count: 2}},
+			},
+			want: []string{"1 no longer allocatable"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := unreleasedAccelerators(baseline, tt.current)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("unreleasedAccelerators() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPhysicalAccelerators(t *testing.T) {
+	newNode := func(capacity string, labels map[string]string) v1.Node {
+		node := newNodeWithCapacity(v1.ResourceList{NVIDIA.ResourceName: resource.MustParse(capacity)})
+		node.Name = "gpu-node"
+		node.Labels = labels
+		return node
+	}
+	cpuNode := newNodeWithCapacity(v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")})
+
+	tests := []struct {
+		name    string
+		nodes   []v1.Node
+		want    int
+		wantErr bool
+	}{
+		{
+			name:  "capacity",
+			nodes: []v1.Node{newNode("8", nil), newNode("4", nil), cpuNode},
+			want:  12,
+		},
+		{
+			name: "count label of the time-sliced GPUs",
+			nodes: []v1.Node{
+				newNode("16", map[string]string{NVIDIA.CountLabel: "4", "nvidia.com/gpu.replicas": "4"}),
+				newNode("8", nil),
+			},
+			want: 12,
+		},
+		{
+			name:    "invalid count label",
+			nodes:   []v1.Node{newNode("8", map[string]string{NVIDIA.CountLabel: "eight"})},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NVIDIA.PhysicalAccelerators(tt.nodes)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PhysicalAccelerators() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("PhysicalAccelerators() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAcceleratorCountShortageReason(t *testing.T) {
+	count := &AcceleratorCount{Nodes: 2, Capacity: 8, Allocatable: 7, Used: 5}
+
+	tests := []struct {
+		name string
+		n    int
+		want string
+	}{
+		{
+			name: "enough",
+			n:    2,
+		},
+		{
+			name: "short",
+			n:    3,
+			want: "At least 3 nvidia.com/gpu are required, but only 2 are available on 2 ready nodes: capacity 8, allocatable 7, used 5",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := count.ShortageReason(NVIDIA.ResourceName, tt.n); got != tt.want {
+				t.Errorf("ShortageReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyVisibleDevices(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		count   int
+		wantErr bool
+	}{
+		{
+			name:   "single device",
+			output: "PATH=/usr/local/sbin:/usr/local/bin\nNVIDIA_VISIBLE_DEVICES=GPU-8f1c9b2e-0d55-4a0e-9a43-2f6b7c1e9d10\nHOME=/root\n",
+			count:  1,
+		},
+		{
+			name:   "multiple devices",
+			output: "NVIDIA_VISIBLE_DEVICES=GPU-0,GPU-1\n",
+			count:  2,
+		},
+		{
+			name:    "all devices",
+			output:  "NVIDIA_VISIBLE_DEVICES=all\n",
+			count:   1,
+			wantErr: true,
+		},
+		{
+			name:    "no device",
+			output:  "NVIDIA_VISIBLE_DEVICES=void\n",
+			count:   1,
+			wantErr: true,
+		},
+		{
+			name:    "more devices than allocated",
+			output:  "NVIDIA_VISIBLE_DEVICES=GPU-0,GPU-1\n",
+			count:   1,
+			wantErr: true,
+		},
+		{
+			name:    "not set",
+			output:  "PATH=/usr/local/sbin:/usr/local/bin\nNVIDIA_DRIVER_CAPABILITIES=compute,utility\n",
+			count:   1,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyVisibleDevices(tt.output, "NVIDIA_VISIBLE_DEVICES", tt.count)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifyVisibleDevices() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMalformedAcceleratorQuantities(t *testing.T) {
+	newNode := func(name string, capacity, allocatable string) v1.Node {
+		node := v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"pool": "gpu"}}}
+		if capacity != "" {
+			node.Status.Capacity = v1.ResourceList{NVIDIA.ResourceName: resource.MustParse(capacity)}
+		}
+		if allocatable != "" {
+			node.Status.Allocatable = v1.ResourceList{NVIDIA.ResourceName: resource.MustParse(allocatable)}
+		}
+		return node
+	}
+
+	tests := []struct {
+		name         string
+		nodes        []v1.Node
+		nodeSelector string
+		want         map[string]string
+		wantErr      bool
+	}{
+		{
+			name:  "well-formed",
+			nodes: []v1.Node{newNode("gpu-a", "8", "8"), newNode("gpu-b", "8", "6"), newNode("cpu", "", "")},
+			want:  map[string]string{},
+		},
+		{
+			name:  "fractional quantities",
+			nodes: []v1.Node{newNode("gpu-a", "1500m", "1500m"), newNode("gpu-b", "8", "0.5")},
+			want: map[string]string{
+				"gpu-a": "capacity 1500m is not an integer, allocatable 1500m is not an integer",
+				"gpu-b": "allocatable 500m is not an integer",
+			},
+		},
+		{
+			name:  "negative allocatable",
+			nodes: []v1.Node{newNode("gpu-a", "8", "-1")},
+			want:  map[string]string{"gpu-a": "allocatable -1 is negative"},
+		},
+		{
+			name:  "allocatable exceeds capacity",
+			nodes: []v1.Node{newNode("gpu-a", "4", "8")},
+			want:  map[string]string{"gpu-a": "allocatable 8 exceeds capacity 4"},
+		},
+		{
+			name:  "capacity is missing",
+			nodes: []v1.Node{newNode("gpu-a", "", "8")},
+			want:  map[string]string{"gpu-a": "capacity is missing"},
+		},
+		{
+			name:         "only the selected nodes are checked",
+			nodes:        []v1.Node{newNode("gpu-a", "4", "8")},
+			nodeSelector: "pool!=gpu",
+			want:         map[string]string{},
+		},
+		{
+			name:         "invalid node selector",
+			nodes:        []v1.Node{newNode("gpu-a", "8", "8")},
+			nodeSelector: "a=b=c",
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accelerator.NodeSelector = tt.nodeSelector
+			defer func() { accelerator.NodeSelector = "" }()
+			got, err := MalformedAcceleratorQuantities(tt.nodes, NVIDIA.ResourceName)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MalformedAcceleratorQuantities() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MalformedAcceleratorQuantities() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
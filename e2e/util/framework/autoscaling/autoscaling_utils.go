@@ -125,15 +125,22 @@ type ResourceConsumer struct {
 	sleepTime                time.Duration
 	requestSizeInMillicores  int
 	requestSizeInMegabytes   int
-	requestSizeCustomMetric  int
+	customMetricLoad         LoadProfile
 	sidecarStatus            SidecarStatusType
 	sidecarType              SidecarWorkloadType
 }
 
 // NewDynamicResourceConsumer is a wrapper to create a new dynamic ResourceConsumer
 func NewDynamicResourceConsumer(ctx context.Context, name, nsName string, kind schema.GroupVersionKind, replicas, initCPUTotal, initMemoryTotal, initCustomMetric int, cpuLimit, memLimit int64, customMetricName string, clientset clientset.Interface, scaleClient scaleclient.ScalesGetter, enableSidecar SidecarStatusType, sidecarType SidecarWorkloadType, podResources *v1.ResourceRequirements) *ResourceConsumer {
+	return NewDynamicResourceConsumerWithLoadProfile(ctx, name, nsName, kind, replicas, initCPUTotal, initMemoryTotal, initCustomMetric, cpuLimit, memLimit, customMetricName, clientset, scaleClient, enableSidecar, sidecarType, podResources, DefaultLoadProfile)
+}
+
+// NewDynamicResourceConsumerWithLoadProfile is a wrapper to create a new dynamic ResourceConsumer which consumes the
+// custom metric with the given load profile
+func NewDynamicResourceConsumerWithLoadProfile(ctx context.Context, name, nsName string, kind schema.GroupVersionKind, replicas, initCPUTotal, initMemoryTotal, initCustomMetric int, cpuLimit, memLimit int64, customMetricName string, clientset clientset.Interface, scaleClient scaleclient.ScalesGetter, enableSidecar SidecarStatusType, sidecarType SidecarWorkloadType, podResources *v1.ResourceRequirements, customMetricLoad LoadProfile) *ResourceConsumer {
+	framework.ExpectNoError(customMetricLoad.Validate())
 	return newResourceConsumer(ctx, name, nsName, kind, replicas, initCPUTotal, initMemoryTotal, initCustomMetric, dynamicConsumptionTimeInSeconds,
-		dynamicRequestSizeInMillicores, dynamicRequestSizeInMegabytes, dynamicRequestSizeCustomMetric, cpuLimit, memLimit, customMetricName, clientset, scaleClient, nil, nil, enableSidecar, sidecarType, podResources)
+		dynamicRequestSizeInMillicores, dynamicRequestSizeInMegabytes, customMetricLoad, cpuLimit, memLimit, customMetricName, clientset, scaleClient, nil, nil, enableSidecar, sidecarType, podResources)
 }
 
 // getSidecarContainer returns sidecar container
@@ -171,7 +178,7 @@ memLimit argument is in megabytes, memLimit is a maximum amount of memory that c
 cpuLimit argument is in millicores, cpuLimit is a maximum amount of cpu that can be consumed by a single pod
 */
 func newResourceConsumer(ctx context.Context, name, nsName string, kind schema.GroupVersionKind, replicas, initCPUTotal, initMemoryTotal, initCustomMetric, consumptionTimeInSeconds, requestSizeInMillicores,
-	requestSizeInMegabytes int, requestSizeCustomMetric int, cpuLimit, memLimit int64, customMetricName string, clientset clientset.Interface, scaleClient scaleclient.ScalesGetter, podAnnotations, serviceAnnotations map[string]string, sidecarStatus SidecarStatusType, sidecarType SidecarWorkloadType, podResources *v1.ResourceRequirements) *ResourceConsumer {
+	requestSizeInMegabytes int, customMetricLoad LoadProfile, cpuLimit, memLimit int64, customMetricName string, clientset clientset.Interface, scaleClient scaleclient.ScalesGetter, podAnnotations, serviceAnnotations map[string]string, sidecarStatus SidecarStatusType, sidecarType SidecarWorkloadType, podResources *v1.ResourceRequirements) *ResourceConsumer {
 	if podAnnotations == nil {
 		podAnnotations = make(map[string]string)
 	}
@@ -223,7 +230,7 @@ func newResourceConsumer(ctx context.Context, name, nsName string, kind schema.G
 		sleepTime:                time.Duration(consumptionTimeInSeconds) * time.Second,
 		requestSizeInMillicores:  requestSizeInMillicores,
 		requestSizeInMegabytes:   requestSizeInMegabytes,
-		requestSizeCustomMetric:  requestSizeCustomMetric,
+		customMetricLoad:         customMetricLoad,
 		sidecarType:              sidecarType,
 		sidecarStatus:            sidecarStatus,
 	}
@@ -330,11 +337,14 @@ func (rc *ResourceConsumer) makeConsumeCustomMetric(ctx context.Context) {
 				framework.Logf("RC %s: disabling consumption of custom metric %s", rc.name, rc.customMetricName)
 			}
 		case <-tick:
+			interval := rc.customMetricLoad.Duration
 			if delta != 0 {
-				framework.Logf("RC %s: sending request to consume %d of custom metric %s", rc.name, delta, rc.customMetricName)
-				rc.sendConsumeCustomMetric(ctx, delta)
+				var requestDelta, requestSize int
+				requestDelta, requestSize, interval = rc.customMetricLoad.request(delta)
+				framework.Logf("RC %s: sending request to consume %d of custom metric %s", rc.name, requestDelta, rc.customMetricName)
+				rc.sendConsumeCustomMetric(ctx, requestDelta, requestSize)
 			}
-			tick = time.After(rc.sleepTime)
+			tick = time.After(interval)
 		case <-ctx.Done():
 			framework.Logf("RC %s: stopping metric consumer: %v", rc.name, ctx.Err())
 			return
@@ -407,7 +417,7 @@ func (rc *ResourceConsumer) sendConsumeMemRequest(ctx context.Context, megabytes
 }
 
 // sendConsumeCustomMetric sends POST request for custom metric consumption
-func (rc *ResourceConsumer) sendConsumeCustomMetric(ctx context.Context, delta int) {
+func (rc *ResourceConsumer) sendConsumeCustomMetric(ctx context.Context, delta, requestSize int) {
 	err := framework.Gomega().Eventually(ctx, func(ctx context.Context) error {
 		proxyRequest, err := e2eservice.GetServicesProxyRequest(rc.clientSet, rc.clientSet.CoreV1().RESTClient().Post())
 		if err != nil {
@@ -418,8 +428,8 @@ func (rc *ResourceConsumer) sendConsumeCustomMetric(ctx context.Context, delta i
 			Suffix("BumpMetric").
 			Param("metric", rc.customMetricName).
 			Param("delta", strconv.Itoa(delta)).
-			Param("durationSec", strconv.Itoa(rc.consumptionTimeInSeconds)).
-			Param("requestSizeMetrics", strconv.Itoa(rc.requestSizeCustomMetric))
+			Param("durationSec", strconv.Itoa(int(rc.customMetricLoad.Duration.Seconds()))).
+			Param("requestSizeMetrics", strconv.Itoa(requestSize))
 		framework.Logf("ConsumeCustomMetric URL: %v", *req.URL())
 		_, err = req.DoRaw(ctx)
 		if err != nil {
@@ -0,0 +1,71 @@
+package autoscaling
+
+import (
+	"fmt"
+	"time"
+)
+
+// LoadMode is how the ResourceConsumer drives the custom metric above the target.
+type LoadMode string
+
+const (
+	// SustainedLoad drives the custom metric by concurrent requests which last for the whole duration of the
+	// profile and are renewed together, like long-lived connections.
+	SustainedLoad LoadMode = "sustained"
+	// RateLoad drives the custom metric by a steady rate of requests, each lasting for the duration of the profile,
+	// so that the overlapping requests sum up to the consumed amount, like the requests in flight of a service.
+	RateLoad LoadMode = "rate"
+)
+
+// LoadProfile is how the ResourceConsumer consumes the custom metric.
+type LoadProfile struct {
+	// Mode is how the custom metric is driven.
+	Mode LoadMode
+	// Duration is how long each request bumps the custom metric.
+	Duration time.Duration
+	// Concurrency is the number of concurrent requests the consumed amount is split into. If it's 0, the amount is
+	// split into requests of dynamicRequestSizeCustomMetric each.
+	Concurrency int
+}
+
+// DefaultLoadProfile is the load profile of the dynamic ResourceConsumer.
+var DefaultLoadProfile = LoadProfile{
+	Mode:     SustainedLoad,
+	Duration: dynamicConsumptionTimeInSeconds * time.Second,
+}
+
+// Validate returns an error if the load profile is invalid.
+func (p LoadProfile) Validate() error {
+	if p.Mode != SustainedLoad && p.Mode != RateLoad {
+		return fmt.Errorf("invalid load mode %q, must be %s or %s", p.Mode, SustainedLoad, RateLoad)
+	}
+	if p.Duration < time.Second {
+		return fmt.Errorf("invalid load duration %v, must be at least 1s", p.Duration)
+	}
+	if p.Concurrency < 0 {
+		return fmt.Errorf("invalid load concurrency %d, must not be negative", p.Concurrency)
+	}
+	return nil
+}
+
+// request returns the amount and the request size of each BumpMetric request sent to the consumer, and the
+// interval between the requests, to consume the given amount of the custom metric.
+func (p LoadProfile) request(amount int) (delta, requestSize int, interval time.Duration) {
+	if p.Mode == RateLoad {
+		concurrency := p.Concurrency
+		if concurrency == 0 {
+			concurrency = ceilDiv(amount, dynamicRequestSizeCustomMetric)
+		}
+		// One request is sent every interval, so the requests in flight sum up to the amount.
+		delta = ceilDiv(amount, concurrency)
+		return delta, delta, p.Duration / time.Duration(concurrency)
+	}
+	if p.Concurrency == 0 {
+		return amount, dynamicRequestSizeCustomMetric, p.Duration
+	}
+	return amount, ceilDiv(amount, p.Concurrency), p.Duration
+}
+
+func ceilDiv(a, b int) int {
+	return (a + b - 1) / b
+}
@@ -0,0 +1,93 @@
+package autoscaling
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadProfileRequest(t *testing.T) {
+	tests := []struct {
+		name            string
+		profile         LoadProfile
+		amount          int
+		wantDelta       int
+		wantRequestSize int
+		wantInterval    time.Duration
+	}{
+		{
+			name:            "default profile",
+			profile:         DefaultLoadProfile,
+			amount:          500,
+			wantDelta:       500,
+			wantRequestSize: dynamicRequestSizeCustomMetric,
+			wantInterval:    30 * time.Second,
+		},
+		{
+			name:            "sustained with concurrency",
+			profile:         LoadProfile{Mode: SustainedLoad, Duration: time.Minute, Concurrency: 3},
+			amount:          500,
+			wantDelta:       500,
+			wantRequestSize: 167,
+			wantInterval:    time.Minute,
+		},
+		{
+			name:            "rate with concurrency",
+			profile:         LoadProfile{Mode: RateLoad, Duration: time.Minute, Concurrency: 4},
+			amount:          500,
+			wantDelta:       125,
+			wantRequestSize: 125,
+			wantInterval:    15 * time.Second,
+		},
+		{
+			name:            "rate without concurrency",
+			profile:         LoadProfile{Mode: RateLoad, Duration: 30 * time.Second},
+			amount:          50,
+			wantDelta:       10,
+			wantRequestSize: 10,
+			wantInterval:    6 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delta, requestSize, interval := tt.profile.request(tt.amount)
+			if delta != tt.wantDelta || requestSize != tt.wantRequestSize || interval != tt.wantInterval {
+				t.Errorf("request() = (%d, %d, %v), want (%d, %d, %v)", delta, requestSize, interval, tt.wantDelta, tt.wantRequestSize, tt.wantInterval)
+			}
+		})
+	}
+}
+
+func TestLoadProfileValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile LoadProfile
+		wantErr bool
+	}{
+		{
+			name:    "default profile",
+			profile: DefaultLoadProfile,
+		},
+		{
+			name:    "invalid mode",
+			profile: LoadProfile{Mode: "burst", Duration: time.Minute},
+			wantErr: true,
+		},
+		{
+			name:    "duration is too short",
+			profile: LoadProfile{Mode: RateLoad, Duration: 500 * time.Millisecond},
+			wantErr: true,
+		},
+		{
+			name:    "negative concurrency",
+			profile: LoadProfile{Mode: SustainedLoad, Duration: time.Minute, Concurrency: -1},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.profile.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
+
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2econfig "k8s.io/kubernetes/test/e2e/framework/config"
+	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
+)
+
+var checkpoint struct {
+	BuildahImage     string `default:"quay.io/buildah/stable:v1.39" usage:"image with buildah which converts the checkpoint archive of a container into an image on the node"`
+	InsecureRegistry bool   `default:"false" usage:"if true, the checkpoint image is pushed to -ai.checkpoint.registry without TLS verification"`
+}
+var _ = e2econfig.AddOptions(&checkpoint, "ai.checkpoint")
+
+const (
+	// checkpointsDir is the directory where the kubelet stores the checkpoint archives.
+	checkpointsDir = "/var/lib/kubelet/checkpoints"
+	// CheckpointAnnotation tells CRI-O to restore the container from the checkpoint image instead of creating it.
+	CheckpointAnnotation = "io.kubernetes.cri-o.annotations.checkpoint.name"
+)
+
+// CheckpointContainer checkpoints the container of the pod via the checkpoint API of the kubelet on the node, and
+// returns the path of the checkpoint archive on the node. An empty path is returned if the kubelet doesn't serve
+// the API, e.g. the ContainerCheckpoint feature gate is disabled.
+func CheckpointContainer(ctx context.Context, client clientset.Interface, nodeName, namespace, podName, containerName string) (string, error) {
+	data, err := client.CoreV1().RESTClient().Post().Resource("nodes").Name(nodeName).SubResource("proxy").
+		Suffix("checkpoint", namespace, podName, containerName).DoRaw(ctx)
+	if apierrors.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("error when checkpointing container %s of pod %s/%s on node %s: %w, response: %s", containerName, namespace, podName, nodeName, err, string(data))
+	}
+	return decodeCheckpointResponse(data)
+}
+
+// decodeCheckpointResponse returns the only checkpoint archive in the response of the checkpoint API.
+func decodeCheckpointResponse(data []byte) (string, error) {
+	var resp struct {
+		Items []string `json:"items"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return "", fmt.Errorf("error when decoding the checkpoint response %s: %w", string(data), err)
+	}
+	if len(resp.Items) != 1 || resp.Items[0] == "" {
+		return "", fmt.Errorf("expected 1 checkpoint archive in the response %s", string(data))
+	}
+	return resp.Items[0], nil
+}
+
+// checkpointImageScript returns the script which builds the image of the checkpoint archive for the container and
+// pushes it as the given image. The archive is mounted at checkpointsDir, and removed on exit as it holds the
+// memory of the container.
+func checkpointImageScript(archive, containerName, image string, insecure bool) string {
+	return fmt.Sprintf(`set -e; trap 'rm -f %[1]q' EXIT; ctr=$(buildah from scratch); `+
+		`buildah add "$ctr" %[1]q /; `+
+		`buildah config --annotation=%[2]s=%[3]s "$ctr"; `+
+		`buildah commit "$ctr" %[4]q; `+
+		`buildah push --tls-verify=%[5]t %[4]q`,
+		path.Join(checkpointsDir, path.Base(archive)), CheckpointAnnotation, containerName, image, !insecure)
+}
+
+// BuildCheckpointImage converts the checkpoint archive of the container on the node into the given image, and
+// pushes it, by a privileged pod created in the namespace with -ai.checkpoint.buildahImage. The archive is removed
+// from the node.
+func BuildCheckpointImage(ctx context.Context, client clientset.Interface, namespace, nodeName, archive, containerName, image string) error {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: "checkpoint-image-"},
+		Spec: v1.PodSpec{
+			NodeName:      nodeName,
+			RestartPolicy: v1.RestartPolicyNever,
+			Tolerations:   []v1.Toleration{{Operator: v1.TolerationOpExists}},
+			Containers: []v1.Container{
+				{
+					Name:    "buildah",
+					Image:   checkpoint.BuildahImage,
+					Command: []string{"/bin/sh", "-c", checkpointImageScript(archive, containerName, image, checkpoint.InsecureRegistry)},
+					SecurityContext: &v1.SecurityContext{
+						Privileged: ptr.To(true),
+						RunAsUser:  ptr.To[int64](0),
+					},
+					VolumeMounts: []v1.VolumeMount{{Name: "checkpoints", MountPath: checkpointsDir}},
+				},
+			},
+			Volumes: []v1.Volume{
+				{
+					Name: "checkpoints",
+					VolumeSource: v1.VolumeSource{
+						HostPath: &v1.HostPathVolumeSource{Path: checkpointsDir, Type: ptr.To(v1.HostPathDirectory)},
+					},
+				},
+			},
+		},
+	}
+	pod, err := client.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("error when creating pod to build the checkpoint image: %w", err)
+	}
+	DeferCleanup(client.CoreV1().Pods(namespace).Delete, pod.Name, metav1.DeleteOptions{})
+
+	err = e2epod.WaitForPodSuccessInNamespace(ctx, client, pod.Name, namespace)
+	output, logErr := e2epod.GetPodLogs(ctx, client, namespace, pod.Name, pod.Spec.Containers[0].Name)
+	if err != nil {
+		return fmt.Errorf("error when building the checkpoint image %s of %s on node %s: %w, output: %s", image, archive, nodeName, err, output)
+	}
+	if logErr != nil {
+		return fmt.Errorf("error when getting logs of pod %s: %w", pod.Name, logErr)
+	}
+	framework.Logf("Built checkpoint image %s of %s on node %s: %s", image, archive, nodeName, output)
+	return nil
+}
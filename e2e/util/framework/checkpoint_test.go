@@ -0,0 +1,57 @@
+package framework
+
+import (
+	"testing"
+)
+
+func TestDecodeCheckpointResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "archive",
+			data: `{"items":["/var/lib/kubelet/checkpoints/checkpoint-counter_default-counter-2025-01-02T03:04:05Z.tar"]}`,
+			want: "/var/lib/kubelet/checkpoints/checkpoint-counter_default-counter-2025-01-02T03:04:05Z.tar",
+		},
+		{
+			name:    "no archive",
+			data:    `{"items":[]}`,
+			wantErr: true,
+		},
+		{
+			name:    "multiple archives",
+			data:    `{"items":["a.tar","b.tar"]}`,
+			wantErr: true,
+		},
+		{
+			name:    "malformed",
+			data:    `checkpointing failed`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeCheckpointResponse([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeCheckpointResponse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("decodeCheckpointResponse() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckpointImageScript(t *testing.T) {
+	got := checkpointImageScript("/var/lib/kubelet/checkpoints/checkpoint-counter.tar", "counter", "registry.example.com/checkpoints/counter:1", true)
+	want := `set -e; trap 'rm -f "/var/lib/kubelet/checkpoints/checkpoint-counter.tar"' EXIT; ctr=$(buildah from scratch); buildah add "$ctr" "/var/lib/kubelet/checkpoints/checkpoint-counter.tar" /; ` +
+		`buildah config --annotation=io.kubernetes.cri-o.annotations.checkpoint.name=counter "$ctr"; ` +
+		`buildah commit "$ctr" "registry.example.com/checkpoints/counter:1"; ` +
+		`buildah push --tls-verify=false "registry.example.com/checkpoints/counter:1"`
+	if got != want {
+		t.Errorf("checkpointImageScript() = %s, want %s", got, want)
+	}
+}
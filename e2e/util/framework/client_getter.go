@@ -1,6 +1,8 @@
 package framework
 
 import (
+	"fmt"
+
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/cli-runtime/pkg/resource"
 	"k8s.io/client-go/discovery"
@@ -20,9 +22,23 @@ type clientGetter struct {
 var _ resource.RESTClientGetter = &clientGetter{}
 
 func NewClientGetter(f *framework.Framework) *clientGetter {
-	discoveryCache := memory.NewMemCacheClient(f.ClientSet.Discovery())
+	return newClientGetter(f.ClientConfig(), f.ClientSet.Discovery())
+}
+
+// NewClientGetterForConfig returns the RESTClientGetter of the given config for the callers without a Framework,
+// e.g. to build the resources of a manifest with k8s.io/cli-runtime outside of a spec.
+func NewClientGetterForConfig(restConfig *rest.Config) (*clientGetter, error) {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error when creating discovery client: %w", err)
+	}
+	return newClientGetter(restConfig, discoveryClient), nil
+}
+
+func newClientGetter(restConfig *rest.Config, discoveryClient discovery.DiscoveryInterface) *clientGetter {
+	discoveryCache := memory.NewMemCacheClient(discoveryClient)
 	return &clientGetter{
-		restConfig:     f.ClientConfig(),
+		restConfig:     restConfig,
 		discoveryCache: discoveryCache,
 		restMapper:     restmapper.NewDeferredDiscoveryRESTMapper(discoveryCache),
 	}
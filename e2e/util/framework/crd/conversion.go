@@ -0,0 +1,65 @@
+package crd
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// ServedVersions returns the names of the versions served by the CRD.
+func ServedVersions(crd *apiextensionsv1.CustomResourceDefinition) []string {
+	var versions []string
+	for _, version := range crd.Spec.Versions {
+		if version.Served {
+			versions = append(versions, version.Name)
+		}
+	}
+	return versions
+}
+
+// StorageVersion returns the name of the version in which the custom resources of the CRD are persisted, or an
+// empty string if there is none.
+func StorageVersion(crd *apiextensionsv1.CustomResourceDefinition) string {
+	for _, version := range crd.Spec.Versions {
+		if version.Storage {
+			return version.Name
+		}
+	}
+	return ""
+}
+
+// ConversionProblem returns the reason why the custom resources of the CRD which serves multiple versions can't be
+// converted between them losslessly, or an empty string if they can or only one version is served. The None
+// strategy only changes the apiVersion of the custom resources, so it's only lossless if the served versions share
+// the same schema. Otherwise, a conversion webhook must be configured.
+func ConversionProblem(crd *apiextensionsv1.CustomResourceDefinition) string {
+	served := ServedVersions(crd)
+	if len(served) <= 1 {
+		return ""
+	}
+	if StorageVersion(crd) == "" {
+		return fmt.Sprintf("CRD %s serves versions %s but has no storage version", crd.Name, strings.Join(served, ", "))
+	}
+	conversion := crd.Spec.Conversion
+	if conversion != nil && conversion.Strategy == apiextensionsv1.WebhookConverter {
+		if conversion.Webhook == nil || conversion.Webhook.ClientConfig == nil {
+			return fmt.Sprintf("CRD %s uses the Webhook conversion strategy but configures no webhook", crd.Name)
+		}
+		return ""
+	}
+	var schemas []*apiextensionsv1.CustomResourceValidation
+	for _, version := range crd.Spec.Versions {
+		if version.Served {
+			schemas = append(schemas, version.Schema)
+		}
+	}
+	for _, schema := range schemas[1:] {
+		if !reflect.DeepEqual(schemas[0], schema) {
+			return fmt.Sprintf("CRD %s serves versions %s with different schemas but has no conversion webhook",
+				crd.Name, strings.Join(served, ", "))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,84 @@
+package crd
+
+import (
+	"testing"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newSchema(properties ...string) *apiextensionsv1.CustomResourceValidation {
+	schema := &apiextensionsv1.JSONSchemaProps{Type: "object", Properties: map[string]apiextensionsv1.JSONSchemaProps{}}
+	for _, property := range properties {
+		schema.Properties[property] = apiextensionsv1.JSONSchemaProps{Type: "string"}
+	}
+	return &apiextensionsv1.CustomResourceValidation{OpenAPIV3Schema: schema}
+}
+
+func newCRD(conversion *apiextensionsv1.CustomResourceConversion, versions ...apiextensionsv1.CustomResourceDefinitionVersion) *apiextensionsv1.CustomResourceDefinition {
+	return &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "jobs.example.com"},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Versions:   versions,
+			Conversion: conversion,
+		},
+	}
+}
+
+func TestConversionProblem(t *testing.T) {
+	v1alpha1 := apiextensionsv1.CustomResourceDefinitionVersion{Name: "v1alpha1", Served: true, Schema: newSchema("image")}
+	v1 := apiextensionsv1.CustomResourceDefinitionVersion{Name: "v1", Served: true, Storage: true, Schema: newSchema("image")}
+	v1Extended := apiextensionsv1.CustomResourceDefinitionVersion{Name: "v1", Served: true, Storage: true, Schema: newSchema("image", "command")}
+	webhook := &apiextensionsv1.CustomResourceConversion{
+		Strategy: apiextensionsv1.WebhookConverter,
+		Webhook: &apiextensionsv1.WebhookConversion{
+			ClientConfig:             &apiextensionsv1.WebhookClientConfig{Service: &apiextensionsv1.ServiceReference{Namespace: "operator", Name: "webhook"}},
+			ConversionReviewVersions: []string{"v1"},
+		},
+	}
+	tests := []struct {
+		name    string
+		crd     *apiextensionsv1.CustomResourceDefinition
+		problem bool
+	}{
+		{
+			name: "single version",
+			crd:  newCRD(nil, v1Extended),
+		},
+		{
+			name: "unserved version with different schema",
+			crd:  newCRD(nil, apiextensionsv1.CustomResourceDefinitionVersion{Name: "v1alpha1", Schema: newSchema("image")}, v1Extended),
+		},
+		{
+			name: "same schema without webhook",
+			crd:  newCRD(&apiextensionsv1.CustomResourceConversion{Strategy: apiextensionsv1.NoneConverter}, v1alpha1, v1),
+		},
+		{
+			name:    "different schemas without webhook",
+			crd:     newCRD(nil, v1alpha1, v1Extended),
+			problem: true,
+		},
+		{
+			name: "different schemas with webhook",
+			crd:  newCRD(webhook, v1alpha1, v1Extended),
+		},
+		{
+			name:    "webhook strategy without webhook",
+			crd:     newCRD(&apiextensionsv1.CustomResourceConversion{Strategy: apiextensionsv1.WebhookConverter}, v1alpha1, v1Extended),
+			problem: true,
+		},
+		{
+			name:    "no storage version",
+			crd:     newCRD(webhook, v1alpha1, apiextensionsv1.CustomResourceDefinitionVersion{Name: "v1", Served: true, Schema: newSchema("image")}),
+			problem: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ConversionProblem(tt.crd)
+			if (got != "") != tt.problem {
+				t.Errorf("ConversionProblem() = %q, want problem %v", got, tt.problem)
+			}
+		})
+	}
+}
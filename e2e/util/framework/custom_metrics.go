@@ -0,0 +1,103 @@
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
+
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+// customMetricsAPIPath is the path of the custom metrics API served by the metrics adapter, e.g. prometheus-adapter.
+const customMetricsAPIPath = "/apis/custom.metrics.k8s.io/v1beta1"
+
+// customMetricValue is a value of a custom metric for an object. Only the fields used by the tests are decoded,
+// so that the custom metrics API types are not required.
+type customMetricValue struct {
+	DescribedObject struct {
+		Kind string `json:"kind"`
+		Name string `json:"name"`
+	} `json:"describedObject"`
+	MetricName string            `json:"metricName"`
+	Value      resource.Quantity `json:"value"`
+}
+
+// decodeCustomMetricValues decodes the items of a MetricValueList returned by the custom metrics API.
+func decodeCustomMetricValues(data []byte) ([]customMetricValue, error) {
+	var list struct {
+		Kind  string              `json:"kind"`
+		Items []customMetricValue `json:"items"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("error when decoding the custom metrics response %q: %w", string(data), err)
+	}
+	if list.Kind != "MetricValueList" {
+		return nil, fmt.Errorf("unexpected kind %q in the custom metrics response", list.Kind)
+	}
+	return list.Items, nil
+}
+
+// WaitForCustomPodMetric waits until the custom metrics API returns a value of the given metric for a pod selected
+// by the selector in the namespace. The HorizontalPodAutoscaler can't act on the metric before it's served, so the
+// tests wait for it instead of sleeping, and the error tells whether the metrics pipeline never serves the metric.
+func WaitForCustomPodMetric(ctx context.Context, client clientset.Interface, namespace, metricName string, selector labels.Selector, timeout time.Duration) error {
+	path := fmt.Sprintf("%s/namespaces/%s/pods/*/%s", customMetricsAPIPath, namespace, metricName)
+	var lastErr error
+	err := wait.PollUntilContextTimeout(ctx, framework.Poll, timeout, true, func(ctx context.Context) (bool, error) {
+		data, err := client.CoreV1().RESTClient().Get().AbsPath(path).Param("labelSelector", selector.String()).DoRaw(ctx)
+		if err != nil {
+			lastErr = err
+			return false, nil
+		}
+		values, err := decodeCustomMetricValues(data)
+		if err != nil {
+			lastErr = err
+			return false, nil
+		}
+		if len(values) == 0 {
+			lastErr = fmt.Errorf("no value is returned")
+			return false, nil
+		}
+		for _, v := range values {
+			framework.Logf("Custom metric %s of %s %s/%s is %s", metricName, v.DescribedObject.Kind, namespace, v.DescribedObject.Name, v.Value.String())
+		}
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("custom metric %s of pods %q in namespace %s was not served by %s within %v: %w, last observed: %v",
+			metricName, selector, namespace, customMetricsAPIPath, timeout, err, lastErr)
+	}
+	return nil
+}
+
+// customMetricListed returns whether the APIResourceList returned by the discovery of the custom metrics API lists
+// the metric of the resource, i.e. <resource>/<metric>, e.g. pods/DCGM_FI_DEV_GPU_UTIL.
+func customMetricListed(data []byte, resourceName, metricName string) (bool, error) {
+	var list metav1.APIResourceList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return false, fmt.Errorf("error when decoding the custom metrics discovery response %q: %w", string(data), err)
+	}
+	for _, r := range list.APIResources {
+		if r.Name == resourceName+"/"+metricName {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CustomMetricExposed returns whether the custom metrics API exposes the metric of the resource, e.g. pods, as the
+// metrics adapter only serves the metrics which its rules map to the objects.
+func CustomMetricExposed(ctx context.Context, client clientset.Interface, resourceName, metricName string) (bool, error) {
+	data, err := client.CoreV1().RESTClient().Get().AbsPath(customMetricsAPIPath).DoRaw(ctx)
+	if err != nil {
+		return false, fmt.Errorf("error when discovering the metrics served by %s: %w", customMetricsAPIPath, err)
+	}
+	return customMetricListed(data, resourceName, metricName)
+}
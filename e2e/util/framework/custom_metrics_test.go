@@ -0,0 +1,116 @@
+package framework
+
+import (
+	"testing"
+)
+
+func TestDecodeCustomMetricValues(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		wantNames []string
+		wantValue string
+		wantErr   bool
+	}{
+		{
+			name: "values of pods",
+			data: `{"kind":"MetricValueList","apiVersion":"custom.metrics.k8s.io/v1beta1","metadata":{},"items":[
+				{"describedObject":{"kind":"Pod","namespace":"e2e","name":"consumer-a","apiVersion":"/v1"},"metricName":"QPS","timestamp":"2025-01-01T00:00:00Z","value":"150"},
+				{"describedObject":{"kind":"Pod","namespace":"e2e","name":"consumer-b","apiVersion":"/v1"},"metricName":"QPS","timestamp":"2025-01-01T00:00:00Z","value":"150"}]}`,
+			wantNames: []string{"consumer-a", "consumer-b"},
+			wantValue: "150",
+		},
+		{
+			name: "no values",
+			data: `{"kind":"MetricValueList","apiVersion":"custom.metrics.k8s.io/v1beta1","metadata":{},"items":[]}`,
+		},
+		{
+			name:    "status instead of values",
+			data:    `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound"}`,
+			wantErr: true,
+		},
+		{
+			name:    "not json",
+			data:    `<html>503 Service Unavailable</html>`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := decodeCustomMetricValues([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeCustomMetricValues() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(values) != len(tt.wantNames) {
+				t.Fatalf("decodeCustomMetricValues() returned %d values, want %d", len(values), len(tt.wantNames))
+			}
+			for i, v := range values {
+				if v.DescribedObject.Name != tt.wantNames[i] {
+					t.Errorf("value %d is of %s, want %s", i, v.DescribedObject.Name, tt.wantNames[i])
+				}
+				if v.Value.String() != tt.wantValue {
+					t.Errorf("value %d is %s, want %s", i, v.Value.String(), tt.wantValue)
+				}
+			}
+		})
+	}
+}
+
+func TestCustomMetricListed(t *testing.T) {
+	data := `{"kind":"APIResourceList","apiVersion":"v1","groupVersion":"custom.metrics.k8s.io/v1beta1","resources":[
+		{"name":"pods/DCGM_FI_DEV_GPU_UTIL","singularName":"","namespaced":true,"kind":"MetricValueList","verbs":["get"]},
+		{"name":"namespaces/DCGM_FI_DEV_GPU_UTIL","singularName":"","namespaced":false,"kind":"MetricValueList","verbs":["get"]},
+		{"name":"services/http_requests","singularName":"","namespaced":true,"kind":"MetricValueList","verbs":["get"]}]}`
+	tests := []struct {
+		name         string
+		data         string
+		resourceName string
+		metricName   string
+		want         bool
+		wantErr      bool
+	}{
+		{
+			name:         "metric of pods",
+			data:         data,
+			resourceName: "pods",
+			metricName:   "DCGM_FI_DEV_GPU_UTIL",
+			want:         true,
+		},
+		{
+			name:         "metric of other resource only",
+			data:         data,
+			resourceName: "pods",
+			metricName:   "http_requests",
+		},
+		{
+			name:         "metric not exposed",
+			data:         data,
+			resourceName: "pods",
+			metricName:   "amd_gpu_gfx_activity",
+		},
+		{
+			name:         "no metrics",
+			data:         `{"kind":"APIResourceList","apiVersion":"v1","groupVersion":"custom.metrics.k8s.io/v1beta1","resources":[]}`,
+			resourceName: "pods",
+			metricName:   "DCGM_FI_DEV_GPU_UTIL",
+		},
+		{
+			name:         "not json",
+			data:         `<html>503 Service Unavailable</html>`,
+			resourceName: "pods",
+			metricName:   "DCGM_FI_DEV_GPU_UTIL",
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := customMetricListed([]byte(tt.data), tt.resourceName, tt.metricName)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("customMetricListed() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("customMetricListed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
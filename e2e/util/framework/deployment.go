@@ -0,0 +1,70 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
+
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+// WaitForDeploymentComplete waits until the rollout of the Deployment is complete, i.e. its latest generation is
+// observed by the controller and all of its replicas are updated and available, with no old replica left. The
+// error on timeout tells which of them is behind and the conditions of the Deployment.
+func WaitForDeploymentComplete(ctx context.Context, client clientset.Interface, deployment *appsv1.Deployment, timeout time.Duration) error {
+	var lastErr error
+	var last *appsv1.Deployment
+	err := wait.PollUntilContextTimeout(ctx, framework.Poll, timeout, true, func(ctx context.Context) (bool, error) {
+		d, err := client.AppsV1().Deployments(deployment.Namespace).Get(ctx, deployment.Name, metav1.GetOptions{})
+		if err != nil {
+			lastErr = err
+			return false, nil
+		}
+		last = d
+		lastErr = deploymentRolloutError(d)
+		return lastErr == nil, nil
+	})
+	if err != nil {
+		return fmt.Errorf("rollout of deployment %s/%s was not complete within %v: %w, last observed: %v, conditions: %s",
+			deployment.Namespace, deployment.Name, timeout, err, lastErr, deploymentConditions(last))
+	}
+	return nil
+}
+
+// deploymentRolloutError returns the reason why the rollout of the Deployment is not complete, or nil if it is.
+func deploymentRolloutError(d *appsv1.Deployment) error {
+	replicas := ptr.Deref(d.Spec.Replicas, 1)
+	switch {
+	case d.Status.ObservedGeneration < d.Generation:
+		return fmt.Errorf("generation %d is not observed yet, the observed one is %d", d.Generation, d.Status.ObservedGeneration)
+	case d.Status.UpdatedReplicas < replicas:
+		return fmt.Errorf("%d/%d replicas are updated", d.Status.UpdatedReplicas, replicas)
+	case d.Status.Replicas > d.Status.UpdatedReplicas:
+		return fmt.Errorf("%d old replicas are pending termination", d.Status.Replicas-d.Status.UpdatedReplicas)
+	case d.Status.AvailableReplicas < replicas:
+		return fmt.Errorf("%d/%d replicas are available", d.Status.AvailableReplicas, replicas)
+	}
+	return nil
+}
+
+// deploymentConditions formats the conditions of the Deployment as "<type>=<status> (<reason>: <message>)".
+func deploymentConditions(d *appsv1.Deployment) string {
+	if d == nil {
+		return "unknown"
+	}
+	var conditions []string
+	for _, cond := range d.Status.Conditions {
+		conditions = append(conditions, fmt.Sprintf("%s=%s (%s: %s)", cond.Type, cond.Status, cond.Reason, cond.Message))
+	}
+	if len(conditions) == 0 {
+		return "none"
+	}
+	return strings.Join(conditions, ", ")
+}
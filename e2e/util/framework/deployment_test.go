@@ -0,0 +1,94 @@
+package framework
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestDeploymentRolloutError(t *testing.T) {
+	newDeployment := func(generation int64, replicas int32, status appsv1.DeploymentStatus) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Generation: generation},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr.To(replicas)},
+			Status:     status,
+		}
+	}
+
+	tests := []struct {
+		name       string
+		deployment *appsv1.Deployment
+		wantErr    string
+	}{
+		{
+			name:       "complete",
+			deployment: newDeployment(2, 2, appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2}),
+		},
+		{
+			name:       "generation not observed",
+			deployment: newDeployment(3, 2, appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2}),
+			wantErr:    "generation 3 is not observed yet, the observed one is 2",
+		},
+		{
+			name:       "replicas not updated",
+			deployment: newDeployment(2, 2, appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 1, AvailableReplicas: 2}),
+			wantErr:    "1/2 replicas are updated",
+		},
+		{
+			name:       "old replicas pending termination",
+			deployment: newDeployment(2, 2, appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 2, AvailableReplicas: 2}),
+			wantErr:    "1 old replicas are pending termination",
+		},
+		{
+			name:       "replicas not available",
+			deployment: newDeployment(2, 2, appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 1}),
+			wantErr:    "1/2 replicas are available",
+		},
+		{
+			name: "1 replica by default",
+			deployment: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Generation: 1},
+				Status:     appsv1.DeploymentStatus{ObservedGeneration: 1},
+			},
+			wantErr: "0/1 replicas are updated",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := deploymentRolloutError(tt.deployment)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("deploymentRolloutError() unexpected error = %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("deploymentRolloutError() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDeploymentConditions(t *testing.T) {
+	d := &appsv1.Deployment{
+		Status: appsv1.DeploymentStatus{
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentAvailable, Status: v1.ConditionFalse, Reason: "MinimumReplicasUnavailable", Message: "Deployment does not have minimum availability."},
+				{Type: appsv1.DeploymentProgressing, Status: v1.ConditionTrue, Reason: "ReplicaSetUpdated", Message: `ReplicaSet "test-1" is progressing.`},
+			},
+		},
+	}
+	want := `Available=False (MinimumReplicasUnavailable: Deployment does not have minimum availability.), Progressing=True (ReplicaSetUpdated: ReplicaSet "test-1" is progressing.)`
+	if got := deploymentConditions(d); got != want {
+		t.Errorf("deploymentConditions() = %s, want %s", got, want)
+	}
+	if got := deploymentConditions(&appsv1.Deployment{}); got != "none" {
+		t.Errorf("deploymentConditions() = %s, want none", got)
+	}
+	if got := deploymentConditions(nil); got != "unknown" {
+		t.Errorf("deploymentConditions() = %s, want unknown", got)
+	}
+}
@@ -0,0 +1,80 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+// deschedulerSelectors are the well-known labels of the descheduler, i.e. the ones of its kustomize manifests and of
+// its helm chart, which runs it as a Deployment or a CronJob.
+var deschedulerSelectors = []string{"app=descheduler", "app.kubernetes.io/name=descheduler"}
+
+// DetectDescheduler returns <kind> <namespace>/<name> of the Deployment or the CronJob which runs the descheduler,
+// found by the given label selector, or by the well-known labels of the descheduler if it's empty. An empty string is
+// returned if none is found.
+func DetectDescheduler(ctx context.Context, client clientset.Interface, selector string) (string, error) {
+	selectors := deschedulerSelectors
+	if selector != "" {
+		selectors = []string{selector}
+	}
+	for _, selector := range selectors {
+		deployments, err := client.AppsV1().Deployments(metav1.NamespaceAll).List(ctx, metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			return "", fmt.Errorf("error when listing deployments labeled %s: %w", selector, err)
+		}
+		if len(deployments.Items) > 0 {
+			d := deployments.Items[0]
+			return fmt.Sprintf("Deployment %s/%s", d.Namespace, d.Name), nil
+		}
+		cronJobs, err := client.BatchV1().CronJobs(metav1.NamespaceAll).List(ctx, metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			return "", fmt.Errorf("error when listing cronjobs labeled %s: %w", selector, err)
+		}
+		if len(cronJobs.Items) > 0 {
+			cronJob := cronJobs.Items[0]
+			return fmt.Sprintf("CronJob %s/%s", cronJob.Namespace, cronJob.Name), nil
+		}
+	}
+	return "", nil
+}
+
+// ImbalancedAcceleratorNodes returns the nodes which an imbalanced placement of n pods requesting 1 accelerator of
+// the resource can be rebalanced between, i.e. the source node which has the most available accelerators, at least
+// n, and the other nodes with an available accelerator which the pods can be moved to, sorted. The available
+// accelerators of a node are its allocatable minus the ones allocated to the pods bound to it. An empty source is
+// returned if there is no such placement.
+func ImbalancedAcceleratorNodes(nodes []v1.Node, count *AcceleratorCount, resourceName v1.ResourceName, n int) (source string, targets []string) {
+	used := map[string]int{}
+	for _, allocation := range count.Allocations {
+		used[allocation.Node] += allocation.Count
+	}
+	available := map[string]int{}
+	for _, node := range nodes {
+		if val, ok := node.Status.Allocatable[resourceName]; ok {
+			available[node.Name] = int(val.Value()) - used[node.Name]
+		}
+	}
+	for name, a := range available {
+		if a > available[source] || a == available[source] && name < source {
+			source = name
+		}
+	}
+	if available[source] < n {
+		return "", nil
+	}
+	for name, a := range available {
+		if name != source && a > 0 {
+			targets = append(targets, name)
+		}
+	}
+	if len(targets) == 0 {
+		return "", nil
+	}
+	sort.Strings(targets)
+	return source, targets
+}
@@ -0,0 +1,120 @@
+package framework
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDetectDescheduler(t *testing.T) {
+	tests := []struct {
+		name     string
+		objects  []runtime.Object
+		selector string
+		want     string
+	}{
+		{
+			name: "deployment of the manifests",
+			objects: []runtime.Object{
+				&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "descheduler", Labels: map[string]string{"app": "descheduler"}}},
+			},
+			want: "Deployment kube-system/descheduler",
+		},
+		{
+			name: "cronjob of the chart",
+			objects: []runtime.Object{
+				&batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Namespace: "descheduler", Name: "descheduler", Labels: map[string]string{"app.kubernetes.io/name": "descheduler"}}},
+			},
+			want: "CronJob descheduler/descheduler",
+		},
+		{
+			name: "custom selector",
+			objects: []runtime.Object{
+				&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "descheduler", Labels: map[string]string{"app": "descheduler"}}},
+				&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "platform", Name: "rebalancer", Labels: map[string]string{"component": "rebalancer"}}},
+			},
+			selector: "component=rebalancer",
+			want:     "Deployment platform/rebalancer",
+		},
+		{
+			name: "none",
+			objects: []runtime.Object{
+				&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "coredns", Labels: map[string]string{"app": "coredns"}}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewClientset(tt.objects...)
+			got, err := DetectDescheduler(context.Background(), client, tt.selector)
+			if err != nil {
+				t.Fatalf("DetectDescheduler() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("DetectDescheduler() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImbalancedAcceleratorNodes(t *testing.T) {
+	newNode := func(name string, allocatable string) v1.Node {
+		return v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: v1.NodeStatus{
+				Allocatable: v1.ResourceList{"nvidia.com/gpu": resource.MustParse(allocatable)},
+			},
+		}
+	}
+	tests := []struct {
+		name        string
+		nodes       []v1.Node
+		allocations []AcceleratorAllocation
+		wantSource  string
+		wantTargets []string
+	}{
+		{
+			name:        "most available accelerators",
+			nodes:       []v1.Node{newNode("node-a", "4"), newNode("node-b", "4"), newNode("node-c", "2")},
+			allocations: []AcceleratorAllocation{{Pod: "default/train", Node: "node-a", Count: 3}},
+			wantSource:  "node-b",
+			wantTargets: []string{"node-a", "node-c"},
+		},
+		{
+			name:        "tie broken by name",
+			nodes:       []v1.Node{newNode("node-b", "2"), newNode("node-a", "2")},
+			wantSource:  "node-a",
+			wantTargets: []string{"node-b"},
+		},
+		{
+			name:        "no available accelerator on other nodes",
+			nodes:       []v1.Node{newNode("node-a", "4"), newNode("node-b", "1")},
+			allocations: []AcceleratorAllocation{{Pod: "default/train", Node: "node-b", Count: 1}},
+		},
+		{
+			name:  "not enough available accelerators on a node",
+			nodes: []v1.Node{newNode("node-a", "1"), newNode("node-b", "1")},
+		},
+		{
+			name:  "single node",
+			nodes: []v1.Node{newNode("node-a", "8")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count := &AcceleratorCount{Allocations: tt.allocations}
+			source, targets := ImbalancedAcceleratorNodes(tt.nodes, count, "nvidia.com/gpu", 2)
+			if source != tt.wantSource || !reflect.DeepEqual(targets, tt.wantTargets) {
+				t.Errorf("ImbalancedAcceleratorNodes() = %q, %v, want %q, %v", source, targets, tt.wantSource, tt.wantTargets)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
+)
+
+// DeviceFilesCommand is the shell command which lists the device files under /dev of a container.
+const DeviceFilesCommand = "ls -lR /dev"
+
+// acceleratorDeviceFiles matches the paths relative to /dev of the device files of a single accelerator, i.e. the
+// NVIDIA GPUs, the render nodes of the DRM drivers, the compute accelerators of the accel subsystem and the AWS
+// Neuron devices. The control files shared by all the accelerators of a node, e.g. /dev/nvidiactl and /dev/kfd, are
+// not matched, as they are mounted into every container which is allocated an accelerator.
+var acceleratorDeviceFiles = regexp.MustCompile(`^(nvidia[0-9]+|dri/renderD[0-9]+|accel/accel[0-9]+|neuron[0-9]+)$`)
+
+// DeviceFile is a character device file of an accelerator.
+type DeviceFile struct {
+	// Path is the path of the file relative to /dev.
+	Path  string
+	Major int
+	Minor int
+}
+
+func (d DeviceFile) String() string {
+	return fmt.Sprintf("/dev/%s (%d:%d)", d.Path, d.Major, d.Minor)
+}
+
+// ParseAcceleratorDeviceFiles parses the output of DeviceFilesCommand and returns the device files of the
+// accelerators, sorted by their major and minor numbers.
+func ParseAcceleratorDeviceFiles(output string) ([]DeviceFile, error) {
+	var files []DeviceFile
+	dir := ""
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasSuffix(line, ":") && !strings.Contains(line, " ") {
+			// The header of a directory, e.g. "/dev/dri:".
+			dir = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSuffix(line, ":"), "/dev"), "/")
+			continue
+		}
+		if !strings.HasPrefix(line, "c") {
+			continue
+		}
+		// The major and minor numbers are printed as "195,   0" or "195,0".
+		fields := strings.Fields(strings.Replace(line, ",", ", ", 1))
+		if len(fields) < 7 {
+			return nil, fmt.Errorf("invalid device file in %q", line)
+		}
+		name := path.Join(dir, fields[len(fields)-1])
+		if !acceleratorDeviceFiles.MatchString(name) {
+			continue
+		}
+		major, err := strconv.Atoi(strings.TrimSuffix(fields[4], ","))
+		if err != nil {
+			return nil, fmt.Errorf("invalid major number of device file in %q: %w", line, err)
+		}
+		minor, err := strconv.Atoi(fields[5])
+		if err != nil {
+			return nil, fmt.Errorf("invalid minor number of device file in %q: %w", line, err)
+		}
+		files = append(files, DeviceFile{Path: name, Major: major, Minor: minor})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].Major != files[j].Major {
+			return files[i].Major < files[j].Major
+		}
+		return files[i].Minor < files[j].Minor
+	})
+	return files, nil
+}
+
+// SharedDeviceFiles returns the device files of a which have the same major and minor numbers as any of b, i.e.
+// the accelerators which are accessible by both containers.
+func SharedDeviceFiles(a, b []DeviceFile) []DeviceFile {
+	var shared []DeviceFile
+	for _, x := range a {
+		for _, y := range b {
+			if x.Major == y.Major && x.Minor == y.Minor {
+				shared = append(shared, x)
+				break
+			}
+		}
+	}
+	return shared
+}
+
+// ListAcceleratorDeviceFiles returns the device files of the accelerators under /dev of the first container of the
+// pod. Unlike the command line tools of the vendors, ls is available in almost every image.
+func ListAcceleratorDeviceFiles(ctx context.Context, f *framework.Framework, podName string) ([]DeviceFile, error) {
+	stdout, stderr, err := e2epod.ExecShellInPodWithFullOutput(ctx, f, podName, DeviceFilesCommand)
+	if err != nil {
+		return nil, fmt.Errorf("error when listing device files of pod %s: %w, stderr: %s", podName, err, stderr)
+	}
+	files, err := ParseAcceleratorDeviceFiles(stdout)
+	if err != nil {
+		return nil, err
+	}
+	framework.Logf("Accelerator device files of pod %s: %v", podName, files)
+	return files, nil
+}
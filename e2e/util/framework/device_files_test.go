@@ -0,0 +1,112 @@
+package framework
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAcceleratorDeviceFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    []DeviceFile
+		wantErr bool
+	}{
+		{
+			name: "nvidia gpus",
+			output: `/dev:
+total 0
+crw-rw-rw-    1 root     root        1,   3 Oct 16 08:00 null
+crw-rw-rw-    1 root     root      195,   1 Oct 16 08:00 nvidia1
+crw-rw-rw-    1 root     root      195, 255 Oct 16 08:00 nvidiactl
+crw-rw-rw-    1 root     root      508,   0 Oct 16 08:00 nvidia-uvm
+drwxrwxrwt    2 root     root            40 Oct 16 08:00 shm
+
+/dev/shm:
+total 0
+`,
+			want: []DeviceFile{{Path: "nvidia1", Major: 195, Minor: 1}},
+		},
+		{
+			name: "amd gpus",
+			output: `/dev:
+total 0
+drwxr-xr-x 2 root root      80 Oct 16 08:00 dri
+crw-rw-rw- 1 root root 236,   0 Oct 16 08:00 kfd
+
+/dev/dri:
+total 0
+crw-rw-rw- 1 root video 226, 129 Oct 16 08:00 renderD129
+crw-rw-rw- 1 root video 226, 128 Oct 16 08:00 renderD128
+`,
+			want: []DeviceFile{{Path: "dri/renderD128", Major: 226, Minor: 128}, {Path: "dri/renderD129", Major: 226, Minor: 129}},
+		},
+		{
+			name: "numbers without spaces",
+			output: `/dev:
+crw-rw-rw- 1 root root 261,0 Oct 16 08:00 neuron0
+`,
+			want: []DeviceFile{{Path: "neuron0", Major: 261, Minor: 0}},
+		},
+		{
+			name: "no accelerator",
+			output: `/dev:
+crw-rw-rw- 1 root root 1, 3 Oct 16 08:00 null
+`,
+		},
+		{
+			name: "invalid minor number",
+			output: `/dev:
+crw-rw-rw- 1 root root 195, x Oct 16 08:00 nvidia0
+`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAcceleratorDeviceFiles(tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAcceleratorDeviceFiles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseAcceleratorDeviceFiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSharedDeviceFiles(t *testing.T) {
+	gpu0 := DeviceFile{Path: "nvidia0", Major: 195, Minor: 0}
+	gpu1 := DeviceFile{Path: "nvidia1", Major: 195, Minor: 1}
+	tests := []struct {
+		name string
+		a    []DeviceFile
+		b    []DeviceFile
+		want []DeviceFile
+	}{
+		{
+			name: "disjoint",
+			a:    []DeviceFile{gpu0},
+			b:    []DeviceFile{gpu1},
+		},
+		{
+			name: "shared",
+			a:    []DeviceFile{gpu0, gpu1},
+			b:    []DeviceFile{gpu1},
+			want: []DeviceFile{gpu1},
+		},
+		{
+			name: "same numbers with different paths",
+			a:    []DeviceFile{gpu0},
+			b:    []DeviceFile{{Path: "nvidia5", Major: 195, Minor: 0}},
+			want: []DeviceFile{gpu0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SharedDeviceFiles(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SharedDeviceFiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+// DevicePluginPod returns the pod of the device plugin of the vendor on the node. The device plugin is the
+// DaemonSet whose labels or pod template labels match -ai.accelerator.devicePluginSelector if it's specified, or
+// any of the DevicePluginSelectors of the vendor otherwise. Nil is returned if the device plugin can't be identified.
+func DevicePluginPod(ctx context.Context, client clientset.Interface, vendor AcceleratorVendor, nodeName string) (*v1.Pod, error) {
+	selectors := vendor.DevicePluginSelectors
+	if accelerator.DevicePluginSelector != "" {
+		selectors = []string{accelerator.DevicePluginSelector}
+	}
+	daemonSets, err := client.AppsV1().DaemonSets(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error when listing DaemonSets: %w", err)
+	}
+	matched, err := matchDaemonSets(daemonSets.Items, selectors)
+	if err != nil {
+		return nil, err
+	}
+	for i := range matched {
+		pod, err := daemonSetPodOnNode(ctx, client, &matched[i], nodeName)
+		if err != nil {
+			return nil, err
+		}
+		if pod != nil {
+			framework.Logf("Found pod %s/%s of device plugin DaemonSet %s on node %s", pod.Namespace, pod.Name, matched[i].Name, nodeName)
+			return pod, nil
+		}
+	}
+	return nil, nil
+}
+
+// matchDaemonSets returns the DaemonSets whose labels or pod template labels match any of the selectors.
+func matchDaemonSets(daemonSets []appsv1.DaemonSet, selectors []string) ([]appsv1.DaemonSet, error) {
+	var parsed []labels.Selector
+	for _, s := range selectors {
+		selector, err := labels.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid device plugin selector %q: %w", s, err)
+		}
+		parsed = append(parsed, selector)
+	}
+	var matched []appsv1.DaemonSet
+	for _, ds := range daemonSets {
+		for _, selector := range parsed {
+			if selector.Matches(labels.Set(ds.Labels)) || selector.Matches(labels.Set(ds.Spec.Template.Labels)) {
+				matched = append(matched, ds)
+				break
+			}
+		}
+	}
+	return matched, nil
+}
+
+// daemonSetPodOnNode returns the pod of the DaemonSet on the node which is not being deleted, or nil.
+func daemonSetPodOnNode(ctx context.Context, client clientset.Interface, ds *appsv1.DaemonSet, nodeName string) (*v1.Pod, error) {
+	selector, err := metav1.LabelSelectorAsSelector(ds.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector of DaemonSet %s/%s: %w", ds.Namespace, ds.Name, err)
+	}
+	pods, err := client.CoreV1().Pods(ds.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: selector.String(),
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", nodeName).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error when listing pods of DaemonSet %s/%s: %w", ds.Namespace, ds.Name, err)
+	}
+	return daemonSetPod(pods.Items, ds), nil
+}
+
+// daemonSetPod returns the pod controlled by the DaemonSet which is not being deleted, or nil.
+func daemonSetPod(pods []v1.Pod, ds *appsv1.DaemonSet) *v1.Pod {
+	for i := range pods {
+		pod := &pods[i]
+		if ref := metav1.GetControllerOf(pod); ref == nil || ref.UID != ds.UID {
+			continue
+		}
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		return pod
+	}
+	return nil
+}
+
+// RestartDevicePlugin deletes the given pod of the device plugin and waits until its DaemonSet replaces it with a
+// ready pod on the same node. The new pod is returned.
+func RestartDevicePlugin(ctx context.Context, client clientset.Interface, pod *v1.Pod, timeout time.Duration) (*v1.Pod, error) {
+	ref := metav1.GetControllerOf(pod)
+	if ref == nil || ref.Kind != "DaemonSet" {
+		return nil, fmt.Errorf("pod %s/%s is not controlled by a DaemonSet", pod.Namespace, pod.Name)
+	}
+	ds, err := client.AppsV1().DaemonSets(pod.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error when getting DaemonSet %s/%s: %w", pod.Namespace, ref.Name, err)
+	}
+	err = client.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error when deleting device plugin pod %s/%s: %w", pod.Namespace, pod.Name, err)
+	}
+
+	var newPod *v1.Pod
+	err = wait.PollUntilContextTimeout(ctx, framework.Poll, timeout, true, func(ctx context.Context) (bool, error) {
+		newPod, err = daemonSetPodOnNode(ctx, client, ds, pod.Spec.NodeName)
+		if err != nil {
+			return false, err
+		}
+		return newPod != nil && newPod.UID != pod.UID && podutil.IsPodReady(newPod), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("DaemonSet %s/%s didn't replace pod %s with a ready pod on node %s within %v: %w",
+			ds.Namespace, ds.Name, pod.Name, pod.Spec.NodeName, timeout, err)
+	}
+	framework.Logf("Device plugin pod %s/%s is replaced by ready pod %s", pod.Namespace, pod.Name, newPod.Name)
+	return newPod, nil
+}
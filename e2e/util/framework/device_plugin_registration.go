@@ -0,0 +1,160 @@
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
+
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
+	imageutils "k8s.io/kubernetes/test/utils/image"
+)
+
+const (
+	// devicePluginsDir is the directory of the sockets of the kubelet and the device plugins, and of the checkpoint
+	// of the devices registered by the device plugins.
+	devicePluginsDir = "/var/lib/kubelet/device-plugins"
+	// devicePluginCheckpoint is the name of the checkpoint file of the kubelet device manager.
+	devicePluginCheckpoint = "kubelet_internal_checkpoint"
+	// devicePluginSocketsPrefix prefixes the line of the sockets printed by the query pod.
+	devicePluginSocketsPrefix = "sockets:"
+	// devicePluginCheckpointUnavailable is printed by the query pod if the checkpoint file doesn't exist.
+	devicePluginCheckpointUnavailable = "checkpoint is unavailable"
+)
+
+// DevicePluginRegistration is the registration of the device plugins on a node, read from the device plugins
+// directory of the kubelet.
+type DevicePluginRegistration struct {
+	// Sockets are the names of the sockets in the directory, i.e. kubelet.sock and the ones of the device plugins.
+	Sockets []string
+	// RegisteredDevices are the IDs of the devices of each resource registered by the device plugins, which are read
+	// from the checkpoint of the kubelet device manager. It's nil if the checkpoint is unavailable.
+	RegisteredDevices map[string][]string
+}
+
+// ResourceNames returns the names of the resources registered by the device plugins, sorted.
+func (r *DevicePluginRegistration) ResourceNames() []string {
+	var names []string
+	for name := range r.RegisteredDevices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PluginSockets returns the sockets of the device plugins, i.e. the sockets except for the one of the kubelet.
+func (r *DevicePluginRegistration) PluginSockets() []string {
+	var sockets []string
+	for _, socket := range r.Sockets {
+		if socket != "kubelet.sock" {
+			sockets = append(sockets, socket)
+		}
+	}
+	return sockets
+}
+
+// parseDevicePluginRegistration parses the output of the query pod, which is the line of the sockets followed by the
+// checkpoint, see ReadDevicePluginRegistration.
+func parseDevicePluginRegistration(output string) (*DevicePluginRegistration, error) {
+	line, checkpoint, _ := strings.Cut(strings.TrimSpace(output), "\n")
+	sockets, ok := strings.CutPrefix(strings.TrimSpace(line), devicePluginSocketsPrefix)
+	if !ok {
+		return nil, fmt.Errorf("missing the sockets of the device plugins in %q", output)
+	}
+	registration := &DevicePluginRegistration{Sockets: strings.Fields(sockets)}
+	checkpoint = strings.TrimSpace(checkpoint)
+	if checkpoint == devicePluginCheckpointUnavailable {
+		return registration, nil
+	}
+	// The subset of the checkpoint of the kubelet device manager, see
+	// k8s.io/kubernetes/pkg/kubelet/cm/devicemanager/checkpoint.
+	var data struct {
+		Data struct {
+			RegisteredDevices map[string][]string `json:"RegisteredDevices"`
+		} `json:"Data"`
+	}
+	if err := json.Unmarshal([]byte(checkpoint), &data); err != nil {
+		return nil, fmt.Errorf("error when decoding the device plugin checkpoint %s: %w", checkpoint, err)
+	}
+	registration.RegisteredDevices = data.Data.RegisteredDevices
+	if registration.RegisteredDevices == nil {
+		registration.RegisteredDevices = map[string][]string{}
+	}
+	return registration, nil
+}
+
+// ReadDevicePluginRegistration reads the sockets and the checkpoint in the device plugins directory of the kubelet
+// on the node by a privileged pod created in the namespace.
+func ReadDevicePluginRegistration(ctx context.Context, client clientset.Interface, namespace, nodeName string) (*DevicePluginRegistration, error) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: "device-plugins-"},
+		Spec: v1.PodSpec{
+			NodeName:      nodeName,
+			RestartPolicy: v1.RestartPolicyNever,
+			Tolerations:   []v1.Toleration{{Operator: v1.TolerationOpExists}},
+			Containers: []v1.Container{
+				{
+					Name:    "reader",
+					Image:   imageutils.GetE2EImage(imageutils.BusyBox),
+					Command: []string{"/bin/sh", "-c"},
+					Args: []string{fmt.Sprintf(`cd %[1]s && echo %[2]s $(ls | grep '\.sock$'); `+
+						`if [ -f %[3]s ]; then cat %[3]s; else echo %[4]q; fi`,
+						devicePluginsDir, devicePluginSocketsPrefix, devicePluginCheckpoint, devicePluginCheckpointUnavailable)},
+					SecurityContext: &v1.SecurityContext{
+						Privileged: ptr.To(true),
+						RunAsUser:  ptr.To[int64](0),
+					},
+					VolumeMounts: []v1.VolumeMount{
+						{Name: "device-plugins", MountPath: devicePluginsDir, ReadOnly: true},
+					},
+				},
+			},
+			Volumes: []v1.Volume{
+				{
+					Name: "device-plugins",
+					VolumeSource: v1.VolumeSource{
+						HostPath: &v1.HostPathVolumeSource{Path: devicePluginsDir, Type: ptr.To(v1.HostPathDirectoryOrCreate)},
+					},
+				},
+			},
+		},
+	}
+	pod, err := client.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error when creating pod to read the device plugins directory: %w", err)
+	}
+	DeferCleanup(client.CoreV1().Pods(namespace).Delete, pod.Name, metav1.DeleteOptions{})
+
+	err = e2epod.WaitForPodSuccessInNamespace(ctx, client, pod.Name, namespace)
+	output, logErr := e2epod.GetPodLogs(ctx, client, namespace, pod.Name, pod.Spec.Containers[0].Name)
+	if err != nil {
+		return nil, fmt.Errorf("error when reading the device plugins directory on node %s: %w, output: %s", nodeName, err, output)
+	}
+	if logErr != nil {
+		return nil, fmt.Errorf("error when getting logs of pod %s: %w", pod.Name, logErr)
+	}
+	framework.Logf("Device plugins directory on node %s: %s", nodeName, output)
+	return parseDevicePluginRegistration(output)
+}
+
+// ExtendedResources returns the extended resources in the capacity of the node, i.e. the ones of the device plugins,
+// with their allocatable and capacity quantities, e.g. nvidia.com/gpu=6/8, sorted.
+func ExtendedResources(node *v1.Node) []string {
+	var resources []string
+	for name, capacity := range node.Status.Capacity {
+		if !strings.Contains(string(name), "/") || strings.Contains(string(name), "kubernetes.io/") {
+			continue
+		}
+		allocatable := node.Status.Allocatable[name]
+		resources = append(resources, fmt.Sprintf("%s=%s/%s", name, allocatable.String(), capacity.String()))
+	}
+	sort.Strings(resources)
+	return resources
+}
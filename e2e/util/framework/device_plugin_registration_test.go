@@ -0,0 +1,86 @@
+package framework
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestParseDevicePluginRegistration(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    *DevicePluginRegistration
+		wantErr bool
+	}{
+		{
+			name: "registered",
+			output: `sockets: kubelet.sock nvidia-gpu.sock
+{"Data":{"PodDeviceEntries":[{"PodUID":"uid","ContainerName":"main","ResourceName":"nvidia.com/gpu","DeviceIDs":{"-1":["GPU-0"]},"AllocResp":"e30="}],"RegisteredDevices":{"nvidia.com/gpu":["GPU-0","GPU-1"]}},"Checksum":1}
+`,
+			want: &DevicePluginRegistration{
+				Sockets:           []string{"kubelet.sock", "nvidia-gpu.sock"},
+				RegisteredDevices: map[string][]string{"nvidia.com/gpu": {"GPU-0", "GPU-1"}},
+			},
+		},
+		{
+			name: "nothing registered",
+			output: `sockets: kubelet.sock
+{"Data":{"PodDeviceEntries":null,"RegisteredDevices":null},"Checksum":1}`,
+			want: &DevicePluginRegistration{
+				Sockets:           []string{"kubelet.sock"},
+				RegisteredDevices: map[string][]string{},
+			},
+		},
+		{
+			name:   "checkpoint unavailable",
+			output: "sockets:\ncheckpoint is unavailable\n",
+			want:   &DevicePluginRegistration{Sockets: []string{}},
+		},
+		{
+			name:    "missing sockets",
+			output:  "checkpoint is unavailable",
+			wantErr: true,
+		},
+		{
+			name:    "invalid checkpoint",
+			output:  "sockets: kubelet.sock\n{",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDevicePluginRegistration(tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseDevicePluginRegistration() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseDevicePluginRegistration() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtendedResources(t *testing.T) {
+	node := &v1.Node{
+		Status: v1.NodeStatus{
+			Capacity: v1.ResourceList{
+				v1.ResourceCPU:                 resource.MustParse("8"),
+				"hugepages-2Mi":                resource.MustParse("0"),
+				"nvidia.com/gpu":               resource.MustParse("8"),
+				"amd.com/gpu":                  resource.MustParse("2"),
+				"example.kubernetes.io/device": resource.MustParse("1"),
+			},
+			Allocatable: v1.ResourceList{
+				v1.ResourceCPU:   resource.MustParse("7"),
+				"nvidia.com/gpu": resource.MustParse("6"),
+			},
+		},
+	}
+	want := []string{"amd.com/gpu=0/2", "nvidia.com/gpu=6/8"}
+	if got := ExtendedResources(node); !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtendedResources() = %v, want %v", got, want)
+	}
+}
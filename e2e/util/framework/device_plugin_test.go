@@ -0,0 +1,120 @@
+package framework
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+)
+
+func TestMatchDaemonSets(t *testing.T) {
+	newDaemonSet := func(name string, labels, templateLabels map[string]string) appsv1.DaemonSet {
+		ds := appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+		ds.Spec.Template.Labels = templateLabels
+		return ds
+	}
+	helm := newDaemonSet("nvidia-device-plugin", map[string]string{"app.kubernetes.io/name": "nvidia-device-plugin"}, nil)
+	operator := newDaemonSet("nvidia-device-plugin-daemonset", map[string]string{"app": "nvidia-device-plugin-daemonset"}, map[string]string{"app": "nvidia-device-plugin-daemonset"})
+	manifest := newDaemonSet("nvidia-device-plugin-daemonset", nil, map[string]string{"name": "nvidia-device-plugin-ds"})
+	exporter := newDaemonSet("dcgm-exporter", map[string]string{"app.kubernetes.io/name": "dcgm-exporter"}, nil)
+
+	tests := []struct {
+		name       string
+		daemonSets []appsv1.DaemonSet
+		selectors  []string
+		want       []string
+		wantErr    bool
+	}{
+		{
+			name:       "labels of the DaemonSets",
+			daemonSets: []appsv1.DaemonSet{exporter, helm, operator},
+			selectors:  NVIDIA.DevicePluginSelectors,
+			want:       []string{"nvidia-device-plugin", "nvidia-device-plugin-daemonset"},
+		},
+		{
+			name:       "labels of the pod template",
+			daemonSets: []appsv1.DaemonSet{exporter, manifest},
+			selectors:  NVIDIA.DevicePluginSelectors,
+			want:       []string{"nvidia-device-plugin-daemonset"},
+		},
+		{
+			name:       "no device plugin",
+			daemonSets: []appsv1.DaemonSet{exporter},
+			selectors:  NVIDIA.DevicePluginSelectors,
+		},
+		{
+			name:       "invalid selector",
+			daemonSets: []appsv1.DaemonSet{helm},
+			selectors:  []string{"app in (nvidia"},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, err := matchDaemonSets(tt.daemonSets, tt.selectors)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("matchDaemonSets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var got []string
+			for _, ds := range matched {
+				got = append(got, ds.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("matchDaemonSets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDaemonSetPod(t *testing.T) {
+	ds := &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "device-plugin", UID: types.UID("ds")}}
+	newPod := func(name string, controllerUID types.UID, deleting bool) v1.Pod {
+		pod := v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if controllerUID != "" {
+			pod.OwnerReferences = []metav1.OwnerReference{{Kind: "DaemonSet", Name: "device-plugin", UID: controllerUID, Controller: ptr.To(true)}}
+		}
+		if deleting {
+			pod.DeletionTimestamp = &metav1.Time{}
+		}
+		return pod
+	}
+
+	tests := []struct {
+		name string
+		pods []v1.Pod
+		want string
+	}{
+		{
+			name: "pod of the DaemonSet",
+			pods: []v1.Pod{newPod("other", "other", false), newPod("device-plugin-abcde", "ds", false)},
+			want: "device-plugin-abcde",
+		},
+		{
+			name: "deleting pod is skipped",
+			pods: []v1.Pod{newPod("device-plugin-abcde", "ds", true), newPod("device-plugin-fghij", "ds", false)},
+			want: "device-plugin-fghij",
+		},
+		{
+			name: "pod without controller",
+			pods: []v1.Pod{newPod("device-plugin", "", false)},
+		},
+		{
+			name: "no pod",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			if pod := daemonSetPod(tt.pods, ds); pod != nil {
+				got = pod.Name
+			}
+			if got != tt.want {
+				t.Errorf("daemonSetPod() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
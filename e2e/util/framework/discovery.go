@@ -0,0 +1,37 @@
+package framework
+
+import (
+	"sync"
+
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+// discoveryCache is the discovery client shared by the specs run by the Ginkgo node.
+var discoveryCache struct {
+	sync.Mutex
+	client discovery.CachedDiscoveryInterface
+}
+
+// InitDiscoveryCache replaces the discovery client shared by the specs run by the Ginkgo node with an empty cache of
+// the discovery of the given client. It's called once per suite, so the APIs installed before the suite are seen.
+func InitDiscoveryCache(client clientset.Interface) {
+	discoveryCache.Lock()
+	defer discoveryCache.Unlock()
+	discoveryCache.client = memory.NewMemCacheClient(client.Discovery())
+}
+
+// CachedDiscovery returns the discovery client shared by the specs run by the Ginkgo node, which caches the
+// discovery results in memory, so that the specs checking the served APIs don't hit the discovery endpoint of the
+// API server each time. It's created from the given client if InitDiscoveryCache has not been called. The APIs
+// installed by the specs are not seen until the cache is invalidated, so the specs which install APIs use their
+// own discovery client, e.g. the one of NewClientGetter.
+func CachedDiscovery(client clientset.Interface) discovery.CachedDiscoveryInterface {
+	discoveryCache.Lock()
+	defer discoveryCache.Unlock()
+	if discoveryCache.client == nil {
+		discoveryCache.client = memory.NewMemCacheClient(client.Discovery())
+	}
+	return discoveryCache.client
+}
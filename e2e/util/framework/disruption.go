@@ -0,0 +1,50 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
+
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+// WaitForPodDisruptionBudgetObserved waits until the latest generation of the PodDisruptionBudget is observed by the
+// disruption controller and all of its expected pods are healthy, so that the evictions are checked against the
+// current state of the pods instead of a stale one, and returns the PodDisruptionBudget.
+func WaitForPodDisruptionBudgetObserved(ctx context.Context, client clientset.Interface, namespace, name string, timeout time.Duration) (*policyv1.PodDisruptionBudget, error) {
+	var lastErr error
+	var last *policyv1.PodDisruptionBudget
+	err := wait.PollUntilContextTimeout(ctx, framework.Poll, timeout, true, func(ctx context.Context) (bool, error) {
+		pdb, err := client.PolicyV1().PodDisruptionBudgets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			lastErr = err
+			return false, nil
+		}
+		last = pdb
+		lastErr = podDisruptionBudgetStatusError(pdb)
+		return lastErr == nil, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("pod disruption budget %s/%s was not observed within %v: %w, last observed: %v", namespace, name, timeout, err, lastErr)
+	}
+	return last, nil
+}
+
+// podDisruptionBudgetStatusError returns the reason why the status of the PodDisruptionBudget is not up to date with
+// all of its expected pods healthy, or nil if it is.
+func podDisruptionBudgetStatusError(pdb *policyv1.PodDisruptionBudget) error {
+	switch {
+	case pdb.Status.ObservedGeneration < pdb.Generation:
+		return fmt.Errorf("generation %d is not observed yet, the observed one is %d", pdb.Generation, pdb.Status.ObservedGeneration)
+	case pdb.Status.ExpectedPods == 0:
+		return fmt.Errorf("no pod is selected")
+	case pdb.Status.CurrentHealthy < pdb.Status.ExpectedPods:
+		return fmt.Errorf("%d/%d pods are healthy", pdb.Status.CurrentHealthy, pdb.Status.ExpectedPods)
+	}
+	return nil
+}
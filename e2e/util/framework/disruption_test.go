@@ -0,0 +1,53 @@
+package framework
+
+import (
+	"testing"
+
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPodDisruptionBudgetStatusError(t *testing.T) {
+	newPDB := func(generation, observedGeneration int64, expected, healthy int32) *policyv1.PodDisruptionBudget {
+		return &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: "workload", Generation: generation},
+			Status: policyv1.PodDisruptionBudgetStatus{
+				ObservedGeneration: observedGeneration,
+				ExpectedPods:       expected,
+				CurrentHealthy:     healthy,
+			},
+		}
+	}
+	tests := []struct {
+		name    string
+		pdb     *policyv1.PodDisruptionBudget
+		wantErr bool
+	}{
+		{
+			name: "observed with all pods healthy",
+			pdb:  newPDB(2, 2, 1, 1),
+		},
+		{
+			name:    "generation not observed",
+			pdb:     newPDB(2, 1, 1, 1),
+			wantErr: true,
+		},
+		{
+			name:    "no pod selected",
+			pdb:     newPDB(1, 1, 0, 0),
+			wantErr: true,
+		},
+		{
+			name:    "unhealthy pods",
+			pdb:     newPDB(1, 1, 2, 1),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := podDisruptionBudgetStatusError(tt.pdb); (err != nil) != tt.wantErr {
+				t.Errorf("podDisruptionBudgetStatusError() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+// productNameAttribute is the attribute preferred to select a device, as the DRA drivers of the accelerator
+// vendors, such as gpu.nvidia.com, publish the product name of their devices with it.
+const productNameAttribute = "productName"
+
+// deviceClassDriverRegex matches the CEL selector of a DeviceClass which selects the devices of a driver.
+var deviceClassDriverRegex = regexp.MustCompile(`device\.driver\s*==\s*["']([^"']+)["']`)
+
+// ListDeviceClasses returns the DeviceClasses sorted by name.
+func ListDeviceClasses(ctx context.Context, client clientset.Interface) ([]resourceapi.DeviceClass, error) {
+	classes, err := client.ResourceV1().DeviceClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error when listing DeviceClasses: %w", err)
+	}
+	sort.Slice(classes.Items, func(i, j int) bool { return classes.Items[i].Name < classes.Items[j].Name })
+	return classes.Items, nil
+}
+
+// DeviceClassDriver returns the driver selected by the CEL selectors of the DeviceClass, e.g. gpu.nvidia.com for
+// device.driver == "gpu.nvidia.com", or an empty string if none of the selectors selects a driver.
+func DeviceClassDriver(class *resourceapi.DeviceClass) string {
+	for _, selector := range class.Spec.Selectors {
+		if selector.CEL == nil {
+			continue
+		}
+		if match := deviceClassDriverRegex.FindStringSubmatch(selector.CEL.Expression); match != nil {
+			return match[1]
+		}
+	}
+	return ""
+}
+
+// DeviceClassRequest is how a ResourceClaim requests a device of a DeviceClass, narrowed by a CEL selector on
+// an attribute of a device published by the driver of the class.
+type DeviceClassRequest struct {
+	// DeviceClassName is the name of the DeviceClass.
+	DeviceClassName string
+	// Driver is the driver selected by the DeviceClass.
+	Driver string
+	// Device is the name of the device whose attribute the selector matches.
+	Device string
+	// Selector is the CEL expression matching the attribute of the device, e.g.
+	// device.attributes["gpu.nvidia.com"].productName == "NVIDIA A100-SXM4-40GB".
+	Selector string
+}
+
+// NewDeviceClassRequest returns the request for the first DeviceClass, or the given one if className is not
+// empty, whose driver, or the given one if driver is not empty, publishes a device with a string attribute in the
+// ResourceSlices. The DeviceClasses whose drivers publish devices with the productName attribute, i.e. accelerators,
+// are preferred over the other ones, e.g. of network devices, and so is the attribute. Nil is returned if no such
+// DeviceClass exists, e.g. no DRA driver is installed besides the ones of the tests.
+func NewDeviceClassRequest(classes []resourceapi.DeviceClass, slices []resourceapi.ResourceSlice, className, driver string) *DeviceClassRequest {
+	for _, acceleratorsOnly := range []bool{true, false} {
+		for i := range classes {
+			class := &classes[i]
+			if className != "" && class.Name != className {
+				continue
+			}
+			classDriver := DeviceClassDriver(class)
+			if classDriver == "" || (driver != "" && classDriver != driver) {
+				continue
+			}
+			for _, slice := range slices {
+				if slice.Spec.Driver != classDriver {
+					continue
+				}
+				for _, device := range slice.Spec.Devices {
+					if acceleratorsOnly && !hasProductName(device) {
+						continue
+					}
+					if selector := deviceAttributeSelector(classDriver, device); selector != "" {
+						return &DeviceClassRequest{DeviceClassName: class.Name, Driver: classDriver, Device: device.Name, Selector: selector}
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// hasProductName returns true if the device has the productName string attribute in any domain.
+func hasProductName(device resourceapi.Device) bool {
+	for name, attr := range device.Attributes {
+		if attr.StringValue != nil && attributeID(string(name)) == productNameAttribute {
+			return true
+		}
+	}
+	return false
+}
+
+// deviceAttributeSelector returns the CEL expression matching the productName attribute of the device, or its
+// first string attribute by name otherwise. An empty string is returned if the device has no string attribute.
+func deviceAttributeSelector(driver string, device resourceapi.Device) string {
+	var names []string
+	for name, attr := range device.Attributes {
+		if attr.StringValue != nil {
+			names = append(names, string(name))
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	sort.Slice(names, func(i, j int) bool {
+		iProduct, jProduct := attributeID(names[i]) == productNameAttribute, attributeID(names[j]) == productNameAttribute
+		if iProduct != jProduct {
+			return iProduct
+		}
+		return names[i] < names[j]
+	})
+	name := names[0]
+	// An attribute name without a domain is in the domain of the driver.
+	domain, id := driver, name
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		domain, id = name[:i], name[i+1:]
+	}
+	value := *device.Attributes[resourceapi.QualifiedName(name)].StringValue
+	return fmt.Sprintf("device.attributes[%s].%s == %s", strconv.Quote(domain), id, strconv.Quote(value))
+}
+
+// attributeID returns the attribute name without the domain.
+func attributeID(name string) string {
+	return name[strings.LastIndex(name, "/")+1:]
+}
+
+// ClaimsAllocatedDevice returns the namespace/name of the ResourceClaims whose allocation includes the device.
+func ClaimsAllocatedDevice(claims []resourceapi.ResourceClaim, device resourceapi.DeviceRequestAllocationResult) []string {
+	var names []string
+	for _, claim := range claims {
+		if claim.Status.Allocation == nil {
+			continue
+		}
+		for _, result := range claim.Status.Allocation.Devices.Results {
+			if result.Driver == device.Driver && result.Pool == device.Pool && result.Device == device.Device {
+				names = append(names, claim.Namespace+"/"+claim.Name)
+				break
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,229 @@
+package framework
+
+import (
+	"reflect"
+	"testing"
+
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestDeviceClassDriver(t *testing.T) {
+	newClass := func(expressions ...string) *resourceapi.DeviceClass {
+		class := &resourceapi.DeviceClass{}
+		for _, expression := range expressions {
+			class.Spec.Selectors = append(class.Spec.Selectors, resourceapi.DeviceSelector{CEL: &resourceapi.CELDeviceSelector{Expression: expression}})
+		}
+		return class
+	}
+
+	tests := []struct {
+		name  string
+		class *resourceapi.DeviceClass
+		want  string
+	}{
+		{
+			name:  "driver selector",
+			class: newClass(`device.driver == "gpu.nvidia.com"`),
+			want:  "gpu.nvidia.com",
+		},
+		{
+			name:  "driver selector with other conditions",
+			class: newClass(`device.attributes["gpu.nvidia.com"].type == "mig"`, `device.driver=='gpu.nvidia.com' && device.attributes["gpu.nvidia.com"].type == "gpu"`),
+			want:  "gpu.nvidia.com",
+		},
+		{
+			name:  "no driver selector",
+			class: newClass(`device.attributes["gpu.nvidia.com"].type == "gpu"`),
+		},
+		{
+			name:  "no selector",
+			class: newClass(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DeviceClassDriver(tt.class); got != tt.want {
+				t.Errorf("DeviceClassDriver() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewDeviceClassRequest(t *testing.T) {
+	newClass := func(name, driver string) resourceapi.DeviceClass {
+		return resourceapi.DeviceClass{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: resourceapi.DeviceClassSpec{
+				Selectors: []resourceapi.DeviceSelector{{CEL: &resourceapi.CELDeviceSelector{Expression: `device.driver == "` + driver + `"`}}},
+			},
+		}
+	}
+	newSlice := func(driver string, devices ...resourceapi.Device) resourceapi.ResourceSlice {
+		return resourceapi.ResourceSlice{Spec: resourceapi.ResourceSliceSpec{Driver: driver, Devices: devices}}
+	}
+	gpu := resourceapi.Device{
+		Name: "gpu-0",
+		Attributes: map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{
+			"architecture":                    {StringValue: ptr.To("Ampere")},
+			"index":                           {IntValue: ptr.To[int64](0)},
+			"gpu.nvidia.com/productName":      {StringValue: ptr.To("NVIDIA A100-SXM4-40GB")},
+			"resource.kubernetes.io/pcieRoot": {StringValue: ptr.To("pci0000:00")},
+		},
+	}
+	tpu := resourceapi.Device{
+		Name: "tpu-0",
+		Attributes: map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{
+			"type":    {StringValue: ptr.To("v5e")},
+			"version": {VersionValue: ptr.To("1.0.0")},
+		},
+	}
+	noAttributes := resourceapi.Device{Name: "device-0"}
+	nic := resourceapi.Device{
+		Name: "eth1",
+		Attributes: map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{
+			"ifName": {StringValue: ptr.To("eth1")},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		classes   []resourceapi.DeviceClass
+		slices    []resourceapi.ResourceSlice
+		className string
+		driver    string
+		want      *DeviceClassRequest
+	}{
+		{
+			name:    "product name is preferred",
+			classes: []resourceapi.DeviceClass{newClass("gpu.nvidia.com", "gpu.nvidia.com")},
+			slices:  []resourceapi.ResourceSlice{newSlice("gpu.nvidia.com", gpu)},
+			want: &DeviceClassRequest{
+				DeviceClassName: "gpu.nvidia.com",
+				Driver:          "gpu.nvidia.com",
+				Device:          "gpu-0",
+				Selector:        `device.attributes["gpu.nvidia.com"].productName == "NVIDIA A100-SXM4-40GB"`,
+			},
+		},
+		{
+			name:    "attribute in the domain of the driver",
+			classes: []resourceapi.DeviceClass{newClass("tpu", "tpu.example.com")},
+			slices:  []resourceapi.ResourceSlice{newSlice("tpu.example.com", tpu)},
+			want: &DeviceClassRequest{
+				DeviceClassName: "tpu",
+				Driver:          "tpu.example.com",
+				Device:          "tpu-0",
+				Selector:        `device.attributes["tpu.example.com"].type == "v5e"`,
+			},
+		},
+		{
+			name:    "devices without string attributes are skipped",
+			classes: []resourceapi.DeviceClass{newClass("test-driver", "test-driver.k8s.io"), newClass("tpu", "tpu.example.com")},
+			slices:  []resourceapi.ResourceSlice{newSlice("test-driver.k8s.io", noAttributes), newSlice("tpu.example.com", tpu)},
+			want: &DeviceClassRequest{
+				DeviceClassName: "tpu",
+				Driver:          "tpu.example.com",
+				Device:          "tpu-0",
+				Selector:        `device.attributes["tpu.example.com"].type == "v5e"`,
+			},
+		},
+		{
+			name:      "the given class",
+			classes:   []resourceapi.DeviceClass{newClass("gpu.nvidia.com", "gpu.nvidia.com"), newClass("tpu", "tpu.example.com")},
+			slices:    []resourceapi.ResourceSlice{newSlice("gpu.nvidia.com", gpu), newSlice("tpu.example.com", tpu)},
+			className: "tpu",
+			want: &DeviceClassRequest{
+				DeviceClassName: "tpu",
+				Driver:          "tpu.example.com",
+				Device:          "tpu-0",
+				Selector:        `device.attributes["tpu.example.com"].type == "v5e"`,
+			},
+		},
+		{
+			name:    "accelerators are preferred",
+			classes: []resourceapi.DeviceClass{newClass("dra.net", "dra.net"), newClass("gpu.nvidia.com", "gpu.nvidia.com")},
+			slices:  []resourceapi.ResourceSlice{newSlice("dra.net", nic), newSlice("gpu.nvidia.com", gpu)},
+			want: &DeviceClassRequest{
+				DeviceClassName: "gpu.nvidia.com",
+				Driver:          "gpu.nvidia.com",
+				Device:          "gpu-0",
+				Selector:        `device.attributes["gpu.nvidia.com"].productName == "NVIDIA A100-SXM4-40GB"`,
+			},
+		},
+		{
+			name:    "the given driver",
+			classes: []resourceapi.DeviceClass{newClass("dra.net", "dra.net"), newClass("gpu.nvidia.com", "gpu.nvidia.com")},
+			slices:  []resourceapi.ResourceSlice{newSlice("dra.net", nic), newSlice("gpu.nvidia.com", gpu)},
+			driver:  "dra.net",
+			want: &DeviceClassRequest{
+				DeviceClassName: "dra.net",
+				Driver:          "dra.net",
+				Device:          "eth1",
+				Selector:        `device.attributes["dra.net"].ifName == "eth1"`,
+			},
+		},
+		{
+			name:    "the given driver has no DeviceClass",
+			classes: []resourceapi.DeviceClass{newClass("gpu.nvidia.com", "gpu.nvidia.com")},
+			slices:  []resourceapi.ResourceSlice{newSlice("gpu.nvidia.com", gpu), newSlice("tpu.example.com", tpu)},
+			driver:  "tpu.example.com",
+		},
+		{
+			name:    "driver without ResourceSlices",
+			classes: []resourceapi.DeviceClass{newClass("gpu.nvidia.com", "gpu.nvidia.com")},
+			slices:  []resourceapi.ResourceSlice{newSlice("tpu.example.com", tpu)},
+		},
+		{
+			name:      "the given class doesn't exist",
+			classes:   []resourceapi.DeviceClass{newClass("gpu.nvidia.com", "gpu.nvidia.com")},
+			slices:    []resourceapi.ResourceSlice{newSlice("gpu.nvidia.com", gpu)},
+			className: "tpu",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewDeviceClassRequest(tt.classes, tt.slices, tt.className, tt.driver)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewDeviceClassRequest() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClaimsAllocatedDevice(t *testing.T) {
+	newClaim := func(name string, results ...resourceapi.DeviceRequestAllocationResult) resourceapi.ResourceClaim {
+		claim := resourceapi.ResourceClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "e2e", Name: name}}
+		if len(results) > 0 {
+			claim.Status.Allocation = &resourceapi.AllocationResult{Devices: resourceapi.DeviceAllocationResult{Results: results}}
+		}
+		return claim
+	}
+	device := resourceapi.DeviceRequestAllocationResult{Request: "accelerator", Driver: "gpu.nvidia.com", Pool: "node-a", Device: "gpu-0"}
+	otherDevice := resourceapi.DeviceRequestAllocationResult{Request: "accelerator", Driver: "gpu.nvidia.com", Pool: "node-a", Device: "gpu-1"}
+	otherPool := resourceapi.DeviceRequestAllocationResult{Request: "accelerator", Driver: "gpu.nvidia.com", Pool: "node-b", Device: "gpu-0"}
+
+	tests := []struct {
+		name   string
+		claims []resourceapi.ResourceClaim
+		want   []string
+	}{
+		{
+			name:   "deallocated",
+			claims: []resourceapi.ResourceClaim{newClaim("accelerator"), newClaim("other", otherDevice, otherPool)},
+		},
+		{
+			name:   "allocated",
+			claims: []resourceapi.ResourceClaim{newClaim("accelerator", otherDevice, device), newClaim("other", otherPool)},
+			want:   []string{"e2e/accelerator"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ClaimsAllocatedDevice(tt.claims, device)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ClaimsAllocatedDevice() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
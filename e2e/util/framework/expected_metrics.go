@@ -0,0 +1,113 @@
+package framework
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+
+	yaml "go.yaml.in/yaml/v2"
+
+	e2econfig "k8s.io/kubernetes/test/e2e/framework/config"
+)
+
+var expectedMetrics struct {
+	File string `default:"" usage:"path of the YAML file listing the required and optional metric names of each accelerator vendor and AI service, in the format of e2e/util/framework/expected_metrics.yaml. If unspecified, the defaults in that file are used, which cover the NVIDIA DCGM exporter, the AMD exporters and the common inference servers"`
+}
+var _ = e2econfig.AddOptions(&expectedMetrics, "ai.expectedMetrics")
+
+// defaultExpectedMetrics are the expected metrics used unless -ai.expectedMetrics.file is specified.
+//
+//go:embed expected_metrics.yaml
+var defaultExpectedMetrics []byte
+
+// MetricNames are the regular expressions of the metric names expected from an exporter or an AI service.
+// Alternatives are separated by "|" to support different exporters or versions.
+type MetricNames struct {
+	// Required are the metrics which MUST be emitted.
+	Required []string `yaml:"required"`
+	// Optional are the metrics which SHOULD be emitted.
+	Optional []string `yaml:"optional"`
+}
+
+// MissingRequired returns the required metrics which are not matched by any of the given names.
+func (m MetricNames) MissingRequired(names []string) []string {
+	return missingMetrics(m.Required, names)
+}
+
+// MissingOptional returns the optional metrics which are not matched by any of the given names.
+func (m MetricNames) MissingOptional(names []string) []string {
+	return missingMetrics(m.Optional, names)
+}
+
+// ExpectedMetrics are the metric names expected by the observability specs in each area.
+type ExpectedMetrics struct {
+	// Accelerator are the metrics of the accelerator exporters keyed by the lowercase name of the vendor.
+	Accelerator map[string]MetricNames `yaml:"accelerator"`
+	// AIService are the metrics of the AI services keyed by the inference server, e.g. vllm.
+	AIService map[string]MetricNames `yaml:"aiService"`
+}
+
+// LoadExpectedMetrics loads the expected metrics from -ai.expectedMetrics.file, or the defaults if it's unspecified.
+func LoadExpectedMetrics() (*ExpectedMetrics, error) {
+	if expectedMetrics.File == "" {
+		return ParseExpectedMetrics(defaultExpectedMetrics)
+	}
+	data, err := os.ReadFile(expectedMetrics.File)
+	if err != nil {
+		return nil, fmt.Errorf("error when reading the expected metrics: %w", err)
+	}
+	m, err := ParseExpectedMetrics(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expected metrics in %s: %w", expectedMetrics.File, err)
+	}
+	return m, nil
+}
+
+// ParseExpectedMetrics parses and validates the expected metrics. Unknown fields are rejected, every metric must be
+// a valid regular expression, and every vendor must require at least one metric, i.e. the utilization of the
+// accelerator.
+func ParseExpectedMetrics(data []byte) (*ExpectedMetrics, error) {
+	m := &ExpectedMetrics{}
+	if err := yaml.UnmarshalStrict(data, m); err != nil {
+		return nil, err
+	}
+	for _, area := range []struct {
+		name    string
+		metrics map[string]MetricNames
+	}{
+		{name: "accelerator", metrics: m.Accelerator},
+		{name: "aiService", metrics: m.AIService},
+	} {
+		keys := make([]string, 0, len(area.metrics))
+		for key := range area.metrics {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			names := area.metrics[key]
+			if area.name == "accelerator" && len(names.Required) == 0 {
+				return nil, fmt.Errorf("%s %s requires no metric, the first required one must be the utilization", area.name, key)
+			}
+			for _, metric := range append(names.Required, names.Optional...) {
+				if _, err := regexp.Compile("^(" + metric + ")$"); err != nil {
+					return nil, fmt.Errorf("invalid metric %q of %s %s: %w", metric, area.name, key, err)
+				}
+			}
+		}
+	}
+	return m, nil
+}
+
+// AcceleratorVendor returns the vendor with its core and recommended metrics replaced by the required and optional
+// metrics of its entry, or the vendor as is if it has no entry.
+func (m *ExpectedMetrics) AcceleratorVendor(vendor AcceleratorVendor) AcceleratorVendor {
+	names, ok := m.Accelerator[vendor.Name]
+	if !ok {
+		return vendor
+	}
+	vendor.CoreMetrics = names.Required
+	vendor.RecommendedMetrics = names.Optional
+	return vendor
+}
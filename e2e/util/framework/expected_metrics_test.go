@@ -0,0 +1,126 @@
+package framework
+
+import (
+	"reflect"
+	"testing"
+)
+
+// withDefaultExpectedMetrics returns the vendor with the metrics of the default expected metrics.
+func withDefaultExpectedMetrics(t *testing.T, vendor AcceleratorVendor) AcceleratorVendor {
+	t.Helper()
+	m, err := ParseExpectedMetrics(defaultExpectedMetrics)
+	if err != nil {
+		t.Fatalf("ParseExpectedMetrics() of the defaults error = %v", err)
+	}
+	return m.AcceleratorVendor(vendor)
+}
+
+func TestParseExpectedMetrics(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    *ExpectedMetrics
+		wantErr bool
+	}{
+		{
+			name: "areas",
+			data: `
+accelerator:
+  nvidia:
+    required: [DCGM_FI_DEV_GPU_UTIL]
+    optional: [DCGM_FI_DEV_GPU_TEMP]
+aiService:
+  vllm:
+    required: ["vllm:num_requests_running"]
+`,
+			want: &ExpectedMetrics{
+				Accelerator: map[string]MetricNames{
+					"nvidia": {Required: []string{"DCGM_FI_DEV_GPU_UTIL"}, Optional: []string{"DCGM_FI_DEV_GPU_TEMP"}},
+				},
+				AIService: map[string]MetricNames{
+					"vllm": {Required: []string{"vllm:num_requests_running"}},
+				},
+			},
+		},
+		{
+			name: "empty",
+			data: "",
+			want: &ExpectedMetrics{},
+		},
+		{
+			name:    "unknown field",
+			data:    "accelerators: {}\n",
+			wantErr: true,
+		},
+		{
+			name:    "vendor without required metrics",
+			data:    "accelerator:\n  nvidia:\n    optional: [DCGM_FI_DEV_GPU_TEMP]\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid regular expression",
+			data:    "aiService:\n  vllm:\n    optional: [\"vllm:(\"]\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseExpectedMetrics([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseExpectedMetrics() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseExpectedMetrics() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultExpectedMetrics(t *testing.T) {
+	m, err := ParseExpectedMetrics(defaultExpectedMetrics)
+	if err != nil {
+		t.Fatalf("ParseExpectedMetrics() of the defaults error = %v", err)
+	}
+	for _, vendor := range AcceleratorVendors {
+		if len(m.AcceleratorVendor(vendor).CoreMetrics) == 0 {
+			t.Errorf("vendor %s has no default core metrics", vendor.Name)
+		}
+	}
+	for _, server := range []string{"vllm", "sglang", "tgi", "triton"} {
+		if len(m.AIService[server].Required) == 0 {
+			t.Errorf("AI service %s has no default required metrics", server)
+		}
+	}
+}
+
+func TestExpectedMetricsAcceleratorVendor(t *testing.T) {
+	m := &ExpectedMetrics{
+		Accelerator: map[string]MetricNames{
+			"nvidia": {Required: []string{"DCGM_FI_PROF_GR_ENGINE_ACTIVE"}},
+		},
+	}
+	got := m.AcceleratorVendor(NVIDIA)
+	if !reflect.DeepEqual(got.CoreMetrics, []string{"DCGM_FI_PROF_GR_ENGINE_ACTIVE"}) || got.RecommendedMetrics != nil {
+		t.Errorf("AcceleratorVendor(NVIDIA) metrics = %v, %v, want the ones of the entry", got.CoreMetrics, got.RecommendedMetrics)
+	}
+	if got.ResourceName != NVIDIA.ResourceName {
+		t.Errorf("AcceleratorVendor(NVIDIA) resource = %s, want %s", got.ResourceName, NVIDIA.ResourceName)
+	}
+	if got := m.AcceleratorVendor(AMD); !reflect.DeepEqual(got, AMD) {
+		t.Errorf("AcceleratorVendor(AMD) = %+v, want it as is", got)
+	}
+}
+
+func TestMetricNamesMissing(t *testing.T) {
+	names := MetricNames{
+		Required: []string{"vllm:num_requests_running", "vllm:num_requests_waiting"},
+		Optional: []string{"vllm:gpu_cache_usage_perc|vllm:kv_cache_usage_perc"},
+	}
+	collected := []string{"vllm:num_requests_running", "vllm:kv_cache_usage_perc"}
+	if got, want := names.MissingRequired(collected), []string{"vllm:num_requests_waiting"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MissingRequired() = %v, want %v", got, want)
+	}
+	if got := names.MissingOptional(collected); got != nil {
+		t.Errorf("MissingOptional() = %v, want none", got)
+	}
+}
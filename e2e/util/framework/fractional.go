@@ -0,0 +1,152 @@
+package framework
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// migResourcePrefix is the prefix of the MIG devices advertised by the NVIDIA device plugin with the mixed MIG
+// strategy, e.g. nvidia.com/mig-1g.5gb.
+const migResourcePrefix = "nvidia.com/mig-"
+
+// FractionalAcceleratorResources are the well-known extended resources of the fractional NVIDIA GPUs, in the order
+// of detection, besides the MIG devices.
+var FractionalAcceleratorResources = []v1.ResourceName{
+	// The replicas of the GPUs shared by time-slicing or MPS of the NVIDIA device plugin with renameByDefault.
+	"nvidia.com/gpu.shared",
+	// The GPU memory in GiB of the GPU sharing of Alibaba Cloud.
+	"aliyun.com/gpu-mem",
+}
+
+// IsMIGResource returns true if the resource is a MIG device of the NVIDIA device plugin.
+func IsMIGResource(resourceName v1.ResourceName) bool {
+	return strings.HasPrefix(string(resourceName), migResourcePrefix)
+}
+
+// gpuMemoryResourceMiB are the MiB of a unit of the well-known extended resources of the GPU memory.
+var gpuMemoryResourceMiB = map[v1.ResourceName]int64{
+	// The GPU memory in GiB of the GPU sharing of Alibaba Cloud.
+	"aliyun.com/gpu-mem": 1024,
+	// The GPU memory in MiB of HAMi.
+	"nvidia.com/gpumem": 1,
+}
+
+// AcceleratorMemoryPartitionMiB returns the accelerator memory in MiB which the fractional resources permit, i.e.
+// the memory of the requested MIG device, e.g. 5120 of nvidia.com/mig-1g.5gb, or the requested quantity of a GPU
+// memory resource, the smallest if several. 0 is returned if the resources don't partition the memory, e.g. the
+// time-sliced GPUs.
+func AcceleratorMemoryPartitionMiB(resources v1.ResourceList) int64 {
+	var partition int64
+	for resourceName, quantity := range resources {
+		var mib int64
+		if IsMIGResource(resourceName) {
+			// The profile is [<compute slices>c.]<GPU slices>g.<memory>gb[+<extension>].
+			profile := strings.TrimPrefix(string(resourceName), migResourcePrefix)
+			profile, _, _ = strings.Cut(profile, "+")
+			memory := profile[strings.LastIndex(profile, ".")+1:]
+			gb, err := strconv.ParseInt(strings.TrimSuffix(memory, "gb"), 10, 64)
+			if err != nil || !strings.HasSuffix(memory, "gb") {
+				continue
+			}
+			mib = gb * 1024 * quantity.Value()
+		} else if unit, ok := gpuMemoryResourceMiB[resourceName]; ok {
+			mib = unit * quantity.Value()
+		}
+		if mib > 0 && (partition == 0 || mib < partition) {
+			partition = mib
+		}
+	}
+	return partition
+}
+
+// DetectFractionalAcceleratorResource returns the first of FractionalAcceleratorResources which is allocatable on
+// any of the given nodes, or a MIG device if none is. An empty name is returned if there is none, or if NVIDIA is
+// not selected by -ai.vendor.
+func DetectFractionalAcceleratorResource(nodes []v1.Node) v1.ResourceName {
+	if len(selectVendors([]AcceleratorVendor{NVIDIA})) == 0 {
+		return ""
+	}
+	for _, resourceName := range FractionalAcceleratorResources {
+		for _, node := range nodes {
+			if val, ok := node.Status.Allocatable[resourceName]; ok && !val.IsZero() {
+				return resourceName
+			}
+		}
+	}
+	var migResources []string
+	for _, node := range nodes {
+		for resourceName, val := range node.Status.Allocatable {
+			if IsMIGResource(resourceName) && !val.IsZero() {
+				migResources = append(migResources, string(resourceName))
+			}
+		}
+	}
+	if len(migResources) == 0 {
+		return ""
+	}
+	// Pick the smallest profile in a deterministic order, e.g. nvidia.com/mig-1g.5gb over nvidia.com/mig-3g.20gb.
+	slices.Sort(migResources)
+	return v1.ResourceName(migResources[0])
+}
+
+// ParseResourceList parses the comma-separated <resource>=<quantity> entries, e.g.
+// nvidia.com/gpu=1,nvidia.com/gpumem=3000.
+func ParseResourceList(s string) (v1.ResourceList, error) {
+	resources := v1.ResourceList{}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid resource %q, must be <resource>=<quantity>", entry)
+		}
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quantity of resource %q: %w", name, err)
+		}
+		resources[v1.ResourceName(name)] = quantity
+	}
+	return resources, nil
+}
+
+// VisibleNVIDIAGPUs returns the number of the GPUs and the MIG devices listed in the output of nvidia-smi -L.
+func VisibleNVIDIAGPUs(output string) (gpus, migDevices int) {
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "GPU "):
+			gpus++
+		case strings.HasPrefix(line, "MIG "):
+			migDevices++
+		}
+	}
+	return gpus, migDevices
+}
+
+// SelectSharedAcceleratorNode returns the first of the given nodes whose allocatable of each of the resources is at
+// least the given number of times its quantity, so that as many pods requesting the resources can share it. Nil is
+// returned if there is none.
+func SelectSharedAcceleratorNode(nodes []v1.Node, resources v1.ResourceList, pods int) *v1.Node {
+	for i := range nodes {
+		fits := true
+		for resourceName, quantity := range resources {
+			required := quantity.DeepCopy()
+			required.Mul(int64(pods))
+			if val, ok := nodes[i].Status.Allocatable[resourceName]; !ok || val.Cmp(required) < 0 {
+				fits = false
+				break
+			}
+		}
+		if fits {
+			return &nodes[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+package framework
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestDetectFractionalAcceleratorResource(t *testing.T) {
+	newNode := func(allocatable map[v1.ResourceName]string) v1.Node {
+		node := v1.Node{Status: v1.NodeStatus{Allocatable: v1.ResourceList{}}}
+		for name, value := range allocatable {
+			node.Status.Allocatable[name] = resource.MustParse(value)
+		}
+		return node
+	}
+
+	tests := []struct {
+		name   string
+		nodes  []v1.Node
+		vendor string
+		want   v1.ResourceName
+	}{
+		{
+			name:  "shared GPUs",
+			nodes: []v1.Node{newNode(map[v1.ResourceName]string{"nvidia.com/gpu": "0", "nvidia.com/gpu.shared": "8"})},
+			want:  "nvidia.com/gpu.shared",
+		},
+		{
+			name: "known resource is preferred over MIG devices",
+			nodes: []v1.Node{
+				newNode(map[v1.ResourceName]string{"nvidia.com/mig-1g.5gb": "7"}),
+				newNode(map[v1.ResourceName]string{"aliyun.com/gpu-mem": "80"}),
+			},
+			want: "aliyun.com/gpu-mem",
+		},
+		{
+			name: "smallest MIG profile",
+			nodes: []v1.Node{
+				newNode(map[v1.ResourceName]string{"nvidia.com/mig-3g.20gb": "2"}),
+				newNode(map[v1.ResourceName]string{"nvidia.com/mig-1g.5gb": "7", "nvidia.com/mig-2g.10gb": "3"}),
+			},
+			want: "nvidia.com/mig-1g.5gb",
+		},
+		{
+			name:  "zero allocatable",
+			nodes: []v1.Node{newNode(map[v1.ResourceName]string{"nvidia.com/gpu.shared": "0", "nvidia.com/mig-1g.5gb": "0"})},
+		},
+		{
+			name:  "whole GPUs only",
+			nodes: []v1.Node{newNode(map[v1.ResourceName]string{"nvidia.com/gpu": "8"})},
+		},
+		{
+			name:   "nvidia is not selected",
+			nodes:  []v1.Node{newNode(map[v1.ResourceName]string{"nvidia.com/gpu.shared": "8"})},
+			vendor: "amd",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vendorFilter.Vendor = tt.vendor
+			defer func() { vendorFilter.Vendor = "" }()
+			if got := DetectFractionalAcceleratorResource(tt.nodes); got != tt.want {
+				t.Errorf("DetectFractionalAcceleratorResource() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAcceleratorMemoryPartitionMiB(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources v1.ResourceList
+		want      int64
+	}{
+		{
+			name:      "MIG device",
+			resources: v1.ResourceList{"nvidia.com/mig-1g.5gb": resource.MustParse("1")},
+			want:      5120,
+		},
+		{
+			name:      "MIG device with compute instance and media extension",
+			resources: v1.ResourceList{"nvidia.com/mig-1c.3g.20gb+me": resource.MustParse("1")},
+			want:      20480,
+		},
+		{
+			name:      "GPU memory in GiB",
+			resources: v1.ResourceList{"aliyun.com/gpu-mem": resource.MustParse("4")},
+			want:      4096,
+		},
+		{
+			name:      "GPU memory in MiB",
+			resources: v1.ResourceList{"nvidia.com/gpu": resource.MustParse("1"), "nvidia.com/gpumem": resource.MustParse("3000")},
+			want:      3000,
+		},
+		{
+			name:      "time-slicing",
+			resources: v1.ResourceList{"nvidia.com/gpu.shared": resource.MustParse("1")},
+		},
+		{
+			name:      "unknown MIG profile",
+			resources: v1.ResourceList{"nvidia.com/mig-unknown": resource.MustParse("1")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AcceleratorMemoryPartitionMiB(tt.resources); got != tt.want {
+				t.Errorf("AcceleratorMemoryPartitionMiB() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseResourceList(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    v1.ResourceList
+		wantErr bool
+	}{
+		{
+			name: "empty",
+			want: v1.ResourceList{},
+		},
+		{
+			name: "multiple resources",
+			s:    "nvidia.com/gpu=1, nvidia.com/gpumem=3000",
+			want: v1.ResourceList{
+				"nvidia.com/gpu":    resource.MustParse("1"),
+				"nvidia.com/gpumem": resource.MustParse("3000"),
+			},
+		},
+		{
+			name:    "missing quantity",
+			s:       "nvidia.com/gpu",
+			wantErr: true,
+		},
+		{
+			name:    "missing resource",
+			s:       "=1",
+			wantErr: true,
+		},
+		{
+			name:    "invalid quantity",
+			s:       "nvidia.com/gpu=one",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseResourceList(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseResourceList() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseResourceList() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVisibleNVIDIAGPUs(t *testing.T) {
+	tests := []struct {
+		name           string
+		output         string
+		wantGPUs       int
+		wantMIGDevices int
+	}{
+		{
+			name:     "single GPU",
+			output:   "GPU 0: NVIDIA A10 (UUID: GPU-8f1c9b2e-0d55-4a0e-9a43-2f6b7c1e9d10)\n",
+			wantGPUs: 1,
+		},
+		{
+			name: "MIG device",
+			output: "GPU 0: NVIDIA A100-SXM4-40GB (UUID: GPU-5d3c0a4e-1b2f-4c6d-8e9f-0a1b2c3d4e5f)\n" +
+				"  MIG 1g.5gb      Device  0: (UUID: MIG-7a8b9c0d-1e2f-5a3b-9c4d-5e6f7a8b9c0d)\n",
+			wantGPUs:       1,
+			wantMIGDevices: 1,
+		},
+		{
+			name: "all GPUs",
+			output: "GPU 0: NVIDIA H100 80GB HBM3 (UUID: GPU-0)\n" +
+				"GPU 1: NVIDIA H100 80GB HBM3 (UUID: GPU-1)\n",
+			wantGPUs: 2,
+		},
+		{
+			name:   "no GPU",
+			output: "No devices found.\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gpus, migDevices := VisibleNVIDIAGPUs(tt.output)
+			if gpus != tt.wantGPUs || migDevices != tt.wantMIGDevices {
+				t.Errorf("VisibleNVIDIAGPUs() = %d, %d, want %d, %d", gpus, migDevices, tt.wantGPUs, tt.wantMIGDevices)
+			}
+		})
+	}
+}
+
+func TestSelectSharedAcceleratorNode(t *testing.T) {
+	newNode := func(name string, allocatable v1.ResourceList) v1.Node {
+		node := v1.Node{Status: v1.NodeStatus{Allocatable: allocatable}}
+		node.Name = name
+		return node
+	}
+	nodes := []v1.Node{
+		newNode("cpu", v1.ResourceList{v1.ResourceCPU: resource.MustParse("8")}),
+		newNode("single-replica", v1.ResourceList{"nvidia.com/gpu.shared": resource.MustParse("1")}),
+		newNode("time-sliced", v1.ResourceList{"nvidia.com/gpu.shared": resource.MustParse("4")}),
+		newNode("hami", v1.ResourceList{"nvidia.com/gpu": resource.MustParse("10"), "nvidia.com/gpumem": resource.MustParse("5000")}),
+	}
+
+	tests := []struct {
+		name      string
+		resources v1.ResourceList
+		pods      int
+		want      string
+	}{
+		{
+			name:      "enough replicas",
+			resources: v1.ResourceList{"nvidia.com/gpu.shared": resource.MustParse("1")},
+			pods:      2,
+			want:      "time-sliced",
+		},
+		{
+			name:      "all resources must fit",
+			resources: v1.ResourceList{"nvidia.com/gpu": resource.MustParse("1"), "nvidia.com/gpumem": resource.MustParse("2500")},
+			pods:      2,
+			want:      "hami",
+		},
+		{
+			name:      "not enough of a resource",
+			resources: v1.ResourceList{"nvidia.com/gpu": resource.MustParse("1"), "nvidia.com/gpumem": resource.MustParse("3000")},
+			pods:      2,
+		},
+		{
+			name:      "resource not advertised",
+			resources: v1.ResourceList{"nvidia.com/mig-1g.5gb": resource.MustParse("1")},
+			pods:      2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			if node := SelectSharedAcceleratorNode(nodes, tt.resources, tt.pods); node != nil {
+				got = node.Name
+			}
+			if got != tt.want {
+				t.Errorf("SelectSharedAcceleratorNode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
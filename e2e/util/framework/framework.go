@@ -7,8 +7,21 @@ import (
 
 	"github.com/onsi/ginkgo/v2"
 	"k8s.io/kubernetes/test/e2e/framework"
+	e2econfig "k8s.io/kubernetes/test/e2e/framework/config"
 )
 
+const (
+	// LevelMust is the label of the specs which are required by the AI conformance.
+	LevelMust = "MUST"
+	// LevelShould is the label of the specs which are recommended but optional.
+	LevelShould = "SHOULD"
+)
+
+var level struct {
+	Level string `default:"MUST" usage:"conformance level of the AI conformance specs to run, either MUST or SHOULD. MUST runs the required specs only, SHOULD runs the recommended specs as well. It's combined with -ginkgo.label-filter"`
+}
+var _ = e2econfig.AddOptions(&level, "ai")
+
 var sigRE = regexp.MustCompile(`^[a-z]+(-[a-z]+)*$`)
 
 // WGDescribe returns a wrapper function for ginkgo.Describe which injects
@@ -25,7 +38,32 @@ func WGDescribe(wg string) func(...interface{}) bool {
 }
 
 // AIConformanceIt is wrapper function for ginkgo It. Adds "[Conformance]" and "[AIConformance]" tags and makes static analysis easier.
+// The MUST label is added without a tag, so that the text of the specs, which the focus patterns and the results of
+// the previous releases refer to, is unchanged.
 func AIConformanceIt(args ...interface{}) bool {
-	args = append(args, ginkgo.Offset(1), framework.WithConformance(), framework.WithLabel("AIConformance"))
+	args = append(args, ginkgo.Offset(1), framework.WithConformance(), framework.WithLabel("AIConformance"), ginkgo.Label(LevelMust))
+	return framework.It(args...)
+}
+
+// AIConformanceShouldIt is wrapper function for ginkgo It for the recommended checks. Adds "[AIConformance]" and
+// "[SHOULD]" tags. The specs are not conformance tests and only run if -ai.level is SHOULD.
+func AIConformanceShouldIt(args ...interface{}) bool {
+	args = append(args, ginkgo.Offset(1), framework.WithLabel("AIConformance"), framework.WithLabel(LevelShould))
 	return framework.It(args...)
 }
+
+// LevelLabelFilter returns the ginkgo label filter which combines the given filter with the one selecting the specs
+// of the conformance level specified by -ai.level.
+func LevelLabelFilter(filter string) (string, error) {
+	switch level.Level {
+	case LevelMust:
+		if filter == "" {
+			return "!" + LevelShould, nil
+		}
+		return "(" + filter + ") && !" + LevelShould, nil
+	case LevelShould:
+		return filter, nil
+	default:
+		return "", fmt.Errorf("invalid -ai.level %q, must be %s or %s", level.Level, LevelMust, LevelShould)
+	}
+}
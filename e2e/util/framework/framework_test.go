@@ -0,0 +1,49 @@
+package framework
+
+import "testing"
+
+func TestLevelLabelFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		level   string
+		filter  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "must without filter",
+			level: LevelMust,
+			want:  "!SHOULD",
+		},
+		{
+			name:   "must with filter",
+			level:  LevelMust,
+			filter: "AIConformance || Conformance",
+			want:   "(AIConformance || Conformance) && !SHOULD",
+		},
+		{
+			name:   "should keeps the filter",
+			level:  LevelShould,
+			filter: "AIConformance",
+			want:   "AIConformance",
+		},
+		{
+			name:    "invalid level",
+			level:   "MAY",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			level.Level = tt.level
+			defer func() { level.Level = LevelMust }()
+			got, err := LevelLabelFilter(tt.filter)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LevelLabelFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("LevelLabelFilter() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
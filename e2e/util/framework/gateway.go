@@ -0,0 +1,237 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+const (
+	// GatewayAPIGroup is the group of the Gateway API.
+	GatewayAPIGroup = "gateway.networking.k8s.io"
+	// gatewayProgrammedTimeout is how long to wait for a Gateway to be programmed by its controller.
+	gatewayProgrammedTimeout = 5 * time.Minute
+)
+
+var (
+	// GatewayGVR is the resource of the Gateway API gateways.
+	GatewayGVR = schema.GroupVersionResource{Group: GatewayAPIGroup, Version: "v1", Resource: "gateways"}
+	// GatewayClassGVR is the resource of the Gateway API gatewayclasses.
+	GatewayClassGVR = schema.GroupVersionResource{Group: GatewayAPIGroup, Version: "v1", Resource: "gatewayclasses"}
+	// HTTPRouteGVR is the resource of the Gateway API httproutes.
+	HTTPRouteGVR = schema.GroupVersionResource{Group: GatewayAPIGroup, Version: "v1", Resource: "httproutes"}
+)
+
+// ResolveGatewayAddress waits until the given Gateway has the Programmed condition with True status and
+// returns the first address reported in its status.addresses. Both Hostname and IPAddress address types
+// are supported, an empty type is treated as IPAddress as defined by the Gateway API.
+func ResolveGatewayAddress(ctx context.Context, client dynamic.Interface, gatewayNamespace, gatewayName string) (string, error) {
+	var address string
+	var lastErr error
+	err := wait.PollUntilContextTimeout(ctx, framework.Poll, gatewayProgrammedTimeout, true, func(ctx context.Context) (bool, error) {
+		gw, err := client.Resource(GatewayGVR).Namespace(gatewayNamespace).Get(ctx, gatewayName, metav1.GetOptions{})
+		if err != nil {
+			lastErr = err
+			return false, nil
+		}
+		if !hasTrueCondition(gw, "Programmed") {
+			lastErr = fmt.Errorf("condition Programmed is not True")
+			return false, nil
+		}
+		addresses, _, err := unstructured.NestedSlice(gw.Object, "status", "addresses")
+		if err != nil {
+			return false, err
+		}
+		for _, item := range addresses {
+			addr, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			addrType, _, _ := unstructured.NestedString(addr, "type")
+			value, _, _ := unstructured.NestedString(addr, "value")
+			if value == "" {
+				continue
+			}
+			switch addrType {
+			case "", "IPAddress", "Hostname":
+				address = value
+				framework.Logf("Gateway %s/%s is programmed with address %s (type %q)", gatewayNamespace, gatewayName, address, addrType)
+				return true, nil
+			}
+		}
+		lastErr = fmt.Errorf("no Hostname or IPAddress address is reported in status.addresses: %v", addresses)
+		return false, nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("gateway %s/%s was not programmed with an address within %v: %w, last observed: %v", gatewayNamespace, gatewayName, gatewayProgrammedTimeout, err, lastErr)
+	}
+	return address, nil
+}
+
+// AcceptedGatewayClass returns the given GatewayClass if it has the Accepted condition with True status, or the first
+// accepted GatewayClass if the name is empty. An empty string is returned if none is accepted.
+func AcceptedGatewayClass(classes []unstructured.Unstructured, name string) string {
+	for i := range classes {
+		if name != "" && classes[i].GetName() != name {
+			continue
+		}
+		if hasTrueCondition(&classes[i], "Accepted") {
+			return classes[i].GetName()
+		}
+	}
+	return ""
+}
+
+// WaitForHTTPRouteAccepted waits until the status.parents entry of the given HTTPRoute for the given Gateway in the
+// same namespace has the Accepted and ResolvedRefs conditions with True status, which proves that the controller of
+// the Gateway reconciles the route.
+func WaitForHTTPRouteAccepted(ctx context.Context, client dynamic.Interface, namespace, name, gatewayName string, timeout time.Duration) error {
+	var lastErr error
+	err := wait.PollUntilContextTimeout(ctx, framework.Poll, timeout, true, func(ctx context.Context) (bool, error) {
+		route, err := client.Resource(HTTPRouteGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			lastErr = err
+			return false, nil
+		}
+		lastErr = routeParentConditions(route, gatewayName, "Accepted", "ResolvedRefs")
+		return lastErr == nil, nil
+	})
+	if err != nil {
+		return fmt.Errorf("route %s/%s was not accepted by gateway %s within %v: %w, last observed: %v", namespace, name, gatewayName, timeout, err, lastErr)
+	}
+	return nil
+}
+
+// routeParentConditions returns an error if the status.parents entry of the route for the given Gateway in the same
+// namespace doesn't have all the given conditions with True status.
+func routeParentConditions(route *unstructured.Unstructured, gatewayName string, conditionTypes ...string) error {
+	parents, _, _ := unstructured.NestedSlice(route.Object, "status", "parents")
+	for _, item := range parents {
+		parent, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		parentName, _, _ := unstructured.NestedString(parent, "parentRef", "name")
+		parentNamespace, _, _ := unstructured.NestedString(parent, "parentRef", "namespace")
+		if parentName != gatewayName || (parentNamespace != "" && parentNamespace != route.GetNamespace()) {
+			continue
+		}
+		conditions, _, _ := unstructured.NestedSlice(parent, "conditions")
+		for _, conditionType := range conditionTypes {
+			if !isTrueCondition(conditions, conditionType) {
+				return fmt.Errorf("condition %s of route %s for gateway %s is not True, conditions: %v", conditionType, route.GetName(), gatewayName, conditions)
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("route %s has no status for gateway %s, parents: %v", route.GetName(), gatewayName, parents)
+}
+
+// hasTrueCondition returns true if the status.conditions of the object contains the given condition
+// type with True status.
+func hasTrueCondition(obj *unstructured.Unstructured, conditionType string) bool {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	return isTrueCondition(conditions, conditionType)
+}
+
+// isTrueCondition returns true if the conditions contain the given condition type with True status.
+func isTrueCondition(conditions []interface{}, conditionType string) bool {
+	for _, item := range conditions {
+		cond, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if cond["type"] == conditionType && cond["status"] == string(metav1.ConditionTrue) {
+			return true
+		}
+	}
+	return false
+}
+
+// GatewayAPIResource is a resource of the Gateway API which must be served at a version.
+type GatewayAPIResource struct {
+	// Resource is the plural name of the resource, e.g. httproutes.
+	Resource string
+	// Version is the version which must be served, e.g. v1.
+	Version string
+}
+
+// String returns the resource in the <resource>/<version> format.
+func (r GatewayAPIResource) String() string {
+	return r.Resource + "/" + r.Version
+}
+
+var (
+	// StandardGatewayAPIResources are the resources of the standard channel of the Gateway API which the AI
+	// inference platforms must serve.
+	StandardGatewayAPIResources = []GatewayAPIResource{
+		{Resource: "gatewayclasses", Version: "v1"},
+		{Resource: "gateways", Version: "v1"},
+		{Resource: "httproutes", Version: "v1"},
+		{Resource: "grpcroutes", Version: "v1"},
+		{Resource: "referencegrants", Version: "v1beta1"},
+	}
+	// ExperimentalGatewayAPIResources are the resources of the experimental channel of the Gateway API which the
+	// platforms claiming the experimental support must serve in addition.
+	ExperimentalGatewayAPIResources = []GatewayAPIResource{
+		{Resource: "tcproutes", Version: "v1alpha2"},
+		{Resource: "tlsroutes", Version: "v1alpha2"},
+	}
+)
+
+// ParseGatewayAPIResources parses the comma-separated <resource>/<version> entries, e.g.
+// httproutes/v1,referencegrants/v1beta1.
+func ParseGatewayAPIResources(s string) ([]GatewayAPIResource, error) {
+	var resources []GatewayAPIResource
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		resource, version, ok := strings.Cut(entry, "/")
+		if !ok || resource == "" || version == "" || strings.Contains(version, "/") {
+			return nil, fmt.Errorf("invalid Gateway API resource %q, must be <resource>/<version>", entry)
+		}
+		resources = append(resources, GatewayAPIResource{Resource: resource, Version: version})
+	}
+	return resources, nil
+}
+
+// MissingGatewayAPIResources returns the required resources which are not served at the required version by the
+// given CRDs, with the reason, e.g. "tlsroutes/v1alpha2 (CRD tlsroutes.gateway.networking.k8s.io is not installed)".
+func MissingGatewayAPIResources(required []GatewayAPIResource, crds []apiextensionsv1.CustomResourceDefinition) []string {
+	byName := map[string]*apiextensionsv1.CustomResourceDefinition{}
+	for i := range crds {
+		byName[crds[i].Name] = &crds[i]
+	}
+	var missing []string
+	for _, r := range required {
+		name := r.Resource + "." + GatewayAPIGroup
+		crd, ok := byName[name]
+		if !ok {
+			missing = append(missing, fmt.Sprintf("%s (CRD %s is not installed)", r, name))
+			continue
+		}
+		var served []string
+		for _, version := range crd.Spec.Versions {
+			if version.Served {
+				served = append(served, version.Name)
+			}
+		}
+		if !slices.Contains(served, r.Version) {
+			missing = append(missing, fmt.Sprintf("%s (CRD %s only serves %v)", r, name, served))
+		}
+	}
+	return missing
+}
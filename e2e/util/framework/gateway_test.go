@@ -0,0 +1,295 @@
+package framework
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestHasTrueCondition(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []interface{}
+		want       bool
+	}{
+		{
+			name: "no conditions",
+			want: false,
+		},
+		{
+			name: "condition is true",
+			conditions: []interface{}{
+				map[string]interface{}{"type": "Accepted", "status": "True"},
+				map[string]interface{}{"type": "Programmed", "status": "True"},
+			},
+			want: true,
+		},
+		{
+			name: "condition is false",
+			conditions: []interface{}{
+				map[string]interface{}{"type": "Programmed", "status": "False"},
+			},
+			want: false,
+		},
+		{
+			name: "condition is unknown",
+			conditions: []interface{}{
+				map[string]interface{}{"type": "Programmed", "status": "Unknown"},
+			},
+			want: false,
+		},
+		{
+			name: "only other conditions are true",
+			conditions: []interface{}{
+				map[string]interface{}{"type": "Accepted", "status": "True"},
+			},
+			want: false,
+		},
+		{
+			name: "malformed condition is ignored",
+			conditions: []interface{}{
+				"Programmed",
+				map[string]interface{}{"type": "Programmed", "status": "True"},
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+			if tt.conditions != nil {
+				obj.Object["status"] = map[string]interface{}{"conditions": tt.conditions}
+			}
+			if got := hasTrueCondition(obj, "Programmed"); got != tt.want {
+				t.Errorf("hasTrueCondition() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAcceptedGatewayClass(t *testing.T) {
+	newClass := func(name, status string) unstructured.Unstructured {
+		return unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": name},
+			"status": map[string]interface{}{
+				"conditions": []interface{}{map[string]interface{}{"type": "Accepted", "status": status}},
+			},
+		}}
+	}
+	classes := []unstructured.Unstructured{newClass("envoy", "False"), newClass("istio", "True"), newClass("kgateway", "True")}
+
+	tests := []struct {
+		name    string
+		classes []unstructured.Unstructured
+		class   string
+		want    string
+	}{
+		{
+			name:    "first accepted class",
+			classes: classes,
+			want:    "istio",
+		},
+		{
+			name:    "the given class",
+			classes: classes,
+			class:   "kgateway",
+			want:    "kgateway",
+		},
+		{
+			name:    "the given class is not accepted",
+			classes: classes,
+			class:   "envoy",
+		},
+		{
+			name:    "the given class doesn't exist",
+			classes: classes,
+			class:   "cilium",
+		},
+		{
+			name: "no classes",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AcceptedGatewayClass(tt.classes, tt.class); got != tt.want {
+				t.Errorf("AcceptedGatewayClass() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouteParentConditions(t *testing.T) {
+	newParent := func(name, namespace string, accepted, resolvedRefs string) interface{} {
+		parentRef := map[string]interface{}{"name": name}
+		if namespace != "" {
+			parentRef["namespace"] = namespace
+		}
+		return map[string]interface{}{
+			"parentRef": parentRef,
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Accepted", "status": accepted},
+				map[string]interface{}{"type": "ResolvedRefs", "status": resolvedRefs},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		parents []interface{}
+		wantErr bool
+	}{
+		{
+			name:    "accepted",
+			parents: []interface{}{newParent("gateway", "", "True", "True")},
+		},
+		{
+			name:    "accepted by the gateway in the same namespace",
+			parents: []interface{}{newParent("gateway", "other", "False", "False"), newParent("gateway", "default", "True", "True")},
+		},
+		{
+			name:    "not accepted",
+			parents: []interface{}{newParent("gateway", "", "False", "True")},
+			wantErr: true,
+		},
+		{
+			name:    "refs not resolved",
+			parents: []interface{}{newParent("gateway", "", "True", "False")},
+			wantErr: true,
+		},
+		{
+			name:    "accepted by another gateway",
+			parents: []interface{}{newParent("other", "", "True", "True")},
+			wantErr: true,
+		},
+		{
+			name:    "no status",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := &unstructured.Unstructured{Object: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "route", "namespace": "default"},
+			}}
+			if tt.parents != nil {
+				route.Object["status"] = map[string]interface{}{"parents": tt.parents}
+			}
+			err := routeParentConditions(route, "gateway", "Accepted", "ResolvedRefs")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("routeParentConditions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseGatewayAPIResources(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    []GatewayAPIResource
+		wantErr bool
+	}{
+		{
+			name:  "resources",
+			value: "httproutes/v1, referencegrants/v1beta1,",
+			want: []GatewayAPIResource{
+				{Resource: "httproutes", Version: "v1"},
+				{Resource: "referencegrants", Version: "v1beta1"},
+			},
+		},
+		{
+			name: "empty",
+		},
+		{
+			name:    "no version",
+			value:   "httproutes",
+			wantErr: true,
+		},
+		{
+			name:    "group",
+			value:   "gateway.networking.k8s.io/v1/httproutes",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseGatewayAPIResources(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseGatewayAPIResources() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseGatewayAPIResources() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMissingGatewayAPIResources(t *testing.T) {
+	newCRD := func(resource string, versions map[string]bool) apiextensionsv1.CustomResourceDefinition {
+		crd := apiextensionsv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: resource + "." + GatewayAPIGroup}}
+		for _, name := range []string{"v1alpha2", "v1beta1", "v1"} {
+			if served, ok := versions[name]; ok {
+				crd.Spec.Versions = append(crd.Spec.Versions, apiextensionsv1.CustomResourceDefinitionVersion{Name: name, Served: served})
+			}
+		}
+		return crd
+	}
+	standard := []apiextensionsv1.CustomResourceDefinition{
+		newCRD("gatewayclasses", map[string]bool{"v1beta1": true, "v1": true}),
+		newCRD("gateways", map[string]bool{"v1beta1": true, "v1": true}),
+		newCRD("httproutes", map[string]bool{"v1beta1": true, "v1": true}),
+		newCRD("grpcroutes", map[string]bool{"v1": true}),
+		newCRD("referencegrants", map[string]bool{"v1beta1": true}),
+	}
+
+	tests := []struct {
+		name     string
+		required []GatewayAPIResource
+		crds     []apiextensionsv1.CustomResourceDefinition
+		want     []string
+	}{
+		{
+			name:     "standard channel",
+			required: StandardGatewayAPIResources,
+			crds:     standard,
+		},
+		{
+			name:     "experimental resources not installed",
+			required: append(slices.Clone(StandardGatewayAPIResources), ExperimentalGatewayAPIResources...),
+			crds:     append(slices.Clone(standard), newCRD("tlsroutes", map[string]bool{"v1alpha2": true})),
+			want:     []string{"tcproutes/v1alpha2 (CRD tcproutes.gateway.networking.k8s.io is not installed)"},
+		},
+		{
+			name:     "version not served",
+			required: []GatewayAPIResource{{Resource: "gateways", Version: "v1"}, {Resource: "grpcroutes", Version: "v1"}},
+			crds: []apiextensionsv1.CustomResourceDefinition{
+				newCRD("gateways", map[string]bool{"v1beta1": true}),
+				newCRD("grpcroutes", map[string]bool{"v1alpha2": true, "v1": false}),
+			},
+			want: []string{
+				"gateways/v1 (CRD gateways.gateway.networking.k8s.io only serves [v1beta1])",
+				"grpcroutes/v1 (CRD grpcroutes.gateway.networking.k8s.io only serves [v1alpha2])",
+			},
+		},
+		{
+			name:     "CRDs of other groups are ignored",
+			required: []GatewayAPIResource{{Resource: "httproutes", Version: "v1"}},
+			crds: []apiextensionsv1.CustomResourceDefinition{
+				{ObjectMeta: metav1.ObjectMeta{Name: "httproutes.example.com"}},
+			},
+			want: []string{"httproutes/v1 (CRD httproutes.gateway.networking.k8s.io is not installed)"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MissingGatewayAPIResources(tt.required, tt.crds)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MissingGatewayAPIResources() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
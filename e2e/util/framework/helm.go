@@ -2,6 +2,7 @@ package framework
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"net"
@@ -12,6 +13,9 @@ import (
 	"syscall"
 	"time"
 
+	yaml "go.yaml.in/yaml/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
 	uexec "k8s.io/utils/exec"
 
 	"k8s.io/kubernetes/test/e2e/framework"
@@ -186,3 +190,182 @@ func RunHelmOrDieInput(namespace string, data string, args ...string) string {
 func RunHelmInput(namespace string, data string, args ...string) (string, error) {
 	return NewHelmCommand(namespace, args...).WithStdinData(data).Exec()
 }
+
+// HelmRepo is a chart repository which can be added via helm repo add.
+type HelmRepo struct {
+	Name string
+	URL  string
+}
+
+// ParseHelmRepos parses comma-separated name=url entries of chart repositories.
+func ParseHelmRepos(repos string) ([]HelmRepo, error) {
+	var result []HelmRepo
+	for _, entry := range strings.Split(repos, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, repoURL, ok := strings.Cut(entry, "=")
+		if !ok || name == "" || repoURL == "" {
+			return nil, fmt.Errorf("invalid chart repository %q, expected name=url", entry)
+		}
+		result = append(result, HelmRepo{Name: name, URL: repoURL})
+	}
+	return result, nil
+}
+
+// AddHelmRepos adds the chart repositories, replacing the existing ones with the same names, so that the chart
+// dependencies referring to them can be resolved.
+func AddHelmRepos(repos []HelmRepo) error {
+	for _, repo := range repos {
+		if _, err := RunHelm("", "repo", "add", repo.Name, repo.URL, "--force-update"); err != nil {
+			return fmt.Errorf("error when adding chart repository %s: %w", repo.Name, err)
+		}
+	}
+	return nil
+}
+
+const (
+	// helmRepoUsernameEnv and helmRepoPasswordEnv are the environment variables of the credentials of a chart
+	// repository.
+	helmRepoUsernameEnv = "HELM_REPO_USERNAME"
+	helmRepoPasswordEnv = "HELM_REPO_PASSWORD"
+)
+
+// HelmRepoCredentials are the credentials of a chart repository.
+type HelmRepoCredentials struct {
+	Username string
+	Password string
+}
+
+// HelmRepoCredentialsFromSecret returns the credentials in the username and password keys of the Secret given in
+// the <namespace>/<name> format.
+func HelmRepoCredentialsFromSecret(ctx context.Context, client clientset.Interface, secret string) (*HelmRepoCredentials, error) {
+	namespace, name, ok := strings.Cut(secret, "/")
+	if !ok || namespace == "" || name == "" {
+		return nil, fmt.Errorf("invalid Secret %q of the chart repository credentials, must be <namespace>/<name>", secret)
+	}
+	s, err := client.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error when getting Secret %s of the chart repository credentials: %w", secret, err)
+	}
+	return helmRepoCredentialsFromSecretData(secret, s.Data)
+}
+
+func helmRepoCredentialsFromSecretData(secret string, data map[string][]byte) (*HelmRepoCredentials, error) {
+	creds := &HelmRepoCredentials{Username: string(data["username"]), Password: string(data["password"])}
+	if creds.Username == "" || creds.Password == "" {
+		return nil, fmt.Errorf("Secret %s of the chart repository credentials must have the username and password keys", secret)
+	}
+	return creds, nil
+}
+
+// HelmRepoCredentialsFromEnv returns the credentials in the HELM_REPO_USERNAME and HELM_REPO_PASSWORD environment
+// variables, or nil if the username is unset.
+func HelmRepoCredentialsFromEnv() *HelmRepoCredentials {
+	return helmRepoCredentialsFromEnv(os.Getenv)
+}
+
+func helmRepoCredentialsFromEnv(getenv func(string) string) *HelmRepoCredentials {
+	username := getenv(helmRepoUsernameEnv)
+	if username == "" {
+		return nil
+	}
+	return &HelmRepoCredentials{Username: username, Password: getenv(helmRepoPasswordEnv)}
+}
+
+// AddAuthenticatedHelmRepo adds the chart repository with the credentials, replacing the existing one with the
+// same name, so that the charts can be referred to as <name>/<chart> by both helm template and helm install. The
+// password is passed via stdin rather than the args, which are logged. If passCredentials is true, the credentials
+// are passed to all domains, e.g. when the repository redirects the chart downloads to another host.
+func AddAuthenticatedHelmRepo(repo HelmRepo, creds *HelmRepoCredentials, passCredentials bool) error {
+	args := []string{"repo", "add", repo.Name, repo.URL, "--force-update", "--username", creds.Username, "--password-stdin"}
+	if passCredentials {
+		args = append(args, "--pass-credentials")
+	}
+	if _, err := RunHelmInput("", creds.Password, args...); err != nil {
+		return fmt.Errorf("error when adding chart repository %s with credentials: %w", repo.Name, err)
+	}
+	return nil
+}
+
+// HelmChartDependencies returns the names of the dependencies declared by the chart. The repo is the chart
+// repository url where to locate the chart, it's ignored if empty, e.g. the chart is a local directory.
+func HelmChartDependencies(chart, repo string) ([]string, error) {
+	args := []string{"show", "chart", chart}
+	if repo != "" {
+		args = append(args, "--repo", repo)
+	}
+	metadata, err := RunHelm("", args...)
+	if err != nil {
+		return nil, fmt.Errorf("error when showing chart %s: %w", chart, err)
+	}
+	return chartDependencies(metadata)
+}
+
+// chartDependencies returns the names of the dependencies declared in the given Chart.yaml.
+func chartDependencies(metadata string) ([]string, error) {
+	var chart struct {
+		Dependencies []struct {
+			Name string `yaml:"name"`
+		} `yaml:"dependencies"`
+	}
+	if err := yaml.Unmarshal([]byte(metadata), &chart); err != nil {
+		return nil, fmt.Errorf("error when decoding chart metadata: %w", err)
+	}
+	var names []string
+	for _, dep := range chart.Dependencies {
+		names = append(names, dep.Name)
+	}
+	return names, nil
+}
+
+// transientHelmErrors are the messages of the errors which helm reports when the cluster isn't ready yet for the
+// release, e.g. the webhook of a CRD applied by the chart isn't serving, rather than when the chart is wrong.
+var transientHelmErrors = []string{
+	"timed out waiting for the condition",
+	"context deadline exceeded",
+	"i/o timeout",
+	"TLS handshake timeout",
+	"connection refused",
+	"connection reset by peer",
+	"failed calling webhook",
+	"no endpoints available for service",
+	"the server is currently unable to handle the request",
+}
+
+// isTransientHelmError returns true if the helm command failed with one of the transientHelmErrors.
+func isTransientHelmError(err error) bool {
+	if err == nil {
+		return false
+	}
+	for _, msg := range transientHelmErrors {
+		if strings.Contains(err.Error(), msg) {
+			return true
+		}
+	}
+	return false
+}
+
+// helmRetryDelay returns the delay before the given retry, starting at 10s and doubled on each retry up to 2m.
+func helmRetryDelay(retry int) time.Duration {
+	delay := 10 * time.Second
+	for i := 1; i < retry && delay < 2*time.Minute; i++ {
+		delay *= 2
+	}
+	return min(delay, 2*time.Minute)
+}
+
+// InstallHelmChart runs helm install for the release of the chart with the given args. If it fails with a transient
+// error, it's retried up to the given times with exponential backoff via helm upgrade --install, so that the release
+// left by the failed attempt is upgraded rather than rejected as existing. Other errors are returned immediately.
+func InstallHelmChart(namespace, releaseName, chart string, retries int, args ...string) (string, error) {
+	output, err := RunHelm(namespace, append([]string{"install", releaseName, chart}, args...)...)
+	for retry := 1; retry <= retries && isTransientHelmError(err); retry++ {
+		delay := helmRetryDelay(retry)
+		framework.Logf("Retrying helm install of release %s in %v (%d/%d) after transient error: %v", releaseName, delay, retry, retries, err)
+		time.Sleep(delay)
+		output, err = RunHelm(namespace, append([]string{"upgrade", "--install", releaseName, chart}, args...)...)
+	}
+	return output, err
+}
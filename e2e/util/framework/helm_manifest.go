@@ -0,0 +1,116 @@
+package framework
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	yaml "go.yaml.in/yaml/v2"
+
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+// helmManifestsDir is the directory in the report directory where the manifests of the helm releases are saved.
+const helmManifestsDir = "helm"
+
+// SaveHelmManifests saves the manifests of the helm release, e.g. the ones rendered by helm template, to
+// <report dir>/helm/<release name>-<suffix>.yaml, so that they are available for debugging after the run. Nothing is
+// saved if the report directory is unspecified.
+func SaveHelmManifests(releaseName, suffix, manifests string) {
+	if framework.TestContext.ReportDir == "" {
+		return
+	}
+	dir := filepath.Join(framework.TestContext.ReportDir, helmManifestsDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		framework.Logf("Error creating directory %q for the helm manifests: %v", dir, err)
+		return
+	}
+	filePath := filepath.Join(dir, releaseName+"-"+suffix+".yaml")
+	if err := os.WriteFile(filePath, []byte(manifests), 0644); err != nil {
+		framework.Logf("Error writing the manifests of release %s to %q: %v", releaseName, filePath, err)
+		return
+	}
+	framework.Logf("Saved the %s manifests of release %s to %q", suffix, releaseName, filePath)
+}
+
+// DiffInstalledHelmManifests gets the manifests installed by the helm release via helm get manifest, saves them
+// next to the rendered ones, see SaveHelmManifests, and logs the resources which differ between them. It's meant to
+// be called when the install fails, so that the rendered resources which aren't installed, e.g. due to different
+// values, can be told apart.
+func DiffInstalledHelmManifests(namespace, releaseName, rendered string) {
+	installed, err := RunHelm(namespace, "get", "manifest", releaseName)
+	if err != nil {
+		framework.Logf("Error getting the installed manifests of release %s: %v", releaseName, err)
+		return
+	}
+	SaveHelmManifests(releaseName, "installed", installed)
+	missing, extra, err := DiffManifestResources(rendered, installed)
+	if err != nil {
+		framework.Logf("Error comparing the manifests of release %s: %v", releaseName, err)
+		return
+	}
+	framework.Logf("Resources of release %s rendered but not installed: %v, installed but not rendered: %v", releaseName, missing, extra)
+}
+
+// DiffManifestResources returns the <kind>/<name> of the resources in the rendered manifests which aren't in the
+// installed ones, and the ones in the installed manifests which aren't in the rendered ones, sorted. The resources
+// are prefixed with their namespaces if they have any, e.g. <namespace>/<kind>/<name>.
+func DiffManifestResources(rendered, installed string) (missing, extra []string, err error) {
+	renderedResources, err := manifestResources(rendered)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error when decoding the rendered manifests: %w", err)
+	}
+	installedResources, err := manifestResources(installed)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error when decoding the installed manifests: %w", err)
+	}
+	for resource := range renderedResources {
+		if !installedResources[resource] {
+			missing = append(missing, resource)
+		}
+	}
+	for resource := range installedResources {
+		if !renderedResources[resource] {
+			extra = append(extra, resource)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(extra)
+	return missing, extra, nil
+}
+
+// manifestResources returns the set of the resources in the multi-document manifests, see DiffManifestResources.
+func manifestResources(manifests string) (map[string]bool, error) {
+	resources := map[string]bool{}
+	decoder := yaml.NewDecoder(bytes.NewBufferString(manifests))
+	for {
+		var obj struct {
+			Kind     string `yaml:"kind"`
+			Metadata struct {
+				Name      string `yaml:"name"`
+				Namespace string `yaml:"namespace"`
+			} `yaml:"metadata"`
+		}
+		err := decoder.Decode(&obj)
+		if errors.Is(err, io.EOF) {
+			return resources, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if obj.Kind == "" {
+			// An empty document, e.g. of a template rendering nothing.
+			continue
+		}
+		parts := []string{obj.Kind, obj.Metadata.Name}
+		if obj.Metadata.Namespace != "" {
+			parts = append([]string{obj.Metadata.Namespace}, parts...)
+		}
+		resources[strings.Join(parts, "/")] = true
+	}
+}
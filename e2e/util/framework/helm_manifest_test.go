@@ -0,0 +1,83 @@
+package framework
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffManifestResources(t *testing.T) {
+	rendered := `---
+# Source: operator/templates/serviceaccount.yaml
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: operator
+  namespace: operator-system
+---
+# Source: operator/templates/webhook.yaml
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: operator
+  namespace: operator-system
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: operator-webhook
+`
+	tests := []struct {
+		name        string
+		rendered    string
+		installed   string
+		wantMissing []string
+		wantExtra   []string
+		wantErr     bool
+	}{
+		{
+			name:      "same",
+			rendered:  rendered,
+			installed: rendered,
+		},
+		{
+			name:     "different",
+			rendered: rendered,
+			installed: `---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: operator
+  namespace: operator-system
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: operator-controller
+  namespace: operator-system
+`,
+			wantMissing: []string{"ValidatingWebhookConfiguration/operator-webhook", "operator-system/Deployment/operator"},
+			wantExtra:   []string{"operator-system/Deployment/operator-controller"},
+		},
+		{
+			name:      "invalid",
+			rendered:  rendered,
+			installed: "kind: [",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			missing, extra, err := DiffManifestResources(tt.rendered, tt.installed)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DiffManifestResources() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(missing, tt.wantMissing) {
+				t.Errorf("DiffManifestResources() missing = %v, want %v", missing, tt.wantMissing)
+			}
+			if !reflect.DeepEqual(extra, tt.wantExtra) {
+				t.Errorf("DiffManifestResources() extra = %v, want %v", extra, tt.wantExtra)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package framework
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseHelmRepos(t *testing.T) {
+	tests := []struct {
+		name    string
+		repos   string
+		want    []HelmRepo
+		wantErr bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:  "multiple repositories",
+			repos: "bitnami=https://charts.bitnami.com/bitnami, jetstack=https://charts.jetstack.io",
+			want: []HelmRepo{
+				{Name: "bitnami", URL: "https://charts.bitnami.com/bitnami"},
+				{Name: "jetstack", URL: "https://charts.jetstack.io"},
+			},
+		},
+		{
+			name:  "url with query",
+			repos: "private=https://example.com/charts?token=a=b",
+			want:  []HelmRepo{{Name: "private", URL: "https://example.com/charts?token=a=b"}},
+		},
+		{
+			name:    "missing url",
+			repos:   "bitnami",
+			wantErr: true,
+		},
+		{
+			name:    "missing name",
+			repos:   "=https://charts.bitnami.com/bitnami",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseHelmRepos(tt.repos)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseHelmRepos() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseHelmRepos() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChartDependencies(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name: "no dependencies",
+			metadata: `apiVersion: v2
+name: kueue
+version: 0.14.0
+`,
+		},
+		{
+			name: "dependencies",
+			metadata: `apiVersion: v2
+name: pipelines
+version: 2.4.0
+dependencies:
+- name: mysql
+  repository: https://charts.bitnami.com/bitnami
+  version: 9.x.x
+- name: minio
+  repository: "@minio"
+  version: 5.x.x
+`,
+			want: []string{"mysql", "minio"},
+		},
+		{
+			name:     "malformed",
+			metadata: "dependencies: {",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := chartDependencies(tt.metadata)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("chartDependencies() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("chartDependencies() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsTransientHelmError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "no error",
+		},
+		{
+			name: "wait timed out",
+			err:  errors.New("error running helm install:\nstderr:\nError: INSTALLATION FAILED: context deadline exceeded"),
+			want: true,
+		},
+		{
+			name: "webhook not serving",
+			err: errors.New(`Error: INSTALLATION FAILED: Internal error occurred: failed calling webhook "validate.example.com": ` +
+				`Post "https://webhook.operator.svc:443/validate": dial tcp 10.96.0.10:443: connect: connection refused`),
+			want: true,
+		},
+		{
+			name: "chart error",
+			err:  errors.New("Error: INSTALLATION FAILED: template: operator/templates/deployment.yaml:12:20: executing \"image\" at <.Values.image.tag>: nil pointer evaluating interface {}.tag"),
+		},
+		{
+			name: "release exists",
+			err:  errors.New("Error: INSTALLATION FAILED: cannot re-use a name that is still in use"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTransientHelmError(tt.err); got != tt.want {
+				t.Errorf("isTransientHelmError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHelmRetryDelay(t *testing.T) {
+	tests := []struct {
+		retry int
+		want  time.Duration
+	}{
+		{retry: 1, want: 10 * time.Second},
+		{retry: 2, want: 20 * time.Second},
+		{retry: 4, want: 80 * time.Second},
+		{retry: 5, want: 2 * time.Minute},
+		{retry: 10, want: 2 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := helmRetryDelay(tt.retry); got != tt.want {
+			t.Errorf("helmRetryDelay(%d) = %v, want %v", tt.retry, got, tt.want)
+		}
+	}
+}
+
+func TestHelmRepoCredentialsFromSecretData(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string][]byte
+		want    *HelmRepoCredentials
+		wantErr bool
+	}{
+		{
+			name: "username and password",
+			data: map[string][]byte{"username": []byte("robot"), "password": []byte("s3cret")},
+			want: &HelmRepoCredentials{Username: "robot", Password: "s3cret"},
+		},
+		{
+			name:    "missing password",
+			data:    map[string][]byte{"username": []byte("robot")},
+			wantErr: true,
+		},
+		{
+			name:    "empty",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := helmRepoCredentialsFromSecretData("operators/chart-repo", tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("helmRepoCredentialsFromSecretData() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("helmRepoCredentialsFromSecretData() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHelmRepoCredentialsFromEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want *HelmRepoCredentials
+	}{
+		{
+			name: "unset",
+		},
+		{
+			name: "username and password",
+			env:  map[string]string{"HELM_REPO_USERNAME": "robot", "HELM_REPO_PASSWORD": "s3cret"},
+			want: &HelmRepoCredentials{Username: "robot", Password: "s3cret"},
+		},
+		{
+			name: "password without username",
+			env:  map[string]string{"HELM_REPO_PASSWORD": "s3cret"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := helmRepoCredentialsFromEnv(func(key string) string { return tt.env[key] })
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("helmRepoCredentialsFromEnv() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
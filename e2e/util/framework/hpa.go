@@ -0,0 +1,75 @@
+package framework
+
+import (
+	"fmt"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VerifyHPACustomMetric returns an error if the HorizontalPodAutoscaler doesn't scale on the custom metric, i.e. it
+// has no Pods or Object metric of the given name, or it also scales on a resource metric, e.g. cpu, which would
+// scale the workload under load regardless of the custom metric.
+func VerifyHPACustomMetric(hpa *autoscalingv2.HorizontalPodAutoscaler, metricName string) error {
+	found := false
+	for _, metric := range hpa.Spec.Metrics {
+		switch metric.Type {
+		case autoscalingv2.PodsMetricSourceType:
+			if metric.Pods != nil && metric.Pods.Metric.Name == metricName {
+				found = true
+			}
+		case autoscalingv2.ObjectMetricSourceType:
+			if metric.Object != nil && metric.Object.Metric.Name == metricName {
+				found = true
+			}
+		case autoscalingv2.ResourceMetricSourceType:
+			if metric.Resource != nil {
+				return fmt.Errorf("HorizontalPodAutoscaler %s/%s scales on the resource metric %s, expected the custom metric %s only", hpa.Namespace, hpa.Name, metric.Resource.Name, metricName)
+			}
+		case autoscalingv2.ContainerResourceMetricSourceType:
+			if metric.ContainerResource != nil {
+				return fmt.Errorf("HorizontalPodAutoscaler %s/%s scales on the resource metric %s of container %s, expected the custom metric %s only",
+					hpa.Namespace, hpa.Name, metric.ContainerResource.Name, metric.ContainerResource.Container, metricName)
+			}
+		}
+	}
+	if !found {
+		return fmt.Errorf("HorizontalPodAutoscaler %s/%s has no Pods or Object metric %s in %+v", hpa.Namespace, hpa.Name, metricName, hpa.Spec.Metrics)
+	}
+	return nil
+}
+
+// NewPodsMetricHPA returns an HorizontalPodAutoscaler named name which scales the Deployment of the same name between
+// minReplicas and maxReplicas on the average value of the custom metric of its pods.
+func NewPodsMetricHPA(namespace, name, metricName string, averageValue resource.Quantity, minReplicas, maxReplicas int32) *autoscalingv2.HorizontalPodAutoscaler {
+	return &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: name},
+			MinReplicas:    &minReplicas,
+			MaxReplicas:    maxReplicas,
+			Metrics: []autoscalingv2.MetricSpec{
+				{
+					Type: autoscalingv2.PodsMetricSourceType,
+					Pods: &autoscalingv2.PodsMetricSource{
+						Metric: autoscalingv2.MetricIdentifier{Name: metricName},
+						Target: autoscalingv2.MetricTarget{Type: autoscalingv2.AverageValueMetricType, AverageValue: &averageValue},
+					},
+				},
+			},
+		},
+	}
+}
+
+// HPACurrentPodsMetric returns the current average value of the custom metric of the pods which the
+// HorizontalPodAutoscaler last observed, or false if it hasn't observed the metric yet.
+func HPACurrentPodsMetric(hpa *autoscalingv2.HorizontalPodAutoscaler, metricName string) (resource.Quantity, bool) {
+	for _, metric := range hpa.Status.CurrentMetrics {
+		if metric.Type == autoscalingv2.PodsMetricSourceType && metric.Pods != nil && metric.Pods.Metric.Name == metricName &&
+			metric.Pods.Current.AverageValue != nil {
+			return *metric.Pods.Current.AverageValue, true
+		}
+	}
+	return resource.Quantity{}, false
+}
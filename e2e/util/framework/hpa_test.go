@@ -0,0 +1,138 @@
+package framework
+
+import (
+	"testing"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestVerifyHPACustomMetric(t *testing.T) {
+	podsMetric := func(name string) autoscalingv2.MetricSpec {
+		return autoscalingv2.MetricSpec{
+			Type: autoscalingv2.PodsMetricSourceType,
+			Pods: &autoscalingv2.PodsMetricSource{Metric: autoscalingv2.MetricIdentifier{Name: name}},
+		}
+	}
+	objectMetric := autoscalingv2.MetricSpec{
+		Type:   autoscalingv2.ObjectMetricSourceType,
+		Object: &autoscalingv2.ObjectMetricSource{Metric: autoscalingv2.MetricIdentifier{Name: "QPS"}},
+	}
+	cpuMetric := autoscalingv2.MetricSpec{
+		Type:     autoscalingv2.ResourceMetricSourceType,
+		Resource: &autoscalingv2.ResourceMetricSource{Name: v1.ResourceCPU},
+	}
+	containerCPUMetric := autoscalingv2.MetricSpec{
+		Type:              autoscalingv2.ContainerResourceMetricSourceType,
+		ContainerResource: &autoscalingv2.ContainerResourceMetricSource{Name: v1.ResourceCPU, Container: "main"},
+	}
+
+	tests := []struct {
+		name    string
+		metrics []autoscalingv2.MetricSpec
+		wantErr bool
+	}{
+		{
+			name:    "pods metric",
+			metrics: []autoscalingv2.MetricSpec{podsMetric("QPS")},
+		},
+		{
+			name:    "object metric",
+			metrics: []autoscalingv2.MetricSpec{objectMetric},
+		},
+		{
+			name:    "no metrics",
+			wantErr: true,
+		},
+		{
+			name:    "other custom metric",
+			metrics: []autoscalingv2.MetricSpec{podsMetric("latency")},
+			wantErr: true,
+		},
+		{
+			name:    "cpu metric only",
+			metrics: []autoscalingv2.MetricSpec{cpuMetric},
+			wantErr: true,
+		},
+		{
+			name:    "custom metric and cpu metric",
+			metrics: []autoscalingv2.MetricSpec{podsMetric("QPS"), cpuMetric},
+			wantErr: true,
+		},
+		{
+			name:    "custom metric and container cpu metric",
+			metrics: []autoscalingv2.MetricSpec{podsMetric("QPS"), containerCPUMetric},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hpa := &autoscalingv2.HorizontalPodAutoscaler{Spec: autoscalingv2.HorizontalPodAutoscalerSpec{Metrics: tt.metrics}}
+			if err := VerifyHPACustomMetric(hpa, "QPS"); (err != nil) != tt.wantErr {
+				t.Errorf("VerifyHPACustomMetric() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewPodsMetricHPA(t *testing.T) {
+	hpa := NewPodsMetricHPA("ns", "workload", "DCGM_FI_DEV_GPU_UTIL", resource.MustParse("40"), 1, 2)
+	if err := VerifyHPACustomMetric(hpa, "DCGM_FI_DEV_GPU_UTIL"); err != nil {
+		t.Errorf("VerifyHPACustomMetric() error = %v", err)
+	}
+	if ref := hpa.Spec.ScaleTargetRef; ref.Kind != "Deployment" || ref.Name != "workload" {
+		t.Errorf("scale target = %+v, want Deployment workload", ref)
+	}
+	if *hpa.Spec.MinReplicas != 1 || hpa.Spec.MaxReplicas != 2 {
+		t.Errorf("replicas = %d-%d, want 1-2", *hpa.Spec.MinReplicas, hpa.Spec.MaxReplicas)
+	}
+	if target := hpa.Spec.Metrics[0].Pods.Target.AverageValue; target.String() != "40" {
+		t.Errorf("target average value = %s, want 40", target.String())
+	}
+}
+
+func TestHPACurrentPodsMetric(t *testing.T) {
+	podsMetric := func(name, value string) autoscalingv2.MetricStatus {
+		q := resource.MustParse(value)
+		return autoscalingv2.MetricStatus{
+			Type: autoscalingv2.PodsMetricSourceType,
+			Pods: &autoscalingv2.PodsMetricStatus{
+				Metric:  autoscalingv2.MetricIdentifier{Name: name},
+				Current: autoscalingv2.MetricValueStatus{AverageValue: &q},
+			},
+		}
+	}
+	tests := []struct {
+		name      string
+		metrics   []autoscalingv2.MetricStatus
+		want      string
+		wantFound bool
+	}{
+		{
+			name:      "observed",
+			metrics:   []autoscalingv2.MetricStatus{podsMetric("latency", "3"), podsMetric("DCGM_FI_DEV_GPU_UTIL", "97")},
+			want:      "97",
+			wantFound: true,
+		},
+		{
+			name:    "other metric only",
+			metrics: []autoscalingv2.MetricStatus{podsMetric("latency", "3")},
+			want:    "0",
+		},
+		{
+			name:    "not observed yet",
+			metrics: []autoscalingv2.MetricStatus{{Type: autoscalingv2.PodsMetricSourceType, Pods: &autoscalingv2.PodsMetricStatus{Metric: autoscalingv2.MetricIdentifier{Name: "DCGM_FI_DEV_GPU_UTIL"}}}},
+			want:    "0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hpa := &autoscalingv2.HorizontalPodAutoscaler{Status: autoscalingv2.HorizontalPodAutoscalerStatus{CurrentMetrics: tt.metrics}}
+			got, found := HPACurrentPodsMetric(hpa, "DCGM_FI_DEV_GPU_UTIL")
+			if got.String() != tt.want || found != tt.wantFound {
+				t.Errorf("HPACurrentPodsMetric() = %s, %v, want %s, %v", got.String(), found, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	yaml "go.yaml.in/yaml/v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	kueueclient "sigs.k8s.io/kueue/client-go/clientset/versioned"
+
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+// kueueConfigKey is the key of the configuration of the Kueue controller manager in its ConfigMap.
+const kueueConfigKey = "controller_manager_config.yaml"
+
+// KueueConfig is the part of the configuration of the Kueue controller manager which the tests check.
+type KueueConfig struct {
+	WaitForPodsReady *KueueWaitForPodsReady `yaml:"waitForPodsReady"`
+}
+
+// KueueWaitForPodsReady is the configuration of the waitForPodsReady feature of Kueue, which evicts the admitted
+// workloads whose pods are not ready within the timeout, so that the partially scheduled gangs don't hold the quota
+// forever. See https://kueue.sigs.k8s.io/docs/tasks/manage/setup_wait_for_pods_ready/
+type KueueWaitForPodsReady struct {
+	Enable         bool   `yaml:"enable"`
+	Timeout        string `yaml:"timeout"`
+	BlockAdmission *bool  `yaml:"blockAdmission"`
+}
+
+// WaitForPodsReadyEnabled returns true if the waitForPodsReady feature is enabled.
+func (c *KueueConfig) WaitForPodsReadyEnabled() bool {
+	return c.WaitForPodsReady != nil && c.WaitForPodsReady.Enable
+}
+
+// GetKueueConfig returns the configuration of the Kueue controller manager in the ConfigMap given in the
+// <namespace>/<name> format. Nil is returned if the ConfigMap doesn't exist, e.g. Kueue is installed differently.
+func GetKueueConfig(ctx context.Context, client clientset.Interface, configMap string) (*KueueConfig, error) {
+	namespace, name, ok := strings.Cut(configMap, "/")
+	if !ok || namespace == "" || name == "" {
+		return nil, fmt.Errorf("invalid Kueue ConfigMap %q, must be <namespace>/<name>", configMap)
+	}
+	cm, err := client.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error when getting Kueue ConfigMap %s: %w", configMap, err)
+	}
+	data, ok := cm.Data[kueueConfigKey]
+	if !ok {
+		return nil, fmt.Errorf("Kueue ConfigMap %s has no %s", configMap, kueueConfigKey)
+	}
+	return decodeKueueConfig(data)
+}
+
+func decodeKueueConfig(data string) (*KueueConfig, error) {
+	config := &KueueConfig{}
+	if err := yaml.Unmarshal([]byte(data), config); err != nil {
+		return nil, fmt.Errorf("error when decoding the Kueue configuration: %w", err)
+	}
+	return config, nil
+}
+
+// DeleteQueuedWorkloads deletes the Workloads in the namespace which are queued in the LocalQueue, e.g. the ones left
+// behind by a failed spec, which would hold the quota of the ClusterQueue and block its deletion. The finalizers of
+// the Workloads which are not deleted within the timeout are removed.
+func DeleteQueuedWorkloads(ctx context.Context, kueueClient kueueclient.Interface, namespace, queueName string, timeout time.Duration) error {
+	workloads := kueueClient.KueueV1beta1().Workloads(namespace)
+	list, err := workloads.List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error when listing Workloads in namespace %s: %w", namespace, err)
+	}
+	remaining := queuedWorkloads(list.Items, queueName)
+	if len(remaining) == 0 {
+		return nil
+	}
+	framework.Logf("Deleting Workloads %v left in LocalQueue %s/%s", remaining, namespace, queueName)
+	var errs []error
+	for _, name := range remaining {
+		err := workloads.Delete(ctx, name, metav1.DeleteOptions{PropagationPolicy: ptr.To(metav1.DeletePropagationBackground)})
+		if err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("error when deleting Workload %s/%s: %w", namespace, name, err))
+		}
+	}
+
+	err = wait.PollUntilContextTimeout(ctx, framework.Poll, timeout, true, func(ctx context.Context) (bool, error) {
+		list, err := workloads.List(ctx, metav1.ListOptions{})
+		if err != nil {
+			framework.Logf("error when listing Workloads in namespace %s: %v", namespace, err)
+			return false, nil
+		}
+		remaining = queuedWorkloads(list.Items, queueName)
+		return len(remaining) == 0, nil
+	})
+	if err == nil {
+		return utilerrors.NewAggregate(errs)
+	}
+	framework.Logf("Removing the finalizers of Workloads %v which are not deleted within %v", remaining, timeout)
+	for _, name := range remaining {
+		_, err := workloads.Patch(ctx, name, types.MergePatchType, []byte(`{"metadata":{"finalizers":null}}`), metav1.PatchOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("error when removing the finalizers of Workload %s/%s: %w", namespace, name, err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// queuedWorkloads returns the sorted names of the Workloads which are queued in the LocalQueue.
+func queuedWorkloads(workloads []kueuev1beta1.Workload, queueName string) []string {
+	var names []string
+	for _, wl := range workloads {
+		if string(wl.Spec.QueueName) == queueName {
+			names = append(names, wl.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,104 @@
+package framework
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+)
+
+func TestDecodeKueueConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "enabled",
+			data: `apiVersion: config.kueue.x-k8s.io/v1beta1
+kind: Configuration
+manageJobsWithoutQueueName: false
+waitForPodsReady:
+  enable: true
+  timeout: 5m
+  blockAdmission: true
+`,
+			want: true,
+		},
+		{
+			name: "disabled",
+			data: `apiVersion: config.kueue.x-k8s.io/v1beta1
+kind: Configuration
+waitForPodsReady:
+  enable: false
+`,
+		},
+		{
+			name: "not configured",
+			data: `apiVersion: config.kueue.x-k8s.io/v1beta1
+kind: Configuration
+integrations:
+  frameworks:
+  - batch/job
+`,
+		},
+		{
+			name:    "invalid",
+			data:    "waitForPodsReady: [",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := decodeKueueConfig(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeKueueConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := config.WaitForPodsReadyEnabled(); got != tt.want {
+				t.Errorf("WaitForPodsReadyEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueuedWorkloads(t *testing.T) {
+	newWorkload := func(name, queueName string) kueuev1beta1.Workload {
+		return kueuev1beta1.Workload{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       kueuev1beta1.WorkloadSpec{QueueName: kueuev1beta1.LocalQueueName(queueName)},
+		}
+	}
+	workloads := []kueuev1beta1.Workload{
+		newWorkload("job-job2-b", "gang"),
+		newWorkload("job-job1-a", "gang"),
+		newWorkload("job-other", "other"),
+	}
+
+	tests := []struct {
+		name      string
+		queueName string
+		want      []string
+	}{
+		{
+			name:      "queued",
+			queueName: "gang",
+			want:      []string{"job-job1-a", "job-job2-b"},
+		},
+		{
+			name:      "empty queue",
+			queueName: "unused",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := queuedWorkloads(workloads, tt.queueName); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("queuedWorkloads() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package framework
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/types"
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2econfig "k8s.io/kubernetes/test/e2e/framework/config"
+)
+
+var list struct {
+	List string `default:"" usage:"path of the file which the AI conformance specs are listed to as JSON, or - for stdout. If specified, the specs are not run, as with -ginkgo.dry-run, and all the AI conformance specs are listed with their areas, levels, labels and locations regardless of -ai.level, so that the conformance manifest can be built from them"`
+}
+var _ = e2econfig.AddOptions(&list, "ai")
+
+// SpecListEntry is an AI conformance spec listed by -ai.list.
+type SpecListEntry struct {
+	// Area is the text of the top level container of the spec, e.g. DRA Support.
+	Area string `json:"area"`
+	// Spec is the text of the spec below its area.
+	Spec string `json:"spec"`
+	// Level is the conformance level of the spec, i.e. MUST or SHOULD.
+	Level  string   `json:"level"`
+	Labels []string `json:"labels"`
+	// Location is the file and the line of the spec, e.g. accelerators.go:352, whose comment describes it.
+	Location string `json:"location"`
+}
+
+// ListingSpecs returns true if the specs are listed by -ai.list instead of being run.
+func ListingSpecs() bool {
+	return list.List != ""
+}
+
+// ListSpecs returns the AI conformance specs of the reports sorted by their areas and texts, including the ones
+// which are filtered out.
+func ListSpecs(reports types.SpecReports) []SpecListEntry {
+	var entries []SpecListEntry
+	for _, report := range reports {
+		if report.LeafNodeType != types.NodeTypeIt || len(report.ContainerHierarchyTexts) == 0 {
+			continue
+		}
+		labels := report.Labels()
+		if !slices.Contains(labels, "AIConformance") {
+			continue
+		}
+		level := LevelMust
+		if slices.Contains(labels, LevelShould) {
+			level = LevelShould
+		}
+		texts := append(slices.Clone(report.ContainerHierarchyTexts[1:]), report.LeafNodeText)
+		entries = append(entries, SpecListEntry{
+			Area:     labelPrefixRE.ReplaceAllString(report.ContainerHierarchyTexts[0], ""),
+			Spec:     strings.Join(texts, " "),
+			Level:    level,
+			Labels:   labels,
+			Location: fmt.Sprintf("%s:%d", filepath.Base(report.LeafNodeLocation.FileName), report.LeafNodeLocation.LineNumber),
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Area != entries[j].Area {
+			return entries[i].Area < entries[j].Area
+		}
+		return entries[i].Spec < entries[j].Spec
+	})
+	return entries
+}
+
+// ReportSpecList is the body of a ginkgo.ReportAfterSuite node which writes the AI conformance specs to the file of
+// -ai.list if it's specified.
+func ReportSpecList(report ginkgo.Report) {
+	if !ListingSpecs() {
+		return
+	}
+	data, err := json.MarshalIndent(ListSpecs(report.SpecReports), "", "  ")
+	framework.ExpectNoError(err, "error when encoding the AI conformance specs")
+	data = append(data, '\n')
+	if list.List == "-" {
+		_, err = os.Stdout.Write(data)
+	} else {
+		err = os.WriteFile(list.List, data, 0644)
+	}
+	framework.ExpectNoError(err, "error when writing the AI conformance specs to %q", list.List)
+}
@@ -0,0 +1,59 @@
+package framework
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/onsi/ginkgo/v2/types"
+)
+
+func TestListSpecs(t *testing.T) {
+	newReport := func(area, text string, line int, state types.SpecState, labels ...string) types.SpecReport {
+		return types.SpecReport{
+			ContainerHierarchyTexts:  []string{"[wg-ai-conformance] " + area},
+			ContainerHierarchyLabels: [][]string{{"wg-ai-conformance"}},
+			LeafNodeType:             types.NodeTypeIt,
+			LeafNodeText:             text,
+			LeafNodeLabels:           labels,
+			LeafNodeLocation:         types.CodeLocation{FileName: "/src/e2e/ai/accelerators.go", LineNumber: line},
+			State:                    state,
+		}
+	}
+	must := []string{"Conformance", "AIConformance", LevelMust}
+	nested := newReport("Accelerator Metrics", "should be collected", 120, types.SpecStatePassed, must...)
+	nested.ContainerHierarchyTexts = append(nested.ContainerHierarchyTexts, "with a GPU workload")
+	nested.ContainerHierarchyLabels = append(nested.ContainerHierarchyLabels, nil)
+	reports := types.SpecReports{
+		newReport("DRA Support", "should allocate a device", 60, types.SpecStatePassed, must...),
+		newReport("Fractional Accelerators", "should share a GPU", 500, types.SpecStateSkipped, "AIConformance", LevelShould),
+		nested,
+		newReport("Networking", "should serve", 10, types.SpecStatePassed, "Conformance"),
+		{LeafNodeType: types.NodeTypeBeforeSuite, State: types.SpecStatePassed},
+	}
+	want := []SpecListEntry{
+		{
+			Area:     "Accelerator Metrics",
+			Spec:     "with a GPU workload should be collected",
+			Level:    LevelMust,
+			Labels:   []string{"wg-ai-conformance", "Conformance", "AIConformance", LevelMust},
+			Location: "accelerators.go:120",
+		},
+		{
+			Area:     "DRA Support",
+			Spec:     "should allocate a device",
+			Level:    LevelMust,
+			Labels:   []string{"wg-ai-conformance", "Conformance", "AIConformance", LevelMust},
+			Location: "accelerators.go:60",
+		},
+		{
+			Area:     "Fractional Accelerators",
+			Spec:     "should share a GPU",
+			Level:    LevelShould,
+			Labels:   []string{"wg-ai-conformance", "AIConformance", LevelShould},
+			Location: "accelerators.go:500",
+		},
+	}
+	if got := ListSpecs(reports); !reflect.DeepEqual(got, want) {
+		t.Errorf("ListSpecs() = %+v, want %+v", got, want)
+	}
+}
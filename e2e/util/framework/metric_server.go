@@ -0,0 +1,182 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
+
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2econfig "k8s.io/kubernetes/test/e2e/framework/config"
+)
+
+var metricServer struct {
+	Image string `default:"docker.io/library/python:3.12-alpine" usage:"image with python3 which runs the metric server exposing the custom metric of the observability tests"`
+}
+var _ = e2econfig.AddOptions(&metricServer, "ai.metricServer")
+
+const (
+	// metricServerPort is the port which the metric server listens on, named http in the pods and the Service.
+	metricServerPort = 8080
+	// metricServerScript serves the gauge named by $METRIC_NAME, whose initial value is $METRIC_VALUE, in the
+	// Prometheus text format at /metrics. The value is set by POST /metric?value=<value>.
+	metricServerScript = `
+import os
+from http.server import BaseHTTPRequestHandler, HTTPServer
+from urllib.parse import parse_qs, urlparse
+
+name = os.environ["METRIC_NAME"]
+value = float(os.environ["METRIC_VALUE"])
+
+
+class Handler(BaseHTTPRequestHandler):
+    def do_GET(self):
+        if urlparse(self.path).path != "/metrics":
+            self.send_error(404)
+            return
+        body = f"# TYPE {name} gauge\n{name} {value!r}\n".encode()
+        self.send_response(200)
+        self.send_header("Content-Type", "text/plain; version=0.0.4")
+        self.send_header("Content-Length", str(len(body)))
+        self.end_headers()
+        self.wfile.write(body)
+
+    def do_POST(self):
+        global value
+        url = urlparse(self.path)
+        if url.path != "/metric":
+            self.send_error(404)
+            return
+        try:
+            value = float(parse_qs(url.query)["value"][0])
+        except (KeyError, ValueError):
+            self.send_error(400, "value must be a number")
+            return
+        self.send_response(204)
+        self.end_headers()
+
+
+HTTPServer(("", int(os.environ["PORT"])), Handler).serve_forever()
+`
+)
+
+// MetricServer is a Deployment exposing a single gauge in the Prometheus text format through a Service, as a
+// lightweight replacement of the resource consumer of the autoscaling utils for the tests which only need a custom
+// metric to be scraped. Its Service is named and labeled like the one of the resource consumer, i.e. name=<name>
+// with a port named http, so the same ServiceMonitor selects either of them.
+type MetricServer struct {
+	client     clientset.Interface
+	Namespace  string
+	Name       string
+	MetricName string
+}
+
+// newMetricServerObjects returns the Deployment and the Service of the metric server with the given number of
+// replicas, which all expose the metric with the initial value.
+func newMetricServerObjects(namespace, name, metricName string, value float64, replicas int32) (*appsv1.Deployment, *v1.Service) {
+	podLabels := map[string]string{"name": name}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: podLabels},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To(replicas),
+			Selector: &metav1.LabelSelector{MatchLabels: podLabels},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: podLabels},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name:    "metric-server",
+							Image:   metricServer.Image,
+							Command: []string{"python3", "-u", "-c", metricServerScript},
+							Env: []v1.EnvVar{
+								{Name: "METRIC_NAME", Value: metricName},
+								{Name: "METRIC_VALUE", Value: formatMetricValue(value)},
+								{Name: "PORT", Value: strconv.Itoa(metricServerPort)},
+							},
+							Ports: []v1.ContainerPort{{Name: "http", ContainerPort: metricServerPort}},
+							ReadinessProbe: &v1.Probe{
+								ProbeHandler: v1.ProbeHandler{
+									HTTPGet: &v1.HTTPGetAction{Path: "/metrics", Port: intstr.FromString("http")},
+								},
+								PeriodSeconds: 5,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: podLabels},
+		Spec: v1.ServiceSpec{
+			Selector: podLabels,
+			Ports: []v1.ServicePort{
+				{Name: "http", Port: metricServerPort, TargetPort: intstr.FromString("http")},
+			},
+		},
+	}
+	return deployment, service
+}
+
+// formatMetricValue formats the value of the metric in the shortest form which parses back to it.
+func formatMetricValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// CreateMetricServer creates the Deployment and the Service of a metric server named name in the namespace, with
+// the given number of replicas which expose the metric with the initial value, and waits for the rollout of the
+// Deployment to complete. Both are deleted on cleanup. The server runs with -ai.metricServer.image.
+func CreateMetricServer(ctx context.Context, client clientset.Interface, namespace, name, metricName string, value float64, replicas int32) (*MetricServer, error) {
+	deployment, service := newMetricServerObjects(namespace, name, metricName, value, replicas)
+	deployment, err := client.AppsV1().Deployments(namespace).Create(ctx, deployment, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error when creating deployment of metric server %s/%s: %w", namespace, name, err)
+	}
+	DeferCleanup(client.AppsV1().Deployments(namespace).Delete, deployment.Name, metav1.DeleteOptions{})
+	service, err = client.CoreV1().Services(namespace).Create(ctx, service, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error when creating service of metric server %s/%s: %w", namespace, name, err)
+	}
+	DeferCleanup(client.CoreV1().Services(namespace).Delete, service.Name, metav1.DeleteOptions{})
+
+	if err := WaitForDeploymentComplete(ctx, client, deployment, framework.PodStartTimeout); err != nil {
+		return nil, err
+	}
+	return &MetricServer{client: client, Namespace: namespace, Name: name, MetricName: metricName}, nil
+}
+
+// SetMetricValue sets the value of the metric on every running pod of the metric server, through the proxy of the
+// API server so that the test runner doesn't need to reach the pods.
+func (s *MetricServer) SetMetricValue(ctx context.Context, value float64) error {
+	pods, err := s.client.CoreV1().Pods(s.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{"name": s.Name}).String(),
+	})
+	if err != nil {
+		return fmt.Errorf("error when listing pods of metric server %s/%s: %w", s.Namespace, s.Name, err)
+	}
+	set := 0
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != v1.PodRunning || pod.DeletionTimestamp != nil {
+			continue
+		}
+		_, err := s.client.CoreV1().RESTClient().Post().Namespace(s.Namespace).Resource("pods").
+			Name(fmt.Sprintf("%s:%d", pod.Name, metricServerPort)).SubResource("proxy").Suffix("metric").
+			Param("value", formatMetricValue(value)).DoRaw(ctx)
+		if err != nil {
+			return fmt.Errorf("error when setting metric %s of pod %s/%s to %s: %w", s.MetricName, pod.Namespace, pod.Name, formatMetricValue(value), err)
+		}
+		set++
+	}
+	if set == 0 {
+		return fmt.Errorf("no running pod of metric server %s/%s is found", s.Namespace, s.Name)
+	}
+	framework.Logf("Set metric %s of %d pods of metric server %s/%s to %s", s.MetricName, set, s.Namespace, s.Name, formatMetricValue(value))
+	return nil
+}
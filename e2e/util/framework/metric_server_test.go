@@ -0,0 +1,51 @@
+package framework
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestNewMetricServerObjects(t *testing.T) {
+	deployment, service := newMetricServerObjects("ns", "ai-service-metrics", "e2e:custom_metric", 150, 2)
+	template := deployment.Spec.Template
+	if !labels.SelectorFromSet(service.Spec.Selector).Matches(labels.Set(template.Labels)) {
+		t.Errorf("service selector %v doesn't select pod labels %v", service.Spec.Selector, template.Labels)
+	}
+	if got := service.Labels["name"]; got != "ai-service-metrics" {
+		t.Errorf("service label name = %q, want %q", got, "ai-service-metrics")
+	}
+	if len(service.Spec.Ports) != 1 || service.Spec.Ports[0].Name != "http" {
+		t.Errorf("service ports = %+v, want a single port named http", service.Spec.Ports)
+	}
+	container := template.Spec.Containers[0]
+	if len(container.Ports) != 1 || container.Ports[0].Name != service.Spec.Ports[0].TargetPort.StrVal {
+		t.Errorf("container ports = %+v, want the target port %v of the service", container.Ports, service.Spec.Ports[0].TargetPort)
+	}
+	env := map[string]string{}
+	for _, e := range container.Env {
+		env[e.Name] = e.Value
+	}
+	if env["METRIC_NAME"] != "e2e:custom_metric" || env["METRIC_VALUE"] != "150" {
+		t.Errorf("container env = %v, want METRIC_NAME=e2e:custom_metric and METRIC_VALUE=150", env)
+	}
+}
+
+func TestFormatMetricValue(t *testing.T) {
+	tests := []struct {
+		value float64
+		want  string
+	}{
+		{value: 150, want: "150"},
+		{value: 0.25, want: "0.25"},
+		{value: -3, want: "-3"},
+		{value: 1e21, want: "1e+21"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := formatMetricValue(tt.value); got != tt.want {
+				t.Errorf("formatMetricValue(%v) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
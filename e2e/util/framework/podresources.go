@@ -0,0 +1,246 @@
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
+
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2econfig "k8s.io/kubernetes/test/e2e/framework/config"
+	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
+)
+
+var podResources struct {
+	Image string `default:"fullstorydev/grpcurl:v1.9.3-alpine" usage:"image with grpcurl and a shell which queries the kubelet pod-resources API on the accelerator nodes"`
+}
+var _ = e2econfig.AddOptions(&podResources, "ai.podResources")
+
+const (
+	// podResourcesDir is the directory of the kubelet pod-resources API socket.
+	podResourcesDir = "/var/lib/kubelet/pod-resources"
+	// podResourcesUnavailable is printed by the query pod if the kubelet doesn't serve the pod-resources API.
+	podResourcesUnavailable = "pod-resources API is unavailable"
+	// podResourcesProto is the subset of k8s.io/kubelet/pkg/apis/podresources/v1/api.proto which grpcurl needs to
+	// list the devices, the CPUs and the memory assigned to the containers, without the gogoproto options.
+	podResourcesProto = `syntax = "proto3";
+package v1;
+service PodResourcesLister {
+  rpc List(ListPodResourcesRequest) returns (ListPodResourcesResponse) {}
+}
+message ListPodResourcesRequest {}
+message ListPodResourcesResponse {
+  repeated PodResources pod_resources = 1;
+}
+message PodResources {
+  string name = 1;
+  string namespace = 2;
+  repeated ContainerResources containers = 3;
+}
+message ContainerResources {
+  string name = 1;
+  repeated ContainerDevices devices = 2;
+  repeated int64 cpu_ids = 3;
+  repeated ContainerMemory memory = 4;
+}
+message ContainerMemory {
+  string memory_type = 1;
+  uint64 size = 2;
+  TopologyInfo topology = 3;
+}
+message ContainerDevices {
+  string resource_name = 1;
+  repeated string device_ids = 2;
+  TopologyInfo topology = 3;
+}
+message TopologyInfo {
+  repeated NUMANode nodes = 1;
+}
+message NUMANode {
+  int64 ID = 1;
+}
+`
+)
+
+// PodResourcesList is the response of the List call of the kubelet pod-resources API.
+type PodResourcesList struct {
+	PodResources []PodResources `json:"podResources"`
+}
+
+// PodResources is the resources assigned to a pod.
+type PodResources struct {
+	Name       string               `json:"name"`
+	Namespace  string               `json:"namespace"`
+	Containers []ContainerResources `json:"containers"`
+}
+
+// ContainerResources is the resources assigned to a container.
+type ContainerResources struct {
+	Name    string             `json:"name"`
+	Devices []ContainerDevices `json:"devices"`
+	// CPUIDs are the exclusive CPUs assigned by the static CPU Manager policy.
+	CPUIDs []protoInt64 `json:"cpuIds"`
+	// Memory is the memory and the hugepages assigned by the static Memory Manager policy.
+	Memory []ContainerMemory `json:"memory"`
+}
+
+// ContainerDevices is the devices of a resource assigned to a container.
+type ContainerDevices struct {
+	ResourceName string        `json:"resourceName"`
+	DeviceIDs    []string      `json:"deviceIds"`
+	Topology     *TopologyInfo `json:"topology"`
+}
+
+// ContainerMemory is the memory of a type, e.g. memory or hugepages-1Gi, assigned to a container.
+type ContainerMemory struct {
+	MemoryType string        `json:"memoryType"`
+	Size       protoInt64    `json:"size"`
+	Topology   *TopologyInfo `json:"topology"`
+}
+
+// TopologyInfo is the NUMA nodes of the devices or the memory.
+type TopologyInfo struct {
+	Nodes []NUMANode `json:"nodes"`
+}
+
+// NUMANode is a NUMA node.
+type NUMANode struct {
+	ID protoInt64 `json:"ID"`
+}
+
+// protoInt64 is an int64 of the protobuf JSON mapping, which is a string, but a number is accepted too.
+type protoInt64 int64
+
+// UnmarshalJSON decodes the string or the number.
+func (i *protoInt64) UnmarshalJSON(data []byte) error {
+	value, err := strconv.ParseInt(strings.Trim(string(data), `"`), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid int64 %s: %w", string(data), err)
+	}
+	*i = protoInt64(value)
+	return nil
+}
+
+// Container returns the resources assigned to the container of the pod, or nil if it's not found.
+func (l *PodResourcesList) Container(namespace, name, containerName string) *ContainerResources {
+	for _, pod := range l.PodResources {
+		if pod.Namespace != namespace || pod.Name != name {
+			continue
+		}
+		for i := range pod.Containers {
+			if pod.Containers[i].Name == containerName {
+				return &pod.Containers[i]
+			}
+		}
+	}
+	return nil
+}
+
+// DeviceIDs returns the IDs of the devices of the resource assigned to all the containers of the pod.
+func (l *PodResourcesList) DeviceIDs(namespace, name string, resourceName v1.ResourceName) []string {
+	var ids []string
+	for _, pod := range l.PodResources {
+		if pod.Namespace != namespace || pod.Name != name {
+			continue
+		}
+		for _, container := range pod.Containers {
+			for _, devices := range container.Devices {
+				if devices.ResourceName == string(resourceName) {
+					ids = append(ids, devices.DeviceIDs...)
+				}
+			}
+		}
+	}
+	return ids
+}
+
+// decodePodResourcesList decodes the output of the query pod. Nil is returned if the API is unavailable.
+func decodePodResourcesList(output string) (*PodResourcesList, error) {
+	output = strings.TrimSpace(output)
+	if output == podResourcesUnavailable {
+		return nil, nil
+	}
+	list := &PodResourcesList{}
+	if err := json.Unmarshal([]byte(output), list); err != nil {
+		return nil, fmt.Errorf("error when decoding the pod resources %s: %w", output, err)
+	}
+	return list, nil
+}
+
+// ListPodResources lists the resources assigned to the pods on the node by the kubelet pod-resources API, which is
+// queried by a privileged pod created in the namespace with -ai.podResources.image. Nil is returned if the kubelet
+// doesn't serve the API.
+func ListPodResources(ctx context.Context, client clientset.Interface, namespace, nodeName string) (*PodResourcesList, error) {
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: "pod-resources-proto-"},
+		Data:       map[string]string{"api.proto": podResourcesProto},
+	}
+	cm, err := client.CoreV1().ConfigMaps(namespace).Create(ctx, cm, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error when creating ConfigMap of the pod-resources API proto: %w", err)
+	}
+	DeferCleanup(client.CoreV1().ConfigMaps(namespace).Delete, cm.Name, metav1.DeleteOptions{})
+
+	socket := podResourcesDir + "/kubelet.sock"
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: "pod-resources-"},
+		Spec: v1.PodSpec{
+			NodeName:      nodeName,
+			RestartPolicy: v1.RestartPolicyNever,
+			Tolerations:   []v1.Toleration{{Operator: v1.TolerationOpExists}},
+			Containers: []v1.Container{
+				{
+					Name:    "grpcurl",
+					Image:   podResources.Image,
+					Command: []string{"/bin/sh", "-c"},
+					Args: []string{fmt.Sprintf(`if [ ! -S %[1]s ]; then echo %[2]q; exit 0; fi; `+
+						`exec grpcurl -plaintext -unix -import-path /proto -proto api.proto %[1]s v1.PodResourcesLister/List`, socket, podResourcesUnavailable)},
+					SecurityContext: &v1.SecurityContext{
+						Privileged: ptr.To(true),
+						RunAsUser:  ptr.To[int64](0),
+					},
+					VolumeMounts: []v1.VolumeMount{
+						{Name: "pod-resources", MountPath: podResourcesDir, ReadOnly: true},
+						{Name: "proto", MountPath: "/proto", ReadOnly: true},
+					},
+				},
+			},
+			Volumes: []v1.Volume{
+				{
+					Name: "pod-resources",
+					VolumeSource: v1.VolumeSource{
+						HostPath: &v1.HostPathVolumeSource{Path: podResourcesDir, Type: ptr.To(v1.HostPathDirectoryOrCreate)},
+					},
+				},
+				{
+					Name: "proto",
+					VolumeSource: v1.VolumeSource{
+						ConfigMap: &v1.ConfigMapVolumeSource{LocalObjectReference: v1.LocalObjectReference{Name: cm.Name}},
+					},
+				},
+			},
+		},
+	}
+	pod, err = client.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error when creating pod to query the pod-resources API: %w", err)
+	}
+	DeferCleanup(client.CoreV1().Pods(namespace).Delete, pod.Name, metav1.DeleteOptions{})
+
+	err = e2epod.WaitForPodSuccessInNamespace(ctx, client, pod.Name, namespace)
+	output, logErr := e2epod.GetPodLogs(ctx, client, namespace, pod.Name, pod.Spec.Containers[0].Name)
+	if err != nil {
+		return nil, fmt.Errorf("error when querying the pod-resources API on node %s: %w, output: %s", nodeName, err, output)
+	}
+	if logErr != nil {
+		return nil, fmt.Errorf("error when getting logs of pod %s: %w", pod.Name, logErr)
+	}
+	framework.Logf("Pod resources on node %s: %s", nodeName, output)
+	return decodePodResourcesList(output)
+}
@@ -0,0 +1,123 @@
+package framework
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestDecodePodResourcesList(t *testing.T) {
+	output := `{
+  "podResources": [
+    {
+      "name": "gpu-pod",
+      "namespace": "e2e",
+      "containers": [
+        {
+          "name": "main",
+          "devices": [
+            {"resourceName": "nvidia.com/gpu", "deviceIds": ["GPU-8f2c"]},
+            {"resourceName": "example.com/nic", "deviceIds": ["nic-0"]}
+          ]
+        },
+        {
+          "name": "sidecar",
+          "devices": [
+            {"resourceName": "nvidia.com/gpu", "deviceIds": ["GPU-1a7e"]}
+          ]
+        }
+      ]
+    },
+    {
+      "name": "cpu-pod",
+      "namespace": "e2e",
+      "containers": [{"name": "main"}]
+    }
+  ]
+}
+`
+
+	tests := []struct {
+		name    string
+		output  string
+		pod     string
+		wantIDs []string
+		wantNil bool
+		wantErr bool
+	}{
+		{
+			name:    "devices of all the containers",
+			output:  output,
+			pod:     "gpu-pod",
+			wantIDs: []string{"GPU-8f2c", "GPU-1a7e"},
+		},
+		{
+			name:   "pod without devices",
+			output: output,
+			pod:    "cpu-pod",
+		},
+		{
+			name:   "pod not found",
+			output: output,
+			pod:    "missing",
+		},
+		{
+			name:   "no pod",
+			output: "{}\n",
+			pod:    "gpu-pod",
+		},
+		{
+			name:    "unavailable",
+			output:  podResourcesUnavailable + "\n",
+			wantNil: true,
+		},
+		{
+			name:    "grpc error",
+			output:  "Failed to dial target host: context deadline exceeded",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodePodResourcesList(tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodePodResourcesList() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if (got == nil) != tt.wantNil {
+				t.Fatalf("decodePodResourcesList() = %v, wantNil %v", got, tt.wantNil)
+			}
+			if tt.wantNil {
+				return
+			}
+			if ids := got.DeviceIDs("e2e", tt.pod, v1.ResourceName("nvidia.com/gpu")); !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("DeviceIDs() = %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestPodResourcesListContainer(t *testing.T) {
+	output := `{"podResources":[{"name":"gpu-pod","namespace":"e2e","containers":[{"name":"main","cpuIds":["2","3"],` +
+		`"devices":[{"resourceName":"nvidia.com/gpu","deviceIds":["GPU-8f2c"],"topology":{"nodes":[{"ID":"1"}]}}],` +
+		`"memory":[{"memoryType":"hugepages-2Mi","size":"2097152","topology":{"nodes":[{"ID":"1"}]}}]}]}]}`
+	list, err := decodePodResourcesList(output)
+	if err != nil {
+		t.Fatalf("decodePodResourcesList() unexpected error: %v", err)
+	}
+	want := &ContainerResources{
+		Name:    "main",
+		CPUIDs:  []protoInt64{2, 3},
+		Devices: []ContainerDevices{{ResourceName: "nvidia.com/gpu", DeviceIDs: []string{"GPU-8f2c"}, Topology: &TopologyInfo{Nodes: []NUMANode{{ID: 1}}}}},
+		Memory:  []ContainerMemory{{MemoryType: "hugepages-2Mi", Size: 2097152, Topology: &TopologyInfo{Nodes: []NUMANode{{ID: 1}}}}},
+	}
+	if got := list.Container("e2e", "gpu-pod", "main"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Container() = %+v, want %+v", got, want)
+	}
+	if got := list.Container("e2e", "gpu-pod", "sidecar"); got != nil {
+		t.Errorf("Container() = %+v, want nil", got)
+	}
+}
@@ -0,0 +1,102 @@
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+// resourceMetricsAPIPath is the path of the resource metrics API served by the metrics-server.
+const resourceMetricsAPIPath = "/apis/metrics.k8s.io/v1beta1"
+
+// ResourceMetrics is the resource usage of a node or a pod returned by the resource metrics API.
+type ResourceMetrics struct {
+	Name      string
+	Timestamp metav1.Time
+	Window    metav1.Duration
+	Usage     v1.ResourceList
+}
+
+// GetNodeResourceMetrics returns the resource usage of the node returned by the resource metrics API.
+func GetNodeResourceMetrics(ctx context.Context, client clientset.Interface, nodeName string) (*ResourceMetrics, error) {
+	data, err := client.CoreV1().RESTClient().Get().AbsPath(resourceMetricsAPIPath, "nodes", nodeName).DoRaw(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error when getting resource metrics of node %s: %w", nodeName, err)
+	}
+	return decodeNodeMetrics(data)
+}
+
+// GetPodResourceMetrics returns the resource usage of the pod, i.e. the sum of the usage of its containers,
+// returned by the resource metrics API.
+func GetPodResourceMetrics(ctx context.Context, client clientset.Interface, namespace, podName string) (*ResourceMetrics, error) {
+	data, err := client.CoreV1().RESTClient().Get().AbsPath(resourceMetricsAPIPath, "namespaces", namespace, "pods", podName).DoRaw(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error when getting resource metrics of pod %s/%s: %w", namespace, podName, err)
+	}
+	return decodePodMetrics(data)
+}
+
+// decodeNodeMetrics decodes a NodeMetrics returned by the resource metrics API. Only the fields used by the tests
+// are decoded, so that the resource metrics API types are not required.
+func decodeNodeMetrics(data []byte) (*ResourceMetrics, error) {
+	var nodeMetrics struct {
+		Kind      string            `json:"kind"`
+		Metadata  metav1.ObjectMeta `json:"metadata"`
+		Timestamp metav1.Time       `json:"timestamp"`
+		Window    metav1.Duration   `json:"window"`
+		Usage     v1.ResourceList   `json:"usage"`
+	}
+	if err := json.Unmarshal(data, &nodeMetrics); err != nil {
+		return nil, fmt.Errorf("error when decoding the node metrics %q: %w", string(data), err)
+	}
+	if nodeMetrics.Kind != "NodeMetrics" {
+		return nil, fmt.Errorf("unexpected kind %q in the node metrics", nodeMetrics.Kind)
+	}
+	return &ResourceMetrics{
+		Name:      nodeMetrics.Metadata.Name,
+		Timestamp: nodeMetrics.Timestamp,
+		Window:    nodeMetrics.Window,
+		Usage:     nodeMetrics.Usage,
+	}, nil
+}
+
+// decodePodMetrics decodes a PodMetrics returned by the resource metrics API and sums the usage of its containers.
+func decodePodMetrics(data []byte) (*ResourceMetrics, error) {
+	var podMetrics struct {
+		Kind       string            `json:"kind"`
+		Metadata   metav1.ObjectMeta `json:"metadata"`
+		Timestamp  metav1.Time       `json:"timestamp"`
+		Window     metav1.Duration   `json:"window"`
+		Containers []struct {
+			Name  string          `json:"name"`
+			Usage v1.ResourceList `json:"usage"`
+		} `json:"containers"`
+	}
+	if err := json.Unmarshal(data, &podMetrics); err != nil {
+		return nil, fmt.Errorf("error when decoding the pod metrics %q: %w", string(data), err)
+	}
+	if podMetrics.Kind != "PodMetrics" {
+		return nil, fmt.Errorf("unexpected kind %q in the pod metrics", podMetrics.Kind)
+	}
+	if len(podMetrics.Containers) == 0 {
+		return nil, fmt.Errorf("no container is reported in the metrics of pod %s", podMetrics.Metadata.Name)
+	}
+	usage := v1.ResourceList{}
+	for _, c := range podMetrics.Containers {
+		for name, quantity := range c.Usage {
+			sum := usage[name]
+			sum.Add(quantity)
+			usage[name] = sum
+		}
+	}
+	return &ResourceMetrics{
+		Name:      podMetrics.Metadata.Name,
+		Timestamp: podMetrics.Timestamp,
+		Window:    podMetrics.Window,
+		Usage:     usage,
+	}, nil
+}
@@ -0,0 +1,97 @@
+package framework
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestDecodeNodeMetrics(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       string
+		wantCPU    string
+		wantMemory string
+		wantErr    bool
+	}{
+		{
+			name:       "node metrics",
+			data:       `{"kind":"NodeMetrics","apiVersion":"metrics.k8s.io/v1beta1","metadata":{"name":"gpu-node"},"timestamp":"2025-01-01T00:00:00Z","window":"20s","usage":{"cpu":"250m","memory":"1Gi"}}`,
+			wantCPU:    "250m",
+			wantMemory: "1Gi",
+		},
+		{
+			name:    "status instead of metrics",
+			data:    `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound"}`,
+			wantErr: true,
+		},
+		{
+			name:    "not json",
+			data:    `<html>503 Service Unavailable</html>`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeNodeMetrics([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeNodeMetrics() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if cpu := got.Usage[v1.ResourceCPU]; cpu.String() != tt.wantCPU {
+				t.Errorf("cpu usage = %s, want %s", cpu.String(), tt.wantCPU)
+			}
+			if memory := got.Usage[v1.ResourceMemory]; memory.String() != tt.wantMemory {
+				t.Errorf("memory usage = %s, want %s", memory.String(), tt.wantMemory)
+			}
+		})
+	}
+}
+
+func TestDecodePodMetrics(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       string
+		wantCPU    string
+		wantMemory string
+		wantErr    bool
+	}{
+		{
+			name: "usage of containers is summed",
+			data: `{"kind":"PodMetrics","apiVersion":"metrics.k8s.io/v1beta1","metadata":{"name":"pod","namespace":"e2e"},"timestamp":"2025-01-01T00:00:00Z","window":"20s","containers":[
+				{"name":"a","usage":{"cpu":"100m","memory":"64Mi"}},
+				{"name":"b","usage":{"cpu":"150m","memory":"64Mi"}}]}`,
+			wantCPU:    "250m",
+			wantMemory: "128Mi",
+		},
+		{
+			name:    "no containers",
+			data:    `{"kind":"PodMetrics","apiVersion":"metrics.k8s.io/v1beta1","metadata":{"name":"pod","namespace":"e2e"},"containers":[]}`,
+			wantErr: true,
+		},
+		{
+			name:    "node metrics instead of pod metrics",
+			data:    `{"kind":"NodeMetrics","apiVersion":"metrics.k8s.io/v1beta1","metadata":{"name":"gpu-node"},"usage":{"cpu":"250m"}}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodePodMetrics([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodePodMetrics() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if cpu := got.Usage[v1.ResourceCPU]; cpu.String() != tt.wantCPU {
+				t.Errorf("cpu usage = %s, want %s", cpu.String(), tt.wantCPU)
+			}
+			if memory := got.Usage[v1.ResourceMemory]; memory.String() != tt.wantMemory {
+				t.Errorf("memory usage = %s, want %s", memory.String(), tt.wantMemory)
+			}
+		})
+	}
+}
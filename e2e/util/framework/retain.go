@@ -0,0 +1,88 @@
+package framework
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+
+	"github.com/onsi/ginkgo/v2"
+
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2econfig "k8s.io/kubernetes/test/e2e/framework/config"
+)
+
+var retain struct {
+	RetainOnFailure bool `default:"false" usage:"if true, the namespaces and the resources created by a failed spec are not deleted, so that the failure can be debugged. The retained namespaces are logged"`
+}
+var _ = e2econfig.AddOptions(&retain, "ai")
+
+func init() {
+	framework.NewFrameworkExtensions = append(framework.NewFrameworkExtensions, func(f *framework.Framework) {
+		ginkgo.BeforeEach(func() {
+			if retain.RetainOnFailure {
+				framework.TestContext.DeleteNamespaceOnFailure = false
+			}
+		})
+		ginkgo.AfterEach(func() {
+			if retainedOnFailure() && f.Namespace != nil {
+				framework.Logf("Retaining namespace %s of the failed spec for debugging", f.Namespace.Name)
+			}
+		})
+	})
+}
+
+// retainedOnFailure returns true if the current spec failed and its resources should be retained.
+func retainedOnFailure() bool {
+	return retain.RetainOnFailure && ginkgo.CurrentSpecReport().Failed()
+}
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// DeferCleanup is a wrapper of ginkgo.DeferCleanup for the cleanups which delete the resources created by the
+// spec. The cleanup is skipped if the spec failed and -ai.retainOnFailure is set. Like ginkgo.DeferCleanup, the
+// cleanup is called with the given args, a context is passed as the first arg if the cleanup accepts one and
+// the args don't include it, and the spec fails if the last value returned by the cleanup is a non-nil error.
+// The args must not be untyped nil.
+func DeferCleanup(cleanup interface{}, args ...interface{}) {
+	fn := reflect.ValueOf(cleanup)
+	if fn.Kind() != reflect.Func {
+		framework.Failf("DeferCleanup requires a function, got %T", cleanup)
+	}
+	ginkgo.DeferCleanup(func(ctx context.Context) error {
+		if retainedOnFailure() {
+			framework.Logf("Skipping cleanup %s%v of the failed spec for debugging", runtime.FuncForPC(fn.Pointer()).Name(), args)
+			return nil
+		}
+		return callCleanup(ctx, fn, args)
+	}, ginkgo.Offset(1))
+}
+
+// callCleanup calls the cleanup function with the given args, passing the context as the first arg if the
+// function accepts one and it's not given, and returns the last value returned by the function if it's an error.
+func callCleanup(ctx context.Context, fn reflect.Value, args []interface{}) error {
+	fnType := fn.Type()
+	var in []reflect.Value
+	if fnType.NumIn() > 0 && fnType.In(0) == contextType {
+		if _, ok := firstArg(args).(context.Context); !ok {
+			in = append(in, reflect.ValueOf(ctx))
+		}
+	}
+	for _, arg := range args {
+		in = append(in, reflect.ValueOf(arg))
+	}
+	out := fn.Call(in)
+	if len(out) == 0 {
+		return nil
+	}
+	if err, ok := out[len(out)-1].Interface().(error); ok {
+		return err
+	}
+	return nil
+}
+
+func firstArg(args []interface{}) interface{} {
+	if len(args) == 0 {
+		return nil
+	}
+	return args[0]
+}
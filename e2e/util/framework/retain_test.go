@@ -0,0 +1,76 @@
+package framework
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type cleanupContextKey struct{}
+
+func TestCallCleanup(t *testing.T) {
+	ctx := context.WithValue(context.Background(), cleanupContextKey{}, "spec")
+	errCleanup := errors.New("cleanup failed")
+
+	var got []interface{}
+	tests := []struct {
+		name    string
+		cleanup interface{}
+		args    []interface{}
+		want    []interface{}
+		wantErr error
+	}{
+		{
+			name:    "no args",
+			cleanup: func() { got = []interface{}{"called"} },
+			want:    []interface{}{"called"},
+		},
+		{
+			name: "context is injected",
+			cleanup: func(ctx context.Context, name string) error {
+				got = []interface{}{ctx.Value(cleanupContextKey{}), name}
+				return nil
+			},
+			args: []interface{}{"pod"},
+			want: []interface{}{"spec", "pod"},
+		},
+		{
+			name: "given context is used",
+			cleanup: func(ctx context.Context, name string) {
+				got = []interface{}{ctx.Value(cleanupContextKey{}), name}
+			},
+			args: []interface{}{context.WithValue(context.Background(), cleanupContextKey{}, "given"), "pod"},
+			want: []interface{}{"given", "pod"},
+		},
+		{
+			name: "variadic args",
+			cleanup: func(namespace string, args ...string) (string, error) {
+				got = []interface{}{namespace, args}
+				return "", nil
+			},
+			args: []interface{}{"ns", "delete", "-f", "operator.yaml"},
+			want: []interface{}{"ns", []string{"delete", "-f", "operator.yaml"}},
+		},
+		{
+			name: "last error is returned",
+			cleanup: func(ctx context.Context) (string, error) {
+				got = nil
+				return "", errCleanup
+			},
+			wantErr: errCleanup,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+			err := callCleanup(ctx, reflect.ValueOf(tt.cleanup), tt.args)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("callCleanup() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("cleanup was called with %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package framework
+
+import (
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// IsPodUnschedulable returns true if the pod is pending and marked as unschedulable by the scheduler.
+func IsPodUnschedulable(pod *v1.Pod) bool {
+	if pod.Status.Phase != v1.PodPending {
+		return false
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == v1.PodScheduled && cond.Status == v1.ConditionFalse && cond.Reason == v1.PodReasonUnschedulable {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPodScheduled returns true if the pod is bound to a node, whatever phase it has reached since.
+func IsPodScheduled(pod *v1.Pod) bool {
+	if pod.Spec.NodeName != "" {
+		return true
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == v1.PodScheduled && cond.Status == v1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// RunningWindow is the time range in which the pods of a workload ran.
+type RunningWindow struct {
+	// Start is the earliest start time of the pods.
+	Start time.Time
+	// End is the latest time a container of the pods terminated.
+	End time.Time
+}
+
+// String returns the window in RFC3339.
+func (w RunningWindow) String() string {
+	return fmt.Sprintf("[%s, %s]", w.Start.Format(time.RFC3339), w.End.Format(time.RFC3339))
+}
+
+// Overlap returns how long the windows overlap, or 0 if they don't.
+func (w RunningWindow) Overlap(other RunningWindow) time.Duration {
+	start, end := w.Start, w.End
+	if other.Start.After(start) {
+		start = other.Start
+	}
+	if other.End.Before(end) {
+		end = other.End
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// PodsRunningWindow returns the window in which the given pods ran, from the earliest start of the pods to the
+// latest termination of their containers. Pods which have not started are ignored, and a pod which is still running
+// extends the window to now. False is returned if none of the pods has started.
+func PodsRunningWindow(pods []v1.Pod, now time.Time) (RunningWindow, bool) {
+	var window RunningWindow
+	started := false
+	for _, pod := range pods {
+		if pod.Status.StartTime == nil {
+			continue
+		}
+		if !started || pod.Status.StartTime.Time.Before(window.Start) {
+			window.Start = pod.Status.StartTime.Time
+		}
+		started = true
+		end := now
+		if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			end = pod.Status.StartTime.Time
+			for _, status := range pod.Status.ContainerStatuses {
+				if status.State.Terminated != nil && status.State.Terminated.FinishedAt.After(end) {
+					end = status.State.Terminated.FinishedAt.Time
+				}
+			}
+		}
+		if end.After(window.End) {
+			window.End = end
+		}
+	}
+	return window, started
+}
+
+// PendingPodCap returns how many pods requesting 1 accelerator of the resource have to be created at most until one
+// of them is pending, i.e. 1 more than the accelerators allocatable on the nodes, so that a loop creating them is
+// bounded. The given cap is returned instead if it's positive.
+func PendingPodCap(nodes []v1.Node, resourceName v1.ResourceName, maxPods int) int {
+	if maxPods > 0 {
+		return maxPods
+	}
+	allocatable := 0
+	for _, node := range nodes {
+		if val, ok := node.Status.Allocatable[resourceName]; ok {
+			allocatable += int(val.Value())
+		}
+	}
+	return allocatable + 1
+}
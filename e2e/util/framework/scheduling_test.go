@@ -0,0 +1,180 @@
+package framework
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPodSchedulingState(t *testing.T) {
+	unschedulable := v1.PodCondition{Type: v1.PodScheduled, Status: v1.ConditionFalse, Reason: v1.PodReasonUnschedulable}
+	scheduled := v1.PodCondition{Type: v1.PodScheduled, Status: v1.ConditionTrue}
+	tests := []struct {
+		name              string
+		pod               v1.Pod
+		wantUnschedulable bool
+		wantScheduled     bool
+	}{
+		{
+			name: "not yet considered",
+			pod:  v1.Pod{Status: v1.PodStatus{Phase: v1.PodPending}},
+		},
+		{
+			name:              "unschedulable",
+			pod:               v1.Pod{Status: v1.PodStatus{Phase: v1.PodPending, Conditions: []v1.PodCondition{unschedulable}}},
+			wantUnschedulable: true,
+		},
+		{
+			name:          "scheduled and pending",
+			pod:           v1.Pod{Status: v1.PodStatus{Phase: v1.PodPending, Conditions: []v1.PodCondition{scheduled}}},
+			wantScheduled: true,
+		},
+		{
+			// The pod may be running by the time it's observed, which has to end the wait as well.
+			name:          "scheduled and running",
+			pod:           v1.Pod{Spec: v1.PodSpec{NodeName: "node-a"}, Status: v1.PodStatus{Phase: v1.PodRunning, Conditions: []v1.PodCondition{scheduled}}},
+			wantScheduled: true,
+		},
+		{
+			name:          "bound before the condition is reported",
+			pod:           v1.Pod{Spec: v1.PodSpec{NodeName: "node-a"}, Status: v1.PodStatus{Phase: v1.PodPending}},
+			wantScheduled: true,
+		},
+		{
+			name: "failed before it's scheduled",
+			pod:  v1.Pod{Status: v1.PodStatus{Phase: v1.PodFailed, Conditions: []v1.PodCondition{unschedulable}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsPodUnschedulable(&tt.pod); got != tt.wantUnschedulable {
+				t.Errorf("IsPodUnschedulable() = %v, want %v", got, tt.wantUnschedulable)
+			}
+			if got := IsPodScheduled(&tt.pod); got != tt.wantScheduled {
+				t.Errorf("IsPodScheduled() = %v, want %v", got, tt.wantScheduled)
+			}
+		})
+	}
+}
+
+func TestPodsRunningWindow(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return base.Add(time.Duration(minutes) * time.Minute) }
+	newPod := func(phase v1.PodPhase, start int, finished ...int) v1.Pod {
+		pod := v1.Pod{Status: v1.PodStatus{Phase: phase, StartTime: &metav1.Time{Time: at(start)}}}
+		for _, minute := range finished {
+			pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, v1.ContainerStatus{
+				State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{FinishedAt: metav1.Time{Time: at(minute)}}},
+			})
+		}
+		return pod
+	}
+	now := at(60)
+
+	tests := []struct {
+		name   string
+		pods   []v1.Pod
+		want   RunningWindow
+		wantOK bool
+	}{
+		{
+			name:   "completed",
+			pods:   []v1.Pod{newPod(v1.PodSucceeded, 2, 10), newPod(v1.PodSucceeded, 1, 8, 12)},
+			want:   RunningWindow{Start: at(1), End: at(12)},
+			wantOK: true,
+		},
+		{
+			name:   "still running",
+			pods:   []v1.Pod{newPod(v1.PodSucceeded, 2, 10), newPod(v1.PodRunning, 3)},
+			want:   RunningWindow{Start: at(2), End: now},
+			wantOK: true,
+		},
+		{
+			name: "not started",
+			pods: []v1.Pod{{Status: v1.PodStatus{Phase: v1.PodPending}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := PodsRunningWindow(tt.pods, now)
+			if ok != tt.wantOK {
+				t.Fatalf("PodsRunningWindow() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !got.Start.Equal(tt.want.Start) || !got.End.Equal(tt.want.End) {
+				t.Errorf("PodsRunningWindow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunningWindowOverlap(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	window := func(start, end int) RunningWindow {
+		return RunningWindow{Start: base.Add(time.Duration(start) * time.Second), End: base.Add(time.Duration(end) * time.Second)}
+	}
+
+	tests := []struct {
+		name string
+		a, b RunningWindow
+		want time.Duration
+	}{
+		{name: "serialized", a: window(0, 60), b: window(70, 120), want: 0},
+		{name: "adjacent", a: window(0, 60), b: window(60, 120), want: 0},
+		{name: "partially overlapping", a: window(0, 60), b: window(50, 120), want: 10 * time.Second},
+		{name: "contained", a: window(0, 120), b: window(30, 60), want: 30 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Overlap(tt.b); got != tt.want {
+				t.Errorf("Overlap() = %v, want %v", got, tt.want)
+			}
+			if got := tt.b.Overlap(tt.a); got != tt.want {
+				t.Errorf("reversed Overlap() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPendingPodCap(t *testing.T) {
+	newNode := func(allocatable v1.ResourceList) v1.Node {
+		return v1.Node{Status: v1.NodeStatus{Allocatable: allocatable}}
+	}
+	nodes := []v1.Node{
+		newNode(v1.ResourceList{"nvidia.com/gpu": resource.MustParse("8")}),
+		newNode(v1.ResourceList{"nvidia.com/gpu": resource.MustParse("4")}),
+		newNode(v1.ResourceList{v1.ResourceCPU: resource.MustParse("16")}),
+	}
+	tests := []struct {
+		name    string
+		nodes   []v1.Node
+		maxPods int
+		want    int
+	}{
+		{
+			name:  "allocatable accelerators",
+			nodes: nodes,
+			want:  13,
+		},
+		{
+			name:    "configured cap",
+			nodes:   nodes,
+			maxPods: 5,
+			want:    5,
+		},
+		{
+			name:  "no accelerator node",
+			nodes: []v1.Node{newNode(v1.ResourceList{v1.ResourceCPU: resource.MustParse("16")})},
+			want:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PendingPodCap(tt.nodes, "nvidia.com/gpu", tt.maxPods); got != tt.want {
+				t.Errorf("PendingPodCap() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,48 +2,195 @@ package framework
 
 import (
 	"context"
-	"maps"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
 
+	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
 	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+	apiregistrationv1 "k8s.io/kube-aggregator/pkg/apis/apiregistration/v1"
+	aggregatorclient "k8s.io/kube-aggregator/pkg/client/clientset_generated/clientset"
 
 	"k8s.io/kubernetes/test/e2e/framework"
 	e2eskipper "k8s.io/kubernetes/test/e2e/framework/skipper"
 )
 
+// clusterAutoscalers are the supported cluster autoscalers and how they are detected.
+var clusterAutoscalers = map[string]func(ctx context.Context, client clientset.Interface) bool{
+	// Check if Cloud Autoscaler is enabled by trying to get its ConfigMap.
+	"k8s.io/autoscaler/cluster-autoscaler": func(ctx context.Context, client clientset.Interface) bool {
+		_, err := client.CoreV1().ConfigMaps("kube-system").Get(ctx, "cluster-autoscaler-status", metav1.GetOptions{})
+		return err == nil
+	},
+	// Check if Karpenter is enabled by trying to get its API resources.
+	"sigs.k8s.io/karpenter": func(ctx context.Context, client clientset.Interface) bool {
+		available, err := IsGroupVersionAvailable(CachedDiscovery(client), "karpenter.sh/v1")
+		if err != nil {
+			framework.Logf("Error when detecting Karpenter: %v", err)
+		}
+		return available
+	},
+}
+
+// DetectClusterAutoscalers returns the supported cluster autoscalers which have been installed.
+func DetectClusterAutoscalers(ctx context.Context, client clientset.Interface) []string {
+	var installed []string
+	for name, fn := range clusterAutoscalers {
+		if fn(ctx, client) {
+			installed = append(installed, name)
+		}
+	}
+	sort.Strings(installed)
+	return installed
+}
+
 // SkipUnlessClusterAutoscalerExists skips the test if no supported cluster autoscaler has been installed.
 func SkipUnlessClusterAutoscalerExists(ctx context.Context, client clientset.Interface) {
-	autoscalers := map[string]func() bool{
-		// Check if Cloud Autoscaler is enabled by trying to get its ConfigMap.
-		"k8s.io/autoscaler/cluster-autoscaler": func() bool {
-			_, err := client.CoreV1().ConfigMaps("kube-system").Get(ctx, "cluster-autoscaler-status", metav1.GetOptions{})
-			return err == nil
-		},
-		// Check if Karpenter is enabled by trying to get its API resources.
-		"sigs.k8s.io/karpenter": func() bool {
-			_, err := client.Discovery().ServerResourcesForGroupVersion("karpenter.sh/v1")
-			return err == nil
-		},
-	}
-
-	for _, fn := range autoscalers {
-		if fn() {
-			return
-		}
+	if len(DetectClusterAutoscalers(ctx, client)) > 0 {
+		return
+	}
+	var supported []string
+	for name := range clusterAutoscalers {
+		supported = append(supported, name)
+	}
+	sort.Strings(supported)
+	e2eskipper.Skipf("no cluster autoscaler has been installed: %v", supported)
+}
+
+// RequireAvailableAccelerators skips the test if less than n accelerators of the resource on the schedulable nodes
+// can be allocated to new pods. Otherwise it returns the count of the accelerators. The nodes provisioned by an
+// autoscaler are not counted if excludeAutoscalerNodes is true, see CountSchedulableAccelerators.
+func RequireAvailableAccelerators(ctx context.Context, client clientset.Interface, resourceName v1.ResourceName, n int, excludeAutoscalerNodes bool) *AcceleratorCount {
+	count, err := CountSchedulableAccelerators(ctx, client, resourceName, excludeAutoscalerNodes)
+	framework.ExpectNoError(err, "error when counting %s", resourceName)
+	if reason := count.ShortageReason(resourceName, n); reason != "" {
+		e2eskipper.Skipf("%s", reason)
+	}
+	return count
+}
+
+// discoveryBackoff is the backoff of the retries of the discovery of a group version which fails transiently.
+var discoveryBackoff = wait.Backoff{Duration: time.Second, Factor: 2, Steps: 5}
+
+// TransientDiscoveryError is returned by IsGroupVersionAvailable if the discovery of the group version keeps
+// failing transiently, e.g. while an aggregated API is unavailable, so it's unknown whether the group version is
+// served. The callers may retry instead of treating the group version as not served.
+type TransientDiscoveryError struct {
+	GroupVersion string
+	Err          error
+}
+
+func (e *TransientDiscoveryError) Error() string {
+	return fmt.Sprintf("discovery of %s failed transiently: %v", e.GroupVersion, e.Err)
+}
+
+func (e *TransientDiscoveryError) Unwrap() error {
+	return e.Err
+}
+
+// isTransientDiscoveryError returns true if the discovery error may go away on retry, as the memory cache of the
+// discovery client considers it, i.e. the server errors, the throttling and the connection errors.
+func isTransientDiscoveryError(err error) bool {
+	var status apierrors.APIStatus
+	if errors.As(err, &status) && status.Status().Code >= http.StatusInternalServerError {
+		return true
+	}
+	return apierrors.IsTooManyRequests(err) || utilnet.IsConnectionRefused(err) || utilnet.IsConnectionReset(err) ||
+		utilnet.IsProbableEOF(err)
+}
+
+// IsGroupVersionAvailable returns true if the group version is served. The discovery is retried with backoff if it
+// fails transiently, and a TransientDiscoveryError is returned if it keeps failing. Other errors are returned as is.
+// The discovery client may be the one of CachedDiscovery, which doesn't know the group versions not served.
+func IsGroupVersionAvailable(discoveryClient discovery.DiscoveryInterface, groupVersion string) (bool, error) {
+	err := retry.OnError(discoveryBackoff, isTransientDiscoveryError, func() error {
+		_, err := discoveryClient.ServerResourcesForGroupVersion(groupVersion)
+		return err
+	})
+	switch {
+	case err == nil:
+		return true, nil
+	case apierrors.IsNotFound(err) || errors.Is(err, memory.ErrCacheNotFound):
+		return false, nil
+	case isTransientDiscoveryError(err):
+		return false, &TransientDiscoveryError{GroupVersion: groupVersion, Err: err}
+	default:
+		return false, err
 	}
-	e2eskipper.Skipf("no cluster autoscaler has been installed: %v", maps.Keys(autoscalers))
 }
 
 // SkipIfGroupVersionUnavaliable skips the test if the group version is not found.
 func SkipIfGroupVersionUnavaliable(ctx context.Context, discoveryClient discovery.DiscoveryInterface, groupVersion string) {
-	_, err := discoveryClient.ServerResourcesForGroupVersion(groupVersion)
+	available, err := IsGroupVersionAvailable(discoveryClient, groupVersion)
+	if err != nil {
+		framework.Failf("failed to get resources in %s: %v", groupVersion, err)
+	}
+	if !available {
+		e2eskipper.Skipf("%s is not found", groupVersion)
+	}
+}
+
+// APIServiceExists returns true if the APIService is registered. An error is returned if it's unknown.
+func APIServiceExists(ctx context.Context, aggrclient aggregatorclient.Interface, name string) (bool, error) {
+	_, err := aggrclient.ApiregistrationV1().APIServices().Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		if apierrors.IsNotFound(err) {
-			e2eskipper.Skipf("%s is not found", groupVersion)
-			return
+			return false, nil
 		}
-		framework.Failf("failed to get resources in %s: %v", groupVersion, err)
+		return false, err
+	}
+	return true, nil
+}
+
+// SkipUnlessAPIServiceExists skips the test if the APIService is not registered, i.e. the aggregated API, such as
+// v1beta1.metrics.k8s.io, is not installed.
+func SkipUnlessAPIServiceExists(ctx context.Context, aggrclient aggregatorclient.Interface, name string) {
+	exists, err := APIServiceExists(ctx, aggrclient, name)
+	if err != nil {
+		framework.Failf("error when getting APIService %s: %v", name, err)
+	}
+	if !exists {
+		e2eskipper.Skipf("The APIService %s does not exist", name)
+	}
+}
+
+// WaitForAPIServiceAvailable waits until the APIService reports the Available condition, i.e. the API server can
+// reach the aggregated API, e.g. once the metrics adapter serving it is ready.
+func WaitForAPIServiceAvailable(ctx context.Context, aggrclient aggregatorclient.Interface, name string, timeout time.Duration) error {
+	reason := "it's not found"
+	err := wait.PollUntilContextTimeout(ctx, framework.Poll, timeout, true, func(ctx context.Context) (bool, error) {
+		apiService, err := aggrclient.ApiregistrationV1().APIServices().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		reason = apiServiceUnavailableReason(apiService)
+		return reason == "", nil
+	})
+	if err != nil {
+		return fmt.Errorf("error when waiting for APIService %s to be available, %s: %w", name, reason, err)
+	}
+	return nil
+}
+
+// apiServiceUnavailableReason returns why the APIService is not available, or an empty string if it is.
+func apiServiceUnavailableReason(apiService *apiregistrationv1.APIService) string {
+	for _, cond := range apiService.Status.Conditions {
+		if cond.Type != apiregistrationv1.Available {
+			continue
+		}
+		if cond.Status == apiregistrationv1.ConditionTrue {
+			return ""
+		}
+		return fmt.Sprintf("its Available condition is %s: %s: %s", cond.Status, cond.Reason, cond.Message)
 	}
+	return "it has no Available condition"
 }
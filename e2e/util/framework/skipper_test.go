@@ -0,0 +1,182 @@
+package framework
+
+import (
+	"errors"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	apiregistrationv1 "k8s.io/kube-aggregator/pkg/apis/apiregistration/v1"
+)
+
+func TestIsGroupVersionAvailable(t *testing.T) {
+	client := fake.NewClientset()
+	client.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "resource.k8s.io/v1",
+			APIResources: []metav1.APIResource{{Name: "resourceclaims", Namespaced: true, Kind: "ResourceClaim"}},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		discoveryClient discovery.DiscoveryInterface
+		groupVersion    string
+		want            bool
+	}{
+		{
+			name:            "served",
+			discoveryClient: client.Discovery(),
+			groupVersion:    "resource.k8s.io/v1",
+			want:            true,
+		},
+		{
+			name:            "not served",
+			discoveryClient: client.Discovery(),
+			groupVersion:    "kueue.x-k8s.io/v1beta1",
+		},
+		{
+			name:            "served in the cache",
+			discoveryClient: memory.NewMemCacheClient(client.Discovery()),
+			groupVersion:    "resource.k8s.io/v1",
+			want:            true,
+		},
+		{
+			name:            "not served in the cache",
+			discoveryClient: memory.NewMemCacheClient(client.Discovery()),
+			groupVersion:    "kueue.x-k8s.io/v1beta1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := IsGroupVersionAvailable(tt.discoveryClient, tt.groupVersion)
+			if err != nil {
+				t.Fatalf("IsGroupVersionAvailable() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsGroupVersionAvailable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsGroupVersionAvailableRetries(t *testing.T) {
+	backoff := discoveryBackoff
+	discoveryBackoff = wait.Backoff{Steps: 3}
+	t.Cleanup(func() { discoveryBackoff = backoff })
+
+	tests := []struct {
+		name          string
+		errs          []error
+		want          bool
+		wantCalls     int
+		wantTransient bool
+		wantErr       bool
+	}{
+		{
+			name:      "transient failure",
+			errs:      []error{apierrors.NewServiceUnavailable("unavailable"), apierrors.NewTooManyRequests("throttled", 1)},
+			want:      true,
+			wantCalls: 3,
+		},
+		{
+			name:          "persistent transient failure",
+			errs:          []error{apierrors.NewServiceUnavailable("unavailable"), apierrors.NewServiceUnavailable("unavailable"), apierrors.NewServiceUnavailable("unavailable")},
+			wantCalls:     3,
+			wantTransient: true,
+			wantErr:       true,
+		},
+		{
+			name:      "not served after a transient failure",
+			errs:      []error{apierrors.NewInternalError(errors.New("aggregation"))},
+			wantCalls: 2,
+		},
+		{
+			name:      "permanent failure",
+			errs:      []error{apierrors.NewForbidden(schema.GroupResource{}, "", errors.New("denied"))},
+			wantCalls: 1,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewClientset()
+			fakeDiscovery := client.Discovery().(*fakediscovery.FakeDiscovery)
+			fakeDiscovery.Resources = []*metav1.APIResourceList{{GroupVersion: "karpenter.sh/v1"}}
+			calls := 0
+			fakeDiscovery.PrependReactor("get", "resource", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				calls++
+				if calls <= len(tt.errs) {
+					return true, nil, tt.errs[calls-1]
+				}
+				return false, nil, nil
+			})
+			groupVersion := "karpenter.sh/v1"
+			if !tt.want {
+				groupVersion = "karpenter.sh/v2"
+			}
+
+			got, err := IsGroupVersionAvailable(client.Discovery(), groupVersion)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("IsGroupVersionAvailable() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var transient *TransientDiscoveryError
+			if errors.As(err, &transient) != tt.wantTransient {
+				t.Errorf("IsGroupVersionAvailable() error = %v, want transient %v", err, tt.wantTransient)
+			}
+			if got != tt.want {
+				t.Errorf("IsGroupVersionAvailable() = %v, want %v", got, tt.want)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("IsGroupVersionAvailable() discovered %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestAPIServiceUnavailableReason(t *testing.T) {
+	newAPIService := func(conditions ...apiregistrationv1.APIServiceCondition) *apiregistrationv1.APIService {
+		return &apiregistrationv1.APIService{Status: apiregistrationv1.APIServiceStatus{Conditions: conditions}}
+	}
+
+	tests := []struct {
+		name       string
+		apiService *apiregistrationv1.APIService
+		want       string
+	}{
+		{
+			name:       "available",
+			apiService: newAPIService(apiregistrationv1.APIServiceCondition{Type: apiregistrationv1.Available, Status: apiregistrationv1.ConditionTrue}),
+		},
+		{
+			name: "unavailable",
+			apiService: newAPIService(apiregistrationv1.APIServiceCondition{
+				Type:    apiregistrationv1.Available,
+				Status:  apiregistrationv1.ConditionFalse,
+				Reason:  "MissingEndpoints",
+				Message: "endpoints for service/prometheus-adapter in \"monitoring\" have no addresses with port name \"https\"",
+			}),
+			want: `its Available condition is False: MissingEndpoints: endpoints for service/prometheus-adapter in "monitoring" have no addresses with port name "https"`,
+		},
+		{
+			name:       "no condition",
+			apiService: newAPIService(),
+			want:       "it has no Available condition",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := apiServiceUnavailableReason(tt.apiService); got != tt.want {
+				t.Errorf("apiServiceUnavailableReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
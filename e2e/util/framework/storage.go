@@ -0,0 +1,71 @@
+package framework
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	storagev1 "k8s.io/api/storage/v1"
+)
+
+// defaultStorageClassAnnotations are the annotations marking the default StorageClass, the beta one is still
+// honored by the API server.
+var defaultStorageClassAnnotations = []string{
+	"storageclass.kubernetes.io/is-default-class",
+	"storageclass.beta.kubernetes.io/is-default-class",
+}
+
+// ddCopiedRegexp matches the summary of dd, i.e. "<bytes> bytes (...) copied, <seconds> s, ..." of GNU coreutils and
+// "<bytes> bytes (...) copied, <seconds> seconds, ..." of busybox.
+var ddCopiedRegexp = regexp.MustCompile(`(\d+) bytes .*copied, ([0-9.]+) s`)
+
+// DefaultStorageClass returns the name of the default StorageClass among the classes, i.e. the one annotated as the
+// default class. If several are, the most recently created one is returned, as the API server assigns it to the
+// claims without a class. An empty string is returned if there is none.
+func DefaultStorageClass(classes []storagev1.StorageClass) string {
+	var found *storagev1.StorageClass
+	for i := range classes {
+		class := &classes[i]
+		if !isDefaultStorageClass(class) {
+			continue
+		}
+		if found == nil || class.CreationTimestamp.After(found.CreationTimestamp.Time) ||
+			class.CreationTimestamp.Equal(&found.CreationTimestamp) && class.Name < found.Name {
+			found = class
+		}
+	}
+	if found == nil {
+		return ""
+	}
+	return found.Name
+}
+
+// isDefaultStorageClass returns whether the class is annotated as the default class.
+func isDefaultStorageClass(class *storagev1.StorageClass) bool {
+	for _, annotation := range defaultStorageClassAnnotations {
+		if class.Annotations[annotation] == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseDDThroughput returns the throughput in bytes per second reported by dd in its output.
+func ParseDDThroughput(output string) (float64, error) {
+	match := ddCopiedRegexp.FindStringSubmatch(output)
+	if match == nil {
+		return 0, fmt.Errorf("no summary of dd is found in the output %q", output)
+	}
+	bytes, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("error when parsing the bytes copied by dd %q: %w", match[1], err)
+	}
+	seconds, err := strconv.ParseFloat(match[2], 64)
+	if err != nil {
+		return 0, fmt.Errorf("error when parsing the duration of dd %q: %w", match[2], err)
+	}
+	if seconds <= 0 {
+		return 0, fmt.Errorf("dd copied %s bytes in %s seconds, too fast to measure the throughput", match[1], match[2])
+	}
+	return bytes / seconds, nil
+}
@@ -0,0 +1,114 @@
+package framework
+
+import (
+	"testing"
+	"time"
+
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDefaultStorageClass(t *testing.T) {
+	now := time.Now()
+	newClass := func(name string, created time.Time, annotations map[string]string) storagev1.StorageClass {
+		return storagev1.StorageClass{
+			ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(created), Annotations: annotations},
+		}
+	}
+	isDefault := map[string]string{"storageclass.kubernetes.io/is-default-class": "true"}
+	tests := []struct {
+		name    string
+		classes []storagev1.StorageClass
+		want    string
+	}{
+		{
+			name: "default class",
+			classes: []storagev1.StorageClass{
+				newClass("local-ssd", now, nil),
+				newClass("standard", now, isDefault),
+			},
+			want: "standard",
+		},
+		{
+			name: "beta annotation",
+			classes: []storagev1.StorageClass{
+				newClass("standard", now, map[string]string{"storageclass.beta.kubernetes.io/is-default-class": "true"}),
+			},
+			want: "standard",
+		},
+		{
+			name: "most recently created default class",
+			classes: []storagev1.StorageClass{
+				newClass("standard", now.Add(-time.Hour), isDefault),
+				newClass("premium", now, isDefault),
+				newClass("balanced", now.Add(-time.Minute), isDefault),
+			},
+			want: "premium",
+		},
+		{
+			name: "tie broken by name",
+			classes: []storagev1.StorageClass{
+				newClass("standard", now, isDefault),
+				newClass("premium", now, isDefault),
+			},
+			want: "premium",
+		},
+		{
+			name: "no default class",
+			classes: []storagev1.StorageClass{
+				newClass("standard", now, map[string]string{"storageclass.kubernetes.io/is-default-class": "false"}),
+			},
+		},
+		{
+			name: "no class",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DefaultStorageClass(tt.classes); got != tt.want {
+				t.Errorf("DefaultStorageClass() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDDThroughput(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    float64
+		wantErr bool
+	}{
+		{
+			name:   "GNU coreutils",
+			output: "1024+0 records in\n1024+0 records out\n1073741824 bytes (1.1 GB, 1.0 GiB) copied, 2 s, 537 MB/s\n",
+			want:   536870912,
+		},
+		{
+			name:   "busybox",
+			output: "1024+0 records in\n1024+0 records out\n1073741824 bytes (1.0GB) copied, 4.000000 seconds, 256.0MB/s\n",
+			want:   268435456,
+		},
+		{
+			name:    "no summary",
+			output:  "dd: can't open '/scratch/dd': Read-only file system\n",
+			wantErr: true,
+		},
+		{
+			name:    "zero duration",
+			output:  "1048576 bytes (1.0MB) copied, 0.000000 seconds, 1.0TB/s\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDDThroughput(tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDDThroughput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseDDThroughput() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package framework
+
+import (
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/types"
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2econfig "k8s.io/kubernetes/test/e2e/framework/config"
+)
+
+var summary struct {
+	RequiredAreas string `default:"" usage:"comma-separated areas, e.g. DRA Support,Gang Scheduling, of which at least one MUST spec has to run instead of being skipped, otherwise the suite fails at its end, so that a conformance run skipping them doesn't pass vacuously. If unspecified, the areas whose MUST specs are all skipped are only reported"`
+}
+var _ = e2econfig.AddOptions(&summary, "ai")
+
+// labelPrefixRE matches the labels prepended to the text of a container by the e2e framework, e.g.
+// "[wg-ai-conformance] ".
+var labelPrefixRE = regexp.MustCompile(`^(\[[^\]]*\] )+`)
+
+// AreaSummary is the number of the MUST specs of an area in each state.
+type AreaSummary struct {
+	// Area is the text of the top level container of the specs, e.g. DRA Support.
+	Area    string
+	Passed  int
+	Failed  int
+	Skipped int
+}
+
+// Ran returns true if any of the specs of the area ran.
+func (s AreaSummary) Ran() bool {
+	return s.Passed+s.Failed > 0
+}
+
+// SummarizeAreas counts the MUST specs of the AI conformance in each state by their areas, sorted by name. The
+// specs filtered out by the label filter or the focus are counted as skipped.
+func SummarizeAreas(reports types.SpecReports) []AreaSummary {
+	areas := map[string]*AreaSummary{}
+	for _, report := range reports {
+		if report.LeafNodeType != types.NodeTypeIt || len(report.ContainerHierarchyTexts) == 0 {
+			continue
+		}
+		labels := report.Labels()
+		if !slices.Contains(labels, "AIConformance") || !slices.Contains(labels, LevelMust) {
+			continue
+		}
+		area := labelPrefixRE.ReplaceAllString(report.ContainerHierarchyTexts[0], "")
+		if areas[area] == nil {
+			areas[area] = &AreaSummary{Area: area}
+		}
+		switch {
+		case report.State == types.SpecStatePassed:
+			areas[area].Passed++
+		case report.Failed():
+			areas[area].Failed++
+		default:
+			areas[area].Skipped++
+		}
+	}
+	var summaries []AreaSummary
+	for _, s := range areas {
+		summaries = append(summaries, *s)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Area < summaries[j].Area })
+	return summaries
+}
+
+// VacuousAreas returns the given areas which have no MUST spec that ran, including the unknown ones.
+func VacuousAreas(summaries []AreaSummary, areas []string) []string {
+	var vacuous []string
+	for _, area := range areas {
+		i := slices.IndexFunc(summaries, func(s AreaSummary) bool { return s.Area == area })
+		if i < 0 || !summaries[i].Ran() {
+			vacuous = append(vacuous, area)
+		}
+	}
+	return vacuous
+}
+
+// ReportAreas is the body of a ginkgo.ReportAfterSuite node which logs the MUST specs of each area which passed,
+// failed or were skipped, and fails the suite if none of the MUST specs of an area of -ai.requiredAreas ran.
+func ReportAreas(report ginkgo.Report) {
+	summaries := SummarizeAreas(report.SpecReports)
+	var skippedAreas []string
+	for _, s := range summaries {
+		framework.Logf("AI conformance area %q: %d passed, %d failed, %d skipped", s.Area, s.Passed, s.Failed, s.Skipped)
+		if !s.Ran() {
+			skippedAreas = append(skippedAreas, s.Area)
+		}
+	}
+	if len(skippedAreas) > 0 {
+		framework.Logf("All the MUST specs of the AI conformance areas %v were skipped", skippedAreas)
+	}
+
+	var required []string
+	for _, area := range strings.Split(summary.RequiredAreas, ",") {
+		if area = strings.TrimSpace(area); area != "" {
+			required = append(required, area)
+		}
+	}
+	if vacuous := VacuousAreas(summaries, required); len(vacuous) > 0 {
+		framework.Failf("None of the MUST specs of the required areas %v ran, see -ai.requiredAreas", vacuous)
+	}
+}
@@ -0,0 +1,77 @@
+package framework
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/onsi/ginkgo/v2/types"
+)
+
+func TestSummarizeAreas(t *testing.T) {
+	newReport := func(area string, state types.SpecState, labels ...string) types.SpecReport {
+		return types.SpecReport{
+			ContainerHierarchyTexts:  []string{"[wg-ai-conformance] " + area},
+			ContainerHierarchyLabels: [][]string{{"wg-ai-conformance"}},
+			LeafNodeType:             types.NodeTypeIt,
+			LeafNodeLabels:           labels,
+			State:                    state,
+		}
+	}
+	must := []string{"Conformance", "AIConformance", LevelMust}
+	reports := types.SpecReports{
+		newReport("DRA Support", types.SpecStatePassed, must...),
+		newReport("DRA Support", types.SpecStateSkipped, must...),
+		newReport("Gang Scheduling", types.SpecStateFailed, must...),
+		newReport("Gang Scheduling", types.SpecStateTimedout, must...),
+		newReport("Accelerator Metrics", types.SpecStateSkipped, must...),
+		newReport("Accelerator Metrics", types.SpecStatePassed, "AIConformance", LevelShould),
+		newReport("Networking", types.SpecStatePassed, "Conformance"),
+		{LeafNodeType: types.NodeTypeBeforeSuite, State: types.SpecStatePassed},
+	}
+	want := []AreaSummary{
+		{Area: "Accelerator Metrics", Skipped: 1},
+		{Area: "DRA Support", Passed: 1, Skipped: 1},
+		{Area: "Gang Scheduling", Failed: 2},
+	}
+	if got := SummarizeAreas(reports); !reflect.DeepEqual(got, want) {
+		t.Errorf("SummarizeAreas() = %+v, want %+v", got, want)
+	}
+}
+
+func TestVacuousAreas(t *testing.T) {
+	summaries := []AreaSummary{
+		{Area: "Accelerator Metrics", Skipped: 1},
+		{Area: "DRA Support", Passed: 1, Skipped: 1},
+		{Area: "Gang Scheduling", Failed: 2},
+	}
+	tests := []struct {
+		name  string
+		areas []string
+		want  []string
+	}{
+		{
+			name:  "all required areas ran",
+			areas: []string{"DRA Support", "Gang Scheduling"},
+		},
+		{
+			name:  "skipped area",
+			areas: []string{"Accelerator Metrics", "DRA Support"},
+			want:  []string{"Accelerator Metrics"},
+		},
+		{
+			name:  "unknown area",
+			areas: []string{"Gang Scheduling", "Accelerator Quotas"},
+			want:  []string{"Accelerator Quotas"},
+		},
+		{
+			name: "no required area",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VacuousAreas(summaries, tt.areas); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("VacuousAreas() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
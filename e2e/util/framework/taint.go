@@ -0,0 +1,76 @@
+package framework
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// ParseTaint parses the taint in the key[=value]:effect form, e.g. nvidia.com/gpu=present:NoSchedule.
+func ParseTaint(taint string) (*v1.Taint, error) {
+	spec, effect, ok := strings.Cut(strings.TrimSpace(taint), ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid taint %q, expected key[=value]:effect", taint)
+	}
+	switch v1.TaintEffect(effect) {
+	case v1.TaintEffectNoSchedule, v1.TaintEffectPreferNoSchedule, v1.TaintEffectNoExecute:
+	default:
+		return nil, fmt.Errorf("invalid effect %q of taint %q", effect, taint)
+	}
+	key, value, _ := strings.Cut(spec, "=")
+	if key == "" {
+		return nil, fmt.Errorf("invalid taint %q, expected key[=value]:effect", taint)
+	}
+	return &v1.Taint{Key: key, Value: value, Effect: v1.TaintEffect(effect)}, nil
+}
+
+// hasTaint returns true if the node carries the taint with the same key, value and effect.
+func hasTaint(node *v1.Node, taint *v1.Taint) bool {
+	for i := range node.Spec.Taints {
+		if node.Spec.Taints[i].MatchTaint(taint) && node.Spec.Taints[i].Value == taint.Value {
+			return true
+		}
+	}
+	return false
+}
+
+// CommonTaint returns the first taint of the first node with the NoSchedule or NoExecute effect which all the nodes
+// carry, or nil if there's none. The PreferNoSchedule taints are ignored, as they don't keep other pods away.
+func CommonTaint(nodes []v1.Node) *v1.Taint {
+	if len(nodes) == 0 {
+		return nil
+	}
+	for i := range nodes[0].Spec.Taints {
+		taint := &nodes[0].Spec.Taints[i]
+		if taint.Effect != v1.TaintEffectNoSchedule && taint.Effect != v1.TaintEffectNoExecute {
+			continue
+		}
+		if len(NodesWithoutTaint(nodes, taint)) == 0 {
+			return taint
+		}
+	}
+	return nil
+}
+
+// NodesWithoutTaint returns the names of the nodes which don't carry the taint.
+func NodesWithoutTaint(nodes []v1.Node, taint *v1.Taint) []string {
+	var names []string
+	for i := range nodes {
+		if !hasTaint(&nodes[i], taint) {
+			names = append(names, nodes[i].Name)
+		}
+	}
+	return names
+}
+
+// TolerationForTaint returns the toleration which tolerates only the taint, rather than all the taints with its
+// effect.
+func TolerationForTaint(taint *v1.Taint) v1.Toleration {
+	return v1.Toleration{
+		Key:      taint.Key,
+		Operator: v1.TolerationOpEqual,
+		Value:    taint.Value,
+		Effect:   taint.Effect,
+	}
+}
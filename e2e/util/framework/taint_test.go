@@ -0,0 +1,116 @@
+package framework
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newNodeWithTaints(name string, taints ...v1.Taint) v1.Node {
+	return v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: v1.NodeSpec{Taints: taints}}
+}
+
+func TestParseTaint(t *testing.T) {
+	tests := []struct {
+		name    string
+		taint   string
+		want    *v1.Taint
+		wantErr bool
+	}{
+		{
+			name:  "key, value and effect",
+			taint: "nvidia.com/gpu=present:NoSchedule",
+			want:  &v1.Taint{Key: "nvidia.com/gpu", Value: "present", Effect: v1.TaintEffectNoSchedule},
+		},
+		{
+			name:  "key and effect",
+			taint: "amd.com/gpu:NoExecute",
+			want:  &v1.Taint{Key: "amd.com/gpu", Effect: v1.TaintEffectNoExecute},
+		},
+		{
+			name:    "missing effect",
+			taint:   "nvidia.com/gpu=present",
+			wantErr: true,
+		},
+		{
+			name:    "invalid effect",
+			taint:   "nvidia.com/gpu=present:Never",
+			wantErr: true,
+		},
+		{
+			name:    "missing key",
+			taint:   "=present:NoSchedule",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTaint(tt.taint)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTaint() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTaint() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommonTaint(t *testing.T) {
+	gpuTaint := v1.Taint{Key: "nvidia.com/gpu", Value: "present", Effect: v1.TaintEffectNoSchedule}
+	preferTaint := v1.Taint{Key: "example.com/prefer", Effect: v1.TaintEffectPreferNoSchedule}
+	poolTaint := v1.Taint{Key: "example.com/pool", Value: "gpu", Effect: v1.TaintEffectNoExecute}
+
+	tests := []struct {
+		name  string
+		nodes []v1.Node
+		want  *v1.Taint
+	}{
+		{
+			name: "no nodes",
+		},
+		{
+			name:  "untainted",
+			nodes: []v1.Node{newNodeWithTaints("a"), newNodeWithTaints("b", gpuTaint)},
+		},
+		{
+			name:  "shared taint",
+			nodes: []v1.Node{newNodeWithTaints("a", gpuTaint), newNodeWithTaints("b", poolTaint, gpuTaint)},
+			want:  &gpuTaint,
+		},
+		{
+			name:  "PreferNoSchedule is ignored",
+			nodes: []v1.Node{newNodeWithTaints("a", preferTaint, poolTaint), newNodeWithTaints("b", poolTaint, preferTaint)},
+			want:  &poolTaint,
+		},
+		{
+			name: "different values",
+			nodes: []v1.Node{
+				newNodeWithTaints("a", gpuTaint),
+				newNodeWithTaints("b", v1.Taint{Key: "nvidia.com/gpu", Value: "absent", Effect: v1.TaintEffectNoSchedule}),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CommonTaint(tt.nodes); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CommonTaint() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNodesWithoutTaint(t *testing.T) {
+	gpuTaint := &v1.Taint{Key: "nvidia.com/gpu", Value: "present", Effect: v1.TaintEffectNoSchedule}
+	nodes := []v1.Node{
+		newNodeWithTaints("tainted", *gpuTaint),
+		newNodeWithTaints("untainted"),
+		newNodeWithTaints("other-effect", v1.Taint{Key: "nvidia.com/gpu", Value: "present", Effect: v1.TaintEffectNoExecute}),
+	}
+	want := []string{"untainted", "other-effect"}
+	if got := NodesWithoutTaint(nodes, gpuTaint); !reflect.DeepEqual(got, want) {
+		t.Errorf("NodesWithoutTaint() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,81 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+// VerifyGracefulTermination returns an error unless all the containers of the deleted pod exited with 0 before its
+// deletion timestamp, i.e. they handled SIGTERM within the grace period instead of being killed by SIGKILL after it.
+func VerifyGracefulTermination(pod *v1.Pod) error {
+	if pod.DeletionTimestamp == nil {
+		return fmt.Errorf("pod %s is not being deleted", pod.Name)
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		terminated := status.State.Terminated
+		if terminated == nil {
+			return fmt.Errorf("container %s of pod %s is not terminated", status.Name, pod.Name)
+		}
+		if terminated.ExitCode != 0 {
+			return fmt.Errorf("container %s of pod %s exited with %d (%s)", status.Name, pod.Name, terminated.ExitCode, terminated.Reason)
+		}
+		// The timestamps are truncated to seconds.
+		if terminated.FinishedAt.Time.After(pod.DeletionTimestamp.Add(time.Second)) {
+			return fmt.Errorf("container %s of pod %s finished at %v, after its grace period ended at %v",
+				status.Name, pod.Name, terminated.FinishedAt, pod.DeletionTimestamp)
+		}
+	}
+	return nil
+}
+
+// RemovePodFinalizer removes the finalizer from the pod. Nothing is done if the pod doesn't exist.
+func RemovePodFinalizer(ctx context.Context, client clientset.Interface, namespace, name, finalizer string) error {
+	pod, err := client.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error when getting pod %s: %w", name, err)
+	}
+	if !slices.Contains(pod.Finalizers, finalizer) {
+		return nil
+	}
+	pod.Finalizers = slices.DeleteFunc(pod.Finalizers, func(f string) bool { return f == finalizer })
+	_, err = client.CoreV1().Pods(namespace).Update(ctx, pod, metav1.UpdateOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("error when removing finalizer %s from pod %s: %w", finalizer, name, err)
+	}
+	return nil
+}
+
+// CrashedContainers returns the containers of the pods which crashed, as "<pod>/<container>: <reason>", sorted. A
+// container crashed if it's waiting in CrashLoopBackOff, or it terminated with a non-zero exit code, e.g. it was
+// killed after failing its liveness probe, unless its pod is being deleted or disrupted, e.g. by the preemption or
+// the eviction of its workload.
+func CrashedContainers(pods []v1.Pod) []string {
+	var crashed []string
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil || slices.ContainsFunc(pod.Status.Conditions, func(cond v1.PodCondition) bool {
+			return cond.Type == v1.DisruptionTarget && cond.Status == v1.ConditionTrue
+		}) {
+			continue
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			switch {
+			case status.State.Waiting != nil && status.State.Waiting.Reason == "CrashLoopBackOff":
+				crashed = append(crashed, fmt.Sprintf("%s/%s: CrashLoopBackOff after %d restarts", pod.Name, status.Name, status.RestartCount))
+			case status.State.Terminated != nil && status.State.Terminated.ExitCode != 0:
+				crashed = append(crashed, fmt.Sprintf("%s/%s: exited with %d (%s)", pod.Name, status.Name, status.State.Terminated.ExitCode, status.State.Terminated.Reason))
+			}
+		}
+	}
+	slices.Sort(crashed)
+	return crashed
+}
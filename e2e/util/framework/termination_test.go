@@ -0,0 +1,115 @@
+package framework
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestVerifyGracefulTermination(t *testing.T) {
+	deletedAt := time.Date(2025, 10, 1, 0, 0, 30, 0, time.UTC)
+	newPod := func(deletionTimestamp *time.Time, states ...v1.ContainerState) *v1.Pod {
+		pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "workload"}}
+		if deletionTimestamp != nil {
+			pod.DeletionTimestamp = &metav1.Time{Time: *deletionTimestamp}
+		}
+		for _, state := range states {
+			pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, v1.ContainerStatus{Name: "main", State: state})
+		}
+		return pod
+	}
+	terminated := func(exitCode int32, reason string, finishedAt time.Time) v1.ContainerState {
+		return v1.ContainerState{Terminated: &v1.ContainerStateTerminated{ExitCode: exitCode, Reason: reason, FinishedAt: metav1.Time{Time: finishedAt}}}
+	}
+
+	tests := []struct {
+		name    string
+		pod     *v1.Pod
+		wantErr bool
+	}{
+		{
+			name: "exited within the grace period",
+			pod:  newPod(&deletedAt, terminated(0, "Completed", deletedAt.Add(-28*time.Second))),
+		},
+		{
+			name: "exited in the last second of the grace period",
+			pod:  newPod(&deletedAt, terminated(0, "Completed", deletedAt.Add(time.Second))),
+		},
+		{
+			name:    "killed by SIGKILL",
+			pod:     newPod(&deletedAt, terminated(137, "Error", deletedAt.Add(time.Second))),
+			wantErr: true,
+		},
+		{
+			name:    "exited after the grace period",
+			pod:     newPod(&deletedAt, terminated(0, "Completed", deletedAt.Add(5*time.Second))),
+			wantErr: true,
+		},
+		{
+			name:    "still running",
+			pod:     newPod(&deletedAt, v1.ContainerState{Running: &v1.ContainerStateRunning{}}),
+			wantErr: true,
+		},
+		{
+			name:    "not deleted",
+			pod:     newPod(nil, terminated(0, "Completed", deletedAt)),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyGracefulTermination(tt.pod)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifyGracefulTermination() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCrashedContainers(t *testing.T) {
+	newPod := func(name string, state v1.ContainerState, conditions ...v1.PodCondition) v1.Pod {
+		return v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: v1.PodStatus{
+				Conditions:        conditions,
+				ContainerStatuses: []v1.ContainerStatus{{Name: "main", State: state, RestartCount: 3}},
+			},
+		}
+	}
+	running := v1.ContainerState{Running: &v1.ContainerStateRunning{}}
+	crashLooping := v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}
+	killed := v1.ContainerState{Terminated: &v1.ContainerStateTerminated{ExitCode: 137, Reason: "Error"}}
+	completed := v1.ContainerState{Terminated: &v1.ContainerStateTerminated{ExitCode: 0, Reason: "Completed"}}
+	deleted := newPod("deleted", killed)
+	deleted.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+
+	tests := []struct {
+		name string
+		pods []v1.Pod
+		want []string
+	}{
+		{
+			name: "running and completed",
+			pods: []v1.Pod{newPod("job-0", completed), newPod("job-1", running)},
+		},
+		{
+			name: "crashed",
+			pods: []v1.Pod{newPod("job-2", crashLooping), newPod("job-1", killed), newPod("job-0", running)},
+			want: []string{"job-1/main: exited with 137 (Error)", "job-2/main: CrashLoopBackOff after 3 restarts"},
+		},
+		{
+			name: "deleted or disrupted",
+			pods: []v1.Pod{deleted, newPod("preempted", killed, v1.PodCondition{Type: v1.DisruptionTarget, Status: v1.ConditionTrue})},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CrashedContainers(tt.pods); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CrashedContainers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
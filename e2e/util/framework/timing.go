@@ -0,0 +1,109 @@
+package framework
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/types"
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2econfig "k8s.io/kubernetes/test/e2e/framework/config"
+)
+
+var timings struct {
+	RecordTimings bool `default:"false" usage:"if true, the durations of the key waits of the specs, e.g. node provisioning, scale-up and metric collection, are written to ai-conformance-timings.json in the report directory at the end of the suite, or logged if -report-dir is unspecified, so that the runs can be compared"`
+}
+var _ = e2econfig.AddOptions(&timings, "ai")
+
+const (
+	// timingEntryName is the name of the report entries of the timings.
+	timingEntryName = "ai-conformance-timing"
+	// timingsFileName is the name of the timings artifact in the report directory.
+	timingsFileName = "ai-conformance-timings.json"
+)
+
+// Timing is the duration of an operation of a spec.
+type Timing struct {
+	Operation string  `json:"operation"`
+	Seconds   float64 `json:"seconds"`
+}
+
+// SpecTiming is a timing with the spec which recorded it.
+type SpecTiming struct {
+	// Area is the text of the top level container of the spec, e.g. Cluster Autoscaling.
+	Area  string `json:"area"`
+	Spec  string `json:"spec"`
+	State string `json:"state"`
+	Timing
+}
+
+// StartTiming starts timing the operation of the current spec, e.g. node provisioning, and returns a function
+// which records its duration in the report of the spec. The function must be called in the spec goroutine, e.g.
+// right after the wait returns.
+func StartTiming(operation string) func() {
+	start := time.Now()
+	return func() {
+		elapsed := time.Since(start)
+		framework.Logf("%s took %v", operation, elapsed)
+		ginkgo.AddReportEntry(timingEntryName, Timing{Operation: operation, Seconds: elapsed.Seconds()}, ginkgo.ReportEntryVisibilityNever)
+	}
+}
+
+// CollectTimings returns the timings recorded by the specs in the order of the reports.
+func CollectTimings(reports types.SpecReports) ([]SpecTiming, error) {
+	var result []SpecTiming
+	for _, report := range reports {
+		var area string
+		if len(report.ContainerHierarchyTexts) > 0 {
+			area = labelPrefixRE.ReplaceAllString(report.ContainerHierarchyTexts[0], "")
+		}
+		for _, entry := range report.ReportEntries {
+			if entry.Name != timingEntryName {
+				continue
+			}
+			// The value is only encoded in AsJSON if the report is sent by another parallel process.
+			data := []byte(entry.Value.AsJSON)
+			if len(data) == 0 {
+				var err error
+				if data, err = json.Marshal(entry.Value.GetRawValue()); err != nil {
+					return nil, fmt.Errorf("error when encoding the timing of spec %q: %w", report.FullText(), err)
+				}
+			}
+			var timing Timing
+			if err := json.Unmarshal(data, &timing); err != nil {
+				return nil, fmt.Errorf("error when decoding the timing %s of spec %q: %w", string(data), report.FullText(), err)
+			}
+			result = append(result, SpecTiming{Area: area, Spec: report.LeafNodeText, State: report.State.String(), Timing: timing})
+		}
+	}
+	return result, nil
+}
+
+// ReportTimings is the body of a ginkgo.ReportAfterSuite node which writes the timings recorded by the specs to
+// the report directory if -ai.recordTimings is set. The timings are informational, so the errors are only logged.
+func ReportTimings(report ginkgo.Report) {
+	if !timings.RecordTimings {
+		return
+	}
+	specTimings, err := CollectTimings(report.SpecReports)
+	if err != nil {
+		framework.Logf("Error collecting the timings: %v", err)
+		return
+	}
+	data, err := json.MarshalIndent(specTimings, "", "  ")
+	if err != nil {
+		framework.Logf("Error encoding the timings: %v", err)
+		return
+	}
+	if framework.TestContext.ReportDir == "" {
+		framework.Logf("AI conformance timings:\n%s", string(data))
+		return
+	}
+	filePath := filepath.Join(framework.TestContext.ReportDir, timingsFileName)
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		framework.Logf("Error writing the timings to %q: %v", filePath, err)
+	}
+}
@@ -0,0 +1,53 @@
+package framework
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/onsi/ginkgo/v2/types"
+)
+
+func TestCollectTimings(t *testing.T) {
+	newReport := func(area, spec string, state types.SpecState, entries ...types.ReportEntry) types.SpecReport {
+		return types.SpecReport{
+			ContainerHierarchyTexts: []string{"[wg-ai-conformance] " + area},
+			LeafNodeText:            spec,
+			LeafNodeType:            types.NodeTypeIt,
+			State:                   state,
+			ReportEntries:           entries,
+		}
+	}
+	reports := types.SpecReports{
+		newReport("Cluster Autoscaling", "should provision a node", types.SpecStatePassed,
+			types.ReportEntry{Name: timingEntryName, Value: types.WrapEntryValue(Timing{Operation: "node provisioning", Seconds: 120})},
+			types.ReportEntry{Name: "other", Value: types.WrapEntryValue("ignored")},
+			types.ReportEntry{Name: timingEntryName, Value: types.WrapEntryValue(Timing{Operation: "node reclaim", Seconds: 600.5})},
+		),
+		newReport("DRA Support", "should allocate a device", types.SpecStatePassed),
+		// The report of another parallel process only carries the encoded value.
+		newReport("Pod Autoscaling", "should scale up", types.SpecStateFailed,
+			types.ReportEntry{Name: timingEntryName, Value: types.ReportEntryValue{AsJSON: `{"operation":"scale-up","seconds":42}`}},
+		),
+	}
+	want := []SpecTiming{
+		{Area: "Cluster Autoscaling", Spec: "should provision a node", State: "passed", Timing: Timing{Operation: "node provisioning", Seconds: 120}},
+		{Area: "Cluster Autoscaling", Spec: "should provision a node", State: "passed", Timing: Timing{Operation: "node reclaim", Seconds: 600.5}},
+		{Area: "Pod Autoscaling", Spec: "should scale up", State: "failed", Timing: Timing{Operation: "scale-up", Seconds: 42}},
+	}
+	got, err := CollectTimings(reports)
+	if err != nil {
+		t.Fatalf("CollectTimings() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CollectTimings() = %+v, want %+v", got, want)
+	}
+
+	malformed := types.SpecReports{
+		newReport("Pod Autoscaling", "should scale up", types.SpecStatePassed,
+			types.ReportEntry{Name: timingEntryName, Value: types.ReportEntryValue{AsJSON: `{"seconds":"forever"}`}},
+		),
+	}
+	if _, err := CollectTimings(malformed); err == nil {
+		t.Errorf("CollectTimings() of a malformed timing should fail")
+	}
+}
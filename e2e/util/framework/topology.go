@@ -0,0 +1,213 @@
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/utils/cpuset"
+
+	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
+	imageutils "k8s.io/kubernetes/test/utils/image"
+)
+
+const (
+	// SingleNUMANodeTopologyPolicy is the Topology Manager policy which aligns the CPUs and the devices of a pod
+	// to a single NUMA node.
+	SingleNUMANodeTopologyPolicy = "single-numa-node"
+	// StaticCPUManagerPolicy is the CPU Manager policy which assigns exclusive CPUs to the guaranteed pods.
+	StaticCPUManagerPolicy = "static"
+	// StaticMemoryManagerPolicy is the Memory Manager policy which assigns the memory and the hugepages of the
+	// guaranteed pods from the NUMA nodes chosen by the Topology Manager.
+	StaticMemoryManagerPolicy = "Static"
+)
+
+// KubeletConfig is the part of the kubelet configuration served by the configz endpoint of the kubelet which the
+// tests check.
+type KubeletConfig struct {
+	CPUManagerPolicy      string `json:"cpuManagerPolicy"`
+	TopologyManagerPolicy string `json:"topologyManagerPolicy"`
+	TopologyManagerScope  string `json:"topologyManagerScope"`
+	MemoryManagerPolicy   string `json:"memoryManagerPolicy"`
+}
+
+// GetKubeletConfig returns the configuration of the kubelet on the node via the node proxy of the API server.
+func GetKubeletConfig(ctx context.Context, client clientset.Interface, nodeName string) (*KubeletConfig, error) {
+	data, err := client.CoreV1().RESTClient().Get().Resource("nodes").Name(nodeName).SubResource("proxy").Suffix("configz").DoRaw(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error when getting the kubelet configuration of node %s: %w", nodeName, err)
+	}
+	return decodeKubeletConfigz(data)
+}
+
+func decodeKubeletConfigz(data []byte) (*KubeletConfig, error) {
+	var configz struct {
+		KubeletConfig *KubeletConfig `json:"kubeletconfig"`
+	}
+	if err := json.Unmarshal(data, &configz); err != nil {
+		return nil, fmt.Errorf("error when decoding the kubelet configuration %s: %w", string(data), err)
+	}
+	if configz.KubeletConfig == nil {
+		return nil, fmt.Errorf("no kubeletconfig in %s", string(data))
+	}
+	return configz.KubeletConfig, nil
+}
+
+// NUMANodeCPUs returns the CPUs of each NUMA node of the node, read from its sysfs by a pod created in the
+// namespace.
+func NUMANodeCPUs(ctx context.Context, client clientset.Interface, namespace, nodeName string) (map[int]cpuset.CPUSet, error) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: "numa-nodes-"},
+		Spec: v1.PodSpec{
+			NodeName:      nodeName,
+			RestartPolicy: v1.RestartPolicyNever,
+			Tolerations:   []v1.Toleration{{Operator: v1.TolerationOpExists}},
+			Containers: []v1.Container{
+				{
+					Name:    "sysfs",
+					Image:   imageutils.GetE2EImage(imageutils.BusyBox),
+					Command: []string{"/bin/sh", "-c", `for node in /sys/devices/system/node/node*; do echo "${node##*/node} $(cat $node/cpulist)"; done`},
+				},
+			},
+		},
+	}
+	pod, err := client.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error when creating pod to read the NUMA nodes: %w", err)
+	}
+	DeferCleanup(client.CoreV1().Pods(namespace).Delete, pod.Name, metav1.DeleteOptions{})
+
+	if err := e2epod.WaitForPodSuccessInNamespace(ctx, client, pod.Name, namespace); err != nil {
+		return nil, fmt.Errorf("error when reading the NUMA nodes of node %s: %w", nodeName, err)
+	}
+	output, err := e2epod.GetPodLogs(ctx, client, namespace, pod.Name, pod.Spec.Containers[0].Name)
+	if err != nil {
+		return nil, fmt.Errorf("error when getting logs of pod %s: %w", pod.Name, err)
+	}
+	return parseNUMANodeCPUs(output)
+}
+
+// parseNUMANodeCPUs parses the "<numa node> <cpulist>" lines.
+func parseNUMANodeCPUs(output string) (map[int]cpuset.CPUSet, error) {
+	numaCPUs := map[int]cpuset.CPUSet{}
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		id, cpus, _ := strings.Cut(strings.TrimSpace(line), " ")
+		numaNode, err := strconv.Atoi(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid NUMA node in %q: %w", line, err)
+		}
+		numaCPUs[numaNode], err = cpuset.Parse(cpus)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cpulist of NUMA node %d in %q: %w", numaNode, line, err)
+		}
+	}
+	return numaCPUs, nil
+}
+
+// VerifyNUMAAlignment returns an error if the exclusive CPUs and the devices of the resource assigned to the
+// container are not on the same single NUMA node. The devices without topology are not checked, as the Topology
+// Manager doesn't align them either.
+func VerifyNUMAAlignment(container *ContainerResources, resourceName v1.ResourceName, numaCPUs map[int]cpuset.CPUSet) error {
+	if len(container.CPUIDs) == 0 {
+		return fmt.Errorf("no exclusive CPU is assigned to container %s", container.Name)
+	}
+	var ids []int
+	for _, id := range container.CPUIDs {
+		ids = append(ids, int(id))
+	}
+	cpus := cpuset.New(ids...)
+
+	numaNodes := map[int]bool{}
+	for numaNode, numaNodeCPUs := range numaCPUs {
+		if cpus.Intersection(numaNodeCPUs).Size() > 0 {
+			numaNodes[numaNode] = true
+		}
+	}
+	if len(numaNodes) != 1 {
+		return fmt.Errorf("CPUs %s of container %s span NUMA nodes %v", cpus, container.Name, sortedKeys(numaNodes))
+	}
+	var cpuNUMANode int
+	for numaNode := range numaNodes {
+		cpuNUMANode = numaNode
+	}
+
+	for _, devices := range container.Devices {
+		if devices.ResourceName != string(resourceName) || devices.Topology == nil {
+			continue
+		}
+		for _, node := range devices.Topology.Nodes {
+			if int(node.ID) != cpuNUMANode {
+				return fmt.Errorf("devices %v of container %s are on NUMA node %d, but its CPUs %s are on NUMA node %d",
+					devices.DeviceIDs, container.Name, node.ID, cpus, cpuNUMANode)
+			}
+		}
+	}
+	return nil
+}
+
+// HugePagesResource returns the hugepages resource of the smallest page size of which at least a page is
+// allocatable on the node, with its page size. An empty name is returned if there is none.
+func HugePagesResource(node *v1.Node) (v1.ResourceName, resource.Quantity) {
+	var selected v1.ResourceName
+	var selectedPageSize resource.Quantity
+	for resourceName, allocatable := range node.Status.Allocatable {
+		size, ok := strings.CutPrefix(string(resourceName), v1.ResourceHugePagesPrefix)
+		if !ok {
+			continue
+		}
+		pageSize, err := resource.ParseQuantity(size)
+		if err != nil || allocatable.Cmp(pageSize) < 0 {
+			continue
+		}
+		if selected == "" || pageSize.Cmp(selectedPageSize) < 0 {
+			selected, selectedPageSize = resourceName, pageSize
+		}
+	}
+	return selected, selectedPageSize
+}
+
+// VerifyHugePagesNUMAAlignment returns an error if the hugepages assigned to the container by the static Memory
+// Manager policy are not on a single NUMA node, or the devices of the resource assigned to the container are on
+// another NUMA node. The devices without topology are not checked.
+func VerifyHugePagesNUMAAlignment(container *ContainerResources, resourceName, hugePages v1.ResourceName) error {
+	numaNodes := map[int]bool{}
+	for _, memory := range container.Memory {
+		if memory.MemoryType != string(hugePages) || memory.Topology == nil {
+			continue
+		}
+		for _, node := range memory.Topology.Nodes {
+			numaNodes[int(node.ID)] = true
+		}
+	}
+	if len(numaNodes) != 1 {
+		return fmt.Errorf("%s of container %s are on NUMA nodes %v, want a single NUMA node", hugePages, container.Name, sortedKeys(numaNodes))
+	}
+	for _, devices := range container.Devices {
+		if devices.ResourceName != string(resourceName) || devices.Topology == nil {
+			continue
+		}
+		for _, node := range devices.Topology.Nodes {
+			if !numaNodes[int(node.ID)] {
+				return fmt.Errorf("devices %v of container %s are on NUMA node %d, but its %s are on NUMA node %d",
+					devices.DeviceIDs, container.Name, node.ID, hugePages, sortedKeys(numaNodes)[0])
+			}
+		}
+	}
+	return nil
+}
+
+func sortedKeys(m map[int]bool) []int {
+	var keys []int
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+	return keys
+}
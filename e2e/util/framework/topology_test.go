@@ -0,0 +1,245 @@
+package framework
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/cpuset"
+)
+
+func TestDecodeKubeletConfigz(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    *KubeletConfig
+		wantErr bool
+	}{
+		{
+			name: "topology manager",
+			data: `{"kubeletconfig":{"cpuManagerPolicy":"static","topologyManagerPolicy":"single-numa-node","topologyManagerScope":"container","maxPods":110}}`,
+			want: &KubeletConfig{CPUManagerPolicy: "static", TopologyManagerPolicy: "single-numa-node", TopologyManagerScope: "container"},
+		},
+		{
+			name: "memory manager",
+			data: `{"kubeletconfig":{"cpuManagerPolicy":"static","memoryManagerPolicy":"Static","topologyManagerPolicy":"single-numa-node"}}`,
+			want: &KubeletConfig{CPUManagerPolicy: "static", TopologyManagerPolicy: "single-numa-node", MemoryManagerPolicy: "Static"},
+		},
+		{
+			name: "defaults",
+			data: `{"kubeletconfig":{"cpuManagerPolicy":"none","topologyManagerPolicy":"none"}}`,
+			want: &KubeletConfig{CPUManagerPolicy: "none", TopologyManagerPolicy: "none"},
+		},
+		{
+			name:    "no kubeletconfig",
+			data:    `{"componentconfig":{}}`,
+			wantErr: true,
+		},
+		{
+			name:    "not json",
+			data:    `404 page not found`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeKubeletConfigz([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeKubeletConfigz() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeKubeletConfigz() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNUMANodeCPUs(t *testing.T) {
+	got, err := parseNUMANodeCPUs("0 0-3,8-11\n1 4-7,12-15\n")
+	if err != nil {
+		t.Fatalf("parseNUMANodeCPUs() unexpected error: %v", err)
+	}
+	want := map[int]cpuset.CPUSet{
+		0: cpuset.New(0, 1, 2, 3, 8, 9, 10, 11),
+		1: cpuset.New(4, 5, 6, 7, 12, 13, 14, 15),
+	}
+	if len(got) != len(want) {
+		t.Fatalf("parseNUMANodeCPUs() = %v, want %v", got, want)
+	}
+	for numaNode, cpus := range want {
+		if !got[numaNode].Equals(cpus) {
+			t.Errorf("parseNUMANodeCPUs()[%d] = %s, want %s", numaNode, got[numaNode], cpus)
+		}
+	}
+
+	for _, output := range []string{"node0 0-3", "0 0-a"} {
+		if _, err := parseNUMANodeCPUs(output); err == nil {
+			t.Errorf("parseNUMANodeCPUs(%q) expected an error", output)
+		}
+	}
+}
+
+func TestVerifyNUMAAlignment(t *testing.T) {
+	numaCPUs := map[int]cpuset.CPUSet{
+		0: cpuset.New(0, 1, 2, 3),
+		1: cpuset.New(4, 5, 6, 7),
+	}
+	gpu := func(numaNodes ...protoInt64) ContainerDevices {
+		devices := ContainerDevices{ResourceName: "nvidia.com/gpu", DeviceIDs: []string{"GPU-8f2c"}}
+		if len(numaNodes) > 0 {
+			devices.Topology = &TopologyInfo{}
+			for _, id := range numaNodes {
+				devices.Topology.Nodes = append(devices.Topology.Nodes, NUMANode{ID: id})
+			}
+		}
+		return devices
+	}
+
+	tests := []struct {
+		name        string
+		container   ContainerResources
+		wantErrText string
+	}{
+		{
+			name:      "aligned",
+			container: ContainerResources{Name: "main", CPUIDs: []protoInt64{4, 5}, Devices: []ContainerDevices{gpu(1)}},
+		},
+		{
+			name:      "device without topology",
+			container: ContainerResources{Name: "main", CPUIDs: []protoInt64{0}, Devices: []ContainerDevices{gpu()}},
+		},
+		{
+			name:        "device on another NUMA node",
+			container:   ContainerResources{Name: "main", CPUIDs: []protoInt64{0, 1}, Devices: []ContainerDevices{gpu(1)}},
+			wantErrText: "are on NUMA node 1, but its CPUs 0-1 are on NUMA node 0",
+		},
+		{
+			name:        "CPUs span NUMA nodes",
+			container:   ContainerResources{Name: "main", CPUIDs: []protoInt64{3, 4}, Devices: []ContainerDevices{gpu(0)}},
+			wantErrText: "span NUMA nodes [0 1]",
+		},
+		{
+			name:        "no exclusive CPU",
+			container:   ContainerResources{Name: "main", Devices: []ContainerDevices{gpu(0)}},
+			wantErrText: "no exclusive CPU",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyNUMAAlignment(&tt.container, "nvidia.com/gpu", numaCPUs)
+			if tt.wantErrText == "" {
+				if err != nil {
+					t.Errorf("VerifyNUMAAlignment() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErrText) {
+				t.Errorf("VerifyNUMAAlignment() error = %v, want error containing %q", err, tt.wantErrText)
+			}
+		})
+	}
+}
+
+func TestHugePagesResource(t *testing.T) {
+	tests := []struct {
+		name         string
+		allocatable  v1.ResourceList
+		want         v1.ResourceName
+		wantPageSize string
+	}{
+		{
+			name:        "no hugepages",
+			allocatable: v1.ResourceList{v1.ResourceMemory: resource.MustParse("64Gi")},
+		},
+		{
+			name:        "no allocatable page",
+			allocatable: v1.ResourceList{"hugepages-2Mi": resource.MustParse("0"), "hugepages-1Gi": resource.MustParse("512Mi")},
+		},
+		{
+			name:         "smallest page size",
+			allocatable:  v1.ResourceList{"hugepages-2Mi": resource.MustParse("1Gi"), "hugepages-1Gi": resource.MustParse("4Gi")},
+			want:         "hugepages-2Mi",
+			wantPageSize: "2Mi",
+		},
+		{
+			name:         "only allocatable page size",
+			allocatable:  v1.ResourceList{"hugepages-2Mi": resource.MustParse("0"), "hugepages-1Gi": resource.MustParse("4Gi")},
+			want:         "hugepages-1Gi",
+			wantPageSize: "1Gi",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &v1.Node{Status: v1.NodeStatus{Allocatable: tt.allocatable}}
+			got, pageSize := HugePagesResource(node)
+			if got != tt.want {
+				t.Errorf("HugePagesResource() = %s, want %s", got, tt.want)
+			}
+			if tt.want != "" && pageSize.String() != tt.wantPageSize {
+				t.Errorf("HugePagesResource() page size = %s, want %s", pageSize.String(), tt.wantPageSize)
+			}
+		})
+	}
+}
+
+func TestVerifyHugePagesNUMAAlignment(t *testing.T) {
+	topology := func(numaNodes ...protoInt64) *TopologyInfo {
+		info := &TopologyInfo{}
+		for _, id := range numaNodes {
+			info.Nodes = append(info.Nodes, NUMANode{ID: id})
+		}
+		return info
+	}
+	gpu := ContainerDevices{ResourceName: "nvidia.com/gpu", DeviceIDs: []string{"GPU-8f2c"}, Topology: topology(1)}
+	memory := ContainerMemory{MemoryType: "memory", Size: 128 << 20, Topology: topology(0)}
+
+	tests := []struct {
+		name        string
+		container   ContainerResources
+		wantErrText string
+	}{
+		{
+			name: "aligned",
+			container: ContainerResources{Name: "main", Devices: []ContainerDevices{gpu},
+				Memory: []ContainerMemory{memory, {MemoryType: "hugepages-2Mi", Size: 2 << 20, Topology: topology(1)}}},
+		},
+		{
+			name: "device without topology",
+			container: ContainerResources{Name: "main", Devices: []ContainerDevices{{ResourceName: "nvidia.com/gpu", DeviceIDs: []string{"GPU-8f2c"}}},
+				Memory: []ContainerMemory{{MemoryType: "hugepages-2Mi", Size: 2 << 20, Topology: topology(0)}}},
+		},
+		{
+			name: "hugepages on another NUMA node",
+			container: ContainerResources{Name: "main", Devices: []ContainerDevices{gpu},
+				Memory: []ContainerMemory{{MemoryType: "hugepages-2Mi", Size: 2 << 20, Topology: topology(0)}}},
+			wantErrText: "are on NUMA node 1, but its hugepages-2Mi are on NUMA node 0",
+		},
+		{
+			name: "hugepages span NUMA nodes",
+			container: ContainerResources{Name: "main", Devices: []ContainerDevices{gpu},
+				Memory: []ContainerMemory{{MemoryType: "hugepages-2Mi", Size: 4 << 20, Topology: topology(0, 1)}}},
+			wantErrText: "are on NUMA nodes [0 1]",
+		},
+		{
+			name:        "no hugepages",
+			container:   ContainerResources{Name: "main", Devices: []ContainerDevices{gpu}, Memory: []ContainerMemory{memory}},
+			wantErrText: "are on NUMA nodes []",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyHugePagesNUMAAlignment(&tt.container, "nvidia.com/gpu", "hugepages-2Mi")
+			if tt.wantErrText == "" {
+				if err != nil {
+					t.Errorf("VerifyHugePagesNUMAAlignment() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErrText) {
+				t.Errorf("VerifyHugePagesNUMAAlignment() error = %v, want error containing %q", err, tt.wantErrText)
+			}
+		})
+	}
+}
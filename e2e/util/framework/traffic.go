@@ -0,0 +1,188 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
+
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2ejob "k8s.io/kubernetes/test/e2e/framework/job"
+	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
+	imageutils "k8s.io/kubernetes/test/utils/image"
+)
+
+const (
+	// responsePrefix marks the log lines written by the request generator for the response of each request.
+	responsePrefix = "response:"
+	// durationPrefix marks the log lines written by the request generator for the duration of each request.
+	durationPrefix = "duration:"
+)
+
+// HTTPRequestGenerator describes the HTTP requests issued from inside the cluster.
+type HTTPRequestGenerator struct {
+	// Name is the name of the Job which issues the requests.
+	Name string
+	// URL is the target of the requests, e.g. the address of a Gateway.
+	URL string
+	// Method is the HTTP method of the requests. Defaults to GET.
+	Method string
+	// Requests is the number of requests to issue. Defaults to 1.
+	Requests int
+	// Interval is the pause between the requests, e.g. to sustain a rate of requests. Defaults to no pause.
+	Interval time.Duration
+	// Headers are added to every request.
+	Headers map[string]string
+	// Timeout is the maximum time allowed for each request. Defaults to 10 seconds.
+	Timeout time.Duration
+}
+
+// HTTPRequestResult aggregates the responses observed by the request generator.
+type HTTPRequestResult struct {
+	// Backends counts the successful (2xx) responses by their body, which is expected to identify
+	// the backend serving the request, e.g. the hostname returned by agnhost netexec.
+	Backends map[string]int
+	// StatusCodes counts the responses by their HTTP status code. 0 means that the request didn't complete.
+	StatusCodes map[int]int
+	// Durations are the total time taken by the requests, in the order they were issued.
+	Durations []time.Duration
+}
+
+// RunHTTPRequestGenerator runs a short-lived Job inside the cluster which issues the HTTP requests described
+// by the generator, waits for it to complete and aggregates the responses from the logs of its pod. It doesn't
+// assume that the test runner can reach the target of the requests.
+func RunHTTPRequestGenerator(ctx context.Context, client clientset.Interface, namespace string, g HTTPRequestGenerator) (*HTTPRequestResult, error) {
+	requests := g.Requests
+	if requests <= 0 {
+		requests = 1
+	}
+	timeout := g.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	// The URL and the headers are passed to the script as positional parameters instead of being interpolated
+	// into it, so any value is passed to curl verbatim.
+	args := []string{g.URL}
+	if g.Method != "" {
+		args = append(args, "-X", g.Method)
+	}
+	headerNames := make([]string, 0, len(g.Headers))
+	for name := range g.Headers {
+		headerNames = append(headerNames, name)
+	}
+	sort.Strings(headerNames)
+	for _, name := range headerNames {
+		args = append(args, "-H", fmt.Sprintf("%s: %s", name, g.Headers[name]))
+	}
+	// curl treats -m 0 as no timeout, so sub-second timeouts are rounded up.
+	timeoutSeconds := int(math.Ceil(timeout.Seconds()))
+	script := fmt.Sprintf(`url="$1"; shift; for i in $(seq 1 %d); do rm -f /tmp/body; out=$(curl -s -o /tmp/body -w '%%{http_code} %%{time_total}' -m %d "$@" "$url"); `+
+		`code=${out%%%% *}; echo "%s ${code:-000} $(cat /tmp/body 2>/dev/null)"; echo "%s ${out#* }"; sleep %g; done`,
+		requests, timeoutSeconds, responsePrefix, durationPrefix, g.Interval.Seconds())
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: g.Name},
+		Spec: batchv1.JobSpec{
+			Parallelism:  ptr.To[int32](1),
+			Completions:  ptr.To[int32](1),
+			BackoffLimit: ptr.To[int32](0),
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					RestartPolicy: v1.RestartPolicyNever,
+					Containers: []v1.Container{
+						{
+							Name:    "generator",
+							Image:   imageutils.GetE2EImage(imageutils.Agnhost),
+							Command: []string{"/bin/sh", "-c", script, "generator"},
+							Args:    args,
+						},
+					},
+				},
+			},
+		},
+	}
+	job, err := e2ejob.CreateJob(ctx, client, namespace, job)
+	if err != nil {
+		return nil, fmt.Errorf("error when creating request generator job %s: %w", g.Name, err)
+	}
+	DeferCleanup(client.BatchV1().Jobs(namespace).Delete, job.Name, metav1.DeleteOptions{PropagationPolicy: ptr.To(metav1.DeletePropagationBackground)})
+
+	// The job doesn't retry, so it's finished as soon as its pod fails.
+	if err := e2ejob.WaitForJobFinish(ctx, client, namespace, job.Name); err != nil {
+		return nil, fmt.Errorf("error when waiting for request generator job %s to finish: %w", job.Name, err)
+	}
+	job, err = e2ejob.GetJob(ctx, client, namespace, job.Name)
+	if err != nil {
+		return nil, fmt.Errorf("error when getting request generator job %s: %w", job.Name, err)
+	}
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == batchv1.JobFailed && cond.Status == v1.ConditionTrue {
+			return nil, fmt.Errorf("request generator job %s failed: %s: %s", job.Name, cond.Reason, cond.Message)
+		}
+	}
+	pods, err := e2ejob.GetJobPods(ctx, client, namespace, job.Name)
+	if err != nil {
+		return nil, fmt.Errorf("error when listing pods of request generator job %s: %w", job.Name, err)
+	}
+
+	result := &HTTPRequestResult{Backends: map[string]int{}, StatusCodes: map[int]int{}}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != v1.PodSucceeded {
+			continue
+		}
+		logs, err := e2epod.GetPodLogs(ctx, client, namespace, pod.Name, "generator")
+		if err != nil {
+			return nil, fmt.Errorf("error when getting logs of pod %s: %w", pod.Name, err)
+		}
+		if err := result.addResponses(logs); err != nil {
+			return nil, fmt.Errorf("error when parsing logs of pod %s: %w", pod.Name, err)
+		}
+	}
+	framework.Logf("Request generator %s observed status codes %v and backends %v", job.Name, result.StatusCodes, result.Backends)
+	return result, nil
+}
+
+// addResponses aggregates the response lines written by the request generator, i.e.
+// "response: <status code> <body>" and "duration: <seconds>", and ignores other lines.
+func (r *HTTPRequestResult) addResponses(logs string) error {
+	for _, line := range strings.Split(logs, "\n") {
+		if strings.HasPrefix(line, durationPrefix) {
+			value := strings.TrimSpace(strings.TrimPrefix(line, durationPrefix))
+			if value == "" {
+				continue
+			}
+			seconds, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("unexpected duration line %q: %w", line, err)
+			}
+			r.Durations = append(r.Durations, time.Duration(seconds*float64(time.Second)))
+			continue
+		}
+		if !strings.HasPrefix(line, responsePrefix) {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(line, responsePrefix))
+		if len(fields) == 0 {
+			continue
+		}
+		code, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return fmt.Errorf("unexpected response line %q: %w", line, err)
+		}
+		r.StatusCodes[code]++
+		if code >= 200 && code < 300 && len(fields) > 1 {
+			r.Backends[strings.Join(fields[1:], " ")]++
+		}
+	}
+	return nil
+}
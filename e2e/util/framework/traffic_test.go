@@ -0,0 +1,98 @@
+package framework
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHTTPRequestResultAddResponses(t *testing.T) {
+	tests := []struct {
+		name            string
+		logs            string
+		wantStatusCodes map[int]int
+		wantBackends    map[string]int
+		wantDurations   []time.Duration
+		wantErr         bool
+	}{
+		{
+			name:            "empty logs",
+			logs:            "",
+			wantStatusCodes: map[int]int{},
+			wantBackends:    map[string]int{},
+		},
+		{
+			name: "successful responses are counted by backend",
+			logs: "response: 200 backend-a\n" +
+				"response: 200 backend-b\n" +
+				"response: 200 backend-a\n",
+			wantStatusCodes: map[int]int{200: 3},
+			wantBackends:    map[string]int{"backend-a": 2, "backend-b": 1},
+		},
+		{
+			name: "failed and incomplete requests are only counted by status code",
+			logs: "response: 503 upstream connect error\n" +
+				"response: 000 \n" +
+				"response: 204\n",
+			wantStatusCodes: map[int]int{503: 1, 0: 1, 204: 1},
+			wantBackends:    map[string]int{},
+		},
+		{
+			name:            "body with spaces is kept",
+			logs:            "response: 200 hello from  pod-1\n",
+			wantStatusCodes: map[int]int{200: 1},
+			wantBackends:    map[string]int{"hello from pod-1": 1},
+		},
+		{
+			name: "other lines are ignored",
+			logs: "curl: (6) Could not resolve host\n" +
+				"response:\n" +
+				"  response: 200 indented\n" +
+				"response: 200 backend-a\n",
+			wantStatusCodes: map[int]int{200: 1},
+			wantBackends:    map[string]int{"backend-a": 1},
+		},
+		{
+			name: "durations are kept in order",
+			logs: "response: 504 upstream request timeout\n" +
+				"duration: 2.003512\n" +
+				"response: 200 backend-a\n" +
+				"duration: 0.0125\n" +
+				"duration: \n",
+			wantStatusCodes: map[int]int{504: 1, 200: 1},
+			wantBackends:    map[string]int{"backend-a": 1},
+			wantDurations:   []time.Duration{2003512 * time.Microsecond, 12500 * time.Microsecond},
+		},
+		{
+			name:    "malformed duration",
+			logs:    "duration: abc\n",
+			wantErr: true,
+		},
+		{
+			name:    "malformed status code",
+			logs:    "response: abc backend-a\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &HTTPRequestResult{Backends: map[string]int{}, StatusCodes: map[int]int{}}
+			err := result.addResponses(tt.logs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("addResponses() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(result.StatusCodes, tt.wantStatusCodes) {
+				t.Errorf("StatusCodes = %v, want %v", result.StatusCodes, tt.wantStatusCodes)
+			}
+			if !reflect.DeepEqual(result.Backends, tt.wantBackends) {
+				t.Errorf("Backends = %v, want %v", result.Backends, tt.wantBackends)
+			}
+			if !reflect.DeepEqual(result.Durations, tt.wantDurations) {
+				t.Errorf("Durations = %v, want %v", result.Durations, tt.wantDurations)
+			}
+		})
+	}
+}
@@ -2,24 +2,37 @@ package framework
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/onsi/ginkgo/v2"
-	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
 	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/kubernetes/test/e2e/framework"
 )
 
 // DeleteNamespaces deletes all namespaces that match the given delete and skip filters.
 // Filter is by simple strings.Contains; first skip filter, then delete filter.
-// Returns the list of deleted namespaces or an error.
+// The namespaces are deleted concurrently and the transient errors, such as conflicts, are retried. Returns the list
+// of deleted namespaces, and an aggregated error of the namespaces which failed to be deleted if any.
 func DeleteNamespaces(ctx context.Context, c clientset.Interface, deleteFilter, deleteLabelFilter, skipFilter []string) ([]string, error) {
 	ginkgo.By("Deleting namespaces")
 	nsList, err := c.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
-	framework.ExpectNoError(err, "Failed to get namespace list")
+	if err != nil {
+		return nil, fmt.Errorf("error when listing namespaces: %w", err)
+	}
 	var deleted []string
+	var errs []error
+	var mu sync.Mutex
 	var wg sync.WaitGroup
 OUTER:
 	for _, item := range nsList.Items {
@@ -53,14 +66,63 @@ OUTER:
 			}
 		}
 		wg.Add(1)
-		deleted = append(deleted, item.Name)
 		go func(nsName string) {
 			defer wg.Done()
-			defer ginkgo.GinkgoRecover()
-			gomega.Expect(c.CoreV1().Namespaces().Delete(ctx, nsName, metav1.DeleteOptions{})).To(gomega.Succeed())
+			err := retry.OnError(retry.DefaultRetry, isTransientError, func() error {
+				return c.CoreV1().Namespaces().Delete(ctx, nsName, metav1.DeleteOptions{})
+			})
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil && !apierrors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("error when deleting namespace %s: %w", nsName, err))
+				return
+			}
+			deleted = append(deleted, nsName)
 			framework.Logf("namespace : %v api call to delete is complete ", nsName)
 		}(item.Name)
 	}
 	wg.Wait()
-	return deleted, nil
+	sort.Strings(deleted)
+	return deleted, utilerrors.NewAggregate(errs)
+}
+
+// isTransientError returns true if the request may succeed when it's retried.
+func isTransientError(err error) bool {
+	return apierrors.IsConflict(err) || apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err) || apierrors.IsTooManyRequests(err)
+}
+
+// WaitForNamespacesDeleted waits until the namespaces are deleted, i.e. their finalizers, such as the ones of the
+// resources holding the accelerators, are done. The namespaces which still exist after the timeout are returned
+// with the error.
+func WaitForNamespacesDeleted(ctx context.Context, c clientset.Interface, names []string, timeout time.Duration) ([]string, error) {
+	ginkgo.By(fmt.Sprintf("Waiting for namespaces %v to vanish", names))
+	remaining := names
+	err := wait.PollUntilContextTimeout(ctx, framework.Poll, timeout, true, func(ctx context.Context) (bool, error) {
+		nsList, err := c.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			framework.Logf("error when listing namespaces: %v", err)
+			return false, nil
+		}
+		remaining = remainingNamespaces(names, nsList.Items)
+		return len(remaining) == 0, nil
+	})
+	if err != nil {
+		return remaining, fmt.Errorf("namespaces %v are not deleted within %v: %w", remaining, timeout, err)
+	}
+	return nil, nil
+}
+
+// remainingNamespaces returns the given names of the namespaces which exist.
+func remainingNamespaces(names []string, namespaces []v1.Namespace) []string {
+	existing := sets.New[string]()
+	for _, ns := range namespaces {
+		existing.Insert(ns.Name)
+	}
+	var remaining []string
+	for _, name := range names {
+		if existing.Has(name) {
+			remaining = append(remaining, name)
+		}
+	}
+	return remaining
 }
@@ -0,0 +1,45 @@
+package framework
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRemainingNamespaces(t *testing.T) {
+	newNamespace := func(name string) v1.Namespace {
+		return v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+
+	tests := []struct {
+		name       string
+		names      []string
+		namespaces []v1.Namespace
+		want       []string
+	}{
+		{
+			name:       "all deleted",
+			names:      []string{"e2e-a", "e2e-b"},
+			namespaces: []v1.Namespace{newNamespace("default"), newNamespace("kube-system")},
+		},
+		{
+			name:       "some terminating",
+			names:      []string{"e2e-a", "e2e-b", "e2e-c"},
+			namespaces: []v1.Namespace{newNamespace("default"), newNamespace("e2e-c"), newNamespace("e2e-a")},
+			want:       []string{"e2e-a", "e2e-c"},
+		},
+		{
+			name:       "nothing to wait for",
+			namespaces: []v1.Namespace{newNamespace("default")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := remainingNamespaces(tt.names, tt.namespaces); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("remainingNamespaces() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
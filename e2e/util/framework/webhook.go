@@ -0,0 +1,133 @@
+package framework
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
+
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2econfig "k8s.io/kubernetes/test/e2e/framework/config"
+	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
+)
+
+var webhookTLS struct {
+	Image string `default:"docker.io/curlimages/curl:8.11.1" usage:"image with curl and a shell which probes the TLS of the webhook services from within the cluster if the test runner can't reach them"`
+}
+var _ = e2econfig.AddOptions(&webhookTLS, "ai.webhookTLS")
+
+// webhookDialTimeout is the timeout of connecting to a webhook service and of its TLS handshake.
+const webhookDialTimeout = 10 * time.Second
+
+// errWebhookUnreachable is returned if the webhook service can't be connected to, e.g. as the test runner is outside
+// the cluster.
+var errWebhookUnreachable = errors.New("webhook service is unreachable")
+
+// WebhookServerName returns the name which the serving certificate of the webhook service must be valid for, i.e.
+// <name>.<namespace>.svc, as the API server verifies it.
+func WebhookServerName(svc *admissionregistrationv1.ServiceReference) string {
+	return fmt.Sprintf("%s.%s.svc", svc.Name, svc.Namespace)
+}
+
+// webhookURL returns the URL which the API server calls the webhook service at. The port defaults to 443 and the
+// path to /.
+func webhookURL(svc *admissionregistrationv1.ServiceReference) string {
+	port := ptr.Deref(svc.Port, 443)
+	path := ptr.Deref(svc.Path, "/")
+	return "https://" + net.JoinHostPort(WebhookServerName(svc), strconv.Itoa(int(port))) + path
+}
+
+// dialWebhookTLS connects to the address and performs a TLS handshake, verifying the certificate of the server
+// against the CA bundle for the server name. An error wrapping errWebhookUnreachable is returned if the address can't
+// be connected to.
+func dialWebhookTLS(ctx context.Context, address, serverName string, caBundle []byte) error {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caBundle) {
+		return fmt.Errorf("no certificate is found in the CA bundle of webhook service %s", serverName)
+	}
+	dialer := &net.Dialer{Timeout: webhookDialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errWebhookUnreachable, err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, webhookDialTimeout)
+	defer cancel()
+	tlsConn := tls.Client(conn, &tls.Config{ServerName: serverName, RootCAs: pool})
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return fmt.Errorf("error when performing the TLS handshake with webhook service %s at %s: %w", serverName, address, err)
+	}
+	return nil
+}
+
+// VerifyWebhookTLS verifies that the service of the webhook serves TLS with a certificate which is valid for the
+// service and signed by the CA bundle of the webhook, as the API server requires to call it. The service is connected
+// to from the test runner, or from a pod created in the namespace with -ai.webhookTLS.image if the test runner can't
+// reach it.
+func VerifyWebhookTLS(ctx context.Context, client clientset.Interface, namespace string, config admissionregistrationv1.WebhookClientConfig) error {
+	if config.Service == nil {
+		return fmt.Errorf("webhook is called at URL %s instead of a service", ptr.Deref(config.URL, ""))
+	}
+	serverName := WebhookServerName(config.Service)
+	if len(config.CABundle) == 0 {
+		return fmt.Errorf("CA bundle of webhook service %s is empty", serverName)
+	}
+	address := net.JoinHostPort(serverName, strconv.Itoa(int(ptr.Deref(config.Service.Port, 443))))
+	err := dialWebhookTLS(ctx, address, serverName, config.CABundle)
+	if !errors.Is(err, errWebhookUnreachable) {
+		return err
+	}
+	framework.Logf("Webhook service %s is unreachable from the test runner, probing it from a pod: %v", serverName, err)
+	return probeWebhookTLS(ctx, client, namespace, config)
+}
+
+// probeWebhookTLS requests the webhook service by curl from a pod created in the namespace, which fails unless the
+// TLS handshake with the service succeeds against the CA bundle of the webhook. Any HTTP response is accepted.
+func probeWebhookTLS(ctx context.Context, client clientset.Interface, namespace string, config admissionregistrationv1.WebhookClientConfig) error {
+	url := webhookURL(config.Service)
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: "webhook-tls-"},
+		Spec: v1.PodSpec{
+			RestartPolicy: v1.RestartPolicyNever,
+			Containers: []v1.Container{
+				{
+					Name:    "probe",
+					Image:   webhookTLS.Image,
+					Command: []string{"/bin/sh", "-c"},
+					Args: []string{fmt.Sprintf(`printf '%%s' "$CA_BUNDLE" > /tmp/ca.crt && `+
+						`curl -sS -o /dev/null --max-time %d --cacert /tmp/ca.crt "$WEBHOOK_URL"`, int(webhookDialTimeout.Seconds()))},
+					Env: []v1.EnvVar{
+						{Name: "CA_BUNDLE", Value: string(config.CABundle)},
+						{Name: "WEBHOOK_URL", Value: url},
+					},
+				},
+			},
+		},
+	}
+	pod, err := client.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("error when creating pod to probe webhook service %s: %w", url, err)
+	}
+	DeferCleanup(client.CoreV1().Pods(namespace).Delete, pod.Name, metav1.DeleteOptions{})
+
+	err = e2epod.WaitForPodSuccessInNamespace(ctx, client, pod.Name, namespace)
+	if err != nil {
+		output, logErr := e2epod.GetPodLogs(ctx, client, namespace, pod.Name, pod.Spec.Containers[0].Name)
+		if logErr != nil {
+			output = logErr.Error()
+		}
+		return fmt.Errorf("error when probing the TLS of webhook service %s: %w, output: %s", url, err, output)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package framework
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestWebhookURL(t *testing.T) {
+	tests := []struct {
+		name string
+		svc  *admissionregistrationv1.ServiceReference
+		want string
+	}{
+		{
+			name: "defaults",
+			svc:  &admissionregistrationv1.ServiceReference{Namespace: "operator-system", Name: "webhook"},
+			want: "https://webhook.operator-system.svc:443/",
+		},
+		{
+			name: "port and path",
+			svc:  &admissionregistrationv1.ServiceReference{Namespace: "operator-system", Name: "webhook", Port: ptr.To[int32](9443), Path: ptr.To("/validate")},
+			want: "https://webhook.operator-system.svc:9443/validate",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := webhookURL(tt.svc); got != tt.want {
+				t.Errorf("webhookURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDialWebhookTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	plain := httptest.NewServer(http.NotFoundHandler())
+	defer plain.Close()
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "other-ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	otherCA, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := listener.Addr().String()
+	listener.Close()
+
+	tests := []struct {
+		name            string
+		address         string
+		serverName      string
+		caBundle        []byte
+		wantErr         bool
+		wantUnreachable bool
+	}{
+		{
+			name:       "valid",
+			address:    server.Listener.Addr().String(),
+			serverName: "example.com",
+			caBundle:   caBundle,
+		},
+		{
+			name:       "certificate not valid for the service",
+			address:    server.Listener.Addr().String(),
+			serverName: "webhook.operator-system.svc",
+			caBundle:   caBundle,
+			wantErr:    true,
+		},
+		{
+			name:       "certificate not signed by the CA bundle",
+			address:    server.Listener.Addr().String(),
+			serverName: "example.com",
+			caBundle:   pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: otherCA}),
+			wantErr:    true,
+		},
+		{
+			name:       "invalid CA bundle",
+			address:    server.Listener.Addr().String(),
+			serverName: "example.com",
+			caBundle:   []byte("invalid"),
+			wantErr:    true,
+		},
+		{
+			name:       "plain HTTP",
+			address:    plain.Listener.Addr().String(),
+			serverName: "example.com",
+			caBundle:   caBundle,
+			wantErr:    true,
+		},
+		{
+			name:            "unreachable",
+			address:         closed,
+			serverName:      "example.com",
+			caBundle:        caBundle,
+			wantErr:         true,
+			wantUnreachable: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := dialWebhookTLS(context.Background(), tt.address, tt.serverName, tt.caBundle)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("dialWebhookTLS() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, errWebhookUnreachable) != tt.wantUnreachable {
+				t.Errorf("dialWebhookTLS() error = %v, wantUnreachable %v", err, tt.wantUnreachable)
+			}
+		})
+	}
+}
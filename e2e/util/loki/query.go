@@ -0,0 +1,100 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	clientset "k8s.io/client-go/kubernetes"
+
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2eservice "k8s.io/kubernetes/test/e2e/framework/service"
+)
+
+// QueryResponse is the response of the Loki query API.
+// See https://grafana.com/docs/loki/latest/reference/loki-http-api/#query-logs-within-a-range-of-time
+type QueryResponse struct {
+	Status string    `json:"status"`
+	Data   QueryData `json:"data"`
+}
+
+// QueryData is the data of a successful query.
+type QueryData struct {
+	ResultType string   `json:"resultType"`
+	Result     []Stream `json:"result"`
+}
+
+// Stream is the log lines of a stream.
+type Stream struct {
+	// Labels are the labels of the stream.
+	Labels map[string]string `json:"stream"`
+	// Values are the [<unix_nano_time>, "<log_line>"] entries of the stream.
+	Values [][2]string `json:"values"`
+}
+
+// Lines returns the log lines of all the streams in the result.
+func (r *QueryResponse) Lines() []string {
+	var lines []string
+	for _, stream := range r.Data.Result {
+		for _, value := range stream.Values {
+			lines = append(lines, value[1])
+		}
+	}
+	return lines
+}
+
+// StructuredLines returns the lines which are JSON objects whose given field is the given value.
+func StructuredLines(lines []string, field, value string) []string {
+	var matched []string
+	for _, line := range lines {
+		var fields map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &fields); err != nil {
+			continue
+		}
+		if fields[field] == value {
+			matched = append(matched, line)
+		}
+	}
+	return matched
+}
+
+// QueryRange runs a LogQL query for the log lines between start and end against the given Loki Service on the
+// given port via the service proxy of the API server. At most limit lines are returned, the most recent first.
+func QueryRange(ctx context.Context, client clientset.Interface, svc *v1.Service, port, query string, start, end time.Time, limit int) (*QueryResponse, error) {
+	proxyRequest, err := e2eservice.GetServicesProxyRequest(client, client.CoreV1().RESTClient().Get())
+	if err != nil {
+		return nil, err
+	}
+	req := proxyRequest.Namespace(svc.Namespace).
+		Name(fmt.Sprintf("%s:%s", svc.Name, port)).
+		Suffix("/loki/api/v1/query_range").
+		Param("query", query).
+		Param("start", strconv.FormatInt(start.UnixNano(), 10)).
+		Param("end", strconv.FormatInt(end.UnixNano(), 10)).
+		Param("limit", strconv.Itoa(limit)).
+		Param("direction", "backward")
+	framework.Logf("Query URL: %v", *req.URL())
+	data, err := req.DoRaw(ctx)
+	if err != nil {
+		return nil, err
+	}
+	framework.Logf("Query result: %s", string(data))
+	return decodeQueryResponse(data)
+}
+
+func decodeQueryResponse(data []byte) (*QueryResponse, error) {
+	resp := &QueryResponse{}
+	if err := json.Unmarshal(data, resp); err != nil {
+		return nil, fmt.Errorf("error when decoding query response %s: %w", string(data), err)
+	}
+	if resp.Status != "success" {
+		return nil, fmt.Errorf("query failed with status %q", resp.Status)
+	}
+	if resp.Data.ResultType != "streams" {
+		return nil, fmt.Errorf("unexpected result type %q of the log query", resp.Data.ResultType)
+	}
+	return resp, nil
+}
@@ -0,0 +1,71 @@
+package loki
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeQueryResponse(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		wantLines []string
+		wantErr   bool
+	}{
+		{
+			name: "streams",
+			data: `{"status":"success","data":{"resultType":"streams","result":[
+				{"stream":{"namespace":"e2e","pod":"a"},"values":[["1700000000000000002","line 2"],["1700000000000000001","line 1"]]},
+				{"stream":{"namespace":"e2e","pod":"b"},"values":[["1700000000000000000","line 0"]]}
+			]}}`,
+			wantLines: []string{"line 2", "line 1", "line 0"},
+		},
+		{
+			name: "no stream",
+			data: `{"status":"success","data":{"resultType":"streams","result":[]}}`,
+		},
+		{
+			name:    "metric query",
+			data:    `{"status":"success","data":{"resultType":"vector","result":[]}}`,
+			wantErr: true,
+		},
+		{
+			name:    "failed",
+			data:    `{"status":"error"}`,
+			wantErr: true,
+		},
+		{
+			name:    "not json",
+			data:    `parse error at line 1, col 2: syntax error`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := decodeQueryResponse([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeQueryResponse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := resp.Lines(); !reflect.DeepEqual(got, tt.wantLines) {
+				t.Errorf("Lines() = %v, want %v", got, tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestStructuredLines(t *testing.T) {
+	lines := []string{
+		`{"level":"info","msg":"request served","request_id":"abc"}`,
+		`{"level":"info","msg":"request served","request_id":"def"}`,
+		`request_id=abc msg="request served"`,
+		`["request_id","abc"]`,
+		`{"level":"info","request_id":1}`,
+	}
+	want := []string{`{"level":"info","msg":"request served","request_id":"abc"}`}
+	if got := StructuredLines(lines, "request_id", "abc"); !reflect.DeepEqual(got, want) {
+		t.Errorf("StructuredLines() = %v, want %v", got, want)
+	}
+}
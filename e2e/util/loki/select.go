@@ -0,0 +1,123 @@
+package loki
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+// lokiSelector selects the Services of Loki installed by its Helm chart.
+const lokiSelector = "app.kubernetes.io/name=loki"
+
+// queryComponents are the components of Loki which serve the query API, an empty one is the single binary.
+var queryComponents = []string{"", "single-binary", "read", "query-frontend"}
+
+// SelectLoki returns the Service of Loki which tests should query on the given port, which is a port number or name.
+// If the name is given, the Service with the name is returned. Otherwise, the only Service labeled
+// app.kubernetes.io/name=loki which serves the query API on the port is returned. Nil is returned if no Loki is
+// installed, and an error listing the candidates is returned if no single Service qualifies, so that the user can
+// pick one via the name. An empty namespace means that Services in all namespaces are considered.
+func SelectLoki(ctx context.Context, client clientset.Interface, namespace, name, port string) (*v1.Service, error) {
+	if name != "" {
+		if namespace == "" {
+			return nil, fmt.Errorf("the namespace of Loki Service %q must be specified", name)
+		}
+		svc, err := client.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error when getting Loki Service %s/%s: %w", namespace, name, err)
+		}
+		if !hasPort(svc, port) {
+			return nil, fmt.Errorf("Loki Service %s/%s doesn't expose port %s", namespace, name, port)
+		}
+		framework.Logf("Selected Loki Service %s/%s as configured", svc.Namespace, svc.Name)
+		return svc, nil
+	}
+
+	svcList, err := client.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{LabelSelector: lokiSelector})
+	if err != nil {
+		return nil, fmt.Errorf("error when listing Loki Services: %w", err)
+	}
+	if len(svcList.Items) == 0 {
+		return nil, nil
+	}
+	svc, err := selectLoki(svcList.Items, port)
+	if err != nil {
+		return nil, err
+	}
+	framework.Logf("Selected Loki Service %s/%s", svc.Namespace, svc.Name)
+	return svc, nil
+}
+
+// selectLoki returns the only Service which serves the query API on the given port.
+func selectLoki(services []v1.Service, port string) (*v1.Service, error) {
+	var qualified []*v1.Service
+	var candidates []string
+	for i := range services {
+		svc := &services[i]
+		candidate := svc.Namespace + "/" + svc.Name
+		if reason := unqualifiedReason(svc, port); reason != "" {
+			candidates = append(candidates, fmt.Sprintf("%s (%s)", candidate, reason))
+			continue
+		}
+		qualified = append(qualified, svc)
+		candidates = append(candidates, candidate)
+	}
+
+	switch len(qualified) {
+	case 1:
+		return qualified[0], nil
+	case 0:
+		return nil, fmt.Errorf("none of the Loki Services serves the query API on port %s, specify one via -ai.loki.name: %s",
+			port, strings.Join(candidates, ", "))
+	default:
+		return nil, fmt.Errorf("%d Loki Services qualify, specify one via -ai.loki.name: %s",
+			len(qualified), strings.Join(candidates, ", "))
+	}
+}
+
+// unqualifiedReason returns the reason why the Service can't be queried on the given port, or an empty string if
+// it can.
+func unqualifiedReason(svc *v1.Service, port string) string {
+	if svc.Spec.ClusterIP == v1.ClusterIPNone {
+		return "it's headless"
+	}
+	component := svc.Labels["app.kubernetes.io/component"]
+	if !isQueryComponent(component) {
+		return fmt.Sprintf("component %s doesn't serve the query API", component)
+	}
+	if !hasPort(svc, port) {
+		return fmt.Sprintf("it doesn't expose port %s", port)
+	}
+	return ""
+}
+
+func isQueryComponent(component string) bool {
+	for _, c := range queryComponents {
+		if c == component {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPort returns true if the Service exposes the port with the given number or name.
+func hasPort(svc *v1.Service, port string) bool {
+	number, err := strconv.Atoi(port)
+	for _, p := range svc.Spec.Ports {
+		if p.Name == port || (err == nil && int(p.Port) == number) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package loki
+
+import (
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newService(name, component, clusterIP string, ports ...v1.ServicePort) v1.Service {
+	svc := v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "loki", Name: name, Labels: map[string]string{"app.kubernetes.io/name": "loki"}},
+		Spec:       v1.ServiceSpec{ClusterIP: clusterIP, Ports: ports},
+	}
+	if component != "" {
+		svc.Labels["app.kubernetes.io/component"] = component
+	}
+	return svc
+}
+
+func TestSelectLoki(t *testing.T) {
+	httpMetrics := v1.ServicePort{Name: "http-metrics", Port: 3100}
+	grpc := v1.ServicePort{Name: "grpc", Port: 9095}
+	singleBinary := newService("loki", "", "10.0.0.1", httpMetrics, grpc)
+	singleBinaryHeadless := newService("loki-headless", "", v1.ClusterIPNone, httpMetrics)
+	read := newService("loki-read", "read", "10.0.0.2", httpMetrics, grpc)
+	write := newService("loki-write", "write", "10.0.0.3", httpMetrics, grpc)
+	gateway := newService("loki-gateway", "gateway", "10.0.0.4", v1.ServicePort{Name: "http-metrics", Port: 80})
+	memberlist := newService("loki-memberlist", "", v1.ClusterIPNone, v1.ServicePort{Name: "tcp", Port: 7946})
+
+	tests := []struct {
+		name        string
+		services    []v1.Service
+		port        string
+		want        string
+		wantErrText string
+	}{
+		{
+			name:     "single binary",
+			services: []v1.Service{singleBinary, singleBinaryHeadless, memberlist},
+			port:     "3100",
+			want:     "loki",
+		},
+		{
+			name:     "simple scalable",
+			services: []v1.Service{read, write, gateway, memberlist},
+			port:     "3100",
+			want:     "loki-read",
+		},
+		{
+			name:     "port by name",
+			services: []v1.Service{read, write},
+			port:     "http-metrics",
+			want:     "loki-read",
+		},
+		{
+			name:        "no query port",
+			services:    []v1.Service{singleBinary},
+			port:        "8080",
+			wantErrText: "loki/loki (it doesn't expose port 8080)",
+		},
+		{
+			name:        "only write path",
+			services:    []v1.Service{write},
+			port:        "3100",
+			wantErrText: "component write doesn't serve the query API",
+		},
+		{
+			name:        "multiple qualified",
+			services:    []v1.Service{singleBinary, read},
+			port:        "3100",
+			wantErrText: "2 Loki Services qualify",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectLoki(tt.services, tt.port)
+			if tt.wantErrText != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErrText) {
+					t.Fatalf("selectLoki() error = %v, want error containing %q", err, tt.wantErrText)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("selectLoki() unexpected error: %v", err)
+			}
+			if got.Name != tt.want {
+				t.Errorf("selectLoki() = %s, want %s", got.Name, tt.want)
+			}
+		})
+	}
+}
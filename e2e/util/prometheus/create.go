@@ -3,21 +3,65 @@ package prometheus
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"strings"
 
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	monitoring "github.com/prometheus-operator/prometheus-operator/pkg/client/versioned"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
 
 	"k8s.io/kubernetes/test/e2e/framework"
 )
 
-// CreateServiceMonitor creates a ServiceMonitor with the given namespace, name, matchLabels and port. If
-// the namespace selector is not nil, the namespace is patched with the servicemonitor namespace selector
-// of the prometheus instance. If the namespace selector is nil, the monitor namespace is set to the namespace
-// of the prometheus instance.
+// ServiceMonitorEndpoint is a port and a path of the Services which a ServiceMonitor scrapes.
+type ServiceMonitorEndpoint struct {
+	// Port is the name of the Service port.
+	Port string
+	// Path is the HTTP path of the metrics, e.g. /metrics.
+	Path string
+}
+
+// String returns the endpoint as <port>:<path>.
+func (e ServiceMonitorEndpoint) String() string {
+	return e.Port + ":" + e.Path
+}
+
+// ParseServiceMonitorEndpoints parses the comma-separated <port>[:<path>] endpoints, e.g.
+// http:/metrics,system:/system/metrics. The path defaults to /metrics.
+func ParseServiceMonitorEndpoints(s string) ([]ServiceMonitorEndpoint, error) {
+	var endpoints []ServiceMonitorEndpoint
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		port, path, ok := strings.Cut(entry, ":")
+		if !ok {
+			path = "/metrics"
+		}
+		if port == "" || !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("invalid endpoint %q, must be <port>[:<path>] with an absolute path", entry)
+		}
+		endpoints = append(endpoints, ServiceMonitorEndpoint{Port: port, Path: path})
+	}
+	return endpoints, nil
+}
+
+// CreateServiceMonitor creates a ServiceMonitor with the given namespace, name, matchLabels and port, which is
+// scraped at /metrics, see CreateServiceMonitorWithEndpoints.
 func CreateServiceMonitor(ctx context.Context, promOpClient monitoring.Interface, prom monitoringv1.Prometheus, client clientset.Interface, namespace, name string, matchLabels map[string]string, port string) *monitoringv1.ServiceMonitor {
+	return CreateServiceMonitorWithEndpoints(ctx, promOpClient, prom, client, namespace, name, matchLabels, []ServiceMonitorEndpoint{{Port: port, Path: "/metrics"}})
+}
+
+// CreateServiceMonitorWithEndpoints creates a ServiceMonitor with the given namespace, name, matchLabels and
+// endpoints. If the namespace selector is not nil, the namespace is patched with the servicemonitor namespace
+// selector of the prometheus instance. If the namespace selector is nil, the monitor namespace is set to the
+// namespace of the prometheus instance.
+func CreateServiceMonitorWithEndpoints(ctx context.Context, promOpClient monitoring.Interface, prom monitoringv1.Prometheus, client clientset.Interface, namespace, name string, matchLabels map[string]string, endpoints []ServiceMonitorEndpoint) *monitoringv1.ServiceMonitor {
 	labels, err := metav1.LabelSelectorAsMap(prom.Spec.ServiceMonitorSelector)
 	framework.ExpectNoError(err, "error when converting label selector to map")
 
@@ -27,16 +71,8 @@ func CreateServiceMonitor(ctx context.Context, promOpClient monitoring.Interface
 		nsLabels, err := metav1.LabelSelectorAsMap(smNamespaceSelector)
 		framework.ExpectNoError(err, "error when converting label selector to map")
 
-		if len(nsLabels) > 0 {
-			nsPatch, err := json.Marshal(map[string]interface{}{
-				"metadata": map[string]interface{}{
-					"labels": nsLabels,
-				},
-			})
-			framework.ExpectNoError(err, "error marshaling namespace patch")
-			_, err = client.CoreV1().Namespaces().Patch(ctx, namespace, types.StrategicMergePatchType, nsPatch, metav1.PatchOptions{})
-			framework.ExpectNoError(err, "error patching namespace")
-		}
+		err = labelNamespace(ctx, client, namespace, nsLabels)
+		framework.ExpectNoError(err, "error patching namespace")
 	} else {
 		smNamespace = prom.Namespace
 	}
@@ -53,17 +89,59 @@ func CreateServiceMonitor(ctx context.Context, promOpClient monitoring.Interface
 			Selector: metav1.LabelSelector{
 				MatchLabels: matchLabels,
 			},
-			Endpoints: []monitoringv1.Endpoint{
-				{
-					Port:     port,
-					Interval: "15s",
-					Path:     "/metrics",
-				},
-			},
 		},
 	}
+	for _, endpoint := range endpoints {
+		sm.Spec.Endpoints = append(sm.Spec.Endpoints, monitoringv1.Endpoint{
+			Port:     endpoint.Port,
+			Interval: "15s",
+			Path:     endpoint.Path,
+		})
+	}
 
 	sm, err = promOpClient.MonitoringV1().ServiceMonitors(smNamespace).Create(ctx, sm, metav1.CreateOptions{})
 	framework.ExpectNoError(err, "error when creating service monitor")
 	return sm
 }
+
+// labelNamespace adds the labels to the namespace. The patch is retried if the namespace is updated concurrently,
+// e.g. by other controllers, and it's skipped if the labels are already present.
+func labelNamespace(ctx context.Context, client clientset.Interface, namespace string, nsLabels map[string]string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		ns, err := client.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("error when getting namespace %s: %w", namespace, err)
+		}
+		nsPatch, err := namespaceLabelPatch(ns, nsLabels)
+		if err != nil || nsPatch == nil {
+			return err
+		}
+		_, err = client.CoreV1().Namespaces().Patch(ctx, namespace, types.StrategicMergePatchType, nsPatch, metav1.PatchOptions{})
+		return err
+	})
+}
+
+// namespaceLabelPatch returns the strategic merge patch which adds the labels missing on the namespace, or nil if
+// all of them are present. The patch carries the resource version of the namespace, so it's rejected with a
+// conflict if the namespace has been updated since.
+func namespaceLabelPatch(ns *v1.Namespace, nsLabels map[string]string) ([]byte, error) {
+	missing := map[string]string{}
+	for key, value := range nsLabels {
+		if current, ok := ns.Labels[key]; !ok || current != value {
+			missing[key] = value
+		}
+	}
+	if len(missing) == 0 {
+		return nil, nil
+	}
+	nsPatch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels":          missing,
+			"resourceVersion": ns.ResourceVersion,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error when marshaling the label patch of namespace %s: %w", ns.Name, err)
+	}
+	return nsPatch, nil
+}
@@ -0,0 +1,101 @@
+package prometheus
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNamespaceLabelPatch(t *testing.T) {
+	newNamespace := func(labels map[string]string) *v1.Namespace {
+		return &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test", ResourceVersion: "42", Labels: labels}}
+	}
+
+	tests := []struct {
+		name     string
+		ns       *v1.Namespace
+		nsLabels map[string]string
+		want     string
+	}{
+		{
+			name:     "missing labels",
+			ns:       newNamespace(map[string]string{"kubernetes.io/metadata.name": "test"}),
+			nsLabels: map[string]string{"monitoring": "enabled"},
+			want:     `{"metadata":{"labels":{"monitoring":"enabled"},"resourceVersion":"42"}}`,
+		},
+		{
+			name:     "only the missing or different labels",
+			ns:       newNamespace(map[string]string{"monitoring": "enabled", "team": "infra"}),
+			nsLabels: map[string]string{"monitoring": "enabled", "team": "ai", "tier": "gpu"},
+			want:     `{"metadata":{"labels":{"team":"ai","tier":"gpu"},"resourceVersion":"42"}}`,
+		},
+		{
+			name:     "labels already present",
+			ns:       newNamespace(map[string]string{"monitoring": "enabled", "team": "ai"}),
+			nsLabels: map[string]string{"monitoring": "enabled"},
+		},
+		{
+			name:     "namespace without labels",
+			ns:       newNamespace(nil),
+			nsLabels: map[string]string{"monitoring": "enabled"},
+			want:     `{"metadata":{"labels":{"monitoring":"enabled"},"resourceVersion":"42"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := namespaceLabelPatch(tt.ns, tt.nsLabels)
+			if err != nil {
+				t.Fatalf("namespaceLabelPatch() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("namespaceLabelPatch() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseServiceMonitorEndpoints(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    []ServiceMonitorEndpoint
+		wantErr bool
+	}{
+		{
+			name: "empty",
+			s:    "",
+		},
+		{
+			name: "endpoints",
+			s:    "http:/metrics, system:/system/metrics,model",
+			want: []ServiceMonitorEndpoint{
+				{Port: "http", Path: "/metrics"},
+				{Port: "system", Path: "/system/metrics"},
+				{Port: "model", Path: "/metrics"},
+			},
+		},
+		{
+			name:    "missing port",
+			s:       ":/metrics",
+			wantErr: true,
+		},
+		{
+			name:    "relative path",
+			s:       "http:metrics",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseServiceMonitorEndpoints(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseServiceMonitorEndpoints() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseServiceMonitorEndpoints() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package prometheus
+
+import "sort"
+
+// draAllocationQueries are the queries of the metrics of the DRA control plane which count the allocated
+// ResourceClaims by the names of the metrics, i.e. the ResourceClaims counted by the resource claim controller and
+// the ones in use on the nodes counted by the kubelet.
+var draAllocationQueries = map[string]string{
+	"resourceclaim_controller_resource_claims": `sum(resourceclaim_controller_resource_claims{allocated="true"})`,
+	"dra_resource_claims_in_use":               `sum(dra_resource_claims_in_use)`,
+}
+
+// DRAAllocationQueries returns the queries of the metrics which count the allocated ResourceClaims among the given
+// metric names, sorted.
+func DRAAllocationQueries(names []string) []string {
+	var queries []string
+	seen := map[string]bool{}
+	for _, name := range names {
+		if query, ok := draAllocationQueries[name]; ok && !seen[name] {
+			seen[name] = true
+			queries = append(queries, query)
+		}
+	}
+	sort.Strings(queries)
+	return queries
+}
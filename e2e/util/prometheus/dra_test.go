@@ -0,0 +1,31 @@
+package prometheus
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDRAAllocationQueries(t *testing.T) {
+	tests := []struct {
+		name  string
+		names []string
+		want  []string
+	}{
+		{
+			name:  "kubelet and controller",
+			names: []string{"resourceclaim_controller_resource_claims", "dra_operations_duration_seconds_bucket", "dra_resource_claims_in_use", "resourceclaim_controller_resource_claims"},
+			want:  []string{`sum(dra_resource_claims_in_use)`, `sum(resourceclaim_controller_resource_claims{allocated="true"})`},
+		},
+		{
+			name:  "no allocation metric",
+			names: []string{"dra_operations_duration_seconds_count", "resourceclaim_controller_creates_total"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DRAAllocationQueries(tt.names); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DRAAllocationQueries() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
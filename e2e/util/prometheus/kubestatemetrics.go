@@ -0,0 +1,20 @@
+package prometheus
+
+import (
+	"regexp"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// KubeStateMetricsSelector selects the Services of kube-state-metrics installed by its Helm chart or manifests.
+const KubeStateMetricsSelector = "app.kubernetes.io/name=kube-state-metrics"
+
+// invalidLabelValueChars matches the characters which kube-state-metrics replaces with underscores in the
+// resource label, e.g. of kube_node_status_allocatable.
+var invalidLabelValueChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// KubeStateMetricsResource returns the resource label of the series of kube-state-metrics for the resource, e.g.
+// nvidia_com_gpu for nvidia.com/gpu.
+func KubeStateMetricsResource(resourceName v1.ResourceName) string {
+	return invalidLabelValueChars.ReplaceAllString(string(resourceName), "_")
+}
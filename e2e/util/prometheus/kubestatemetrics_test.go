@@ -0,0 +1,24 @@
+package prometheus
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestKubeStateMetricsResource(t *testing.T) {
+	tests := []struct {
+		resourceName v1.ResourceName
+		want         string
+	}{
+		{resourceName: "nvidia.com/gpu", want: "nvidia_com_gpu"},
+		{resourceName: "amd.com/gpu", want: "amd_com_gpu"},
+		{resourceName: "nvidia.com/mig-1g.5gb", want: "nvidia_com_mig_1g_5gb"},
+		{resourceName: v1.ResourceCPU, want: "cpu"},
+	}
+	for _, tt := range tests {
+		if got := KubeStateMetricsResource(tt.resourceName); got != tt.want {
+			t.Errorf("KubeStateMetricsResource(%s) = %s, want %s", tt.resourceName, got, tt.want)
+		}
+	}
+}
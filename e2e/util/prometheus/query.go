@@ -0,0 +1,233 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	clientset "k8s.io/client-go/kubernetes"
+
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2econfig "k8s.io/kubernetes/test/e2e/framework/config"
+	e2eservice "k8s.io/kubernetes/test/e2e/framework/service"
+)
+
+var prometheusQuery struct {
+	Port       string `default:"http-web" usage:"port name or number of the Service of the Prometheus instance which serves the query API, e.g. web for the prometheus-operated Service"`
+	PathPrefix string `default:"" usage:"path prefix of the query API of the Prometheus instance, e.g. /prometheus. If unspecified, the route prefix of the Prometheus instance is used"`
+}
+var _ = e2econfig.AddOptions(&prometheusQuery, "ai.prometheus")
+
+// QueryResponse is the response of the Prometheus query API.
+// See https://prometheus.io/docs/prometheus/latest/querying/api/#format-overview
+type QueryResponse struct {
+	Status    string    `json:"status"`
+	Data      QueryData `json:"data"`
+	ErrorType string    `json:"errorType,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// QueryData is the data of a successful query.
+type QueryData struct {
+	ResultType string   `json:"resultType"`
+	Result     []Sample `json:"result"`
+}
+
+// Sample is a single series of a vector or matrix result.
+type Sample struct {
+	// Metric contains the labels of the series, including __name__ if it's not dropped by the query.
+	Metric map[string]string `json:"metric"`
+	// Value is set for the vector result of an instant query.
+	Value *SamplePair `json:"value,omitempty"`
+	// Values is set for the matrix result of a range query.
+	Values []SamplePair `json:"values,omitempty"`
+}
+
+// SamplePair is a value of a series at the given timestamp.
+type SamplePair struct {
+	Timestamp float64
+	Value     float64
+}
+
+// UnmarshalJSON decodes the [<unix_time>, "<sample_value>"] format of the query API.
+func (p *SamplePair) UnmarshalJSON(data []byte) error {
+	var raw []interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if len(raw) != 2 {
+		return fmt.Errorf("unexpected sample %s", string(data))
+	}
+	ts, ok := raw[0].(float64)
+	if !ok {
+		return fmt.Errorf("unexpected timestamp in sample %s", string(data))
+	}
+	str, ok := raw[1].(string)
+	if !ok {
+		return fmt.Errorf("unexpected value in sample %s", string(data))
+	}
+	value, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return fmt.Errorf("unexpected value in sample %s: %w", string(data), err)
+	}
+	p.Timestamp, p.Value = ts, value
+	return nil
+}
+
+// MetricNames returns the names of the series in the result.
+func (r *QueryResponse) MetricNames() []string {
+	var names []string
+	for _, sample := range r.Data.Result {
+		if name, ok := sample.Metric["__name__"]; ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// SeriesWithLabels returns the series in the result which carry all the given labels. An empty value only
+// requires the label to be present.
+func (r *QueryResponse) SeriesWithLabels(labels map[string]string) []Sample {
+	var matched []Sample
+	for _, sample := range r.Data.Result {
+		if hasLabels(sample.Metric, labels) {
+			matched = append(matched, sample)
+		}
+	}
+	return matched
+}
+
+// GroupByLabel groups the series in the result by the value of the first of the given labels which they carry,
+// e.g. pod and exported_pod. The series carrying none of the labels are dropped.
+func (r *QueryResponse) GroupByLabel(labels ...string) map[string][]Sample {
+	groups := map[string][]Sample{}
+	for _, sample := range r.Data.Result {
+		for _, label := range labels {
+			if value, ok := sample.Metric[label]; ok {
+				groups[value] = append(groups[value], sample)
+				break
+			}
+		}
+	}
+	return groups
+}
+
+// LabelValues returns the distinct values of the label of the series in the result, sorted. The series without the
+// label are ignored.
+func (r *QueryResponse) LabelValues(label string) []string {
+	seen := map[string]bool{}
+	var values []string
+	for _, sample := range r.Data.Result {
+		if value, ok := sample.Metric[label]; ok && !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+	sort.Strings(values)
+	return values
+}
+
+// MismatchedValues compares the series grouped by the first of the given labels which they carry, see GroupByLabel,
+// with the wanted values keyed by the label values, and returns the differences sorted by the label values. Every
+// series of a group must have the wanted value, and the groups which aren't wanted are ignored.
+func (r *QueryResponse) MismatchedValues(want map[string]float64, labels ...string) []string {
+	groups := r.GroupByLabel(labels...)
+	var mismatched []string
+	for key, value := range want {
+		samples, ok := groups[key]
+		if !ok {
+			mismatched = append(mismatched, fmt.Sprintf("%s: missing, want %g", key, value))
+			continue
+		}
+		for _, sample := range samples {
+			if sample.Value == nil {
+				mismatched = append(mismatched, fmt.Sprintf("%s: no value, want %g", key, value))
+			} else if sample.Value.Value != value {
+				mismatched = append(mismatched, fmt.Sprintf("%s: got %g, want %g", key, sample.Value.Value, value))
+			}
+		}
+	}
+	sort.Strings(mismatched)
+	return mismatched
+}
+
+func hasLabels(metric, labels map[string]string) bool {
+	for key, value := range labels {
+		actual, ok := metric[key]
+		if !ok || (value != "" && actual != value) {
+			return false
+		}
+	}
+	return true
+}
+
+// Query runs an instant query against the given Prometheus instance via the service proxy of the API server.
+func Query(ctx context.Context, client clientset.Interface, prom monitoringv1.Prometheus, query string) (*QueryResponse, error) {
+	return doQuery(ctx, client, prom, "/api/v1/query", map[string]string{"query": query})
+}
+
+// QueryRange runs a range query against the given Prometheus instance via the service proxy of the API server.
+// The result is a matrix whose samples are evaluated at every step between start and end.
+func QueryRange(ctx context.Context, client clientset.Interface, prom monitoringv1.Prometheus, query string, start, end time.Time, step time.Duration) (*QueryResponse, error) {
+	return doQuery(ctx, client, prom, "/api/v1/query_range", map[string]string{
+		"query": query,
+		"start": strconv.FormatInt(start.Unix(), 10),
+		"end":   strconv.FormatInt(end.Unix(), 10),
+		"step":  strconv.FormatFloat(step.Seconds(), 'f', -1, 64),
+	})
+}
+
+func doQuery(ctx context.Context, client clientset.Interface, prom monitoringv1.Prometheus, path string, params map[string]string) (*QueryResponse, error) {
+	data, err := doGet(ctx, client, prom, path, params)
+	if err != nil {
+		return nil, err
+	}
+	return decodeQueryResponse(data)
+}
+
+// doGet gets the given path of the API of the Prometheus instance via the service proxy of the API server.
+func doGet(ctx context.Context, client clientset.Interface, prom monitoringv1.Prometheus, path string, params map[string]string) ([]byte, error) {
+	proxyRequest, err := e2eservice.GetServicesProxyRequest(client, client.CoreV1().RESTClient().Get())
+	if err != nil {
+		return nil, err
+	}
+	req := proxyRequest.Namespace(prom.Namespace).
+		Name(fmt.Sprintf("%s:%s", prom.Name, prometheusQuery.Port)).
+		Suffix(queryPath(prom, path))
+	for key, value := range params {
+		req = req.Param(key, value)
+	}
+	framework.Logf("Query URL: %v", *req.URL())
+	data, err := req.DoRaw(ctx)
+	if err != nil {
+		return nil, err
+	}
+	framework.Logf("Query result: %s", string(data))
+	return data, nil
+}
+
+// queryPath returns the path of the query API under -ai.prometheus.pathPrefix, or the route prefix of the
+// Prometheus instance if it's unspecified.
+func queryPath(prom monitoringv1.Prometheus, path string) string {
+	prefix := prometheusQuery.PathPrefix
+	if prefix == "" {
+		prefix = prom.Spec.RoutePrefix
+	}
+	return strings.TrimSuffix(prefix, "/") + path
+}
+
+func decodeQueryResponse(data []byte) (*QueryResponse, error) {
+	resp := &QueryResponse{}
+	if err := json.Unmarshal(data, resp); err != nil {
+		return nil, fmt.Errorf("error when decoding query response %s: %w", string(data), err)
+	}
+	if resp.Status != "success" {
+		return nil, fmt.Errorf("query failed with %s: %s", resp.ErrorType, resp.Error)
+	}
+	return resp, nil
+}
@@ -0,0 +1,360 @@
+package prometheus
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+)
+
+func TestSamplePairUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    SamplePair
+		wantErr bool
+	}{
+		{
+			name: "integer value",
+			data: `[1435781451.781, "1"]`,
+			want: SamplePair{Timestamp: 1435781451.781, Value: 1},
+		},
+		{
+			name: "float value",
+			data: `[1435781451, "0.25"]`,
+			want: SamplePair{Timestamp: 1435781451, Value: 0.25},
+		},
+		{
+			name:    "not an array",
+			data:    `{"value": "1"}`,
+			wantErr: true,
+		},
+		{
+			name:    "too few elements",
+			data:    `[1435781451]`,
+			wantErr: true,
+		},
+		{
+			name:    "string timestamp",
+			data:    `["1435781451", "1"]`,
+			wantErr: true,
+		},
+		{
+			name:    "number value",
+			data:    `[1435781451, 1]`,
+			wantErr: true,
+		},
+		{
+			name:    "malformed value",
+			data:    `[1435781451, "one"]`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got SamplePair
+			err := json.Unmarshal([]byte(tt.data), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeQueryResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    *QueryResponse
+		wantErr bool
+	}{
+		{
+			name: "vector",
+			data: `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"__name__":"up","job":"prometheus"},"value":[1435781451.781,"1"]}]}}`,
+			want: &QueryResponse{
+				Status: "success",
+				Data: QueryData{
+					ResultType: "vector",
+					Result: []Sample{
+						{
+							Metric: map[string]string{"__name__": "up", "job": "prometheus"},
+							Value:  &SamplePair{Timestamp: 1435781451.781, Value: 1},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "matrix",
+			data: `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{},"values":[[1435781430,"1"],[1435781445,"2"]]}]}}`,
+			want: &QueryResponse{
+				Status: "success",
+				Data: QueryData{
+					ResultType: "matrix",
+					Result: []Sample{
+						{
+							Metric: map[string]string{},
+							Values: []SamplePair{{Timestamp: 1435781430, Value: 1}, {Timestamp: 1435781445, Value: 2}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "empty result",
+			data: `{"status":"success","data":{"resultType":"vector","result":[]}}`,
+			want: &QueryResponse{
+				Status: "success",
+				Data:   QueryData{ResultType: "vector", Result: []Sample{}},
+			},
+		},
+		{
+			name:    "failed query",
+			data:    `{"status":"error","errorType":"bad_data","error":"parse error"}`,
+			wantErr: true,
+		},
+		{
+			name:    "malformed sample",
+			data:    `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1435781451,1]}]}}`,
+			wantErr: true,
+		},
+		{
+			name:    "not json",
+			data:    `<html>502 Bad Gateway</html>`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeQueryResponse([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeQueryResponse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeQueryResponse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryResponseSeriesWithLabels(t *testing.T) {
+	resp := &QueryResponse{
+		Data: QueryData{
+			Result: []Sample{
+				{Metric: map[string]string{"__name__": "go_goroutines", "job": "vllm"}},
+				{Metric: map[string]string{"__name__": "vllm:num_requests_running", "job": "vllm", "model_name": "llama", "engine": "0"}},
+				{Metric: map[string]string{"__name__": "vllm:num_requests_waiting", "job": "vllm", "model_name": "qwen"}},
+			},
+		},
+	}
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   []string
+	}{
+		{
+			name:   "no labels",
+			labels: map[string]string{},
+			want:   []string{"go_goroutines", "vllm:num_requests_running", "vllm:num_requests_waiting"},
+		},
+		{
+			name:   "label value",
+			labels: map[string]string{"model_name": "llama"},
+			want:   []string{"vllm:num_requests_running"},
+		},
+		{
+			name:   "empty value only requires the label",
+			labels: map[string]string{"model_name": ""},
+			want:   []string{"vllm:num_requests_running", "vllm:num_requests_waiting"},
+		},
+		{
+			name:   "all labels are required",
+			labels: map[string]string{"model_name": "", "engine": ""},
+			want:   []string{"vllm:num_requests_running"},
+		},
+		{
+			name:   "no series matches",
+			labels: map[string]string{"model_name": "mistral"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched := &QueryResponse{Data: QueryData{Result: resp.SeriesWithLabels(tt.labels)}}
+			if got := matched.MetricNames(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SeriesWithLabels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryResponseGroupByLabel(t *testing.T) {
+	resp := &QueryResponse{
+		Data: QueryData{
+			Result: []Sample{
+				{Metric: map[string]string{"__name__": "DCGM_FI_DEV_GPU_UTIL", "gpu": "0", "pod": "workload-0"}},
+				{Metric: map[string]string{"__name__": "DCGM_FI_DEV_GPU_UTIL", "gpu": "0", "pod": "dcgm-exporter", "exported_pod": "workload-1"}},
+				{Metric: map[string]string{"__name__": "DCGM_FI_DEV_GPU_UTIL", "gpu": "1", "exported_pod": "workload-1"}},
+				{Metric: map[string]string{"__name__": "DCGM_FI_DEV_GPU_UTIL", "gpu": "2"}},
+			},
+		},
+	}
+	tests := []struct {
+		name   string
+		labels []string
+		want   map[string][]string
+	}{
+		{
+			name:   "first label wins",
+			labels: []string{"exported_pod", "pod"},
+			want:   map[string][]string{"workload-0": {"0"}, "workload-1": {"0", "1"}},
+		},
+		{
+			name:   "single label",
+			labels: []string{"pod"},
+			want:   map[string][]string{"workload-0": {"0"}, "dcgm-exporter": {"0"}},
+		},
+		{
+			name:   "no series carries the label",
+			labels: []string{"container"},
+			want:   map[string][]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := map[string][]string{}
+			for value, samples := range resp.GroupByLabel(tt.labels...) {
+				for _, sample := range samples {
+					got[value] = append(got[value], sample.Metric["gpu"])
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GroupByLabel() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryResponseLabelValues(t *testing.T) {
+	resp := &QueryResponse{
+		Data: QueryData{
+			Result: []Sample{
+				{Metric: map[string]string{"__name__": "DCGM_FI_DEV_GPU_UTIL", "gpu": "0", "UUID": "GPU-b"}},
+				{Metric: map[string]string{"__name__": "DCGM_FI_DEV_GPU_UTIL", "gpu": "1", "UUID": "GPU-a"}},
+				// A MIG device of the first GPU.
+				{Metric: map[string]string{"__name__": "DCGM_FI_DEV_GPU_UTIL", "gpu": "0", "UUID": "GPU-b", "GPU_I_ID": "1"}},
+				{Metric: map[string]string{"__name__": "DCGM_FI_DEV_GPU_UTIL", "gpu": "0"}},
+			},
+		},
+	}
+	tests := []struct {
+		name  string
+		label string
+		want  []string
+	}{
+		{
+			name:  "distinct values",
+			label: "UUID",
+			want:  []string{"GPU-a", "GPU-b"},
+		},
+		{
+			name:  "no series carries the label",
+			label: "container",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resp.LabelValues(tt.label); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LabelValues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryResponseMismatchedValues(t *testing.T) {
+	sample := func(value float64, labels ...string) Sample {
+		metric := map[string]string{"__name__": "kube_node_status_allocatable", "resource": "nvidia_com_gpu"}
+		for i := 0; i+1 < len(labels); i += 2 {
+			metric[labels[i]] = labels[i+1]
+		}
+		return Sample{Metric: metric, Value: &SamplePair{Timestamp: 1435781451, Value: value}}
+	}
+	resp := &QueryResponse{
+		Data: QueryData{
+			Result: []Sample{
+				sample(8, "node", "node-a"),
+				sample(4, "node", "node-b"),
+				// A replica of kube-state-metrics lagging behind.
+				sample(3, "node", "node-b"),
+				sample(2, "exported_node", "node-c", "node", "ignored"),
+				sample(1, "node", "unwanted"),
+			},
+		},
+	}
+	tests := []struct {
+		name string
+		want map[string]float64
+		diff []string
+	}{
+		{
+			name: "consistent",
+			want: map[string]float64{"node-a": 8, "node-c": 2},
+		},
+		{
+			name: "mismatched and missing",
+			want: map[string]float64{"node-a": 8, "node-b": 4, "node-d": 1},
+			diff: []string{"node-b: got 3, want 4", "node-d: missing, want 1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resp.MismatchedValues(tt.want, "exported_node", "node"); !reflect.DeepEqual(got, tt.diff) {
+				t.Errorf("MismatchedValues() = %q, want %q", got, tt.diff)
+			}
+		})
+	}
+}
+
+func TestQueryPath(t *testing.T) {
+	tests := []struct {
+		name        string
+		pathPrefix  string
+		routePrefix string
+		want        string
+	}{
+		{
+			name: "no prefix",
+			want: "/api/v1/query",
+		},
+		{
+			name:        "route prefix of the instance",
+			routePrefix: "/prometheus/",
+			want:        "/prometheus/api/v1/query",
+		},
+		{
+			name:        "configured prefix wins",
+			pathPrefix:  "/thanos",
+			routePrefix: "/prometheus",
+			want:        "/thanos/api/v1/query",
+		},
+		{
+			name:        "root route prefix",
+			routePrefix: "/",
+			want:        "/api/v1/query",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prometheusQuery.PathPrefix = tt.pathPrefix
+			defer func() { prometheusQuery.PathPrefix = "" }()
+			prom := monitoringv1.Prometheus{}
+			prom.Spec.RoutePrefix = tt.routePrefix
+			if got := queryPath(prom, "/api/v1/query"); got != tt.want {
+				t.Errorf("queryPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	monitoring "github.com/prometheus-operator/prometheus-operator/pkg/client/versioned"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	clientset "k8s.io/client-go/kubernetes"
+
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2econfig "k8s.io/kubernetes/test/e2e/framework/config"
+)
+
+var prometheusSelection struct {
+	PreferTestNamespace bool `default:"false" usage:"if true, the Prometheus instances in the test namespace of the specs which create ServiceMonitors are considered first, and the instances in all namespaces if none of them qualifies, e.g. for the tenant-isolated clusters whose Prometheus runs in the namespace of the workloads"`
+}
+var _ = e2econfig.AddOptions(&prometheusSelection, "ai.prometheus")
+
+// SelectPrometheus returns the Prometheus instance which tests should query. If the name is given, the
+// instance with the name is returned. Otherwise, if the monitor namespace is given, the instance which would
+// select a ServiceMonitor created by CreateServiceMonitor for the monitor namespace is returned. If the monitor
+// namespace is empty, i.e. the test doesn't create any ServiceMonitor, the only instance is returned. If
+// -ai.prometheus.preferTestNamespace is set, the instances in the monitor namespace are considered first.
+// An error listing the candidates is returned if no single instance qualifies, so that the user can pick
+// one via the name. An empty namespace means that instances in all namespaces are considered.
+func SelectPrometheus(ctx context.Context, promOpClient monitoring.Interface, client clientset.Interface, namespace, name, monitorNamespace string) (monitoringv1.Prometheus, error) {
+	promList, err := promOpClient.MonitoringV1().Prometheuses(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return monitoringv1.Prometheus{}, fmt.Errorf("error when getting Prometheus list: %w", err)
+	}
+	if len(promList.Items) == 0 {
+		return monitoringv1.Prometheus{}, fmt.Errorf("no Prometheus is found in namespace %q", namespace)
+	}
+
+	if name != "" {
+		for _, prom := range promList.Items {
+			if prom.Name == name {
+				framework.Logf("Selected Prometheus %s/%s as configured", prom.Namespace, prom.Name)
+				return prom, nil
+			}
+		}
+		return monitoringv1.Prometheus{}, fmt.Errorf("Prometheus %q is not found in namespace %q", name, namespace)
+	}
+
+	var ns *v1.Namespace
+	if monitorNamespace != "" {
+		ns, err = client.CoreV1().Namespaces().Get(ctx, monitorNamespace, metav1.GetOptions{})
+		if err != nil {
+			return monitoringv1.Prometheus{}, fmt.Errorf("error when getting namespace %s: %w", monitorNamespace, err)
+		}
+	}
+	if prometheusSelection.PreferTestNamespace && ns != nil {
+		return selectPrometheusByProximity(promList.Items, ns)
+	}
+	prom, err := selectPrometheus(promList.Items, ns)
+	if err != nil {
+		return monitoringv1.Prometheus{}, err
+	}
+	framework.Logf("Selected Prometheus %s/%s", prom.Namespace, prom.Name)
+	return prom, nil
+}
+
+// selectPrometheusByProximity returns the only instance in the given namespace which would select a ServiceMonitor
+// created by CreateServiceMonitor for the namespace, or the only one in all namespaces if none in the namespace
+// qualifies.
+func selectPrometheusByProximity(proms []monitoringv1.Prometheus, monitorNamespace *v1.Namespace) (monitoringv1.Prometheus, error) {
+	var local []monitoringv1.Prometheus
+	for _, prom := range proms {
+		if prom.Namespace == monitorNamespace.Name {
+			local = append(local, prom)
+		}
+	}
+	if len(local) > 0 {
+		prom, err := selectPrometheus(local, monitorNamespace)
+		if err == nil {
+			framework.Logf("Selected Prometheus %s/%s in the test namespace", prom.Namespace, prom.Name)
+			return prom, nil
+		}
+		framework.Logf("Falling back to the Prometheus instances in all namespaces: %v", err)
+	} else {
+		framework.Logf("No Prometheus instance is found in the test namespace %s, falling back to the instances in all namespaces", monitorNamespace.Name)
+	}
+	prom, err := selectPrometheus(proms, monitorNamespace)
+	if err != nil {
+		return monitoringv1.Prometheus{}, err
+	}
+	framework.Logf("Selected Prometheus %s/%s", prom.Namespace, prom.Name)
+	return prom, nil
+}
+
+// selectPrometheus returns the only instance which would select a ServiceMonitor created by CreateServiceMonitor
+// for the given namespace. If the namespace is nil, every instance qualifies.
+func selectPrometheus(proms []monitoringv1.Prometheus, monitorNamespace *v1.Namespace) (monitoringv1.Prometheus, error) {
+	var qualified []monitoringv1.Prometheus
+	var candidates []string
+	for _, prom := range proms {
+		candidate := prom.Namespace + "/" + prom.Name
+		if monitorNamespace != nil {
+			if reason := serviceMonitorUnselectedReason(prom, monitorNamespace); reason != "" {
+				candidates = append(candidates, fmt.Sprintf("%s (%s)", candidate, reason))
+				continue
+			}
+		}
+		qualified = append(qualified, prom)
+		candidates = append(candidates, candidate)
+	}
+
+	switch len(qualified) {
+	case 1:
+		return qualified[0], nil
+	case 0:
+		return monitoringv1.Prometheus{}, fmt.Errorf("none of the Prometheus instances would select the ServiceMonitors created in namespace %s, specify one via -ai.prometheus.name: %s",
+			monitorNamespace.Name, strings.Join(candidates, ", "))
+	default:
+		return monitoringv1.Prometheus{}, fmt.Errorf("%d Prometheus instances qualify, specify one via -ai.prometheus.name: %s",
+			len(qualified), strings.Join(candidates, ", "))
+	}
+}
+
+// serviceMonitorUnselectedReason returns the reason why a ServiceMonitor created by CreateServiceMonitor for the
+// given namespace would not be selected by the Prometheus instance, or an empty string if it would be selected.
+// It mirrors CreateServiceMonitor: the monitor is labeled after the ServiceMonitor selector of the instance, and
+// it's created in the given namespace labeled after the ServiceMonitor namespace selector of the instance, or in
+// the namespace of the instance if the instance has no ServiceMonitor namespace selector.
+func serviceMonitorUnselectedReason(prom monitoringv1.Prometheus, monitorNamespace *v1.Namespace) string {
+	if prom.Spec.ServiceMonitorSelector == nil {
+		return "it doesn't select any ServiceMonitor"
+	}
+	smLabels, err := metav1.LabelSelectorAsMap(prom.Spec.ServiceMonitorSelector)
+	if err != nil {
+		return fmt.Sprintf("its ServiceMonitor selector can't be converted to labels: %v", err)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(prom.Spec.ServiceMonitorSelector)
+	if err != nil {
+		return fmt.Sprintf("its ServiceMonitor selector is invalid: %v", err)
+	}
+	if !selector.Matches(labels.Set(smLabels)) {
+		return fmt.Sprintf("its ServiceMonitor selector %q doesn't match the labels %v", selector, smLabels)
+	}
+
+	if prom.Spec.ServiceMonitorNamespaceSelector == nil {
+		// The monitor is created in the namespace of the instance and selects the endpoints of the monitor
+		// namespace via its namespace selector, which the instance may be told to ignore.
+		if prom.Spec.IgnoreNamespaceSelectors && prom.Namespace != monitorNamespace.Name {
+			return fmt.Sprintf("it only selects ServiceMonitors in namespace %s and ignores their namespace selectors", prom.Namespace)
+		}
+		return ""
+	}
+	nsLabels, err := metav1.LabelSelectorAsMap(prom.Spec.ServiceMonitorNamespaceSelector)
+	if err != nil {
+		return fmt.Sprintf("its ServiceMonitor namespace selector can't be converted to labels: %v", err)
+	}
+	nsSelector, err := metav1.LabelSelectorAsSelector(prom.Spec.ServiceMonitorNamespaceSelector)
+	if err != nil {
+		return fmt.Sprintf("its ServiceMonitor namespace selector is invalid: %v", err)
+	}
+	if patched := labels.Merge(monitorNamespace.Labels, nsLabels); !nsSelector.Matches(patched) {
+		return fmt.Sprintf("its ServiceMonitor namespace selector %q doesn't match namespace %s with the labels %v", nsSelector, monitorNamespace.Name, patched)
+	}
+	return ""
+}
+
+// PrometheusForServiceMonitor returns the Prometheus instance which scrapes the created ServiceMonitor, i.e. whose
+// ServiceMonitor selector matches the labels of the monitor and whose ServiceMonitor namespace selector matches the
+// namespace of the monitor. The preferred instance, e.g. the one returned by SelectPrometheus, is returned if it
+// selects the monitor, otherwise the first of the selecting instances in all namespaces, so that the monitor picked
+// up by another instance in the clusters with multiple instances is still queried. An error listing the candidates
+// is returned if no instance selects the monitor.
+func PrometheusForServiceMonitor(ctx context.Context, promOpClient monitoring.Interface, client clientset.Interface, sm *monitoringv1.ServiceMonitor, preferred monitoringv1.Prometheus) (monitoringv1.Prometheus, error) {
+	if serviceMonitorSelectedReason(preferred, sm, nil) == "" {
+		return preferred, nil
+	}
+	promList, err := promOpClient.MonitoringV1().Prometheuses("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return monitoringv1.Prometheus{}, fmt.Errorf("error when getting Prometheus list: %w", err)
+	}
+	ns, err := client.CoreV1().Namespaces().Get(ctx, sm.Namespace, metav1.GetOptions{})
+	if err != nil {
+		return monitoringv1.Prometheus{}, fmt.Errorf("error when getting namespace %s: %w", sm.Namespace, err)
+	}
+	prom, err := prometheusForServiceMonitor(promList.Items, sm, ns, preferred)
+	if err != nil {
+		return monitoringv1.Prometheus{}, err
+	}
+	if prom.Namespace != preferred.Namespace || prom.Name != preferred.Name {
+		framework.Logf("ServiceMonitor %s/%s is selected by Prometheus %s/%s instead of %s/%s", sm.Namespace, sm.Name, prom.Namespace, prom.Name, preferred.Namespace, preferred.Name)
+	}
+	return prom, nil
+}
+
+// prometheusForServiceMonitor returns the preferred instance if it selects the ServiceMonitor in the given namespace,
+// otherwise the first of the instances which select it, sorted by namespace and name.
+func prometheusForServiceMonitor(proms []monitoringv1.Prometheus, sm *monitoringv1.ServiceMonitor, smNamespace *v1.Namespace, preferred monitoringv1.Prometheus) (monitoringv1.Prometheus, error) {
+	if serviceMonitorSelectedReason(preferred, sm, smNamespace) == "" {
+		return preferred, nil
+	}
+	proms = slices.Clone(proms)
+	slices.SortFunc(proms, func(a, b monitoringv1.Prometheus) int {
+		return strings.Compare(a.Namespace+"/"+a.Name, b.Namespace+"/"+b.Name)
+	})
+	var candidates []string
+	for _, prom := range proms {
+		reason := serviceMonitorSelectedReason(prom, sm, smNamespace)
+		if reason == "" {
+			return prom, nil
+		}
+		candidates = append(candidates, fmt.Sprintf("%s/%s (%s)", prom.Namespace, prom.Name, reason))
+	}
+	return monitoringv1.Prometheus{}, fmt.Errorf("none of the Prometheus instances selects ServiceMonitor %s/%s with the labels %v: %s",
+		sm.Namespace, sm.Name, sm.Labels, strings.Join(candidates, ", "))
+}
+
+// serviceMonitorSelectedReason returns the reason why the created ServiceMonitor is not selected by the Prometheus
+// instance, or an empty string if it's selected. If the namespace of the monitor is nil, only the instances which
+// select the monitors in their own namespace, i.e. without a ServiceMonitor namespace selector, can select it.
+func serviceMonitorSelectedReason(prom monitoringv1.Prometheus, sm *monitoringv1.ServiceMonitor, smNamespace *v1.Namespace) string {
+	if prom.Spec.ServiceMonitorSelector == nil {
+		return "it doesn't select any ServiceMonitor"
+	}
+	selector, err := metav1.LabelSelectorAsSelector(prom.Spec.ServiceMonitorSelector)
+	if err != nil {
+		return fmt.Sprintf("its ServiceMonitor selector is invalid: %v", err)
+	}
+	if !selector.Matches(labels.Set(sm.Labels)) {
+		return fmt.Sprintf("its ServiceMonitor selector %q doesn't match the labels of the monitor", selector)
+	}
+
+	if prom.Spec.ServiceMonitorNamespaceSelector == nil {
+		if prom.Namespace != sm.Namespace {
+			return fmt.Sprintf("it only selects ServiceMonitors in namespace %s", prom.Namespace)
+		}
+		return ""
+	}
+	if smNamespace == nil {
+		return "the labels of the namespace of the monitor are unknown"
+	}
+	nsSelector, err := metav1.LabelSelectorAsSelector(prom.Spec.ServiceMonitorNamespaceSelector)
+	if err != nil {
+		return fmt.Sprintf("its ServiceMonitor namespace selector is invalid: %v", err)
+	}
+	if !nsSelector.Matches(labels.Set(smNamespace.Labels)) {
+		return fmt.Sprintf("its ServiceMonitor namespace selector %q doesn't match namespace %s", nsSelector, smNamespace.Name)
+	}
+	return ""
+}
@@ -0,0 +1,282 @@
+package prometheus
+
+import (
+	"strings"
+	"testing"
+
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newPrometheus(namespace, name string, smSelector, smNamespaceSelector *metav1.LabelSelector) monitoringv1.Prometheus {
+	prom := monitoringv1.Prometheus{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	prom.Spec.ServiceMonitorSelector = smSelector
+	prom.Spec.ServiceMonitorNamespaceSelector = smNamespaceSelector
+	return prom
+}
+
+func TestServiceMonitorUnselectedReason(t *testing.T) {
+	testNamespace := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "e2e", Labels: map[string]string{"team": "ai"}}}
+	ignoreNamespaceSelectors := newPrometheus("monitoring", "k8s", &metav1.LabelSelector{}, nil)
+	ignoreNamespaceSelectors.Spec.IgnoreNamespaceSelectors = true
+
+	tests := []struct {
+		name       string
+		prom       monitoringv1.Prometheus
+		wantReason string
+	}{
+		{
+			name:       "no ServiceMonitor selector",
+			prom:       newPrometheus("monitoring", "k8s", nil, nil),
+			wantReason: "doesn't select any ServiceMonitor",
+		},
+		{
+			name: "ServiceMonitor selector by matchLabels",
+			prom: newPrometheus("monitoring", "k8s", &metav1.LabelSelector{MatchLabels: map[string]string{"release": "kps"}}, nil),
+		},
+		{
+			name: "ServiceMonitor selector by In with a single value",
+			prom: newPrometheus("monitoring", "k8s", &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "release", Operator: metav1.LabelSelectorOpIn, Values: []string{"kps"}},
+			}}, nil),
+		},
+		{
+			name: "ServiceMonitor selector by Exists",
+			prom: newPrometheus("monitoring", "k8s", &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "release", Operator: metav1.LabelSelectorOpExists},
+			}}, nil),
+			wantReason: "can't be converted to labels",
+		},
+		{
+			name: "contradicting ServiceMonitor selector",
+			prom: newPrometheus("monitoring", "k8s", &metav1.LabelSelector{
+				MatchLabels: map[string]string{"release": "a"},
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "release", Operator: metav1.LabelSelectorOpIn, Values: []string{"b"}},
+				},
+			}, nil),
+			wantReason: "doesn't match the labels",
+		},
+		{
+			name:       "namespace selectors are ignored",
+			prom:       ignoreNamespaceSelectors,
+			wantReason: "ignores their namespace selectors",
+		},
+		{
+			name: "all namespaces are selected",
+			prom: newPrometheus("monitoring", "k8s", &metav1.LabelSelector{}, &metav1.LabelSelector{}),
+		},
+		{
+			name: "namespace selector by matchLabels",
+			prom: newPrometheus("monitoring", "k8s", &metav1.LabelSelector{}, &metav1.LabelSelector{MatchLabels: map[string]string{"monitoring": "enabled"}}),
+		},
+		{
+			name: "namespace selector by NotIn",
+			prom: newPrometheus("monitoring", "k8s", &metav1.LabelSelector{}, &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "team", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"ai"}},
+			}}),
+			wantReason: "namespace selector can't be converted to labels",
+		},
+		{
+			name: "contradicting namespace selector",
+			prom: newPrometheus("monitoring", "k8s", &metav1.LabelSelector{}, &metav1.LabelSelector{
+				MatchLabels: map[string]string{"team": "ai"},
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "team", Operator: metav1.LabelSelectorOpIn, Values: []string{"ml"}},
+				},
+			}),
+			wantReason: "doesn't match namespace e2e",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason := serviceMonitorUnselectedReason(tt.prom, testNamespace)
+			if tt.wantReason == "" && reason != "" {
+				t.Errorf("serviceMonitorUnselectedReason() = %q, want the monitor to be selected", reason)
+			}
+			if tt.wantReason != "" && !strings.Contains(reason, tt.wantReason) {
+				t.Errorf("serviceMonitorUnselectedReason() = %q, want it to contain %q", reason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestSelectPrometheus(t *testing.T) {
+	testNamespace := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "e2e"}}
+	selecting := newPrometheus("monitoring", "selecting", &metav1.LabelSelector{}, &metav1.LabelSelector{})
+	alsoSelecting := newPrometheus("other", "also-selecting", &metav1.LabelSelector{}, &metav1.LabelSelector{})
+	notSelecting := newPrometheus("monitoring", "not-selecting", nil, nil)
+
+	tests := []struct {
+		name             string
+		proms            []monitoringv1.Prometheus
+		monitorNamespace *v1.Namespace
+		want             string
+		wantErr          string
+	}{
+		{
+			name:             "the only selecting instance is preferred",
+			proms:            []monitoringv1.Prometheus{notSelecting, selecting},
+			monitorNamespace: testNamespace,
+			want:             "selecting",
+		},
+		{
+			name:             "no instance selects the monitor",
+			proms:            []monitoringv1.Prometheus{notSelecting},
+			monitorNamespace: testNamespace,
+			wantErr:          "monitoring/not-selecting (it doesn't select any ServiceMonitor)",
+		},
+		{
+			name:             "multiple instances select the monitor",
+			proms:            []monitoringv1.Prometheus{selecting, alsoSelecting},
+			monitorNamespace: testNamespace,
+			wantErr:          "monitoring/selecting, other/also-selecting",
+		},
+		{
+			name:  "the only instance is used without monitors",
+			proms: []monitoringv1.Prometheus{notSelecting},
+			want:  "not-selecting",
+		},
+		{
+			name:    "multiple instances without monitors",
+			proms:   []monitoringv1.Prometheus{selecting, notSelecting},
+			wantErr: "2 Prometheus instances qualify",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prom, err := selectPrometheus(tt.proms, tt.monitorNamespace)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("selectPrometheus() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("selectPrometheus() unexpected error = %v", err)
+			}
+			if prom.Name != tt.want {
+				t.Errorf("selectPrometheus() = %s, want %s", prom.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectPrometheusByProximity(t *testing.T) {
+	testNamespace := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "e2e"}}
+	local := newPrometheus("e2e", "local", &metav1.LabelSelector{}, &metav1.LabelSelector{})
+	alsoLocal := newPrometheus("e2e", "also-local", &metav1.LabelSelector{}, &metav1.LabelSelector{})
+	localNotSelecting := newPrometheus("e2e", "local-not-selecting", nil, nil)
+	clusterWide := newPrometheus("monitoring", "cluster-wide", &metav1.LabelSelector{}, &metav1.LabelSelector{})
+
+	tests := []struct {
+		name    string
+		proms   []monitoringv1.Prometheus
+		want    string
+		wantErr string
+	}{
+		{
+			name:  "the instance in the test namespace is preferred",
+			proms: []monitoringv1.Prometheus{clusterWide, local},
+			want:  "local",
+		},
+		{
+			name:  "fall back to all namespaces if no instance is in the test namespace",
+			proms: []monitoringv1.Prometheus{clusterWide},
+			want:  "cluster-wide",
+		},
+		{
+			name:  "fall back to all namespaces if no instance in the test namespace qualifies",
+			proms: []monitoringv1.Prometheus{localNotSelecting, clusterWide},
+			want:  "cluster-wide",
+		},
+		{
+			name:    "multiple instances in the test namespace fall back to all namespaces",
+			proms:   []monitoringv1.Prometheus{local, alsoLocal, clusterWide},
+			wantErr: "3 Prometheus instances qualify",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prom, err := selectPrometheusByProximity(tt.proms, testNamespace)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("selectPrometheusByProximity() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("selectPrometheusByProximity() unexpected error = %v", err)
+			}
+			if prom.Name != tt.want {
+				t.Errorf("selectPrometheusByProximity() = %s, want %s", prom.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrometheusForServiceMonitor(t *testing.T) {
+	smNamespace := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "e2e", Labels: map[string]string{"monitoring": "true"}}}
+	sm := &monitoringv1.ServiceMonitor{ObjectMeta: metav1.ObjectMeta{Namespace: "e2e", Name: "workload", Labels: map[string]string{"release": "kube-prometheus"}}}
+	release := func(value string) *metav1.LabelSelector {
+		return &metav1.LabelSelector{MatchLabels: map[string]string{"release": value}}
+	}
+	monitoringNamespaces := &metav1.LabelSelector{MatchLabels: map[string]string{"monitoring": "true"}}
+	preferred := newPrometheus("monitoring", "default", release("default"), &metav1.LabelSelector{})
+	selecting := newPrometheus("monitoring", "kube-prometheus", release("kube-prometheus"), monitoringNamespaces)
+	alsoSelecting := newPrometheus("other", "kube-prometheus", release("kube-prometheus"), &metav1.LabelSelector{})
+	sameNamespace := newPrometheus("e2e", "tenant", &metav1.LabelSelector{}, nil)
+	otherNamespace := newPrometheus("monitoring", "own-namespace", &metav1.LabelSelector{}, nil)
+	notSelecting := newPrometheus("monitoring", "not-selecting", nil, nil)
+
+	tests := []struct {
+		name      string
+		proms     []monitoringv1.Prometheus
+		preferred monitoringv1.Prometheus
+		want      string
+		wantErr   string
+	}{
+		{
+			name:      "the preferred instance selects the monitor",
+			proms:     []monitoringv1.Prometheus{alsoSelecting, selecting},
+			preferred: selecting,
+			want:      "monitoring/kube-prometheus",
+		},
+		{
+			name:      "the first selecting instance",
+			proms:     []monitoringv1.Prometheus{alsoSelecting, preferred, selecting},
+			preferred: preferred,
+			want:      "monitoring/kube-prometheus",
+		},
+		{
+			name:      "an instance without namespace selector in the namespace of the monitor",
+			proms:     []monitoringv1.Prometheus{otherNamespace, sameNamespace, preferred},
+			preferred: preferred,
+			want:      "e2e/tenant",
+		},
+		{
+			name:      "no instance selects the monitor",
+			proms:     []monitoringv1.Prometheus{notSelecting, preferred, otherNamespace},
+			preferred: preferred,
+			wantErr:   "monitoring/not-selecting (it doesn't select any ServiceMonitor)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prom, err := prometheusForServiceMonitor(tt.proms, sm, smNamespace, tt.preferred)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("prometheusForServiceMonitor() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("prometheusForServiceMonitor() unexpected error = %v", err)
+			}
+			if got := prom.Namespace + "/" + prom.Name; got != tt.want {
+				t.Errorf("prometheusForServiceMonitor() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+// TargetsResponse is the response of the targets API of Prometheus.
+// See https://prometheus.io/docs/prometheus/latest/querying/api/#targets
+type TargetsResponse struct {
+	Status    string      `json:"status"`
+	Data      TargetsData `json:"data"`
+	ErrorType string      `json:"errorType"`
+	Error     string      `json:"error"`
+}
+
+// TargetsData is the data of a successful targets request.
+type TargetsData struct {
+	ActiveTargets []Target `json:"activeTargets"`
+}
+
+// Target is a scrape target of Prometheus.
+type Target struct {
+	// Labels are the labels of the target after relabeling, e.g. job and instance.
+	Labels map[string]string `json:"labels"`
+	// ScrapePool is the scrape pool of the target, e.g. serviceMonitor/<namespace>/<name>/<endpoint index>.
+	ScrapePool string `json:"scrapePool"`
+	// ScrapeURL is the URL the target is scraped from.
+	ScrapeURL string `json:"scrapeUrl"`
+	// Health is the health of the last scrape, i.e. up, down or unknown.
+	Health string `json:"health"`
+}
+
+// IsHTTPS returns true if the target is scraped over HTTPS.
+func (t *Target) IsHTTPS() bool {
+	return strings.HasPrefix(t.ScrapeURL, "https://")
+}
+
+// JobTargets returns the targets whose job label is one of the given jobs.
+func JobTargets(targets []Target, jobs []string) []Target {
+	var matched []Target
+	for _, target := range targets {
+		for _, job := range jobs {
+			if target.Labels["job"] == job {
+				matched = append(matched, target)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// UnscrapedEndpoints returns the <port>:<path> endpoints of the ServiceMonitor which have no healthy target of the
+// job among the targets, in the order of the endpoints. The targets of an endpoint are in the scrape pool
+// serviceMonitor/<namespace>/<name>/<endpoint index>.
+func UnscrapedEndpoints(targets []Target, sm *monitoringv1.ServiceMonitor, job string) []string {
+	var unscraped []string
+	for i, endpoint := range sm.Spec.Endpoints {
+		scrapePool := fmt.Sprintf("serviceMonitor/%s/%s/%d", sm.Namespace, sm.Name, i)
+		scraped := slices.ContainsFunc(targets, func(target Target) bool {
+			return target.ScrapePool == scrapePool && target.Labels["job"] == job && target.Health == "up"
+		})
+		if !scraped {
+			unscraped = append(unscraped, ServiceMonitorEndpoint{Port: endpoint.Port, Path: endpoint.Path}.String())
+		}
+	}
+	return unscraped
+}
+
+// ActiveTargets returns the active scrape targets of the given Prometheus instance via the service proxy of the API
+// server.
+func ActiveTargets(ctx context.Context, client clientset.Interface, prom monitoringv1.Prometheus) ([]Target, error) {
+	data, err := doGet(ctx, client, prom, "/api/v1/targets", map[string]string{"state": "active"})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := decodeTargetsResponse(data)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Data.ActiveTargets, nil
+}
+
+func decodeTargetsResponse(data []byte) (*TargetsResponse, error) {
+	resp := &TargetsResponse{}
+	if err := json.Unmarshal(data, resp); err != nil {
+		return nil, fmt.Errorf("error when decoding targets response %s: %w", string(data), err)
+	}
+	if resp.Status != "success" {
+		return nil, fmt.Errorf("targets request failed with %s: %s", resp.ErrorType, resp.Error)
+	}
+	return resp, nil
+}
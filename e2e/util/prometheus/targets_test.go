@@ -0,0 +1,117 @@
+package prometheus
+
+import (
+	"reflect"
+	"testing"
+
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDecodeTargetsResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []Target
+		wantErr bool
+	}{
+		{
+			name: "active targets",
+			data: `{"status":"success","data":{"activeTargets":[{"discoveredLabels":{"__address__":"10.0.0.1:9400"},` +
+				`"labels":{"instance":"10.0.0.1:9400","job":"nvidia-dcgm-exporter"},"scrapePool":"serviceMonitor/gpu-operator/nvidia-dcgm-exporter/0",` +
+				`"scrapeUrl":"https://10.0.0.1:9400/metrics","health":"up"}],"droppedTargets":[]}}`,
+			want: []Target{
+				{
+					Labels:     map[string]string{"instance": "10.0.0.1:9400", "job": "nvidia-dcgm-exporter"},
+					ScrapePool: "serviceMonitor/gpu-operator/nvidia-dcgm-exporter/0",
+					ScrapeURL:  "https://10.0.0.1:9400/metrics",
+					Health:     "up",
+				},
+			},
+		},
+		{
+			name:    "error",
+			data:    `{"status":"error","errorType":"bad_data","error":"invalid state"}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid json",
+			data:    `not json`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeTargetsResponse([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeTargetsResponse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got.Data.ActiveTargets, tt.want) {
+				t.Errorf("decodeTargetsResponse() = %+v, want %+v", got.Data.ActiveTargets, tt.want)
+			}
+		})
+	}
+}
+
+func TestJobTargets(t *testing.T) {
+	dcgm := Target{Labels: map[string]string{"job": "nvidia-dcgm-exporter"}, ScrapeURL: "http://10.0.0.1:9400/metrics"}
+	dcgmTLS := Target{Labels: map[string]string{"job": "nvidia-dcgm-exporter"}, ScrapeURL: "https://10.0.0.2:9400/metrics"}
+	node := Target{Labels: map[string]string{"job": "node-exporter"}, ScrapeURL: "https://10.0.0.1:9100/metrics"}
+	targets := []Target{dcgm, node, dcgmTLS}
+
+	got := JobTargets(targets, []string{"nvidia-dcgm-exporter"})
+	if want := []Target{dcgm, dcgmTLS}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("JobTargets() = %+v, want %+v", got, want)
+	}
+	if got[0].IsHTTPS() || !got[1].IsHTTPS() {
+		t.Errorf("IsHTTPS() of %s and %s = %v, %v, want false, true", got[0].ScrapeURL, got[1].ScrapeURL, got[0].IsHTTPS(), got[1].IsHTTPS())
+	}
+	if got := JobTargets(targets, nil); got != nil {
+		t.Errorf("JobTargets() without jobs = %+v, want nil", got)
+	}
+}
+
+func TestUnscrapedEndpoints(t *testing.T) {
+	sm := &monitoringv1.ServiceMonitor{ObjectMeta: metav1.ObjectMeta{Namespace: "ai", Name: "vllm"}}
+	sm.Spec.Endpoints = []monitoringv1.Endpoint{{Port: "http", Path: "/metrics"}, {Port: "system", Path: "/system/metrics"}}
+	newTarget := func(scrapePool, job, health string) Target {
+		return Target{Labels: map[string]string{"job": job}, ScrapePool: scrapePool, Health: health}
+	}
+	tests := []struct {
+		name    string
+		targets []Target
+		want    []string
+	}{
+		{
+			name: "all scraped",
+			targets: []Target{
+				newTarget("serviceMonitor/ai/vllm/0", "vllm", "up"),
+				newTarget("serviceMonitor/ai/vllm/1", "vllm", "down"),
+				newTarget("serviceMonitor/ai/vllm/1", "vllm", "up"),
+			},
+		},
+		{
+			name: "unhealthy endpoint",
+			targets: []Target{
+				newTarget("serviceMonitor/ai/vllm/0", "vllm", "up"),
+				newTarget("serviceMonitor/ai/vllm/1", "vllm", "down"),
+			},
+			want: []string{"system:/system/metrics"},
+		},
+		{
+			name: "targets of other jobs and monitors",
+			targets: []Target{
+				newTarget("serviceMonitor/ai/vllm/0", "router", "up"),
+				newTarget("serviceMonitor/ai/other/1", "vllm", "up"),
+			},
+			want: []string{"http:/metrics", "system:/system/metrics"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UnscrapedEndpoints(tt.targets, sm, "vllm"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UnscrapedEndpoints() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}